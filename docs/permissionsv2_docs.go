@@ -58,7 +58,7 @@ const docTemplatepermissionsv2 = `{
                     },
                     {
                         "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
+                        "description": "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'",
                         "name": "permissions",
                         "in": "query"
                     },
@@ -73,64 +73,62 @@ const docTemplatepermissionsv2 = `{
                         "description": "offset to be used in combination with limit",
                         "name": "offset",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "string"
-                            }
-                        }
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    {
+                        "type": "string",
+                        "description": "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset",
+                        "name": "cursor",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Unauthorized"
+                    {
+                        "type": "string",
+                        "description": "lists only elements with an id starting with the prefix",
+                        "name": "id_prefix",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
-                }
-            }
-        },
-        "/admin/load/permission-search": {
-            "post": {
-                "security": [
                     {
-                        "Bearer": []
-                    }
-                ],
-                "description": "load rights from permission-search, requesting user must have admin right",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "admin"
-                ],
-                "summary": "load rights from permission-search",
-                "parameters": [
+                        "type": "string",
+                        "description": "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters",
+                        "name": "id_regex",
+                        "in": "query"
+                    },
                     {
-                        "description": "load configuration",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.AdminLoadPermSearchRequest"
-                        }
+                        "type": "string",
+                        "description": "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "update count",
+                        "description": "OK",
                         "schema": {
-                            "type": "integer"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "link to the next page (rel next), if limit is set and the page is full"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "number of elements of the whole list; only set if total=true"
+                            }
                         }
                     },
                     "400": {
@@ -148,28 +146,57 @@ const docTemplatepermissionsv2 = `{
                 }
             }
         },
-        "/admin/resources/{topic}": {
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "lists resource ids in topic, requesting user must be in admin group",
+                "description": "lists recorded changes of resource permissions and topics, newest first, requesting user must be in admin group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "admin"
                 ],
-                "summary": "lists resource ids in topic",
+                "summary": "list audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic Id",
+                        "description": "filter by topic id",
                         "name": "topic",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by resource id",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by acting user id",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by operation (set_permission, remove_resource, set_topic, remove_topic, import, load_permission_search, expiry_sweep)",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "filter by timestamp \u003e= from (unix milliseconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "filter by timestamp \u003c until (unix milliseconds)",
+                        "name": "until",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
@@ -190,7 +217,7 @@ const docTemplatepermissionsv2 = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/model.AuditEntry"
                             }
                         }
                     },
@@ -209,48 +236,31 @@ const docTemplatepermissionsv2 = `{
                 }
             }
         },
-        "/admin/topics": {
+        "/admin/credentials": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "lists topics with their configuration, requesting user must be admin",
+                "description": "lists service credentials without keys, requesting user must be admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
-                ],
-                "summary": "lists topics with their configuration",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "limits size of result; 0 means unlimited",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "offset to be used in combination with limit",
-                        "name": "offset",
-                        "in": "query"
-                    }
+                    "credentials"
                 ],
+                "summary": "list service credentials",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.Topic"
+                                "$ref": "#/definitions/model.ServiceCredential"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
@@ -268,7 +278,7 @@ const docTemplatepermissionsv2 = `{
                         "Bearer": []
                     }
                 ],
-                "description": "set topic config, requesting user must be admin",
+                "description": "creates a named service credential, requesting user must be admin; the response contains the key, which can not be read later\nservices use the key with the Authorization header 'ApiKey \u003ckey\u003e'",
                 "consumes": [
                     "application/json"
                 ],
@@ -276,17 +286,17 @@ const docTemplatepermissionsv2 = `{
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
+                    "credentials"
                 ],
-                "summary": "set topic config",
+                "summary": "create service credential",
                 "parameters": [
                     {
-                        "description": "Topic",
+                        "description": "Credential",
                         "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Topic"
+                            "$ref": "#/definitions/model.ServiceCredential"
                         }
                     }
                 ],
@@ -294,13 +304,7 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Topic"
-                        }
-                    },
-                    "202": {
-                        "description": "Accepted",
-                        "schema": {
-                            "$ref": "#/definitions/model.Topic"
+                            "$ref": "#/definitions/model.ServiceCredentialWithKey"
                         }
                     },
                     "400": {
@@ -312,8 +316,8 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
+                    "409": {
+                        "description": "Conflict"
                     },
                     "500": {
                         "description": "Internal Server Error"
@@ -321,25 +325,25 @@ const docTemplatepermissionsv2 = `{
                 }
             }
         },
-        "/admin/topics/{id}": {
+        "/admin/credentials/{id}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "get topic config, requesting user must be admin",
+                "description": "get service credential without key, requesting user must be admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
+                    "credentials"
                 ],
-                "summary": "get topic config",
+                "summary": "get service credential",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic Id",
+                        "description": "Credential Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -349,7 +353,7 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Topic"
+                            "$ref": "#/definitions/model.ServiceCredential"
                         }
                     },
                     "400": {
@@ -375,7 +379,7 @@ const docTemplatepermissionsv2 = `{
                         "Bearer": []
                     }
                 ],
-                "description": "set topic config, requesting user must be admin",
+                "description": "updates description, topics, scopes and the disabled flag of a service credential, requesting user must be admin; the key stays valid",
                 "consumes": [
                     "application/json"
                 ],
@@ -383,24 +387,24 @@ const docTemplatepermissionsv2 = `{
                     "application/json"
                 ],
                 "tags": [
-                    "topics"
+                    "credentials"
                 ],
-                "summary": "set topic config",
+                "summary": "update service credential",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic Id",
+                        "description": "Credential Id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Topic",
+                        "description": "Credential",
                         "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.Topic"
+                            "$ref": "#/definitions/model.ServiceCredential"
                         }
                     }
                 ],
@@ -408,7 +412,7 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Topic"
+                            "$ref": "#/definitions/model.ServiceCredential"
                         }
                     },
                     "400": {
@@ -434,15 +438,15 @@ const docTemplatepermissionsv2 = `{
                         "Bearer": []
                     }
                 ],
-                "description": "remove topic config, requesting user must be admin",
+                "description": "revokes a service credential, requesting user must be admin",
                 "tags": [
-                    "topics"
+                    "credentials"
                 ],
-                "summary": "remove topic config",
+                "summary": "remove service credential",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic Id",
+                        "description": "Credential Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -461,60 +465,41 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/check/{topic}": {
-            "get": {
+        "/admin/credentials/{id}/key": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "check multiple permissions",
+                "description": "replaces the key of a service credential, requesting user must be admin; the previous key is invalid immediately",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "check"
+                    "credentials"
                 ],
-                "summary": "check multiple permissions",
+                "summary": "renew service credential key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Topic Id",
-                        "name": "topic",
+                        "description": "Credential Id",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Ids, comma seperated",
-                        "name": "ids",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
-                        "name": "permissions",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "boolean"
-                            }
+                            "$ref": "#/definitions/model.ServiceCredentialWithKey"
                         }
                     },
                     "400": {
@@ -523,54 +508,52 @@ const docTemplatepermissionsv2 = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/check/{topic}/{id}": {
-            "get": {
+        "/admin/load/permission-search": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "check permission",
+                "description": "load rights from permission-search, requesting user must have admin right",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "check"
+                    "admin"
                 ],
-                "summary": "check permission",
+                "summary": "load rights from permission-search",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "checked permissions in the form of 'rwxa', defaults to 'r'",
-                        "name": "permissions",
-                        "in": "query"
+                        "description": "load configuration",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AdminLoadPermSearchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "update count",
                         "schema": {
-                            "type": "boolean"
+                            "type": "integer"
                         }
                     },
                     "400": {
@@ -579,50 +562,78 @@ const docTemplatepermissionsv2 = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/export": {
+        "/admin/resources/{topic}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "export",
+                "description": "lists resource ids in topic, requesting user must be in admin group or a delegated admin of the topic",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "import/export"
+                    "admin"
                 ],
-                "summary": "export",
+                "summary": "lists resource ids in topic",
                 "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "default false; if true, export includes topic configurations",
-                        "name": "include_topic_config",
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "default false; if true, export includes resource permissions",
-                        "name": "include_permissions",
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "comma separated list of topics; export only topic-configs and resource-permissions with topics that are in this list",
-                        "name": "filter_topics",
+                        "description": "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "comma separated list of ids; export only resource-permissions for the given ids",
-                        "name": "filter_resource_id",
+                        "description": "lists only elements with an id starting with the prefix",
+                        "name": "id_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters",
+                        "name": "id_regex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor",
+                        "name": "total",
                         "in": "query"
                     }
                 ],
@@ -630,7 +641,20 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ImportExport"
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "link to the next page (rel next), if limit is set and the page is full"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "number of elements of the whole list; only set if total=true"
+                            }
                         }
                     },
                     "400": {
@@ -642,77 +666,81 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/health": {
+        "/admin/topics": {
             "get": {
-                "description": "checks health and reachability of the service",
-                "tags": [
-                    "health"
-                ],
-                "summary": "health check",
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            }
-        },
-        "/import": {
-            "put": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "import",
+                "description": "lists topics with their configuration, requesting user must be admin",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "import/export"
+                    "topics"
                 ],
-                "summary": "import",
+                "summary": "lists topics with their configuration",
                 "parameters": [
                     {
-                        "type": "boolean",
-                        "description": "default false; if true, import handles topic-configurations",
-                        "name": "include_topic_config",
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "default false; if true, import handles resource-permissions",
-                        "name": "include_permissions",
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "comma separated list of topics; import handles only the given topics; null-\u003eall; []-\u003enone",
-                        "name": "filter_topics",
+                        "description": "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "comma separated list of ids; import handles only the given resource-permissions ids; null-\u003eall; []-\u003enone",
-                        "name": "filter_resource_id",
+                        "description": "lists only elements with an id starting with the prefix",
+                        "name": "id_prefix",
                         "in": "query"
                     },
                     {
-                        "description": "import",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.ImportExport"
-                        }
+                        "type": "string",
+                        "description": "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters",
+                        "name": "id_regex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Topic"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "link to the next page (rel next), if limit is set and the page is full"
+                            }
+                        }
+                    },
                     "400": {
                         "description": "Bad Request"
                     },
@@ -722,59 +750,50 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
-                    "404": {
-                        "description": "Not Found"
-                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/manage/{topic}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "lists resources the user has admin rights to",
+                "description": "set topic config, requesting user must be admin",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "manage"
+                    "topics"
                 ],
-                "summary": "lists resources the user has admin rights to",
+                "summary": "set topic config",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "limits size of result; 0 means unlimited",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "offset to be used in combination with limit",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Topic",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Topic"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Resource"
-                            }
+                            "$ref": "#/definitions/model.Topic"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/model.Topic"
                         }
                     },
                     "400": {
@@ -786,38 +805,34 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/manage/{topic}/{id}": {
+        "/admin/topics/{id}": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "get resource, requesting user must have admin right  on the resource",
+                "description": "get topic config, requesting user must be admin or a delegated admin of the topic",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "manage"
+                    "topics"
                 ],
-                "summary": "get resource",
+                "summary": "get topic config",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -827,7 +842,7 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Resource"
+                            "$ref": "#/definitions/model.Topic"
                         }
                     },
                     "400": {
@@ -839,6 +854,9 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -850,7 +868,7 @@ const docTemplatepermissionsv2 = `{
                         "Bearer": []
                     }
                 ],
-                "description": "get resource rights, requesting user must have admin right on resource to update, requesting user must have admin rights on topic to create",
+                "description": "set topic config, requesting user must be admin or a delegated admin of the topic; only admins may create topics or change kafka settings",
                 "consumes": [
                     "application/json"
                 ],
@@ -858,37 +876,24 @@ const docTemplatepermissionsv2 = `{
                     "application/json"
                 ],
                 "tags": [
-                    "manage"
+                    "topics"
                 ],
-                "summary": "set resource rights",
+                "summary": "set topic config",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "boolean",
-                        "description": "if set to true, the response will be sent after the corresponding kafka done signal has been received",
-                        "name": "wait",
-                        "in": "query"
-                    },
                     {
                         "description": "Topic",
                         "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.ResourcePermissions"
+                            "$ref": "#/definitions/model.Topic"
                         }
                     }
                 ],
@@ -896,7 +901,7 @@ const docTemplatepermissionsv2 = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.ResourcePermissions"
+                            "$ref": "#/definitions/model.Topic"
                         }
                     },
                     "400": {
@@ -908,6 +913,9 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -919,22 +927,15 @@ const docTemplatepermissionsv2 = `{
                         "Bearer": []
                     }
                 ],
-                "description": "delete resource, requesting user must have admin right on the resource, topic must have NoCqrs=true",
+                "description": "remove topic config, requesting user must be admin",
                 "tags": [
-                    "manage"
+                    "topics"
                 ],
-                "summary": "delete resource",
+                "summary": "remove topic config",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -942,10 +943,7 @@ const docTemplatepermissionsv2 = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.Resource"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request"
@@ -956,257 +954,2568 @@ const docTemplatepermissionsv2 = `{
                     "403": {
                         "description": "Forbidden"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
             }
         },
-        "/permissions/{topic}": {
+        "/admin/webhooks": {
             "get": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "list the computed permissions to resources of the given topic and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "description": "lists webhook subscriptions without secrets, requesting user must be admin",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions"
-                ],
-                "summary": "list the computed permissions to resources of the given topic and ids",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Resource Ids, comma seperated",
-                        "name": "ids",
-                        "in": "query",
-                        "required": true
-                    }
+                    "webhooks"
                 ],
+                "summary": "list webhooks",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.ComputedPermissions"
+                                "$ref": "#/definitions/model.Webhook"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
-                    },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/query/permissions/{topic}": {
+            },
             "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "list the computed permissions to resources of the given topic and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "description": "creates a webhook subscription, requesting user must be admin; the id is generated if not set",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "permissions"
+                    "webhooks"
                 ],
-                "summary": "list the computed permissions to resources of the given topic and ids",
+                "summary": "create webhook",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Topic Id",
-                        "name": "topic",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Resource Ids",
-                        "name": "ids",
+                        "description": "Webhook",
+                        "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "array",
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get webhook subscription without secret, requesting user must be admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "get webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "creates or updates a webhook subscription, requesting user must be admin; an empty secret keeps the stored secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "set webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "removes a webhook subscription and its deliveries, requesting user must be admin",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "remove webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "lists the deliveries of a webhook with status and last error, newest first, requesting user must be admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "list webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by status (pending, delivered, failed)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
                             "items": {
-                                "type": "string"
+                                "$ref": "#/definitions/model.WebhookDelivery"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries/{delivery}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "schedules a new delivery attempt of a failed delivery, requesting user must be admin",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "retry webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery Id",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/changes/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "streams the permission changes of resources in the topic as server-sent events; each \"change\" event has the resume token as id and a model.ChangeEvent as data.\nadmins of the topic receive all changes; other users receive changes of resources they hold the requested permissions on, without permission details.\nreconnects continue after the Last-Event-ID header or the resume_token query parameter; unknown tokens are rejected with 410 (the client should reload its state and reconnect without token).\nstream errors are sent as \"error\" event and end the stream.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "watch permission changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only changes of resources with these permissions (e.g. rwx); default: a",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "evaluation of multiple permissions: all (default) or any",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "continue after the event with this token",
+                        "name": "resume_token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "continue after the event with this token; overrides resume_token",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ChangeEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "410": {
+                        "description": "Gone"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/check": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "checks multiple permissions of resources of multiple topics with one request; each query is checked like GET /check/{topic}. the result maps topic ids to the access maps of the queries; if multiple queries of the same topic contain an id, all of them must grant access",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "check permissions of multiple topics",
+                "parameters": [
+                    {
+                        "description": "Queries",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CheckQuery"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "object",
+                                "additionalProperties": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/check/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check multiple permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "check multiple permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "boolean"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/check/{topic}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "check permission",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "check permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "boolean"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/explain/{topic}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "explains the result of GET /check/{topic}/{id}: for each permission the decision and all rules (admin, topic default, user, group or role entries) that contributed to it; admins may explain the permissions of other users by setting user, roles and/or groups",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "check"
+                ],
+                "summary": "explain permission check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'",
+                        "name": "permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "admin only: explain for this user id instead of the requesting user",
+                        "name": "user",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "admin only: explain for these roles (comma seperated) instead of the roles of the requesting user",
+                        "name": "roles",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "admin only: explain for these groups (comma seperated) instead of the groups of the requesting user",
+                        "name": "groups",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Explanation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "export",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import/export"
+                ],
+                "summary": "export",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "default false; if true, export includes topic configurations",
+                        "name": "include_topic_config",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "default false; if true, export includes resource permissions",
+                        "name": "include_permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of topics; export only topic-configs and resource-permissions with topics that are in this list",
+                        "name": "filter_topics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of ids; export only resource-permissions for the given ids",
+                        "name": "filter_resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportExport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "checks health and reachability of the service",
+                "tags": [
+                    "health"
+                ],
+                "summary": "health check",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/import": {
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "import",
+                "tags": [
+                    "import/export"
+                ],
+                "summary": "import",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "default false; if true, import handles topic-configurations",
+                        "name": "include_topic_config",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "default false; if true, import handles resource-permissions",
+                        "name": "include_permissions",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of topics; import handles only the given topics; null-\u003eall; []-\u003enone",
+                        "name": "filter_topics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated list of ids; import handles only the given resource-permissions ids; null-\u003eall; []-\u003enone",
+                        "name": "filter_resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "description": "import",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ImportExport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/manage/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "lists resources the user has admin rights to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "lists resources the user has admin rights to",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lists only elements with an id starting with the prefix",
+                        "name": "id_prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters",
+                        "name": "id_regex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Resource"
+                            }
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "link to the next page (rel next), if limit is set and the page is full"
+                            },
+                            "X-Total-Count": {
+                                "type": "integer",
+                                "description": "number of elements of the whole list; only set if total=true"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "sets the rights of many resources of the topic with one request; each item is checked like a set of the rights of a single resource. valid items are stored together and published to kafka in batches. the response contains one result per item, in the order of the request, with the status code of the item and, if the item is rejected by the sharing policy of the topic, its violations",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "set rights of many resources",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Items",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.BulkPermissionsItem"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.BulkPermissionsResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/manage/{topic}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource, requesting user must have admin right on the resource or be a delegated admin of the topic",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "get resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Resource"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "get resource rights, requesting user must have admin right on resource to update, requesting user must have admin rights on topic or be allowed by the creation policy of the topic to create; creators become admin of the new resource. users who are no admins may only grant permissions allowed by the sharing policy of the topic; violations are described by the 403 response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "set resource rights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "if set to true, the response will be sent after the corresponding kafka done signal has been received",
+                        "name": "wait",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "expected version; alternative to If-Match",
+                        "name": "expected_version",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Topic",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "new version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.SharingPolicyError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "delete resource, requesting user must have admin right on the resource, topic must have NoCqrs=true",
+                "tags": [
+                    "manage"
+                ],
+                "summary": "delete resource",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "expected version; alternative to If-Match",
+                        "name": "expected_version",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Resource"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "adds, changes or removes individual user, group or role entries of the rights of an existing resource; a null entry removes the user, group or role; unlisted entries are unchanged. the result is checked like a set of the rights: requesting user must have admin right on the resource, added groups must contain the requesting user and added users must share a group with the requesting user and the sharing policy of the topic must allow the grants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "patch resource rights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "expected version; alternative to If-Match",
+                        "name": "expected_version",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Patch",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PermissionsPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "new version of the resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.SharingPolicyError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "412": {
+                        "description": "Precondition Failed"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/manage/{topic}/{id}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "lists the stored permission versions of a resource, newest first; requesting user must have admin right on the resource or topic",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "list permission versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limits size of result; 0 means unlimited",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "offset to be used in combination with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.PermissionsVersion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/manage/{topic}/{id}/versions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "returns the changes between two permission versions of a resource; requesting user must have admin right on the resource or topic",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "diff permission versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.PermissionsDiff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/manage/{topic}/{id}/versions/{version}/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "sets the permissions of a resource to a stored version; the rollback is checked and published like a set of the permissions and is stored as a new version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "manage"
+                ],
+                "summary": "rollback permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/model.SharingPolicyError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/permissions/{topic}": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "list the computed permissions to resources of the given topic and ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource Ids, comma seperated",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/query/permissions/{topic}": {
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "list the computed permissions to resources of the given topic and ids, group and user permissions are merged, unknown ids will get entries in the result",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "permissions"
+                ],
+                "summary": "list the computed permissions to resources of the given topic and ids",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Topic Id",
+                        "name": "topic",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resource Ids",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ComputedPermissions"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.AdminLoadPermSearchRequest": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "true -\u003e log changes without executing them",
+                    "type": "boolean"
+                },
+                "overwrite_existing": {
+                    "description": "false -\u003e skip known elements; true -\u003e force state of permission-search",
+                    "type": "boolean"
+                },
+                "permission_search_url": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "description": "topic as used in permissions-v2",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "new_permissions": {
+                    "description": "nil if the resource was removed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    ]
+                },
+                "new_topic": {
+                    "description": "nil if the topic was removed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Topic"
+                        }
+                    ]
+                },
+                "operation": {
+                    "$ref": "#/definitions/model.AuditOperation"
+                },
+                "previous_permissions": {
+                    "description": "nil if the resource did not exist",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    ]
+                },
+                "previous_topic": {
+                    "description": "nil if the topic did not exist",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Topic"
+                        }
+                    ]
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "unix milliseconds",
+                    "type": "integer"
+                },
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "acting user; empty for changes by the service itself (e.g. expiry sweep)",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AuditOperation": {
+            "type": "string",
+            "enum": [
+                "set_permission",
+                "patch_permission",
+                "bulk_set_permission",
+                "remove_resource",
+                "set_topic",
+                "remove_topic",
+                "import",
+                "load_permission_search",
+                "expiry_sweep",
+                "rollback",
+                "inherited_change"
+            ],
+            "x-enum-varnames": [
+                "AuditOperationSetPermission",
+                "AuditOperationPatchPermission",
+                "AuditOperationBulkSetPermission",
+                "AuditOperationRemoveResource",
+                "AuditOperationSetTopic",
+                "AuditOperationRemoveTopic",
+                "AuditOperationImport",
+                "AuditOperationLoadPermissionSearch",
+                "AuditOperationExpirySweep",
+                "AuditOperationRollback",
+                "AuditOperationInheritedChange"
+            ]
+        },
+        "model.BulkPermissionsItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "$ref": "#/definitions/model.ResourcePermissions"
+                }
+            }
+        },
+        "model.BulkPermissionsResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "version of the stored resource on success",
+                    "type": "integer"
+                },
+                "violations": {
+                    "description": "set if the item violates the sharing policy of the topic",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SharingPolicyViolation"
+                    }
+                }
+            }
+        },
+        "model.ChangeEvent": {
+            "type": "object",
+            "properties": {
+                "access_lost": {
+                    "description": "true if the subscriber had the subscribed permissions before this change and lost them by this change;\nonly set for subscribers without admin rights on the topic, the event contains no details",
+                    "type": "boolean"
+                },
+                "deleted": {
+                    "description": "true if the resource was removed by this change; Permissions is empty",
+                    "type": "boolean"
+                },
+                "effective_permissions": {
+                    "description": "set if permissions are inherited from a parent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "operation": {
+                    "$ref": "#/definitions/model.AuditOperation"
+                },
+                "permissions": {
+                    "$ref": "#/definitions/model.ResourcePermissions"
+                },
+                "timestamp": {
+                    "description": "unix milliseconds",
+                    "type": "integer"
+                },
+                "token": {
+                    "description": "resume token; a subscription started with this token continues after this event",
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "acting user; empty for changes by the service itself (e.g. expiry sweep)",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CheckQuery": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "mode": {
+                    "description": "optional; PermissionModeAll (default) or PermissionModeAny",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PermissionMode"
+                        }
+                    ]
+                },
+                "permissions": {
+                    "description": "in the form of 'rwxa' and letters of custom permissions declared by the topic; defaults to 'r'",
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ComputedPermissions": {
+            "type": "object",
+            "properties": {
+                "administrate": {
+                    "type": "boolean"
+                },
+                "custom": {
+                    "description": "custom permissions declared by the topic; key is the permission letter",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "execute": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "valid_from": {
+                    "description": "optional; the entry is ignored before this time",
+                    "type": "string"
+                },
+                "valid_until": {
+                    "description": "optional; the entry is ignored from this time on and will be removed",
+                    "type": "string"
+                },
+                "write": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.CustomPermission": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "letter": {
+                    "description": "single ascii letter, other than r, w, x and a; e.g. \"s\"",
+                    "type": "string"
+                }
+            }
+        },
+        "model.ExplainSubject": {
+            "type": "object",
+            "properties": {
+                "group_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.Explanation": {
+            "type": "object",
+            "properties": {
+                "access": {
+                    "description": "result of the permission check of all permissions together; equal to CheckPermission",
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PermissionExplanation"
+                    }
+                },
+                "subject": {
+                    "$ref": "#/definitions/model.ExplainSubject"
+                },
+                "topic_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ExplanationRule": {
+            "type": "object",
+            "properties": {
+                "effect": {
+                    "description": "RuleEffectGrant or RuleEffectDeny",
+                    "type": "string"
+                },
+                "inherited": {
+                    "description": "the entry is inherited from the parent of the resource",
+                    "type": "boolean"
+                },
+                "kind": {
+                    "description": "RuleKindUser, RuleKindGroup or RuleKindRole; empty for RuleSourceAdmin",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "RuleSourceAdmin, RuleSourceTopicDefault or RuleSourceResource",
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "user, group or role id of the matching entry",
+                    "type": "string"
+                },
+                "valid_from": {
+                    "type": "string"
+                },
+                "valid_until": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ImportExport": {
+            "type": "object",
+            "properties": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Resource"
+                    }
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Topic"
+                    }
+                }
+            }
+        },
+        "model.ParentChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/model.ResourceReference"
+                },
+                "before": {
+                    "$ref": "#/definitions/model.ResourceReference"
+                }
+            }
+        },
+        "model.PermissionExplanation": {
+            "type": "object",
+            "properties": {
+                "granted": {
+                    "description": "result of a check of this permission alone; Access may be false although all permissions are granted alone, if they are granted by different sources (topic defaults and resource)",
+                    "type": "boolean"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "rules": {
+                    "description": "all currently active rules matching the subject; empty if no rule grants the permission",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ExplanationRule"
+                    }
+                }
+            }
+        },
+        "model.PermissionMode": {
+            "type": "string",
+            "enum": [
+                "all",
+                "any"
+            ],
+            "x-enum-comments": {
+                "PermissionModeAll": "all permissions must be granted; default",
+                "PermissionModeAny": "at least one of the permissions must be granted"
+            },
+            "x-enum-varnames": [
+                "PermissionModeAll",
+                "PermissionModeAny"
+            ]
+        },
+        "model.PermissionsChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "$ref": "#/definitions/model.PermissionsMap"
+                },
+                "before": {
+                    "$ref": "#/definitions/model.PermissionsMap"
+                },
+                "field": {
+                    "description": "json name of the map; e.g. \"user_permissions\" or \"group_denies\"",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "user, group or role id",
+                    "type": "string"
+                }
+            }
+        },
+        "model.PermissionsDiff": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PermissionsChange"
+                    }
+                },
+                "from": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent": {
+                    "description": "nil if the parent is unchanged",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ParentChange"
+                        }
+                    ]
+                },
+                "to": {
+                    "type": "integer"
+                },
+                "topic_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.PermissionsMap": {
+            "type": "object",
+            "properties": {
+                "administrate": {
+                    "type": "boolean"
+                },
+                "custom": {
+                    "description": "custom permissions declared by the topic; key is the permission letter",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "execute": {
+                    "type": "boolean"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "valid_from": {
+                    "description": "optional; the entry is ignored before this time",
+                    "type": "string"
+                },
+                "valid_until": {
+                    "description": "optional; the entry is ignored from this time on and will be removed",
+                    "type": "string"
+                },
+                "write": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.PermissionsPatch": {
+            "type": "object",
+            "properties": {
+                "group_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "group_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "role_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "role_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                }
+            }
+        },
+        "model.PermissionsVersion": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "true if the resource was removed by this change; Permissions is empty",
+                    "type": "boolean"
+                },
+                "effective_permissions": {
+                    "description": "set if permissions are inherited from a parent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string"
+                },
+                "operation": {
+                    "$ref": "#/definitions/model.AuditOperation"
+                },
+                "permissions": {
+                    "$ref": "#/definitions/model.ResourcePermissions"
+                },
+                "timestamp": {
+                    "description": "unix milliseconds",
+                    "type": "integer"
+                },
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "acting user; empty for changes by the service itself (e.g. expiry sweep)",
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Resource": {
+            "type": "object",
+            "properties": {
+                "creator": {
+                    "description": "user who created the resource; stored on creation and kept on updates; empty for resources created before it was recorded",
+                    "type": "string"
+                },
+                "effective_permissions": {
+                    "description": "set by the database if permissions are inherited from a parent; ignored on updates",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
+                        }
+                    ]
+                },
+                "group_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "group_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "parent": {
+                    "description": "optional; the resource inherits the permissions of the parent; own entries override inherited entries of the same user, group or role\nnil is replaced by the currently stored parent on updates by non-admin users; an empty reference removes the parent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourceReference"
+                        }
+                    ]
+                },
+                "role_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "role_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_denies": {
+                    "description": "denies override grants of the same ResourcePermissions; a true flag in a PermissionsMap denies the permission\ndenies in topic default permissions override the topic default grants and all grants of resources in the topic\nnil deny maps are replaced by the currently stored denies on updates by non-admin users",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "version": {
+                    "description": "set by the database; incremented on every change of the resource permissions; ignored on updates (use WriteOptions)",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ResourceCreationPolicy": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.ComputedPermissions"
-                            }
+                },
+                "initial_permissions": {
+                    "description": "optional; entries added to new resources, if the creator does not set an entry for the same user, group or role",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourcePermissions"
                         }
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "401": {
-                        "description": "Unauthorized"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.AdminLoadPermSearchRequest": {
+        },
+        "model.ResourcePermissions": {
             "type": "object",
             "properties": {
-                "dry_run": {
-                    "description": "true -\u003e log changes without executing them",
-                    "type": "boolean"
+                "group_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
                 },
-                "overwrite_existing": {
-                    "description": "false -\u003e skip known elements; true -\u003e force state of permission-search",
-                    "type": "boolean"
+                "group_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
                 },
-                "permission_search_url": {
-                    "type": "string"
+                "parent": {
+                    "description": "optional; the resource inherits the permissions of the parent; own entries override inherited entries of the same user, group or role\nnil is replaced by the currently stored parent on updates by non-admin users; an empty reference removes the parent",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourceReference"
+                        }
+                    ]
                 },
-                "token": {
+                "role_denies": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "role_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_denies": {
+                    "description": "denies override grants of the same ResourcePermissions; a true flag in a PermissionsMap denies the permission\ndenies in topic default permissions override the topic default grants and all grants of resources in the topic\nnil deny maps are replaced by the currently stored denies on updates by non-admin users",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                },
+                "user_permissions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.PermissionsMap"
+                    }
+                }
+            }
+        },
+        "model.ResourceReference": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "string"
                 },
                 "topic_id": {
-                    "description": "topic as used in permissions-v2",
                     "type": "string"
                 }
             }
         },
-        "model.ComputedPermissions": {
+        "model.ServiceCredential": {
             "type": "object",
             "properties": {
-                "administrate": {
-                    "type": "boolean"
+                "created_at": {
+                    "description": "unix milliseconds; set by the service",
+                    "type": "integer"
                 },
-                "execute": {
+                "description": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "requests with disabled credentials are rejected",
                     "type": "boolean"
                 },
                 "id": {
+                    "description": "name of the service; ascii letters, digits, '_' and '-'",
                     "type": "string"
                 },
-                "read": {
-                    "type": "boolean"
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ServiceCredentialScope"
+                    }
                 },
-                "write": {
-                    "type": "boolean"
+                "topic_ids": {
+                    "description": "optional; empty -\u003e all topics; operations without topic (e.g. list topics) need access to all topics",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "model.ImportExport": {
+        "model.ServiceCredentialScope": {
+            "type": "string",
+            "enum": [
+                "check",
+                "manage",
+                "topic_admin"
+            ],
+            "x-enum-comments": {
+                "ServiceCredentialScopeCheck": "permission checks and reads of resource permissions, history and change feed",
+                "ServiceCredentialScopeManage": "changes of resource permissions; includes check",
+                "ServiceCredentialScopeTopicAdmin": "reads and changes of topics"
+            },
+            "x-enum-varnames": [
+                "ServiceCredentialScopeCheck",
+                "ServiceCredentialScopeManage",
+                "ServiceCredentialScopeTopicAdmin"
+            ]
+        },
+        "model.ServiceCredentialWithKey": {
             "type": "object",
             "properties": {
-                "permissions": {
+                "created_at": {
+                    "description": "unix milliseconds; set by the service",
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "description": "requests with disabled credentials are rejected",
+                    "type": "boolean"
+                },
+                "id": {
+                    "description": "name of the service; ascii letters, digits, '_' and '-'",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "use with ServiceCredentialAuthPrefix as Authorization header",
+                    "type": "string"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Resource"
+                        "$ref": "#/definitions/model.ServiceCredentialScope"
                     }
                 },
-                "topics": {
+                "topic_ids": {
+                    "description": "optional; empty -\u003e all topics; operations without topic (e.g. list topics) need access to all topics",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Topic"
+                        "type": "string"
                     }
                 }
             }
         },
-        "model.PermissionsMap": {
+        "model.SharingPolicy": {
             "type": "object",
             "properties": {
-                "administrate": {
+                "allow_admin_delegation": {
+                    "description": "the administrate permission may be granted",
                     "type": "boolean"
                 },
-                "execute": {
+                "allow_groups": {
+                    "description": "groups, which have no entry yet, may be added",
                     "type": "boolean"
                 },
-                "read": {
-                    "type": "boolean"
+                "allowed_roles": {
+                    "description": "roles, which may be granted permissions; empty: no role",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "write": {
-                    "type": "boolean"
+                "max_group_permissions": {
+                    "$ref": "#/definitions/model.PermissionsMap"
+                },
+                "max_role_permissions": {
+                    "$ref": "#/definitions/model.PermissionsMap"
+                },
+                "max_user_permissions": {
+                    "description": "optional; upper limits of the permissions, which may be granted to users, groups or roles; nil means no limit\nadministrate is not limited by these maps but by AllowAdminDelegation",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.PermissionsMap"
+                        }
+                    ]
                 }
             }
         },
-        "model.Resource": {
+        "model.SharingPolicyError": {
             "type": "object",
             "properties": {
-                "group_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
-                },
-                "id": {
-                    "type": "string"
-                },
-                "role_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
-                },
-                "topic_id": {
+                "error": {
                     "type": "string"
                 },
-                "user_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.SharingPolicyViolation"
                     }
                 }
             }
         },
-        "model.ResourcePermissions": {
+        "model.SharingPolicyViolation": {
             "type": "object",
             "properties": {
-                "group_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "kind": {
+                    "description": "RuleKindUser, RuleKindGroup or RuleKindRole",
+                    "type": "string"
                 },
-                "role_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "permissions": {
+                    "description": "rejected permissions; e.g. \"wa\"",
+                    "type": "string"
                 },
-                "user_permissions": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/model.PermissionsMap"
-                    }
+                "rule": {
+                    "description": "SharingRuleMaxPermissions, SharingRuleAllowedRoles, SharingRuleAllowGroups or SharingRuleAllowAdminDelegation",
+                    "type": "string"
+                },
+                "subject": {
+                    "description": "user, group or role id of the entry",
+                    "type": "string"
                 }
             }
         },
         "model.Topic": {
             "type": "object",
             "properties": {
+                "admin_groups": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "admin_roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "admin_users": {
+                    "description": "optional delegated admins; they may manage the topic config (except kafka settings), list all resources of the topic,\nread, set and remove them and export/import the topic, without being platform admins",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "creation_policy": {
+                    "description": "optional; allows users, who are no admins, to create new resources",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ResourceCreationPolicy"
+                        }
+                    ]
+                },
+                "custom_permissions": {
+                    "description": "additional permissions, usable besides r, w, x and a",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CustomPermission"
+                    }
+                },
                 "default_permissions": {
                     "$ref": "#/definitions/model.ResourcePermissions"
                 },
@@ -1225,6 +3534,145 @@ const docTemplatepermissionsv2 = `{
                 },
                 "publish_to_kafka_topic": {
                     "type": "string"
+                },
+                "sharing_policy": {
+                    "description": "optional; limits the permissions, users who are no admins may grant",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SharingPolicy"
+                        }
+                    ]
+                }
+            }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "disabled": {
+                    "description": "no new deliveries are created for disabled webhooks",
+                    "type": "boolean"
+                },
+                "events": {
+                    "description": "optional; empty -\u003e all events",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookEventType"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "key of the callback signatures; write only; an empty secret keeps the stored secret on updates",
+                    "type": "string"
+                },
+                "topic_ids": {
+                    "description": "optional; empty -\u003e all topics",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "unix milliseconds",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_attempt": {
+                    "description": "unix milliseconds",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_status_code": {
+                    "description": "http status code of the last attempt; 0 if no response was received",
+                    "type": "integer"
+                },
+                "next_attempt": {
+                    "description": "unix milliseconds; next attempt of pending deliveries",
+                    "type": "integer"
+                },
+                "payload": {
+                    "$ref": "#/definitions/model.WebhookPayload"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.WebhookDeliveryStatus"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "WebhookDeliveryFailed": "no further attempts; may be retried manually"
+            },
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliveryDelivered",
+                "WebhookDeliveryFailed"
+            ]
+        },
+        "model.WebhookEventType": {
+            "type": "string",
+            "enum": [
+                "set_resource",
+                "remove_resource",
+                "set_topic",
+                "remove_topic"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventSetResource",
+                "WebhookEventRemoveResource",
+                "WebhookEventSetTopic",
+                "WebhookEventRemoveTopic"
+            ]
+        },
+        "model.WebhookPayload": {
+            "type": "object",
+            "properties": {
+                "event": {
+                    "$ref": "#/definitions/model.WebhookEventType"
+                },
+                "permissions": {
+                    "$ref": "#/definitions/model.ResourcePermissions"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "unix milliseconds of the change",
+                    "type": "integer"
+                },
+                "topic": {
+                    "$ref": "#/definitions/model.Topic"
+                },
+                "topic_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "acting user; empty for changes by the service itself",
+                    "type": "string"
                 }
             }
         }
@@ -19,6 +19,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
@@ -33,14 +34,21 @@ type AdminEndpoints struct{}
 
 // AdminListResourceIds godoc
 // @Summary      lists resource ids in topic
-// @Description  lists resource ids in topic, requesting user must be in admin group
+// @Description  lists resource ids in topic, requesting user must be in admin group or a delegated admin of the topic
 // @Tags         admin
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        limit query integer false "limits size of result; 0 means unlimited"
 // @Param        offset query integer false "offset to be used in combination with limit"
+// @Param        cursor query string false "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset"
+// @Param        id_prefix query string false "lists only elements with an id starting with the prefix"
+// @Param        id_regex query string false "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters"
+// @Param        sort query string false "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id"
+// @Param        total query boolean false "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor"
 // @Produce      json
 // @Success      200 {array}  string
+// @Header       200 {string}  Link "link to the next page (rel next), if limit is set and the page is full"
+// @Header       200 {integer} X-Total-Count "number of elements of the whole list; only set if total=true"
 // @Failure      400
 // @Failure      401
 // @Failure      403
@@ -64,6 +72,56 @@ func (this *TopicsEndpoints) AdminListResourceIds(config configuration.Config, r
 			http.Error(w, err.Error(), code)
 			return
 		}
+		if req.URL.Query().Get("total") == "true" {
+			total, err, code := ctrl.AdminCountResourceIdsContext(req.Context(), token, topic, listOptions)
+			if err != nil {
+				http.Error(w, err.Error(), code)
+				return
+			}
+			w.Header().Set(model.TotalCountHeader, strconv.FormatInt(total, 10))
+		}
+		setNextPageLink(w, req, listOptions, result, func(id string) string { return id })
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// ListAuditEntries godoc
+// @Summary      list audit log
+// @Description  lists recorded changes of resource permissions and topics, newest first, requesting user must be in admin group
+// @Tags         admin
+// @Security Bearer
+// @Param        topic query string false "filter by topic id"
+// @Param        resource query string false "filter by resource id"
+// @Param        user query string false "filter by acting user id"
+// @Param        operation query string false "filter by operation (set_permission, remove_resource, set_topic, remove_topic, import, load_permission_search, expiry_sweep)"
+// @Param        from query integer false "filter by timestamp >= from (unix milliseconds)"
+// @Param        until query integer false "filter by timestamp < until (unix milliseconds)"
+// @Param        limit query integer false "limits size of result; 0 means unlimited"
+// @Param        offset query integer false "offset to be used in combination with limit"
+// @Produce      json
+// @Success      200 {array}  model.AuditEntry
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/audit [get]
+func (this *AdminEndpoints) ListAuditEntries(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/audit", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		options, err := model.AuditListOptionsFromQuery(req.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.ListAuditEntriesContext(req.Context(), token, options)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(result)
 		if err != nil {
@@ -24,9 +24,11 @@ import (
 	"net/http"
 	"reflect"
 	"runtime/debug"
+	"strconv"
 
 	"github.com/SENERGY-Platform/gin-middleware/otelx"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/api/util"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/auth"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/SENERGY-Platform/service-commons/pkg/accesslog"
@@ -84,8 +86,15 @@ func GetRouter(config configuration.Config, command Controller) http.Handler {
 		log.Fatal("FATAL: failed to setup OpenTelemetry: ", err)
 	}
 
-	handler = accesslog.New(util.NewVersionCheck(util.NewCors(otel), model.ClientVersion))
-	return handler
+	verifier, err := auth.New(config)
+	if err != nil {
+		log.Fatal("FATAL: invalid token verification config: ", err)
+	}
+
+	handler = util.NewVersionCheck(util.NewCors(util.NewTokenVerification(otel, verifier)), model.ClientVersion)
+
+	//the access log does not support flushing, which is needed by the change feed
+	return util.NewEventStreamBypass(accesslog.New(handler), handler)
 }
 
 func GetRouterWithoutMiddleware(config configuration.Config, command Controller) http.Handler {
@@ -120,3 +129,23 @@ func getTypeName(t reflect.Type) (res string) {
 	}
 	return t.Name()
 }
+
+// setNextPageLink sets a Link header with the url of the next page, if the page is full.
+// the link is relative to the request url and contains only the query, to stay valid behind proxies that strip path prefixes.
+func setNextPageLink[T any](w http.ResponseWriter, req *http.Request, options model.ListOptions, page []T, id func(element T) string) {
+	if len(page) == 0 {
+		return
+	}
+	next, ok := options.NextPage(len(page), id(page[len(page)-1]))
+	if !ok {
+		return
+	}
+	query := req.URL.Query()
+	if next.Cursor != "" {
+		query.Del("offset")
+		query.Set("cursor", next.Cursor)
+	} else {
+		query.Set("offset", strconv.FormatInt(next.Offset, 10))
+	}
+	w.Header().Set("Link", fmt.Sprintf("<?%v>; rel=\"next\"", query.Encode()))
+}
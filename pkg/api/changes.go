@@ -0,0 +1,166 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func init() {
+	endpoints = append(endpoints, &ChangeFeedEndpoints{})
+}
+
+type ChangeFeedEndpoints struct{}
+
+// WatchPermissionChanges godoc
+// @Summary      watch permission changes
+// @Description  streams the permission changes of resources in the topic as server-sent events; each "change" event has the resume token as id and a model.ChangeEvent as data.
+// @Description  admins of the topic receive all changes; other users receive changes of resources they hold the requested permissions on, without permission details.
+// @Description  reconnects continue after the Last-Event-ID header or the resume_token query parameter; unknown tokens are rejected with 410 (the client should reload its state and reconnect without token).
+// @Description  stream errors are sent as "error" event and end the stream.
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        permissions query string false "only changes of resources with these permissions (e.g. rwx); default: a"
+// @Param        mode query string false "evaluation of multiple permissions: all (default) or any"
+// @Param        resume_token query string false "continue after the event with this token"
+// @Param        Last-Event-ID header string false "continue after the event with this token; overrides resume_token"
+// @Produce      text/event-stream
+// @Success      200 {object} model.ChangeEvent
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      410
+// @Failure      500
+// @Router       /changes/{topic} [get]
+func (this *ChangeFeedEndpoints) WatchPermissionChanges(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /changes/{topic}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+
+		query := req.URL.Query()
+		options := model.ChangeFeedOptions{
+			ResumeToken: query.Get("resume_token"),
+			Mode:        model.PermissionMode(query.Get("mode")),
+		}
+		if lastEventId := req.Header.Get("Last-Event-ID"); lastEventId != "" {
+			options.ResumeToken = lastEventId
+		}
+		if permissionsStr := query.Get("permissions"); permissionsStr != "" {
+			var err error
+			options.Permissions, err = model.PermissionListFromString(permissionsStr)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		events, err, code := ctrl.WatchPermissionChangesContext(ctx, token, topic, options)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no") //disables response buffering of nginx proxies
+		w.WriteHeader(http.StatusOK)
+		stream := &eventStream{w: w, rc: http.NewResponseController(w)}
+		err = stream.write(": connected\n\n")
+		if errors.Is(err, http.ErrNotSupported) {
+			config.GetLogger().ErrorContext(req.Context(), "change feed needs a response writer with flush support")
+		}
+		if err != nil {
+			return
+		}
+
+		wg := sync.WaitGroup{}
+		defer wg.Wait()
+		defer cancel()
+		if heartbeat := config.ChangeFeedHeartbeat.GetDuration(); heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(heartbeat)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if stream.write(": keep-alive\n\n") != nil {
+							cancel()
+							return
+						}
+					}
+				}
+			}()
+		}
+
+		for event, err := range events {
+			if err != nil {
+				config.GetLogger().WarnContext(req.Context(), "change feed stream error", "error", err, "topic", topic)
+				_ = stream.write(fmt.Sprintf("event: error\ndata: %v\n\n", strings.ReplaceAll(err.Error(), "\n", " ")))
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				config.GetLogger().ErrorContext(req.Context(), "unable to encode change event", "error", err)
+				return
+			}
+			err = stream.write(fmt.Sprintf("id: %v\nevent: change\ndata: %v\n\n", event.Token, string(data)))
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+// eventStream serializes the writes of server-sent events and keep-alive comments
+type eventStream struct {
+	mux sync.Mutex
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+}
+
+func (this *eventStream) write(message string) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	_, err := this.w.Write([]byte(message))
+	if err != nil {
+		return err
+	}
+	return this.rc.Flush()
+}
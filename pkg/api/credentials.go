@@ -0,0 +1,246 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func init() {
+	endpoints = append(endpoints, &CredentialEndpoints{})
+}
+
+type CredentialEndpoints struct{}
+
+// ListServiceCredentials godoc
+// @Summary      list service credentials
+// @Description  lists service credentials without keys, requesting user must be admin
+// @Tags         credentials
+// @Security Bearer
+// @Produce      json
+// @Success      200 {array}  model.ServiceCredential
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/credentials [get]
+func (this *CredentialEndpoints) ListServiceCredentials(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/credentials", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		result, err, code := ctrl.ListServiceCredentialsContext(req.Context(), token)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// GetServiceCredential godoc
+// @Summary      get service credential
+// @Description  get service credential without key, requesting user must be admin
+// @Tags         credentials
+// @Security Bearer
+// @Param        id path string true "Credential Id"
+// @Produce      json
+// @Success      200 {object}  model.ServiceCredential
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/credentials/{id} [get]
+func (this *CredentialEndpoints) GetServiceCredential(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/credentials/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.GetServiceCredentialContext(req.Context(), token, id)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// CreateServiceCredential godoc
+// @Summary      create service credential
+// @Description  creates a named service credential, requesting user must be admin; the response contains the key, which can not be read later
+// @Description  services use the key with the Authorization header 'ApiKey <key>'
+// @Tags         credentials
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        message body model.ServiceCredential true "Credential"
+// @Success      200 {object}  model.ServiceCredentialWithKey
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      409
+// @Failure      500
+// @Router       /admin/credentials [post]
+func (this *CredentialEndpoints) CreateServiceCredential(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /admin/credentials", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		credential := model.ServiceCredential{}
+		err := json.NewDecoder(req.Body).Decode(&credential)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.CreateServiceCredentialContext(req.Context(), token, credential)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// UpdateServiceCredential godoc
+// @Summary      update service credential
+// @Description  updates description, topics, scopes and the disabled flag of a service credential, requesting user must be admin; the key stays valid
+// @Tags         credentials
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "Credential Id"
+// @Param        message body model.ServiceCredential true "Credential"
+// @Success      200 {object}  model.ServiceCredential
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/credentials/{id} [put]
+func (this *CredentialEndpoints) UpdateServiceCredential(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("PUT /admin/credentials/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		credential := model.ServiceCredential{}
+		err := json.NewDecoder(req.Body).Decode(&credential)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if credential.Id == "" {
+			credential.Id = id
+		}
+		if credential.Id != id {
+			http.Error(w, "credential id mismatch", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.UpdateServiceCredentialContext(req.Context(), token, credential)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// RenewServiceCredentialKey godoc
+// @Summary      renew service credential key
+// @Description  replaces the key of a service credential, requesting user must be admin; the previous key is invalid immediately
+// @Tags         credentials
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "Credential Id"
+// @Success      200 {object}  model.ServiceCredentialWithKey
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/credentials/{id}/key [post]
+func (this *CredentialEndpoints) RenewServiceCredentialKey(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /admin/credentials/{id}/key", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.RenewServiceCredentialKeyContext(req.Context(), token, id)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// DeleteServiceCredential godoc
+// @Summary      remove service credential
+// @Description  revokes a service credential, requesting user must be admin
+// @Tags         credentials
+// @Security Bearer
+// @Param        id path string true "Credential Id"
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/credentials/{id} [delete]
+func (this *CredentialEndpoints) DeleteServiceCredential(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("DELETE /admin/credentials/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		err, code := ctrl.RemoveServiceCredentialContext(req.Context(), token, id)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -18,6 +18,7 @@ package api
 
 import (
 	"context"
+	"iter"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 )
@@ -39,6 +40,8 @@ type AdminInterface interface {
 	SetTopicContext(ctx context.Context, token string, topic model.Topic) (result model.Topic, err error, code int)
 	AdminListResourceIds(tokenStr string, topicId string, options model.ListOptions) (ids []string, err error, code int)
 	AdminListResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (ids []string, err error, code int)
+	AdminCountResourceIds(tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int)
+	AdminCountResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int)
 
 	// AdminLoadFromPermissionSearch is not supported by the client
 	// because this request should never be automated
@@ -49,6 +52,48 @@ type AdminInterface interface {
 	ExportContext(ctx context.Context, token string, options model.ImportExportOptions) (result model.ImportExport, err error, code int)
 	Import(token string, importModel model.ImportExport, options model.ImportExportOptions) (err error, code int)
 	ImportContext(ctx context.Context, token string, importModel model.ImportExport, options model.ImportExportOptions) (err error, code int)
+
+	// ListAuditEntries lists recorded permission and topic changes, newest first
+	ListAuditEntries(token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int)
+	ListAuditEntriesContext(ctx context.Context, token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int)
+
+	// ListWebhooks, GetWebhook and SetWebhook return webhooks without secrets
+	ListWebhooks(token string) (result []model.Webhook, err error, code int)
+	ListWebhooksContext(ctx context.Context, token string) (result []model.Webhook, err error, code int)
+	GetWebhook(token string, id string) (result model.Webhook, err error, code int)
+	GetWebhookContext(ctx context.Context, token string, id string) (result model.Webhook, err error, code int)
+
+	// SetWebhook creates (empty id) or updates a webhook; an empty secret keeps the stored secret
+	SetWebhook(token string, webhook model.Webhook) (result model.Webhook, err error, code int)
+	SetWebhookContext(ctx context.Context, token string, webhook model.Webhook) (result model.Webhook, err error, code int)
+	RemoveWebhook(token string, id string) (err error, code int)
+	RemoveWebhookContext(ctx context.Context, token string, id string) (err error, code int)
+
+	// ListWebhookDeliveries lists the deliveries of a webhook, newest first
+	ListWebhookDeliveries(token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int)
+	ListWebhookDeliveriesContext(ctx context.Context, token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int)
+
+	// RetryWebhookDelivery schedules a new attempt of a failed delivery
+	RetryWebhookDelivery(token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int)
+	RetryWebhookDeliveryContext(ctx context.Context, token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int)
+
+	// ListServiceCredentials, GetServiceCredential and UpdateServiceCredential return credentials without keys
+	ListServiceCredentials(token string) (result []model.ServiceCredential, err error, code int)
+	ListServiceCredentialsContext(ctx context.Context, token string) (result []model.ServiceCredential, err error, code int)
+	GetServiceCredential(token string, id string) (result model.ServiceCredential, err error, code int)
+	GetServiceCredentialContext(ctx context.Context, token string, id string) (result model.ServiceCredential, err error, code int)
+
+	// CreateServiceCredential and RenewServiceCredentialKey return the key, which can not be read later
+	CreateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int)
+	CreateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int)
+	RenewServiceCredentialKey(token string, id string) (result model.ServiceCredentialWithKey, err error, code int)
+	RenewServiceCredentialKeyContext(ctx context.Context, token string, id string) (result model.ServiceCredentialWithKey, err error, code int)
+
+	// UpdateServiceCredential updates description, topics, scopes and the disabled flag; the key stays valid
+	UpdateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int)
+	UpdateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int)
+	RemoveServiceCredential(token string, id string) (err error, code int)
+	RemoveServiceCredentialContext(ctx context.Context, token string, id string) (err error, code int)
 }
 
 type PermissionsCheckInterface interface {
@@ -56,25 +101,87 @@ type PermissionsCheckInterface interface {
 	CheckPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ...model.Permission) (access bool, err error, code int)
 	CheckMultiplePermissions(token string, topicId string, ids []string, permissions ...model.Permission) (access map[string]bool, err error, code int)
 	CheckMultiplePermissionsContext(ctx context.Context, token string, topicId string, ids []string, permissions ...model.Permission) (access map[string]bool, err error, code int)
+
+	// CheckPermissionWithMode, CheckMultiplePermissionsWithMode and ListAccessibleResourceIdsWithMode evaluate the permissions in the mode:
+	// model.PermissionModeAll requires all permissions (like the variants without mode), model.PermissionModeAny requires at least one of them
+	CheckPermissionWithMode(token string, topicId string, id string, mode model.PermissionMode, permissions ...model.Permission) (access bool, err error, code int)
+	CheckPermissionWithModeContext(ctx context.Context, token string, topicId string, id string, mode model.PermissionMode, permissions ...model.Permission) (access bool, err error, code int)
+	CheckMultiplePermissionsWithMode(token string, topicId string, ids []string, mode model.PermissionMode, permissions ...model.Permission) (access map[string]bool, err error, code int)
+	CheckMultiplePermissionsWithModeContext(ctx context.Context, token string, topicId string, ids []string, mode model.PermissionMode, permissions ...model.Permission) (access map[string]bool, err error, code int)
+	ListAccessibleResourceIdsWithMode(token string, topicId string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (ids []string, err error, code int)
+	ListAccessibleResourceIdsWithModeContext(ctx context.Context, token string, topicId string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (ids []string, err error, code int)
+
+	// CheckPermissionsBatch answers CheckMultiplePermissions queries of multiple topics with one call; the result maps topic ids to access maps
+	CheckPermissionsBatch(token string, queries []model.CheckQuery) (access map[string]map[string]bool, err error, code int)
+	CheckPermissionsBatchContext(ctx context.Context, token string, queries []model.CheckQuery) (access map[string]map[string]bool, err error, code int)
+
 	ListAccessibleResourceIds(token string, topicId string, options model.ListOptions, permissions ...model.Permission) (ids []string, err error, code int)
 	ListAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options model.ListOptions, permissions ...model.Permission) (ids []string, err error, code int)
+
+	// CountAccessibleResourceIds returns the total number of ids, ListAccessibleResourceIdsWithMode would list without paging;
+	// the id search of the options is applied, paging and sorting are ignored
+	CountAccessibleResourceIds(token string, topicId string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (count int64, err error, code int)
+	CountAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (count int64, err error, code int)
+
 	ListComputedPermissions(token string, topic string, ids []string) (result []model.ComputedPermissions, err error, code int)
 	ListComputedPermissionsContext(ctx context.Context, token string, topic string, ids []string) (result []model.ComputedPermissions, err error, code int)
+
+	// ExplainPermission returns the decision of CheckPermission and the contributing rules for each permission.
+	// onBehalf may only be used by admins; nil explains the permissions of the token
+	ExplainPermission(token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int)
+	ExplainPermissionContext(ctx context.Context, token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int)
 }
 
 type PermissionsManagementInterface interface {
 	ListResourcesWithAdminPermission(token string, topicId string, options model.ListOptions) (result []model.Resource, err error, code int)
 	ListResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options model.ListOptions) (result []model.Resource, err error, code int)
+	CountResourcesWithAdminPermission(token string, topicId string, options model.ListOptions) (count int64, err error, code int)
+	CountResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options model.ListOptions) (count int64, err error, code int)
 	GetResource(token string, topicId string, id string) (result model.Resource, err error, code int)
 	GetResourceContext(ctx context.Context, token string, topicId string, id string) (result model.Resource, err error, code int)
 
 	// RemoveResource removes a resource
 	// only admins may remove resources
-	RemoveResource(token string, topicId string, id string) (err error, code int)
-	RemoveResourceContext(ctx context.Context, token string, topicId string, id string) (err error, code int)
+	// an expected version (WriteOptions) prevents the removal of a changed resource (http.StatusPreconditionFailed)
+	RemoveResource(token string, topicId string, id string, options ...model.WriteOptions) (err error, code int)
+	RemoveResourceContext(ctx context.Context, token string, topicId string, id string, options ...model.WriteOptions) (err error, code int)
 
 	// SetPermission sets the permissions of a resource.
-	// resource initialization needs to be done by an admin; user tokens may update their rights but may not create the initial resource
-	SetPermission(token string, topicId string, id string, permissions model.ResourcePermissions) (result model.ResourcePermissions, err error, code int)
-	SetPermissionContext(ctx context.Context, token string, topicId string, id string, permissions model.ResourcePermissions) (result model.ResourcePermissions, err error, code int)
+	// resource initialization needs to be done by an admin, unless the creation policy of the topic (model.Topic.CreationPolicy) allows the user to create the resource;
+	// the creator becomes admin of the new resource. other user tokens may update their rights but may not create the initial resource
+	// grants of users, who are no admins, are limited by the sharing policy of the topic (model.Topic.SharingPolicy); violations return a *model.SharingPolicyError with http.StatusForbidden
+	// a WriteOptions.ExpectedVersion != 0 rejects the change with http.StatusPreconditionFailed, if the resource has another version
+	SetPermission(token string, topicId string, id string, permissions model.ResourcePermissions, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int)
+	SetPermissionContext(ctx context.Context, token string, topicId string, id string, permissions model.ResourcePermissions, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int)
+
+	// SetPermissionsBulk sets the permissions of many resources of one topic; each item is checked like a SetPermission call.
+	// the result contains the status code of each item; err and code are only set if the request as a whole fails
+	SetPermissionsBulk(token string, topicId string, items []model.BulkPermissionsItem) (result []model.BulkPermissionsResult, err error, code int)
+	SetPermissionsBulkContext(ctx context.Context, token string, topicId string, items []model.BulkPermissionsItem) (result []model.BulkPermissionsResult, err error, code int)
+
+	// PatchPermission adds, changes or removes individual entries of the permissions of an existing resource.
+	// the resulting permissions are checked like the permissions of SetPermission
+	// without expected version, the patch is reapplied if the resource is changed concurrently
+	PatchPermission(token string, topicId string, id string, patch model.PermissionsPatch, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int)
+	PatchPermissionContext(ctx context.Context, token string, topicId string, id string, patch model.PermissionsPatch, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int)
+
+	// ListPermissionVersions lists the stored permission versions of a resource, newest first
+	// requesting user must have admin right on the resource or topic
+	ListPermissionVersions(token string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int)
+	ListPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int)
+
+	// DiffPermissionVersions returns the changes between two permission versions of a resource
+	DiffPermissionVersions(token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int)
+	DiffPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int)
+
+	// RollbackPermissions sets the permissions of a resource to a stored version
+	// the rollback is checked and published like a SetPermission call
+	RollbackPermissions(token string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int)
+	RollbackPermissionsContext(ctx context.Context, token string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int)
+
+	// WatchPermissionChanges streams the permission changes of resources in the topic, until the context is done or the iteration is stopped.
+	// admins of the topic receive all changes; other users receive changes of resources they hold options.Permissions on, without permission details.
+	// an unknown options.ResumeToken is rejected with http.StatusGone (or yielded as model.ErrResumeTokenExpired)
+	WatchPermissionChanges(token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int)
+	WatchPermissionChangesContext(ctx context.Context, token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int)
 }
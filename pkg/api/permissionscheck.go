@@ -19,6 +19,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
@@ -39,7 +40,8 @@ type PermissionsCheckEndpoints struct{}
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        id path string true "Resource Id"
-// @Param        permissions query string false "checked permissions in the form of 'rwxa', defaults to 'r'"
+// @Param        permissions query string false "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'"
+// @Param        mode query string false "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted"
 // @Produce      json
 // @Success      200 {object} bool
 // @Failure      400
@@ -70,7 +72,79 @@ func (this *PermissionsCheckEndpoints) CheckPermission(config configuration.Conf
 			return
 		}
 
-		result, err, code := ctrl.CheckPermissionContext(req.Context(), token, topic, id, permissions...)
+		mode, err := model.PermissionModeFromString(req.URL.Query().Get("mode"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.CheckPermissionWithModeContext(req.Context(), token, topic, id, mode, permissions...)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// ExplainPermission godoc
+// @Summary      explain permission check
+// @Description  explains the result of GET /check/{topic}/{id}: for each permission the decision and all rules (admin, topic default, user, group or role entries) that contributed to it; admins may explain the permissions of other users by setting user, roles and/or groups
+// @Tags         check
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        id path string true "Resource Id"
+// @Param        permissions query string false "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'"
+// @Param        user query string false "admin only: explain for this user id instead of the requesting user"
+// @Param        roles query string false "admin only: explain for these roles (comma seperated) instead of the roles of the requesting user"
+// @Param        groups query string false "admin only: explain for these groups (comma seperated) instead of the groups of the requesting user"
+// @Produce      json
+// @Success      200 {object} model.Explanation
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /explain/{topic}/{id} [get]
+func (this *PermissionsCheckEndpoints) ExplainPermission(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /explain/{topic}/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+
+		permissionsStr := req.URL.Query().Get("permissions")
+		if permissionsStr == "" {
+			permissionsStr = "r"
+		}
+		permissions, err := model.PermissionListFromString(permissionsStr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var onBehalf *model.ExplainSubject
+		query := req.URL.Query()
+		if query.Has("user") || query.Has("roles") || query.Has("groups") {
+			onBehalf = &model.ExplainSubject{
+				UserId:   query.Get("user"),
+				RoleIds:  splitCommaList(query.Get("roles")),
+				GroupIds: splitCommaList(query.Get("groups")),
+			}
+		}
+
+		result, err, code := ctrl.ExplainPermissionContext(req.Context(), token, topic, id, onBehalf, permissions...)
 		if err != nil {
 			http.Error(w, err.Error(), code)
 			return
@@ -83,6 +157,17 @@ func (this *PermissionsCheckEndpoints) CheckPermission(config configuration.Conf
 	})
 }
 
+func splitCommaList(str string) (result []string) {
+	result = []string{}
+	for _, e := range strings.Split(str, ",") {
+		e = strings.TrimSpace(e)
+		if e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
 // CheckMultiplePermissions godoc
 // @Summary      check multiple permissions
 // @Description  check multiple permissions
@@ -90,7 +175,8 @@ func (this *PermissionsCheckEndpoints) CheckPermission(config configuration.Conf
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        ids query string true "Resource Ids, comma seperated"
-// @Param        permissions query string false "checked permissions in the form of 'rwxa', defaults to 'r'"
+// @Param        permissions query string false "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'"
+// @Param        mode query string false "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted"
 // @Produce      json
 // @Success      200 {object} map[string]bool
 // @Failure      400
@@ -122,7 +208,49 @@ func (this *PermissionsCheckEndpoints) CheckMultiplePermissions(config configura
 			return
 		}
 
-		result, err, code := ctrl.CheckMultiplePermissionsContext(req.Context(), token, topic, idList, permissions...)
+		mode, err := model.PermissionModeFromString(req.URL.Query().Get("mode"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.CheckMultiplePermissionsWithModeContext(req.Context(), token, topic, idList, mode, permissions...)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// CheckPermissionsBatch godoc
+// @Summary      check permissions of multiple topics
+// @Description  checks multiple permissions of resources of multiple topics with one request; each query is checked like GET /check/{topic}. the result maps topic ids to the access maps of the queries; if multiple queries of the same topic contain an id, all of them must grant access
+// @Tags         check
+// @Security Bearer
+// @Param        message body []model.CheckQuery true "Queries"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} map[string]map[string]bool
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /check [post]
+func (this *PermissionsCheckEndpoints) CheckPermissionsBatch(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /check", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		queries := []model.CheckQuery{}
+		err := json.NewDecoder(req.Body).Decode(&queries)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.CheckPermissionsBatchContext(req.Context(), token, queries)
 		if err != nil {
 			http.Error(w, err.Error(), code)
 			return
@@ -141,13 +269,22 @@ func (this *PermissionsCheckEndpoints) CheckMultiplePermissions(config configura
 // @Tags         resource
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
-// @Param        permissions query string false "checked permissions in the form of 'rwxa', defaults to 'r'"
+// @Param        permissions query string false "checked permissions in the form of 'rwxa' and letters of custom permissions declared by the topic, defaults to 'r'"
 // @Param        limit query integer false "limits size of result; 0 means unlimited"
 // @Param        offset query integer false "offset to be used in combination with limit"
+// @Param        cursor query string false "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset"
+// @Param        id_prefix query string false "lists only elements with an id starting with the prefix"
+// @Param        id_regex query string false "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters"
+// @Param        sort query string false "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id"
+// @Param        total query boolean false "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor"
+// @Param        mode query string false "'all' (default): all permissions must be granted; 'any': at least one of the permissions must be granted"
 // @Produce      json
 // @Success      200 {array} string
+// @Header       200 {string}  Link "link to the next page (rel next), if limit is set and the page is full"
+// @Header       200 {integer} X-Total-Count "number of elements of the whole list; only set if total=true"
 // @Failure      400
 // @Failure      401
+// @Failure      403
 // @Failure      500
 // @Router       /accessible/{topic} [get]
 func (this *PermissionsCheckEndpoints) ListAccessibleResourceIds(config configuration.Config, router *http.ServeMux, ctrl Controller) {
@@ -175,11 +312,26 @@ func (this *PermissionsCheckEndpoints) ListAccessibleResourceIds(config configur
 			return
 		}
 
-		result, err, code := ctrl.ListAccessibleResourceIdsContext(req.Context(), token, topic, listOptions, permissions...)
+		mode, err := model.PermissionModeFromString(req.URL.Query().Get("mode"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.ListAccessibleResourceIdsWithModeContext(req.Context(), token, topic, listOptions, mode, permissions...)
 		if err != nil {
 			http.Error(w, err.Error(), code)
 			return
 		}
+		if req.URL.Query().Get("total") == "true" {
+			total, err, code := ctrl.CountAccessibleResourceIdsContext(req.Context(), token, topic, listOptions, mode, permissions...)
+			if err != nil {
+				http.Error(w, err.Error(), code)
+				return
+			}
+			w.Header().Set(model.TotalCountHeader, strconv.FormatInt(total, 10))
+		}
+		setNextPageLink(w, req, listOptions, result, func(id string) string { return id })
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(result)
 		if err != nil {
@@ -18,7 +18,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
@@ -39,8 +42,15 @@ type PermissionsManagementEndpoints struct{}
 // @Param        topic path string true "Topic Id"
 // @Param        limit query integer false "limits size of result; 0 means unlimited"
 // @Param        offset query integer false "offset to be used in combination with limit"
+// @Param        cursor query string false "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset"
+// @Param        id_prefix query string false "lists only elements with an id starting with the prefix"
+// @Param        id_regex query string false "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters"
+// @Param        sort query string false "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id"
+// @Param        total query boolean false "if true, the X-Total-Count header contains the number of elements of the whole list, independent of limit, offset and cursor"
 // @Produce      json
 // @Success      200 {array}  model.Resource
+// @Header       200 {string}  Link "link to the next page (rel next), if limit is set and the page is full"
+// @Header       200 {integer} X-Total-Count "number of elements of the whole list; only set if total=true"
 // @Failure      400
 // @Failure      401
 // @Failure      403
@@ -66,6 +76,15 @@ func (this *PermissionsManagementEndpoints) ListResourcesWithAdminPermission(con
 			http.Error(w, err.Error(), code)
 			return
 		}
+		if req.URL.Query().Get("total") == "true" {
+			total, err, code := ctrl.CountResourcesWithAdminPermissionContext(req.Context(), token, topic, listOptions)
+			if err != nil {
+				http.Error(w, err.Error(), code)
+				return
+			}
+			w.Header().Set(model.TotalCountHeader, strconv.FormatInt(total, 10))
+		}
+		setNextPageLink(w, req, listOptions, result, func(resource model.Resource) string { return resource.Id })
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(result)
 		if err != nil {
@@ -76,13 +95,14 @@ func (this *PermissionsManagementEndpoints) ListResourcesWithAdminPermission(con
 
 // GetResource godoc
 // @Summary      get resource
-// @Description  get resource, requesting user must have admin right  on the resource
+// @Description  get resource, requesting user must have admin right on the resource or be a delegated admin of the topic
 // @Tags         manage
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        id path string true "Resource Id"
 // @Produce      json
 // @Success      200 {object}  model.Resource
+// @Header       200 {string}  ETag "version of the resource"
 // @Failure      400
 // @Failure      401
 // @Failure      403
@@ -107,6 +127,7 @@ func (this *PermissionsManagementEndpoints) GetResource(config configuration.Con
 			http.Error(w, err.Error(), code)
 			return
 		}
+		w.Header().Set("ETag", model.ETag(result.Version))
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(result)
 		if err != nil {
@@ -122,10 +143,13 @@ func (this *PermissionsManagementEndpoints) GetResource(config configuration.Con
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        id path string true "Resource Id"
+// @Param        If-Match header string false "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected"
+// @Param        expected_version query integer false "expected version; alternative to If-Match"
 // @Success      200 {object}  model.Resource
 // @Failure      400
 // @Failure      401
 // @Failure      403
+// @Failure      412
 // @Failure      500
 // @Router       /manage/{topic}/{id} [delete]
 func (this *PermissionsManagementEndpoints) DeleteResource(config configuration.Config, router *http.ServeMux, ctrl Controller) {
@@ -142,7 +166,13 @@ func (this *PermissionsManagementEndpoints) DeleteResource(config configuration.
 			return
 		}
 
-		err, code := ctrl.RemoveResourceContext(req.Context(), token, topic, id)
+		options, err := writeOptionsFromRequest(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err, code := ctrl.RemoveResourceContext(req.Context(), token, topic, id, options)
 		if err != nil {
 			http.Error(w, err.Error(), code)
 			return
@@ -153,19 +183,23 @@ func (this *PermissionsManagementEndpoints) DeleteResource(config configuration.
 
 // SetPermission godoc
 // @Summary      set resource rights
-// @Description  get resource rights, requesting user must have admin right on resource to update, requesting user must have admin rights on topic to create
+// @Description  get resource rights, requesting user must have admin right on resource to update, requesting user must have admin rights on topic or be allowed by the creation policy of the topic to create; creators become admin of the new resource. users who are no admins may only grant permissions allowed by the sharing policy of the topic; violations are described by the 403 response
 // @Tags         manage
 // @Security Bearer
 // @Param        topic path string true "Topic Id"
 // @Param        id path string true "Resource Id"
 // @Param        wait query bool false "if set to true, the response will be sent after the corresponding kafka done signal has been received"
+// @Param        If-Match header string false "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected"
+// @Param        expected_version query integer false "expected version; alternative to If-Match"
 // @Param        message body model.ResourcePermissions true "Topic"
 // @Accept       json
 // @Produce      json
 // @Success      200 {object}  model.ResourcePermissions
+// @Header       200 {string}  ETag "new version of the resource"
 // @Failure      400
 // @Failure      401
-// @Failure      403
+// @Failure      403 {object} model.SharingPolicyError
+// @Failure      412
 // @Failure      500
 // @Router       /manage/{topic}/{id} [put]
 func (this *PermissionsManagementEndpoints) SetPermission(config configuration.Config, router *http.ServeMux, ctrl Controller) {
@@ -190,7 +224,178 @@ func (this *PermissionsManagementEndpoints) SetPermission(config configuration.C
 			return
 		}
 
-		result, err, code := ctrl.SetPermissionContext(req.Context(), token, topic, id, permissions)
+		options, err := writeOptionsFromRequest(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		options.OnStored = func(version int64) {
+			w.Header().Set("ETag", model.ETag(version))
+		}
+		result, err, code := ctrl.SetPermissionContext(req.Context(), token, topic, id, permissions, options)
+		if err != nil {
+			writePermissionsError(w, err, code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// SetPermissionsBulk godoc
+// @Summary      set rights of many resources
+// @Description  sets the rights of many resources of the topic with one request; each item is checked like a set of the rights of a single resource. valid items are stored together and published to kafka in batches. the response contains one result per item, in the order of the request, with the status code of the item and, if the item is rejected by the sharing policy of the topic, its violations
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        message body []model.BulkPermissionsItem true "Items"
+// @Accept       json
+// @Produce      json
+// @Success      200 {array}  model.BulkPermissionsResult
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      413
+// @Failure      500
+// @Router       /manage/{topic} [put]
+func (this *PermissionsManagementEndpoints) SetPermissionsBulk(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("PUT /manage/{topic}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		var err error
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+
+		items := []model.BulkPermissionsItem{}
+		err = json.NewDecoder(req.Body).Decode(&items)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.SetPermissionsBulkContext(req.Context(), token, topic, items)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// PatchPermission godoc
+// @Summary      patch resource rights
+// @Description  adds, changes or removes individual user, group or role entries of the rights of an existing resource; a null entry removes the user, group or role; unlisted entries are unchanged. the result is checked like a set of the rights: requesting user must have admin right on the resource, added groups must contain the requesting user and added users must share a group with the requesting user and the sharing policy of the topic must allow the grants
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        id path string true "Resource Id"
+// @Param        If-Match header string false "expected version (ETag of GET /manage/{topic}/{id}); * only matches existing resources; weak entity tags are rejected"
+// @Param        expected_version query integer false "expected version; alternative to If-Match"
+// @Param        message body model.PermissionsPatch true "Patch"
+// @Accept       json
+// @Produce      json
+// @Success      200 {object}  model.ResourcePermissions
+// @Header       200 {string}  ETag "new version of the resource"
+// @Failure      400
+// @Failure      401
+// @Failure      403 {object} model.SharingPolicyError
+// @Failure      404
+// @Failure      412
+// @Failure      500
+// @Router       /manage/{topic}/{id} [patch]
+func (this *PermissionsManagementEndpoints) PatchPermission(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("PATCH /manage/{topic}/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		var err error
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+
+		patch := model.PermissionsPatch{}
+		err = json.NewDecoder(req.Body).Decode(&patch)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		options, err := writeOptionsFromRequest(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		options.OnStored = func(version int64) {
+			w.Header().Set("ETag", model.ETag(version))
+		}
+		result, err, code := ctrl.PatchPermissionContext(req.Context(), token, topic, id, patch, options)
+		if err != nil {
+			writePermissionsError(w, err, code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// ListPermissionVersions godoc
+// @Summary      list permission versions
+// @Description  lists the stored permission versions of a resource, newest first; requesting user must have admin right on the resource or topic
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        id path string true "Resource Id"
+// @Param        limit query integer false "limits size of result; 0 means unlimited"
+// @Param        offset query integer false "offset to be used in combination with limit"
+// @Produce      json
+// @Success      200 {array}  model.PermissionsVersion
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /manage/{topic}/{id}/versions [get]
+func (this *PermissionsManagementEndpoints) ListPermissionVersions(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /manage/{topic}/{id}/versions", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+
+		listOptions, err := model.ListOptionsFromQuery(req.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.ListPermissionVersionsContext(req.Context(), token, topic, id, listOptions)
 		if err != nil {
 			http.Error(w, err.Error(), code)
 			return
@@ -202,3 +407,136 @@ func (this *PermissionsManagementEndpoints) SetPermission(config configuration.C
 		}
 	})
 }
+
+// DiffPermissionVersions godoc
+// @Summary      diff permission versions
+// @Description  returns the changes between two permission versions of a resource; requesting user must have admin right on the resource or topic
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        id path string true "Resource Id"
+// @Param        from query integer true "version to compare from"
+// @Param        to query integer true "version to compare to"
+// @Produce      json
+// @Success      200 {object}  model.PermissionsDiff
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /manage/{topic}/{id}/versions/diff [get]
+func (this *PermissionsManagementEndpoints) DiffPermissionVersions(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /manage/{topic}/{id}/versions/diff", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		from, err := strconv.ParseInt(req.URL.Query().Get("from"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid from version: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to, err := strconv.ParseInt(req.URL.Query().Get("to"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid to version: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.DiffPermissionVersionsContext(req.Context(), token, topic, id, from, to)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// RollbackPermissions godoc
+// @Summary      rollback permissions
+// @Description  sets the permissions of a resource to a stored version; the rollback is checked and published like a set of the permissions and is stored as a new version
+// @Tags         manage
+// @Security Bearer
+// @Param        topic path string true "Topic Id"
+// @Param        id path string true "Resource Id"
+// @Param        version path integer true "Version"
+// @Produce      json
+// @Success      200 {object}  model.ResourcePermissions
+// @Failure      400
+// @Failure      401
+// @Failure      403 {object} model.SharingPolicyError
+// @Failure      404
+// @Failure      500
+// @Router       /manage/{topic}/{id}/versions/{version}/rollback [post]
+func (this *PermissionsManagementEndpoints) RollbackPermissions(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /manage/{topic}/{id}/versions/{version}/rollback", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		topic := req.PathValue("topic")
+		if topic == "" {
+			http.Error(w, "missing topic", http.StatusBadRequest)
+			return
+		}
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		version, err := strconv.ParseInt(req.PathValue("version"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid version: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err, code := ctrl.RollbackPermissionsContext(req.Context(), token, topic, id, version)
+		if err != nil {
+			writePermissionsError(w, err, code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// writeOptionsFromRequest reads the expected version from the If-Match header or the expected_version query parameter
+func writeOptionsFromRequest(req *http.Request) (result model.WriteOptions, err error) {
+	if ifMatch := req.Header.Get("If-Match"); ifMatch != "" {
+		result.ExpectedVersion, err = model.ParseETag(ifMatch)
+		if err != nil {
+			return result, fmt.Errorf("invalid If-Match header: %w", err)
+		}
+		return result, nil
+	}
+	if version := req.URL.Query().Get("expected_version"); version != "" {
+		result.ExpectedVersion, err = strconv.ParseInt(version, 10, 64)
+		if err != nil {
+			return result, fmt.Errorf("invalid expected_version: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// writePermissionsError responds with the error; violations of the sharing policy are sent as json (model.SharingPolicyError)
+func writePermissionsError(w http.ResponseWriter, err error, code int) {
+	var violation *model.SharingPolicyError
+	if !errors.As(err, &violation) {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(violation)
+}
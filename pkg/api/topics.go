@@ -38,8 +38,13 @@ type TopicsEndpoints struct{}
 // @Security Bearer
 // @Param        limit query integer false "limits size of result; 0 means unlimited"
 // @Param        offset query integer false "offset to be used in combination with limit"
+// @Param        cursor query string false "continues the list after the last element of the previous page; taken from the Link header of the previous response; may not be combined with offset"
+// @Param        id_prefix query string false "lists only elements with an id starting with the prefix"
+// @Param        id_regex query string false "lists only elements with an id matching the regular expression; may not be combined with id_prefix; admins only; max 256 characters"
+// @Param        sort query string false "'id' (default) or 'timestamp' (last change), optionally followed by '.asc' (default) or '.desc'; e.g. 'timestamp.desc'; cursors are only supported for sort by id"
 // @Produce      json
 // @Success      200 {array}  model.Topic
+// @Header       200 {string}  Link "link to the next page (rel next), if limit is set and the page is full"
 // @Failure      400
 // @Failure      401
 // @Failure      403
@@ -58,6 +63,7 @@ func (this *TopicsEndpoints) ListTopics(config configuration.Config, router *htt
 			http.Error(w, err.Error(), code)
 			return
 		}
+		setNextPageLink(w, req, listOptions, result, func(topic model.Topic) string { return topic.Id })
 		w.Header().Set("Content-Type", "application/json")
 		err = json.NewEncoder(w).Encode(result)
 		if err != nil {
@@ -68,7 +74,7 @@ func (this *TopicsEndpoints) ListTopics(config configuration.Config, router *htt
 
 // GetTopic godoc
 // @Summary      get topic config
-// @Description  get topic config, requesting user must be admin
+// @Description  get topic config, requesting user must be admin or a delegated admin of the topic
 // @Tags         topics
 // @Security Bearer
 // @Param        id path string true "Topic Id"
@@ -103,7 +109,7 @@ func (this *TopicsEndpoints) GetTopic(config configuration.Config, router *http.
 
 // SetTopic godoc
 // @Summary      set topic config
-// @Description  set topic config, requesting user must be admin
+// @Description  set topic config, requesting user must be admin or a delegated admin of the topic; only admins may create topics or change kafka settings
 // @Tags         topics
 // @Accept       json
 // @Produce      json
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NewEventStreamBypass passes requests, that accept text/event-stream, to stream and all other requests to handler.
+// allows streams to skip middlewares, which do not support flushing.
+func NewEventStreamBypass(handler http.Handler, stream http.Handler) *EventStreamBypass {
+	return &EventStreamBypass{handler: handler, stream: stream}
+}
+
+type EventStreamBypass struct {
+	handler http.Handler
+	stream  http.Handler
+}
+
+func (this *EventStreamBypass) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		this.stream.ServeHTTP(w, r)
+	} else {
+		this.handler.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/auth"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// NewTokenVerification rejects requests with invalid tokens and adds verified tokens to the request context (see jwt.GetParsedToken).
+// requests from trusted networks are marked (see auth.WithTrustedNetwork) and their tokens are not verified.
+// a nil verifier returns the handler unchanged.
+func NewTokenVerification(handler http.Handler, verifier *auth.Verifier) http.Handler {
+	if verifier == nil {
+		return handler
+	}
+	return &TokenVerification{handler: handler, verifier: verifier}
+}
+
+type TokenVerification struct {
+	handler  http.Handler
+	verifier *auth.Verifier
+}
+
+func (this *TokenVerification) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	//the Forwarded header is not evaluated, so requests using it are never trusted
+	if r.Header.Get("Forwarded") == "" && this.verifier.TrustedNetworks().Contains(append([]string{r.RemoteAddr}, auth.ForwardedFor(r.Header.Values("X-Forwarded-For"))...)...) {
+		ctx = auth.WithTrustedNetwork(ctx)
+	}
+	//service credentials are checked by the controller
+	if tokenStr := jwt.GetAuthToken(r); tokenStr != "" && !model.IsServiceCredentialAuth(tokenStr) {
+		token, err := this.verifier.Parse(ctx, tokenStr)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		ctx = jwt.AddTokenToContext(ctx, token)
+	}
+	this.handler.ServeHTTP(w, r.WithContext(ctx))
+}
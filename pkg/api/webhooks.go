@@ -0,0 +1,291 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func init() {
+	endpoints = append(endpoints, &WebhookEndpoints{})
+}
+
+type WebhookEndpoints struct{}
+
+// ListWebhooks godoc
+// @Summary      list webhooks
+// @Description  lists webhook subscriptions without secrets, requesting user must be admin
+// @Tags         webhooks
+// @Security Bearer
+// @Produce      json
+// @Success      200 {array}  model.Webhook
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/webhooks [get]
+func (this *WebhookEndpoints) ListWebhooks(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/webhooks", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		result, err, code := ctrl.ListWebhooksContext(req.Context(), token)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// GetWebhook godoc
+// @Summary      get webhook
+// @Description  get webhook subscription without secret, requesting user must be admin
+// @Tags         webhooks
+// @Security Bearer
+// @Param        id path string true "Webhook Id"
+// @Produce      json
+// @Success      200 {object}  model.Webhook
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/webhooks/{id} [get]
+func (this *WebhookEndpoints) GetWebhook(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/webhooks/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.GetWebhookContext(req.Context(), token, id)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// SetWebhook godoc
+// @Summary      set webhook
+// @Description  creates or updates a webhook subscription, requesting user must be admin; an empty secret keeps the stored secret
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        id path string true "Webhook Id"
+// @Param        message body model.Webhook true "Webhook"
+// @Success      200 {object}  model.Webhook
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/webhooks/{id} [put]
+func (this *WebhookEndpoints) SetWebhook(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("PUT /admin/webhooks/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		webhook := model.Webhook{}
+		err := json.NewDecoder(req.Body).Decode(&webhook)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if webhook.Id == "" {
+			webhook.Id = id
+		}
+		if webhook.Id != id {
+			http.Error(w, "webhook id mismatch", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.SetWebhookContext(req.Context(), token, webhook)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// SetWebhookByPost godoc
+// @Summary      create webhook
+// @Description  creates a webhook subscription, requesting user must be admin; the id is generated if not set
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Security Bearer
+// @Param        message body model.Webhook true "Webhook"
+// @Success      200 {object}  model.Webhook
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/webhooks [post]
+func (this *WebhookEndpoints) SetWebhookByPost(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /admin/webhooks", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		webhook := model.Webhook{}
+		err := json.NewDecoder(req.Body).Decode(&webhook)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.SetWebhookContext(req.Context(), token, webhook)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// DeleteWebhook godoc
+// @Summary      remove webhook
+// @Description  removes a webhook subscription and its deliveries, requesting user must be admin
+// @Tags         webhooks
+// @Security Bearer
+// @Param        id path string true "Webhook Id"
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/webhooks/{id} [delete]
+func (this *WebhookEndpoints) DeleteWebhook(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("DELETE /admin/webhooks/{id}", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		err, code := ctrl.RemoveWebhookContext(req.Context(), token, id)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+// ListWebhookDeliveries godoc
+// @Summary      list webhook deliveries
+// @Description  lists the deliveries of a webhook with status and last error, newest first, requesting user must be admin
+// @Tags         webhooks
+// @Security Bearer
+// @Param        id path string true "Webhook Id"
+// @Param        status query string false "filter by status (pending, delivered, failed)"
+// @Param        limit query integer false "limits size of result; 0 means unlimited"
+// @Param        offset query integer false "offset to be used in combination with limit"
+// @Produce      json
+// @Success      200 {array}  model.WebhookDelivery
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /admin/webhooks/{id}/deliveries [get]
+func (this *WebhookEndpoints) ListWebhookDeliveries(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("GET /admin/webhooks/{id}/deliveries", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		options, err := model.WebhookDeliveryListOptionsFromQuery(req.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.ListWebhookDeliveriesContext(req.Context(), token, id, options)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
+
+// RetryWebhookDelivery godoc
+// @Summary      retry webhook delivery
+// @Description  schedules a new delivery attempt of a failed delivery, requesting user must be admin
+// @Tags         webhooks
+// @Security Bearer
+// @Param        id path string true "Webhook Id"
+// @Param        delivery path string true "Delivery Id"
+// @Produce      json
+// @Success      200 {object}  model.WebhookDelivery
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /admin/webhooks/{id}/deliveries/{delivery}/retry [post]
+func (this *WebhookEndpoints) RetryWebhookDelivery(config configuration.Config, router *http.ServeMux, ctrl Controller) {
+	router.HandleFunc("POST /admin/webhooks/{id}/deliveries/{delivery}/retry", func(w http.ResponseWriter, req *http.Request) {
+		token := jwt.GetAuthToken(req)
+		id := req.PathValue("id")
+		if id == "" {
+			http.Error(w, "missing id", http.StatusBadRequest)
+			return
+		}
+		delivery := req.PathValue("delivery")
+		if delivery == "" {
+			http.Error(w, "missing delivery", http.StatusBadRequest)
+			return
+		}
+		result, err, code := ctrl.RetryWebhookDeliveryContext(req.Context(), token, id, delivery)
+		if err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(result)
+		if err != nil {
+			config.GetLogger().ErrorContext(req.Context(), "unable to encode response", "error", err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+)
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+var ErrUnsupportedKey = errors.New("unsupported key")
+
+// loadKeys returns the signature keys of the jwks endpoint by key id; encryption keys and unsupported key types are skipped
+func loadKeys(ctx context.Context, client *http.Client, url string) (result map[string]interface{}, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return result, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		temp, _ := io.ReadAll(resp.Body)
+		return result, fmt.Errorf("unexpected jwks statuscode %v: %v", resp.StatusCode, string(temp))
+	}
+	set := jwks{}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return result, err
+	}
+	result = map[string]interface{}{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.PublicKey()
+		if errors.Is(err, ErrUnsupportedKey) {
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("invalid jwks key '%v': %w", key.Kid, err)
+		}
+		result[key.Kid] = publicKey
+	}
+	return result, nil
+}
+
+// PublicKey returns a *rsa.PublicKey or *ecdsa.PublicKey
+func (this jwk) PublicKey() (interface{}, error) {
+	switch this.Kty {
+	case "RSA":
+		n, err := decodeBigInt(this.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(this.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch this.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: curve '%v'", ErrUnsupportedKey, this.Crv)
+		}
+		x, err := decodeBigInt(this.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(this.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("%w: type '%v'", ErrUnsupportedKey, this.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, errors.New("missing key parameter")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TrustedNetworks contains the internal networks, whose requests may use unverified tokens (e.g. client.InternalAdminToken)
+type TrustedNetworks []*net.IPNet
+
+// NewTrustedNetworks parses cidrs (e.g. "10.0.0.0/8") and single ip addresses
+func NewTrustedNetworks(list []string) (result TrustedNetworks, err error) {
+	for _, element := range list {
+		element = strings.TrimSpace(element)
+		if element == "" {
+			continue
+		}
+		if !strings.Contains(element, "/") {
+			ip := net.ParseIP(element)
+			if ip == nil {
+				return result, fmt.Errorf("invalid trusted network '%v'", element)
+			}
+			result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(element)
+		if err != nil {
+			return result, fmt.Errorf("invalid trusted network '%v': %w", element, err)
+		}
+		result = append(result, network)
+	}
+	return result, nil
+}
+
+// Contains returns true if every address is part of a trusted network.
+// addresses may contain a port (e.g. http.Request.RemoteAddr).
+// the remote address and all addresses of a X-Forwarded-For header should be checked,
+// to not trust requests, which are forwarded from outside by an internal proxy.
+func (this TrustedNetworks) Contains(addresses ...string) bool {
+	if len(this) == 0 || len(addresses) == 0 {
+		return false
+	}
+	for _, address := range addresses {
+		address = strings.TrimSpace(address)
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			address = host
+		}
+		ip := net.ParseIP(strings.Trim(address, "[]"))
+		if ip == nil {
+			return false
+		}
+		trusted := false
+		for _, network := range this {
+			if network.Contains(ip) {
+				trusted = true
+				break
+			}
+		}
+		if !trusted {
+			return false
+		}
+	}
+	return true
+}
+
+// ForwardedFor splits the values of X-Forwarded-For headers into single addresses
+func ForwardedFor(values []string) (result []string) {
+	for _, value := range values {
+		for _, address := range strings.Split(value, ",") {
+			result = append(result, strings.TrimSpace(address))
+		}
+	}
+	return result
+}
+
+type trustedNetworkKey struct{}
+
+// WithTrustedNetwork marks the context of a request from a trusted network; Verifier.Parse does not verify tokens of marked requests
+func WithTrustedNetwork(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trustedNetworkKey{}, true)
+}
+
+func IsTrustedNetwork(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	trusted, _ := ctx.Value(trustedNetworkKey{}).(bool)
+	return trusted
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+const defaultRefreshInterval = time.Hour
+const minReloadInterval = 10 * time.Second //limits reloads triggered by unknown key ids
+const jwksTimeout = 10 * time.Second
+const maxCachedTokens = 10000
+
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Verifier checks signature, expiry, issuer and audience of tokens against the keys of a jwks endpoint.
+// a nil *Verifier parses tokens without verification.
+type Verifier struct {
+	jwksUrl         string
+	issuer          string
+	audience        string
+	refreshInterval time.Duration
+	trustedNetworks TrustedNetworks
+	client          *http.Client
+	logger          *slog.Logger
+
+	keysMux         sync.Mutex
+	keys            map[string]interface{}
+	keysLoaded      time.Time
+	lastLoadAttempt time.Time
+
+	tokensMux sync.Mutex
+	tokens    map[string]verifiedToken
+}
+
+type verifiedToken struct {
+	token   jwt.Token
+	expires time.Time
+}
+
+// New returns nil, if config.JwksUrl is empty or "-"
+func New(config configuration.Config) (*Verifier, error) {
+	if config.JwksUrl == "" || config.JwksUrl == "-" {
+		return nil, nil
+	}
+	trusted, err := NewTrustedNetworks(config.JwtTrustedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	refreshInterval := config.JwksRefreshInterval.GetDuration()
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+	return &Verifier{
+		jwksUrl:         config.JwksUrl,
+		issuer:          config.JwtIssuer,
+		audience:        config.JwtAudience,
+		refreshInterval: refreshInterval,
+		trustedNetworks: trusted,
+		client:          &http.Client{Timeout: jwksTimeout},
+		logger:          config.GetLogger(),
+		tokens:          map[string]verifiedToken{},
+	}, nil
+}
+
+// TrustedNetworks returns the networks, whose requests may use unverified tokens
+func (this *Verifier) TrustedNetworks() TrustedNetworks {
+	if this == nil {
+		return nil
+	}
+	return this.trustedNetworks
+}
+
+// Parse verifies the token and returns its claims.
+// without verifier or if ctx is marked by WithTrustedNetwork, the token is parsed without verification.
+func (this *Verifier) Parse(ctx context.Context, tokenStr string) (token jwt.Token, err error) {
+	if this == nil || IsTrustedNetwork(ctx) {
+		return jwt.Parse(tokenStr)
+	}
+	if token, ok := this.getVerified(tokenStr); ok {
+		return token, nil
+	}
+	raw := strings.TrimSpace(tokenStr)
+	if len(raw) > 7 && strings.ToLower(raw[:7]) == "bearer " {
+		raw = strings.TrimSpace(raw[7:])
+	}
+	if raw == "" {
+		return token, jwt.ErrMissingAuthToken
+	}
+	options := []jwtv5.ParserOption{jwtv5.WithValidMethods(validMethods), jwtv5.WithExpirationRequired(), jwtv5.WithIssuedAt()}
+	if this.issuer != "" {
+		options = append(options, jwtv5.WithIssuer(this.issuer))
+	}
+	if this.audience != "" {
+		options = append(options, jwtv5.WithAudience(this.audience))
+	}
+	parsed, err := jwtv5.Parse(raw, func(t *jwtv5.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return this.getKey(ctx, kid)
+	}, options...)
+	if err != nil {
+		return token, fmt.Errorf("%w: %v", jwt.ErrInvalidAuth, err.Error())
+	}
+	exp, err := parsed.Claims.GetExpirationTime()
+	if err != nil {
+		return token, fmt.Errorf("%w: %v", jwt.ErrInvalidAuth, err.Error())
+	}
+	token, err = jwt.Parse(tokenStr)
+	if err != nil {
+		return token, err
+	}
+	this.setVerified(tokenStr, token, exp.Time)
+	return token, nil
+}
+
+// getVerified returns tokens, that have already been verified and are not expired
+func (this *Verifier) getVerified(tokenStr string) (jwt.Token, bool) {
+	this.tokensMux.Lock()
+	defer this.tokensMux.Unlock()
+	entry, ok := this.tokens[tokenStr]
+	if !ok {
+		return jwt.Token{}, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(this.tokens, tokenStr)
+		return jwt.Token{}, false
+	}
+	return entry.token, true
+}
+
+func (this *Verifier) setVerified(tokenStr string, token jwt.Token, expires time.Time) {
+	this.tokensMux.Lock()
+	defer this.tokensMux.Unlock()
+	if len(this.tokens) >= maxCachedTokens {
+		this.tokens = map[string]verifiedToken{}
+	}
+	this.tokens[tokenStr] = verifiedToken{token: token, expires: expires}
+}
+
+// getKey returns the cached key with the id.
+// the keys are reloaded after the refresh interval and if the id is unknown (key rotation), but at most once per minReloadInterval.
+// if a reload fails, the previously loaded keys are used.
+func (this *Verifier) getKey(ctx context.Context, kid string) (interface{}, error) {
+	this.keysMux.Lock()
+	defer this.keysMux.Unlock()
+	now := time.Now()
+	key, ok := this.lookupKey(kid)
+	outdated := now.Sub(this.keysLoaded) >= this.refreshInterval
+	if (!ok || outdated) && now.Sub(this.lastLoadAttempt) >= minReloadInterval {
+		this.lastLoadAttempt = now
+		keys, err := loadKeys(ctx, this.client, this.jwksUrl)
+		if err != nil {
+			this.logger.ErrorContext(ctx, "unable to load jwks", "error", err)
+			if !ok {
+				return nil, err
+			}
+		} else {
+			this.keys = keys
+			this.keysLoaded = now
+			key, ok = this.lookupKey(kid)
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id '%v'", kid)
+	}
+	return key, nil
+}
+
+// lookupKey accepts tokens without key id, if the jwks contains exactly one key
+func (this *Verifier) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(this.keys) == 1 {
+		for _, key := range this.keys {
+			return key, true
+		}
+	}
+	key, ok := this.keys[kid]
+	return key, ok
+}
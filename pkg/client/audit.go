@@ -0,0 +1,67 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+type AuditEntry = model.AuditEntry
+type AuditListOptions = model.AuditListOptions
+
+func (this *ClientImpl) ListAuditEntries(token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	return this.ListAuditEntriesContext(context.TODO(), token, options)
+}
+
+func (this *ClientImpl) ListAuditEntriesContext(ctx context.Context, token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	query := url.Values{}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(options.Limit, 10))
+	}
+	if options.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(options.Offset, 10))
+	}
+	if options.TopicId != "" {
+		query.Set("topic", options.TopicId)
+	}
+	if options.ResourceId != "" {
+		query.Set("resource", options.ResourceId)
+	}
+	if options.UserId != "" {
+		query.Set("user", options.UserId)
+	}
+	if options.Operation != "" {
+		query.Set("operation", string(options.Operation))
+	}
+	if options.From != 0 {
+		query.Set("from", strconv.FormatInt(options.From, 10))
+	}
+	if options.Until != 0 {
+		query.Set("until", strconv.FormatInt(options.Until, 10))
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/admin/audit?%v", this.serverUrl, query.Encode()), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]model.AuditEntry](ctx, token, req)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SENERGY-Platform/gin-middleware/otelx"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+type ChangeEvent = model.ChangeEvent
+type ChangeFeedOptions = model.ChangeFeedOptions
+
+var ErrResumeTokenExpired = model.ErrResumeTokenExpired
+
+// WatchPermissionChanges streams the permission changes of resources in the topic, until the iteration is stopped.
+// the connection is held open until the returned iterator is used and stopped.
+func (this *ClientImpl) WatchPermissionChanges(token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	return this.WatchPermissionChangesContext(context.TODO(), token, topicId, options)
+}
+
+// WatchPermissionChangesContext streams the permission changes of resources in the topic, until ctx is done or the iteration is stopped.
+// a lost connection ends the iteration with an error; a new call with the token of the last received event continues without gaps.
+// ErrResumeTokenExpired (or http.StatusGone) signals, that the client has to reload its state and start without token.
+func (this *ClientImpl) WatchPermissionChangesContext(ctx context.Context, token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	query := url.Values{}
+	query.Set("version", ClientVersion)
+	if len(options.Permissions) > 0 {
+		query.Set("permissions", options.Permissions.Encode())
+	}
+	if options.Mode != "" {
+		query.Set("mode", string(options.Mode))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%v/changes/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
+	if err != nil {
+		return nil, err, 0
+	}
+	err = otelx.InjectContextToRequest(ctx, req)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	req.Header.Set("Authorization", token)
+	req.Header.Set("Accept", "text/event-stream")
+	if options.ResumeToken != "" {
+		req.Header.Set("Last-Event-ID", options.ResumeToken)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	if resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		temp, _ := io.ReadAll(resp.Body) //read error response end ensure that resp.Body is read to EOF
+		return nil, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)), resp.StatusCode
+	}
+	return func(yield func(model.ChangeEvent, error) bool) {
+		defer resp.Body.Close()
+		for event, err := range readServerSentEvents(resp.Body) {
+			if err != nil {
+				if ctx.Err() == nil {
+					yield(model.ChangeEvent{}, err)
+				}
+				return
+			}
+			switch event.name {
+			case "change":
+				element := model.ChangeEvent{}
+				err = json.Unmarshal([]byte(event.data), &element)
+				if err != nil {
+					yield(element, err)
+					return
+				}
+				if !yield(element, nil) {
+					return
+				}
+			case "error":
+				if event.data == model.ErrResumeTokenExpired.Error() {
+					yield(model.ChangeEvent{}, model.ErrResumeTokenExpired)
+				} else {
+					yield(model.ChangeEvent{}, errors.New(event.data))
+				}
+				return
+			}
+		}
+	}, nil, resp.StatusCode
+}
+
+type serverSentEvent struct {
+	name string
+	data string
+}
+
+// readServerSentEvents parses the event stream until EOF, which ends the iteration with io.ErrUnexpectedEOF
+func readServerSentEvents(body io.Reader) iter.Seq2[serverSentEvent, error] {
+	return func(yield func(serverSentEvent, error) bool) {
+		reader := bufio.NewReader(body)
+		event := serverSentEvent{name: "message"}
+		data := []string{}
+		for {
+			line, err := reader.ReadString('\n')
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				yield(event, err)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "" {
+				if len(data) > 0 {
+					event.data = strings.Join(data, "\n")
+					if !yield(event, nil) {
+						return
+					}
+				}
+				event = serverSentEvent{name: "message"}
+				data = []string{}
+				continue
+			}
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "event":
+				event.name = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+	}
+}
@@ -60,13 +60,7 @@ func (this *ClientImpl) ListTopics(token string, options ListOptions) (result []
 }
 
 func (this *ClientImpl) ListTopicsContext(ctx context.Context, token string, options ListOptions) (result []Topic, err error, code int) {
-	query := url.Values{}
-	if options.Limit > 0 {
-		query.Set("limit", strconv.FormatInt(options.Limit, 10))
-	}
-	if options.Offset > 0 {
-		query.Set("offset", strconv.FormatInt(options.Offset, 10))
-	}
+	query := options.Query()
 	req, err := http.NewRequest(http.MethodGet, this.serverUrl+"/admin/topics?"+query.Encode(), nil)
 	if err != nil {
 		return result, err, 0
@@ -119,7 +113,18 @@ func (this *ClientImpl) CheckPermission(token string, topicId string, id string,
 }
 
 func (this *ClientImpl) CheckPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ...Permission) (access bool, err error, code int) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/check/%v/%v?permissions=%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), PermissionList(permissions).Encode()), nil)
+	return this.CheckPermissionWithModeContext(ctx, token, topicId, id, PermissionModeAll, permissions...)
+}
+
+func (this *ClientImpl) CheckPermissionWithMode(token string, topicId string, id string, mode PermissionMode, permissions ...Permission) (access bool, err error, code int) {
+	return this.CheckPermissionWithModeContext(context.TODO(), token, topicId, id, mode, permissions...)
+}
+
+func (this *ClientImpl) CheckPermissionWithModeContext(ctx context.Context, token string, topicId string, id string, mode PermissionMode, permissions ...Permission) (access bool, err error, code int) {
+	query := url.Values{}
+	query.Set("permissions", PermissionList(permissions).Encode())
+	setMode(query, mode)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/check/%v/%v?%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), query.Encode()), nil)
 	if err != nil {
 		return access, err, 0
 	}
@@ -131,9 +136,18 @@ func (this *ClientImpl) CheckMultiplePermissions(token string, topicId string, i
 }
 
 func (this *ClientImpl) CheckMultiplePermissionsContext(ctx context.Context, token string, topicId string, ids []string, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsWithModeContext(ctx, token, topicId, ids, PermissionModeAll, permissions...)
+}
+
+func (this *ClientImpl) CheckMultiplePermissionsWithMode(token string, topicId string, ids []string, mode PermissionMode, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsWithModeContext(context.TODO(), token, topicId, ids, mode, permissions...)
+}
+
+func (this *ClientImpl) CheckMultiplePermissionsWithModeContext(ctx context.Context, token string, topicId string, ids []string, mode PermissionMode, permissions ...Permission) (access map[string]bool, err error, code int) {
 	query := url.Values{}
 	query.Set("permissions", PermissionList(permissions).Encode())
 	query.Set("ids", strings.Join(ids, ","))
+	setMode(query, mode)
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/check/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
 	if err != nil {
 		return access, err, 0
@@ -141,6 +155,41 @@ func (this *ClientImpl) CheckMultiplePermissionsContext(ctx context.Context, tok
 	return doWithContext[map[string]bool](ctx, token, req)
 }
 
+func (this *ClientImpl) CheckPermissionsBatch(token string, queries []CheckQuery) (access map[string]map[string]bool, err error, code int) {
+	return this.CheckPermissionsBatchContext(context.TODO(), token, queries)
+}
+
+func (this *ClientImpl) CheckPermissionsBatchContext(ctx context.Context, token string, queries []CheckQuery) (access map[string]map[string]bool, err error, code int) {
+	body, err := json.Marshal(queries)
+	if err != nil {
+		return access, err, 0
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%v/check", this.serverUrl), bytes.NewReader(body))
+	if err != nil {
+		return access, err, 0
+	}
+	return doWithContext[map[string]map[string]bool](ctx, token, req)
+}
+
+func (this *ClientImpl) ExplainPermission(token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...Permission) (result model.Explanation, err error, code int) {
+	return this.ExplainPermissionContext(context.TODO(), token, topicId, id, onBehalf, permissions...)
+}
+
+func (this *ClientImpl) ExplainPermissionContext(ctx context.Context, token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...Permission) (result model.Explanation, err error, code int) {
+	query := url.Values{}
+	query.Set("permissions", PermissionList(permissions).Encode())
+	if onBehalf != nil {
+		query.Set("user", onBehalf.UserId)
+		query.Set("roles", strings.Join(onBehalf.RoleIds, ","))
+		query.Set("groups", strings.Join(onBehalf.GroupIds, ","))
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/explain/%v/%v?%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), query.Encode()), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.Explanation](ctx, token, req)
+}
+
 func (this *ClientImpl) ListComputedPermissions(token string, topicId string, ids []string) (result []model.ComputedPermissions, err error, code int) {
 	return this.ListComputedPermissionsContext(context.TODO(), token, topicId, ids)
 }
@@ -162,13 +211,7 @@ func (this *ClientImpl) AdminListResourceIds(token string, topicId string, optio
 }
 
 func (this *ClientImpl) AdminListResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions) (ids []string, err error, code int) {
-	query := url.Values{}
-	if options.Limit > 0 {
-		query.Set("limit", strconv.FormatInt(options.Limit, 10))
-	}
-	if options.Offset > 0 {
-		query.Set("offset", strconv.FormatInt(options.Offset, 10))
-	}
+	query := options.Query()
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/admin/resources/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
 	if err != nil {
 		return ids, err, 0
@@ -176,19 +219,35 @@ func (this *ClientImpl) AdminListResourceIdsContext(ctx context.Context, token s
 	return doWithContext[[]string](ctx, token, req)
 }
 
+func (this *ClientImpl) AdminCountResourceIds(token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return this.AdminCountResourceIdsContext(context.TODO(), token, topicId, options)
+}
+
+func (this *ClientImpl) AdminCountResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	query := countQuery(options)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/admin/resources/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
+	if err != nil {
+		return count, err, 0
+	}
+	return doTotalCountWithContext(ctx, token, req)
+}
+
 func (this *ClientImpl) ListAccessibleResourceIds(token string, topicId string, options ListOptions, permissions ...Permission) (ids []string, err error, code int) {
 	return this.ListAccessibleResourceIdsContext(context.TODO(), token, topicId, options, permissions...)
 }
 
 func (this *ClientImpl) ListAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions, permissions ...Permission) (ids []string, err error, code int) {
-	query := url.Values{}
+	return this.ListAccessibleResourceIdsWithModeContext(ctx, token, topicId, options, PermissionModeAll, permissions...)
+}
+
+func (this *ClientImpl) ListAccessibleResourceIdsWithMode(token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (ids []string, err error, code int) {
+	return this.ListAccessibleResourceIdsWithModeContext(context.TODO(), token, topicId, options, mode, permissions...)
+}
+
+func (this *ClientImpl) ListAccessibleResourceIdsWithModeContext(ctx context.Context, token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (ids []string, err error, code int) {
+	query := options.Query()
 	query.Set("permissions", PermissionList(permissions).Encode())
-	if options.Limit > 0 {
-		query.Set("limit", strconv.FormatInt(options.Limit, 10))
-	}
-	if options.Offset > 0 {
-		query.Set("offset", strconv.FormatInt(options.Offset, 10))
-	}
+	setMode(query, mode)
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/accessible/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
 	if err != nil {
 		return ids, err, 0
@@ -196,18 +255,27 @@ func (this *ClientImpl) ListAccessibleResourceIdsContext(ctx context.Context, to
 	return doWithContext[[]string](ctx, token, req)
 }
 
+func (this *ClientImpl) CountAccessibleResourceIds(token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (count int64, err error, code int) {
+	return this.CountAccessibleResourceIdsContext(context.TODO(), token, topicId, options, mode, permissions...)
+}
+
+func (this *ClientImpl) CountAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (count int64, err error, code int) {
+	query := countQuery(options)
+	query.Set("permissions", PermissionList(permissions).Encode())
+	setMode(query, mode)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/accessible/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
+	if err != nil {
+		return count, err, 0
+	}
+	return doTotalCountWithContext(ctx, token, req)
+}
+
 func (this *ClientImpl) ListResourcesWithAdminPermission(token string, topicId string, options ListOptions) (result []Resource, err error, code int) {
 	return this.ListResourcesWithAdminPermissionContext(context.TODO(), token, topicId, options)
 }
 
 func (this *ClientImpl) ListResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options ListOptions) (result []Resource, err error, code int) {
-	query := url.Values{}
-	if options.Limit > 0 {
-		query.Set("limit", strconv.FormatInt(options.Limit, 10))
-	}
-	if options.Offset > 0 {
-		query.Set("offset", strconv.FormatInt(options.Offset, 10))
-	}
+	query := options.Query()
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/manage/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
 	if err != nil {
 		return result, err, 0
@@ -215,6 +283,19 @@ func (this *ClientImpl) ListResourcesWithAdminPermissionContext(ctx context.Cont
 	return doWithContext[[]Resource](ctx, token, req)
 }
 
+func (this *ClientImpl) CountResourcesWithAdminPermission(token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return this.CountResourcesWithAdminPermissionContext(context.TODO(), token, topicId, options)
+}
+
+func (this *ClientImpl) CountResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	query := countQuery(options)
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/manage/%v?%v", this.serverUrl, url.PathEscape(topicId), query.Encode()), nil)
+	if err != nil {
+		return count, err, 0
+	}
+	return doTotalCountWithContext(ctx, token, req)
+}
+
 func (this *ClientImpl) GetResource(token string, topicId string, id string) (result Resource, err error, code int) {
 	return this.GetResourceContext(context.TODO(), token, topicId, id)
 }
@@ -229,25 +310,28 @@ func (this *ClientImpl) GetResourceContext(ctx context.Context, token string, to
 
 // RemoveResource removes a resource
 // only admins may remove resources
-func (this *ClientImpl) RemoveResource(token string, topicId string, id string) (err error, code int) {
-	return this.RemoveResourceContext(context.TODO(), token, topicId, id)
+func (this *ClientImpl) RemoveResource(token string, topicId string, id string, options ...WriteOptions) (err error, code int) {
+	return this.RemoveResourceContext(context.TODO(), token, topicId, id, options...)
 }
 
-func (this *ClientImpl) RemoveResourceContext(ctx context.Context, token string, topicId string, id string) (err error, code int) {
+func (this *ClientImpl) RemoveResourceContext(ctx context.Context, token string, topicId string, id string, options ...WriteOptions) (err error, code int) {
 	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%v/manage/%v/%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id)), nil)
 	if err != nil {
 		return err, 0
 	}
+	setIfMatch(req, options)
 	return doVoidWithContext(ctx, token, req)
 }
 
 // SetPermission sets the permissions of a resource.
-// resource initialization needs to be done by an admin; user tokens may update their rights but may not create the initial resource
-func (this *ClientImpl) SetPermission(token string, topicId string, id string, permissions ResourcePermissions) (result ResourcePermissions, err error, code int) {
-	return this.SetPermissionContext(context.TODO(), token, topicId, id, permissions)
+// resource initialization needs to be done by an admin, unless the creation policy of the topic (model.Topic.CreationPolicy) allows the user to create the resource;
+// the creator becomes admin of the new resource. other user tokens may update their rights but may not create the initial resource
+// grants of users, who are no admins, are limited by the sharing policy of the topic; violations return an error wrapping *SharingPolicyError
+func (this *ClientImpl) SetPermission(token string, topicId string, id string, permissions ResourcePermissions, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return this.SetPermissionContext(context.TODO(), token, topicId, id, permissions, options...)
 }
 
-func (this *ClientImpl) SetPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ResourcePermissions) (result ResourcePermissions, err error, code int) {
+func (this *ClientImpl) SetPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ResourcePermissions, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
 	body, err := json.Marshal(permissions)
 	if err != nil {
 		return result, err, 0
@@ -256,9 +340,45 @@ func (this *ClientImpl) SetPermissionContext(ctx context.Context, token string,
 	if err != nil {
 		return result, err, 0
 	}
+	setIfMatch(req, options)
+	return doWithContext[ResourcePermissions](ctx, token, req)
+}
+
+// PatchPermission adds, changes or removes individual entries of the permissions of an existing resource.
+// the resulting permissions are checked like the permissions of SetPermission
+func (this *ClientImpl) PatchPermission(token string, topicId string, id string, patch PermissionsPatch, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return this.PatchPermissionContext(context.TODO(), token, topicId, id, patch, options...)
+}
+
+func (this *ClientImpl) PatchPermissionContext(ctx context.Context, token string, topicId string, id string, patch PermissionsPatch, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return result, err, 0
+	}
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("%v/manage/%v/%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id)), bytes.NewReader(body))
+	if err != nil {
+		return result, err, 0
+	}
+	setIfMatch(req, options)
 	return doWithContext[ResourcePermissions](ctx, token, req)
 }
 
+func (this *ClientImpl) SetPermissionsBulk(token string, topicId string, items []BulkPermissionsItem) (result []BulkPermissionsResult, err error, code int) {
+	return this.SetPermissionsBulkContext(context.TODO(), token, topicId, items)
+}
+
+func (this *ClientImpl) SetPermissionsBulkContext(ctx context.Context, token string, topicId string, items []BulkPermissionsItem) (result []BulkPermissionsResult, err error, code int) {
+	body, err := json.Marshal(items)
+	if err != nil {
+		return result, err, 0
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%v/manage/%v", this.serverUrl, url.PathEscape(topicId)), bytes.NewReader(body))
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]BulkPermissionsResult](ctx, token, req)
+}
+
 // AdminLoadFromPermissionSearch is not supported by the client
 // because this request should never be automated
 func (this *ClientImpl) AdminLoadFromPermissionSearch(req model.AdminLoadPermSearchRequest) (updateCount int, err error, code int) {
@@ -269,6 +389,20 @@ func (this *ClientImpl) AdminLoadFromPermissionSearchContext(_ context.Context,
 	panic("no client support: this request should never be automated")
 }
 
+// setMode adds the mode to the query; the default mode is omitted to keep the requests of the variants without mode unchanged
+func setMode(query url.Values, mode PermissionMode) {
+	if mode != "" && mode != PermissionModeAll {
+		query.Set("mode", string(mode))
+	}
+}
+
+// setIfMatch sends the expected version of the options as If-Match header
+func setIfMatch(req *http.Request, options []WriteOptions) {
+	if len(options) > 0 && options[0].ExpectedVersion != 0 {
+		req.Header.Set("If-Match", model.ETag(options[0].ExpectedVersion))
+	}
+}
+
 func doWithContext[T any](ctx context.Context, token string, req *http.Request) (result T, err error, code int) {
 	err = otelx.InjectContextToRequest(ctx, req)
 	if err != nil {
@@ -300,7 +434,7 @@ func do[T any](token string, req *http.Request) (result T, err error, code int)
 	defer resp.Body.Close()
 	if resp.StatusCode > 299 {
 		temp, _ := io.ReadAll(resp.Body) //read error response end ensure that resp.Body is read to EOF
-		return result, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)), resp.StatusCode
+		return result, responseError(resp.StatusCode, temp), resp.StatusCode
 	}
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	if err != nil {
@@ -310,6 +444,53 @@ func do[T any](token string, req *http.Request) (result T, err error, code int)
 	return result, nil, resp.StatusCode
 }
 
+// responseError returns the error of a failed request; sharing policy violations are wrapped as *model.SharingPolicyError
+func responseError(code int, body []byte) error {
+	if code == http.StatusForbidden {
+		if violation, ok := model.SharingPolicyErrorFromResponse(body); ok {
+			return fmt.Errorf("unexpected statuscode %v: %w", code, violation)
+		}
+	}
+	return fmt.Errorf("unexpected statuscode %v: %v", code, string(body))
+}
+
+// countQuery returns the query of a list request for the total count of the list with the id search of the options
+func countQuery(options ListOptions) url.Values {
+	query := ListOptions{IdPrefix: options.IdPrefix, IdRegex: options.IdRegex}.Query()
+	query.Set("limit", "1")
+	query.Set("total", "true")
+	return query
+}
+
+// doTotalCountWithContext requests a list with total=true and returns the total count header; the list itself is ignored
+func doTotalCountWithContext(ctx context.Context, token string, req *http.Request) (count int64, err error, code int) {
+	err = otelx.InjectContextToRequest(ctx, req)
+	if err != nil {
+		return count, err, http.StatusInternalServerError
+	}
+	req.Header.Set("Authorization", token)
+
+	//add version query param
+	query := req.URL.Query()
+	query.Set("version", ClientVersion)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return count, err, http.StatusInternalServerError
+	}
+	defer resp.Body.Close()
+	temp, _ := io.ReadAll(resp.Body) //ensure resp.Body is read to EOF
+	if resp.StatusCode > 299 {
+		return count, fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)), resp.StatusCode
+	}
+	count, err = strconv.ParseInt(resp.Header.Get(model.TotalCountHeader), 10, 64)
+	if err != nil {
+		return count, fmt.Errorf("invalid %v header: %w", model.TotalCountHeader, err), http.StatusInternalServerError
+	}
+	return count, nil, resp.StatusCode
+}
+
 func doVoid(token string, req *http.Request) (err error, code int) {
 	req.Header.Set("Authorization", token)
 
@@ -325,7 +506,7 @@ func doVoid(token string, req *http.Request) (err error, code int) {
 	defer resp.Body.Close()
 	if resp.StatusCode > 299 {
 		temp, _ := io.ReadAll(resp.Body) //read error response end ensure that resp.Body is read to EOF
-		return fmt.Errorf("unexpected statuscode %v: %v", resp.StatusCode, string(temp)), resp.StatusCode
+		return responseError(resp.StatusCode, temp), resp.StatusCode
 	}
 	return nil, resp.StatusCode
 }
@@ -19,11 +19,28 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/grpcapi"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestClientInterface(t *testing.T) {
@@ -35,6 +52,11 @@ func TestClientInterface(t *testing.T) {
 		t.Error("client is nil")
 		return
 	}
+	c = NewGrpcWithConnection(nil) //*GrpcClientImpl implements Client
+	if c == nil {
+		t.Error("client is nil")
+		return
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	c, err = NewTestClient(ctx) //*controller.Controller implements Client
@@ -143,3 +165,902 @@ func TestEmbed(t *testing.T) {
 		return
 	}
 }
+
+func TestExpectedVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "testtopic"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+	_, err, _ = httpClient.SetPermission(InternalAdminToken, "testtopic", "r1", permissions)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resource, err, _ := httpClient.GetResource(InternalAdminToken, "testtopic", "r1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if resource.Version != 1 {
+		t.Errorf("expected version 1, got %v", resource.Version)
+		return
+	}
+	_, err, _ = httpClient.SetPermission(InternalAdminToken, "testtopic", "r1", permissions, WriteOptions{ExpectedVersion: resource.Version})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, code := httpClient.SetPermission(InternalAdminToken, "testtopic", "r1", permissions, WriteOptions{ExpectedVersion: resource.Version})
+	if err == nil || code != http.StatusPreconditionFailed {
+		t.Errorf("expected precondition failed, got %v %v", err, code)
+	}
+	err, code = httpClient.RemoveResource(InternalAdminToken, "testtopic", "r1", WriteOptions{ExpectedVersion: resource.Version})
+	if err == nil || code != http.StatusPreconditionFailed {
+		t.Errorf("expected precondition failed, got %v %v", err, code)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/permissions/manage/testtopic/r1", nil)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	req.Header.Set("Authorization", InternalAdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	resp.Body.Close()
+	if etag := resp.Header.Get("ETag"); etag != `"2"` {
+		t.Errorf("unexpected etag %v", etag)
+	}
+
+	write := func(method string, id string, ifMatch string, body string) (etag string, code int) {
+		req, err := http.NewRequest(method, server.URL+"/permissions/manage/testtopic/"+id, strings.NewReader(body))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		req.Header.Set("Authorization", InternalAdminToken)
+		req.Header.Set("If-Match", ifMatch)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		return resp.Header.Get("ETag"), resp.StatusCode
+	}
+	body := `{"user_permissions": {"owner": {"read": true, "write": true, "execute": true, "administrate": true}}}`
+	if _, code = write(http.MethodPut, "r1", `W/"2"`, body); code != http.StatusBadRequest {
+		t.Errorf("expected bad request for weak etag, got %v", code)
+	}
+	if _, code = write(http.MethodPut, "unknown", "*", body); code != http.StatusPreconditionFailed {
+		t.Errorf("expected precondition failed for missing resource, got %v", code)
+	}
+	if _, code = write(http.MethodPatch, "unknown", "*", `{}`); code != http.StatusPreconditionFailed {
+		t.Errorf("expected precondition failed for missing resource, got %v", code)
+	}
+	etag, code := write(http.MethodPut, "r1", "*", body)
+	if code != http.StatusOK || etag != `"3"` {
+		t.Errorf("unexpected put response %v %v", code, etag)
+	}
+	etag, code = write(http.MethodPatch, "r1", etag, `{"user_permissions": {"reader": {"read": true}}}`)
+	if code != http.StatusOK || etag != `"4"` {
+		t.Errorf("unexpected patch response %v %v", code, etag)
+	}
+	err, code = httpClient.RemoveResource(InternalAdminToken, "testtopic", "unknown", WriteOptions{ExpectedVersion: model.ExpectExisting})
+	if err == nil || code != http.StatusPreconditionFailed {
+		t.Errorf("expected precondition failed for missing resource, got %v %v", err, code)
+	}
+	err, _ = httpClient.RemoveResource(InternalAdminToken, "testtopic", "r1", WriteOptions{ExpectedVersion: model.ExpectExisting})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCursorPagination(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	expectedTopics := []string{"topic1", "topic2", "topic3"}
+	for _, topic := range expectedTopics {
+		_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: topic})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+	expectedIds := []string{"a", "b", "c", "d", "e"}
+	for _, id := range expectedIds {
+		_, err, _ = httpClient.SetPermission(InternalAdminToken, "topic1", id, permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	t.Run("iterate", func(t *testing.T) {
+		for _, pageSize := range []int64{1, 2, 5, 10} {
+			ids := []string{}
+			for id, err := range IterateAdminResourceIds(ctx, httpClient, InternalAdminToken, "topic1", ListOptions{Limit: pageSize}) {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids = append(ids, id)
+			}
+			if !reflect.DeepEqual(ids, expectedIds) {
+				t.Errorf("%v: %#v", pageSize, ids)
+			}
+
+			ids = []string{}
+			for id, err := range IterateAccessibleResourceIds(ctx, httpClient, InternalAdminToken, "topic1", ListOptions{Limit: pageSize}, PermissionModeAll, Read) {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids = append(ids, id)
+			}
+			if !reflect.DeepEqual(ids, expectedIds) {
+				t.Errorf("%v: %#v", pageSize, ids)
+			}
+
+			ids = []string{}
+			for resource, err := range IterateResourcesWithAdminPermission(ctx, httpClient, InternalAdminToken, "topic1", ListOptions{Limit: pageSize}) {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids = append(ids, resource.Id)
+			}
+			if !reflect.DeepEqual(ids, expectedIds) {
+				t.Errorf("%v: %#v", pageSize, ids)
+			}
+
+			ids = []string{}
+			for topic, err := range IterateTopics(ctx, httpClient, InternalAdminToken, ListOptions{Limit: pageSize}) {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				ids = append(ids, topic.Id)
+			}
+			if !reflect.DeepEqual(ids, expectedTopics) {
+				t.Errorf("%v: %#v", pageSize, ids)
+			}
+		}
+	})
+
+	t.Run("link header", func(t *testing.T) {
+		path := "/permissions/admin/resources/topic1?limit=2"
+		ids := []string{}
+		for path != "" {
+			req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			req.Header.Set("Authorization", InternalAdminToken)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			page := []string{}
+			err = json.NewDecoder(resp.Body).Decode(&page)
+			resp.Body.Close()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids = append(ids, page...)
+			path = ""
+			if link := resp.Header.Get("Link"); link != "" {
+				next, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(link, "<"), `>; rel="next"`))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				path = req.URL.ResolveReference(next).RequestURI()
+			}
+		}
+		if !reflect.DeepEqual(ids, expectedIds) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("cursor with offset", func(t *testing.T) {
+		_, err, code := httpClient.AdminListResourceIds(InternalAdminToken, "topic1", ListOptions{Limit: 2, Offset: 1, Cursor: model.NextCursor("a")})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v %v", err, code)
+		}
+	})
+}
+
+func TestTotalCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "topic1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		_, err, _ = httpClient.SetPermission(InternalAdminToken, "topic1", id, permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	counts := map[string]func() (int64, error, int){
+		"admin ids": func() (int64, error, int) {
+			return httpClient.AdminCountResourceIds(InternalAdminToken, "topic1", ListOptions{})
+		},
+		"accessible": func() (int64, error, int) {
+			return httpClient.CountAccessibleResourceIds(InternalAdminToken, "topic1", ListOptions{}, PermissionModeAny, Read, Execute)
+		},
+		"admin permission": func() (int64, error, int) {
+			return httpClient.CountResourcesWithAdminPermission(InternalAdminToken, "topic1", ListOptions{})
+		},
+	}
+	for name, count := range counts {
+		t.Run(name, func(t *testing.T) {
+			result, err, _ := count()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if result != 3 {
+				t.Errorf("expected 3, got %v", result)
+			}
+		})
+	}
+
+	t.Run("header", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/permissions/accessible/topic1?limit=2&total=true", nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		req.Header.Set("Authorization", InternalAdminToken)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ids := []string{}
+		err = json.NewDecoder(resp.Body).Decode(&ids)
+		resp.Body.Close()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 2 || resp.Header.Get(model.TotalCountHeader) != "3" {
+			t.Errorf("%#v %#v", ids, resp.Header.Get(model.TotalCountHeader))
+		}
+	})
+}
+
+func TestListSearchAndSort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "topic1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+	for _, id := range []string{"b-2", "a-1", "b-1", "a-2"} {
+		_, err, _ = httpClient.SetPermission(InternalAdminToken, "topic1", id, permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	t.Run("prefix desc", func(t *testing.T) {
+		ids, err, _ := httpClient.AdminListResourceIds(InternalAdminToken, "topic1", ListOptions{IdPrefix: "b-", SortDesc: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"b-2", "b-1"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("regex count", func(t *testing.T) {
+		count, err, _ := httpClient.CountAccessibleResourceIds(InternalAdminToken, "topic1", ListOptions{IdRegex: "-1$"}, PermissionModeAll, Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 2 {
+			t.Errorf("expected 2, got %v", count)
+		}
+	})
+
+	t.Run("iterate by timestamp", func(t *testing.T) {
+		ids := []string{}
+		for resource, err := range IterateResourcesWithAdminPermission(ctx, httpClient, InternalAdminToken, "topic1", ListOptions{Limit: 3, SortBy: SortByTimestamp, SortDesc: true}) {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids = append(ids, resource.Id)
+		}
+		if !reflect.DeepEqual(ids, []string{"a-2", "b-1", "a-1", "b-2"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("iterate desc", func(t *testing.T) {
+		ids := []string{}
+		for id, err := range IterateAccessibleResourceIds(ctx, httpClient, InternalAdminToken, "topic1", ListOptions{Limit: 1, SortDesc: true}, PermissionModeAll, Read) {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids = append(ids, id)
+		}
+		if !reflect.DeepEqual(ids, []string{"b-2", "b-1", "a-2", "a-1"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		_, err, code := httpClient.ListResourcesWithAdminPermission(InternalAdminToken, "topic1", ListOptions{SortBy: "name"})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v %v", err, code)
+		}
+	})
+}
+
+func TestChangeFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "topic1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+
+	receive := func(events iter.Seq2[ChangeEvent, error], count int) (result []ChangeEvent) {
+		for event, err := range events {
+			if err != nil {
+				t.Error(err)
+				return result
+			}
+			result = append(result, event)
+			if len(result) == count {
+				return result
+			}
+		}
+		return result
+	}
+
+	subCtx, subCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer subCancel()
+	events, err, _ := httpClient.WatchPermissionChangesContext(subCtx, InternalAdminToken, "topic1", ChangeFeedOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, id := range []string{"a", "b"} {
+		_, err, _ = httpClient.SetPermission(InternalAdminToken, "topic1", id, permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	received := receive(events, 2)
+	if len(received) != 2 || received[0].Id != "a" || received[1].Id != "b" || !received[1].Permissions.UserPermissions["owner"].Administrate {
+		t.Errorf("%#v", received)
+		return
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		events, err, _ := httpClient.WatchPermissionChangesContext(subCtx, InternalAdminToken, "topic1", ChangeFeedOptions{ResumeToken: received[0].Token})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resumed := receive(events, 1)
+		if len(resumed) != 1 || resumed[0].Id != "b" || resumed[0].Token != received[1].Token {
+			t.Errorf("%#v", resumed)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err, code := httpClient.WatchPermissionChangesContext(subCtx, InternalAdminToken, "topic1", ChangeFeedOptions{ResumeToken: "unknown"})
+		if err == nil || code != http.StatusGone {
+			t.Errorf("expected gone, got %v %v", err, code)
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	received := make(chan WebhookPayload, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		err := VerifyWebhookSignature("secret", req.Header, body, time.Minute)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		payload := WebhookPayload{}
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer receiver.Close()
+
+	webhook, err, _ := httpClient.SetWebhook(InternalAdminToken, Webhook{Url: receiver.URL, Secret: "secret", Events: []WebhookEventType{WebhookEventSetResource}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if webhook.Id == "" || webhook.Secret != "" {
+		t.Errorf("%#v", webhook)
+		return
+	}
+	webhook.Url = receiver.URL + "/"
+	_, err, _ = httpClient.SetWebhook(InternalAdminToken, webhook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stored, err, _ := httpClient.GetWebhook(InternalAdminToken, webhook.Id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if stored.Url != receiver.URL+"/" {
+		t.Errorf("%#v", stored)
+	}
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "topic1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = httpClient.SetPermission(InternalAdminToken, "topic1", "a", ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	select {
+	case payload := <-received:
+		if payload.Event != WebhookEventSetResource || payload.TopicId != "topic1" || payload.ResourceId != "a" {
+			t.Errorf("%#v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("timeout")
+		return
+	}
+
+	var deliveries []WebhookDelivery
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(50 * time.Millisecond) {
+		deliveries, err, _ = httpClient.ListWebhookDeliveries(InternalAdminToken, webhook.Id, WebhookDeliveryListOptions{Status: model.WebhookDeliveryDelivered})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(deliveries) == 1 {
+			break
+		}
+	}
+	if len(deliveries) != 1 {
+		t.Errorf("%#v", deliveries)
+		return
+	}
+	_, err, code := httpClient.RetryWebhookDelivery(InternalAdminToken, webhook.Id, deliveries[0].Id)
+	if err == nil || code != http.StatusBadRequest {
+		t.Error(err, code)
+	}
+
+	err, _ = httpClient.RemoveWebhook(InternalAdminToken, webhook.Id)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	list, err, _ := httpClient.ListWebhooks(InternalAdminToken)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list) != 0 {
+		t.Errorf("%#v", list)
+	}
+}
+
+func TestGrpc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+
+	startGrpc := func(config configuration.Config) (*GrpcClientImpl, error) {
+		listener := bufconn.Listen(1 << 20)
+		grpcServer, err := grpcapi.NewServer(config, c)
+		if err != nil {
+			return nil, err
+		}
+		go grpcServer.Serve(listener)
+		go func() {
+			<-ctx.Done()
+			grpcServer.Stop()
+		}()
+		return NewGrpc("passthrough:///bufconn", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}))
+	}
+	grpcClient, err := startGrpc(configuration.Config{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer grpcClient.Close()
+
+	permissions := ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+		RolePermissions: map[string]PermissionsMap{"admin": {Read: true}},
+	}
+
+	type callResult struct {
+		Name   string
+		Result any
+		Err    bool
+		Code   int
+	}
+	//run the same calls with both clients; topic and resource ids are the same, because the clients use different topics
+	run := func(client Client, topicId string) (results []callResult) {
+		add := func(name string, result any, err error, code int) {
+			results = append(results, callResult{Name: name, Result: result, Err: err != nil, Code: code})
+		}
+		topic, err, code := client.SetTopic(InternalAdminToken, Topic{Id: topicId})
+		topic.Id = ""
+		add("SetTopic", topic, err, code)
+		topic, err, code = client.SetTopic(InternalAdminToken, Topic{Id: topicId})
+		topic.Id = ""
+		add("SetTopic unchanged", topic, err, code)
+		_, err, code = client.GetTopic(InternalAdminToken, topicId+"-unknown")
+		add("GetTopic unknown", nil, err, code)
+		result, err, code := client.SetPermission(InternalAdminToken, topicId, "r1", permissions)
+		add("SetPermission", result, err, code)
+		result, err, code = client.SetPermission(InternalAdminToken, topicId, "r1", permissions, WriteOptions{ExpectedVersion: 5})
+		add("SetPermission version mismatch", result, err, code)
+		resource, err, code := client.GetResource(InternalAdminToken, topicId, "r1")
+		resource.TopicId = ""
+		add("GetResource", resource, err, code)
+		_, err, code = client.GetResource("", topicId, "r1")
+		add("GetResource without token", nil, err, code)
+		access, err, code := client.CheckPermission(InternalAdminToken, topicId, "r1", Read)
+		add("CheckPermission", access, err, code)
+		access, err, code = client.CheckPermission(InternalAdminToken, topicId, "r1")
+		add("CheckPermission default", access, err, code)
+		accessMap, err, code := client.CheckMultiplePermissionsWithMode(InternalAdminToken, topicId, []string{"r1", "r2"}, PermissionModeAny, Write, Read)
+		add("CheckMultiplePermissionsWithMode", accessMap, err, code)
+		_, err, code = client.CheckMultiplePermissions(InternalAdminToken, topicId, []string{"r1"}, Permission('?'))
+		add("CheckMultiplePermissions invalid", nil, err, code)
+		ids, err, code := client.ListAccessibleResourceIds(InternalAdminToken, topicId, ListOptions{}, Read)
+		add("ListAccessibleResourceIds", ids, err, code)
+		count, err, code := client.CountAccessibleResourceIds(InternalAdminToken, topicId, ListOptions{}, PermissionModeAll, Read)
+		add("CountAccessibleResourceIds", count, err, code)
+		ids, err, code = client.AdminListResourceIds(InternalAdminToken, topicId, ListOptions{})
+		add("AdminListResourceIds", ids, err, code)
+		patched, err, code := client.PatchPermission(InternalAdminToken, topicId, "r1", PermissionsPatch{RolePermissions: map[string]*PermissionsMap{"admin": nil}})
+		add("PatchPermission", patched, err, code)
+		versions, err, code := client.ListPermissionVersions(InternalAdminToken, topicId, "r1", ListOptions{})
+		add("ListPermissionVersions", len(versions), err, code)
+		bulk, err, code := client.SetPermissionsBulk(InternalAdminToken, topicId, []BulkPermissionsItem{{Id: "r2", Permissions: permissions}, {Id: "r3"}})
+		add("SetPermissionsBulk", bulk, err, code)
+		err, code = client.RemoveResource(InternalAdminToken, topicId, "r2")
+		add("RemoveResource", nil, err, code)
+		return results
+	}
+
+	httpResults := run(New(server.URL+"/permissions"), "http")
+	grpcResults := run(grpcClient, "grpc")
+	for i := range httpResults {
+		if i >= len(grpcResults) || !reflect.DeepEqual(httpResults[i], grpcResults[i]) {
+			t.Errorf("\nhttp: %#v\ngrpc: %#v", httpResults[i], grpcResults[i])
+		}
+	}
+
+	t.Run("change feed", func(t *testing.T) {
+		subCtx, subCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer subCancel()
+		_, err, code := grpcClient.WatchPermissionChangesContext(subCtx, InternalAdminToken, "grpc", ChangeFeedOptions{ResumeToken: "unknown"})
+		if err == nil || code != http.StatusGone {
+			t.Errorf("expected gone, got %v %v", err, code)
+		}
+		events, err, _ := grpcClient.WatchPermissionChangesContext(subCtx, InternalAdminToken, "grpc", ChangeFeedOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = grpcClient.SetPermission(InternalAdminToken, "grpc", "r4", permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for event, err := range events {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if event.Id != "r4" || event.TopicId != "grpc" {
+				t.Errorf("%#v", event)
+			}
+			break
+		}
+	})
+
+	t.Run("version check", func(t *testing.T) {
+		err = grpcClient.conn.Invoke(metadata.AppendToOutgoingContext(ctx, grpcapi.VersionMetadata, "1"), grpcapi.FullMethod(grpcapi.MethodListTopics), grpcapi.ListRequest{}, &[]Topic{}, grpc.CallContentSubtype(grpcapi.CodecName))
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Error(err)
+		}
+	})
+
+	t.Run("edit forward", func(t *testing.T) {
+		forwarding, err := startGrpc(configuration.Config{EditForward: "http://localhost"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer forwarding.Close()
+		_, err, code := forwarding.SetTopic(InternalAdminToken, Topic{Id: "forward"})
+		if err == nil || code != http.StatusNotImplemented {
+			t.Errorf("expected not implemented, got %v %v", err, code)
+		}
+		_, err, _ = forwarding.GetTopic(InternalAdminToken, "grpc")
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestServiceCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "topic1"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	credential, err, _ := httpClient.CreateServiceCredential(InternalAdminToken, ServiceCredential{Id: "service1", TopicIds: []string{"topic1"}, Scopes: []ServiceCredentialScope{ServiceCredentialScopeManage}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if credential.Key == "" {
+		t.Errorf("%#v", credential)
+		return
+	}
+
+	provider := NewServiceCredentialProvider(credential.Key)
+	token, err := provider()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = httpClient.SetPermission(token, "topic1", "a", ResourcePermissions{
+		UserPermissions: map[string]PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	access, err, _ := httpClient.CheckPermission(token, "topic1", "a", model.Administrate)
+	if err != nil || !access {
+		t.Error(access, err)
+		return
+	}
+	_, err, code := httpClient.ListServiceCredentials(token)
+	if err == nil || code != http.StatusForbidden {
+		t.Error(err, code)
+	}
+
+	renewed, err, _ := httpClient.RenewServiceCredentialKey(InternalAdminToken, "service1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, code = httpClient.CheckPermission(token, "topic1", "a", model.Read)
+	if err == nil || code != http.StatusUnauthorized {
+		t.Error(err, code)
+	}
+	_, err, _ = httpClient.CheckPermission(ServiceCredentialToken(renewed.Key), "topic1", "a", model.Read)
+	if err != nil {
+		t.Error(err)
+	}
+
+	credential.Disabled = true
+	_, err, _ = httpClient.UpdateServiceCredential(InternalAdminToken, credential.ServiceCredential)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	stored, err, _ := httpClient.GetServiceCredential(InternalAdminToken, "service1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !stored.Disabled || stored.KeyHash != "" {
+		t.Errorf("%#v", stored)
+	}
+
+	err, _ = httpClient.RemoveServiceCredential(InternalAdminToken, "service1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	list, err, _ := httpClient.ListServiceCredentials(InternalAdminToken)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(list) != 0 {
+		t.Errorf("%#v", list)
+	}
+}
+
+// user "testOwner" with the role "user"
+const testUserToken = `Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJqdGkiOiIwOGM0N2E4OC0yYzc5LTQyMGYtODEwNC02NWJkOWViYmU0MWUiLCJleHAiOjE1NDY1MDcyMzMsIm5iZiI6MCwiaWF0IjoxNTQ2NTA3MTczLCJpc3MiOiJodHRwOi8vbG9jYWxob3N0OjgwMDEvYXV0aC9yZWFsbXMvbWFzdGVyIiwiYXVkIjoiZnJvbnRlbmQiLCJzdWIiOiJ0ZXN0T3duZXIiLCJ0eXAiOiJCZWFyZXIiLCJhenAiOiJmcm9udGVuZCIsIm5vbmNlIjoiOTJjNDNjOTUtNzViMC00NmNmLTgwYWUtNDVkZDk3M2I0YjdmIiwiYXV0aF90aW1lIjoxNTQ2NTA3MDA5LCJzZXNzaW9uX3N0YXRlIjoiNWRmOTI4ZjQtMDhmMC00ZWI5LTliNjAtM2EwYWUyMmVmYzczIiwiYWNyIjoiMCIsImFsbG93ZWQtb3JpZ2lucyI6WyIqIl0sInJlYWxtX2FjY2VzcyI6eyJyb2xlcyI6WyJ1c2VyIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsibWFzdGVyLXJlYWxtIjp7InJvbGVzIjpbInZpZXctcmVhbG0iLCJ2aWV3LWlkZW50aXR5LXByb3ZpZGVycyIsIm1hbmFnZS1pZGVudGl0eS1wcm92aWRlcnMiLCJpbXBlcnNvbmF0aW9uIiwiY3JlYXRlLWNsaWVudCIsIm1hbmFnZS11c2VycyIsInF1ZXJ5LXJlYWxtcyIsInZpZXctYXV0aG9yaXphdGlvbiIsInF1ZXJ5LWNsaWVudHMiLCJxdWVyeS11c2VycyIsIm1hbmFnZS1ldmVudHMiLCJtYW5hZ2UtcmVhbG0iLCJ2aWV3LWV2ZW50cyIsInZpZXctdXNlcnMiLCJ2aWV3LWNsaWVudHMiLCJtYW5hZ2UtYXV0aG9yaXphdGlvbiIsIm1hbmFnZS1jbGllbnRzIiwicXVlcnktZ3JvdXBzIl19LCJhY2NvdW50Ijp7InJvbGVzIjpbIm1hbmFnZS1hY2NvdW50IiwibWFuYWdlLWFjY291bnQtbGlua3MiLCJ2aWV3LXByb2ZpbGUiXX19LCJyb2xlcyI6WyJ1c2VyIl19.ykpuOmlpzj75ecSI6cHbCATIeY4qpyut2hMc1a67Ycg`
+
+func TestSharingPolicyError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewTestClient(ctx)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	server := httptest.NewServer(EmbedPermissionsClientIntoRouter(c, http.NewServeMux(), "/permissions/", nil))
+	defer server.Close()
+	httpClient := New(server.URL + "/permissions")
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer, err := grpcapi.NewServer(configuration.Config{}, c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+	grpcClient, err := NewGrpc("passthrough:///bufconn", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer grpcClient.Close()
+
+	_, err, _ = httpClient.SetTopic(InternalAdminToken, Topic{Id: "shared", SharingPolicy: &SharingPolicy{MaxUserPermissions: &PermissionsMap{Read: true}}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	owner := PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	_, err, _ = httpClient.SetPermission(InternalAdminToken, "shared", "r1", ResourcePermissions{UserPermissions: map[string]PermissionsMap{"testOwner": owner}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for name, client := range map[string]Client{"http": httpClient, "grpc": grpcClient} {
+		t.Run(name, func(t *testing.T) {
+			_, err, code := client.SetPermission(testUserToken, "shared", "r1", ResourcePermissions{UserPermissions: map[string]PermissionsMap{"testOwner": owner, "other": {Read: true, Write: true}}})
+			if code != http.StatusForbidden || !errors.Is(err, ErrSharingPolicyViolation) {
+				t.Error(code, err)
+				return
+			}
+			var violation *SharingPolicyError
+			if !errors.As(err, &violation) {
+				t.Errorf("%T", err)
+				return
+			}
+			if !reflect.DeepEqual(violation.Violations, []SharingPolicyViolation{{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "other", Permissions: "w"}}) {
+				t.Errorf("%#v", violation.Violations)
+			}
+			_, err, _ = client.SetPermission(testUserToken, "shared", "r1", ResourcePermissions{UserPermissions: map[string]PermissionsMap{"testOwner": owner, "other": {Read: true}}})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+type ServiceCredential = model.ServiceCredential
+type ServiceCredentialWithKey = model.ServiceCredentialWithKey
+type ServiceCredentialScope = model.ServiceCredentialScope
+
+const ServiceCredentialScopeCheck = model.ServiceCredentialScopeCheck
+const ServiceCredentialScopeManage = model.ServiceCredentialScopeManage
+const ServiceCredentialScopeTopicAdmin = model.ServiceCredentialScopeTopicAdmin
+
+// ServiceCredentialToken returns the value of the Authorization header for the key of a service credential.
+// it may be used as token in all client methods, in place of a user token or InternalAdminToken.
+func ServiceCredentialToken(key string) string {
+	return model.ServiceCredentialAuthPrefix + key
+}
+
+// NewServiceCredentialProvider returns a token provider for the key of a service credential; may be used in place of NewTokenProvider
+func NewServiceCredentialProvider(key string) func() (string, error) {
+	token := ServiceCredentialToken(key)
+	return func() (string, error) {
+		return token, nil
+	}
+}
+
+func (this *ClientImpl) ListServiceCredentials(token string) (result []model.ServiceCredential, err error, code int) {
+	return this.ListServiceCredentialsContext(context.TODO(), token)
+}
+
+func (this *ClientImpl) ListServiceCredentialsContext(ctx context.Context, token string) (result []model.ServiceCredential, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, this.serverUrl+"/admin/credentials", nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]model.ServiceCredential](ctx, token, req)
+}
+
+func (this *ClientImpl) GetServiceCredential(token string, id string) (result model.ServiceCredential, err error, code int) {
+	return this.GetServiceCredentialContext(context.TODO(), token, id)
+}
+
+func (this *ClientImpl) GetServiceCredentialContext(ctx context.Context, token string, id string) (result model.ServiceCredential, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, this.serverUrl+"/admin/credentials/"+url.PathEscape(id), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.ServiceCredential](ctx, token, req)
+}
+
+func (this *ClientImpl) CreateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.CreateServiceCredentialContext(context.TODO(), token, credential)
+}
+
+func (this *ClientImpl) CreateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	body, err := json.Marshal(credential)
+	if err != nil {
+		return result, err, 0
+	}
+	req, err := http.NewRequest(http.MethodPost, this.serverUrl+"/admin/credentials", bytes.NewBuffer(body))
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.ServiceCredentialWithKey](ctx, token, req)
+}
+
+func (this *ClientImpl) UpdateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	return this.UpdateServiceCredentialContext(context.TODO(), token, credential)
+}
+
+func (this *ClientImpl) UpdateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	body, err := json.Marshal(credential)
+	if err != nil {
+		return result, err, 0
+	}
+	req, err := http.NewRequest(http.MethodPut, this.serverUrl+"/admin/credentials/"+url.PathEscape(credential.Id), bytes.NewBuffer(body))
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.ServiceCredential](ctx, token, req)
+}
+
+func (this *ClientImpl) RenewServiceCredentialKey(token string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.RenewServiceCredentialKeyContext(context.TODO(), token, id)
+}
+
+func (this *ClientImpl) RenewServiceCredentialKeyContext(ctx context.Context, token string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, this.serverUrl+"/admin/credentials/"+url.PathEscape(id)+"/key", nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.ServiceCredentialWithKey](ctx, token, req)
+}
+
+func (this *ClientImpl) RemoveServiceCredential(token string, id string) (err error, code int) {
+	return this.RemoveServiceCredentialContext(context.TODO(), token, id)
+}
+
+func (this *ClientImpl) RemoveServiceCredentialContext(ctx context.Context, token string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodDelete, this.serverUrl+"/admin/credentials/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err, 0
+	}
+	return doVoidWithContext(ctx, token, req)
+}
@@ -0,0 +1,518 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/grpcapi"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// NewGrpc returns a Client, that uses the grpc api of the service (config.GrpcPort) instead of the http api.
+// without options, the connection is not encrypted.
+func NewGrpc(target string, options ...grpc.DialOption) (client *GrpcClientImpl, err error) {
+	if len(options) == 0 {
+		options = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	conn, err := grpc.NewClient(target, options...)
+	if err != nil {
+		return nil, err
+	}
+	return NewGrpcWithConnection(conn), nil
+}
+
+// NewGrpcWithConnection returns a Client, that uses the connection for the calls of the grpc api
+func NewGrpcWithConnection(conn grpc.ClientConnInterface) (client *GrpcClientImpl) {
+	return &GrpcClientImpl{conn: conn}
+}
+
+type GrpcClientImpl struct {
+	conn grpc.ClientConnInterface
+}
+
+// Close closes the connection, if it has been created by NewGrpc
+func (this *GrpcClientImpl) Close() error {
+	if closer, ok := this.conn.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (this *GrpcClientImpl) ListTopics(token string, options ListOptions) (result []Topic, err error, code int) {
+	return this.ListTopicsContext(context.TODO(), token, options)
+}
+
+func (this *GrpcClientImpl) ListTopicsContext(ctx context.Context, token string, options ListOptions) (result []Topic, err error, code int) {
+	return invokeGrpc[[]Topic](ctx, this.conn, token, grpcapi.MethodListTopics, grpcapi.ListRequest{Options: options})
+}
+
+func (this *GrpcClientImpl) GetTopic(token string, id string) (result Topic, err error, code int) {
+	return this.GetTopicContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) GetTopicContext(ctx context.Context, token string, id string) (result Topic, err error, code int) {
+	return invokeGrpc[Topic](ctx, this.conn, token, grpcapi.MethodGetTopic, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) RemoveTopic(token string, id string) (err error, code int) {
+	return this.RemoveTopicContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) RemoveTopicContext(ctx context.Context, token string, id string) (err error, code int) {
+	return invokeVoidGrpc(ctx, this.conn, token, grpcapi.MethodRemoveTopic, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) SetTopic(token string, topic Topic) (result Topic, err error, code int) {
+	return this.SetTopicContext(context.TODO(), token, topic)
+}
+
+func (this *GrpcClientImpl) SetTopicContext(ctx context.Context, token string, topic Topic) (result Topic, err error, code int) {
+	return invokeGrpc[Topic](ctx, this.conn, token, grpcapi.MethodSetTopic, topic)
+}
+
+func (this *GrpcClientImpl) AdminListResourceIds(token string, topicId string, options ListOptions) (ids []string, err error, code int) {
+	return this.AdminListResourceIdsContext(context.TODO(), token, topicId, options)
+}
+
+func (this *GrpcClientImpl) AdminListResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions) (ids []string, err error, code int) {
+	return invokeGrpc[[]string](ctx, this.conn, token, grpcapi.MethodAdminListResourceIds, grpcapi.ListRequest{TopicId: topicId, Options: options})
+}
+
+func (this *GrpcClientImpl) AdminCountResourceIds(token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return this.AdminCountResourceIdsContext(context.TODO(), token, topicId, options)
+}
+
+func (this *GrpcClientImpl) AdminCountResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return invokeGrpc[int64](ctx, this.conn, token, grpcapi.MethodAdminCountResourceIds, grpcapi.ListRequest{TopicId: topicId, Options: options})
+}
+
+// AdminLoadFromPermissionSearch is not supported by the client
+// because this request should never be automated
+func (this *GrpcClientImpl) AdminLoadFromPermissionSearch(req model.AdminLoadPermSearchRequest) (updateCount int, err error, code int) {
+	return this.AdminLoadFromPermissionSearchContext(context.TODO(), req)
+}
+
+func (this *GrpcClientImpl) AdminLoadFromPermissionSearchContext(_ context.Context, req model.AdminLoadPermSearchRequest) (updateCount int, err error, code int) {
+	panic("no client support: this request should never be automated")
+}
+
+func (this *GrpcClientImpl) Export(token string, options model.ImportExportOptions) (result model.ImportExport, err error, code int) {
+	return this.ExportContext(context.TODO(), token, options)
+}
+
+func (this *GrpcClientImpl) ExportContext(ctx context.Context, token string, options model.ImportExportOptions) (result model.ImportExport, err error, code int) {
+	return invokeGrpc[model.ImportExport](ctx, this.conn, token, grpcapi.MethodExport, options)
+}
+
+func (this *GrpcClientImpl) Import(token string, importModel model.ImportExport, options model.ImportExportOptions) (err error, code int) {
+	return this.ImportContext(context.TODO(), token, importModel, options)
+}
+
+func (this *GrpcClientImpl) ImportContext(ctx context.Context, token string, importModel model.ImportExport, options model.ImportExportOptions) (err error, code int) {
+	return invokeVoidGrpc(ctx, this.conn, token, grpcapi.MethodImport, grpcapi.ImportRequest{Import: importModel, Options: options})
+}
+
+func (this *GrpcClientImpl) ListAuditEntries(token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	return this.ListAuditEntriesContext(context.TODO(), token, options)
+}
+
+func (this *GrpcClientImpl) ListAuditEntriesContext(ctx context.Context, token string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	return invokeGrpc[[]model.AuditEntry](ctx, this.conn, token, grpcapi.MethodListAuditEntries, options)
+}
+
+func (this *GrpcClientImpl) ListWebhooks(token string) (result []model.Webhook, err error, code int) {
+	return this.ListWebhooksContext(context.TODO(), token)
+}
+
+func (this *GrpcClientImpl) ListWebhooksContext(ctx context.Context, token string) (result []model.Webhook, err error, code int) {
+	return invokeGrpc[[]model.Webhook](ctx, this.conn, token, grpcapi.MethodListWebhooks, grpcapi.Empty{})
+}
+
+func (this *GrpcClientImpl) GetWebhook(token string, id string) (result model.Webhook, err error, code int) {
+	return this.GetWebhookContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) GetWebhookContext(ctx context.Context, token string, id string) (result model.Webhook, err error, code int) {
+	return invokeGrpc[model.Webhook](ctx, this.conn, token, grpcapi.MethodGetWebhook, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) SetWebhook(token string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	return this.SetWebhookContext(context.TODO(), token, webhook)
+}
+
+func (this *GrpcClientImpl) SetWebhookContext(ctx context.Context, token string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	return invokeGrpc[model.Webhook](ctx, this.conn, token, grpcapi.MethodSetWebhook, webhook)
+}
+
+func (this *GrpcClientImpl) RemoveWebhook(token string, id string) (err error, code int) {
+	return this.RemoveWebhookContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) RemoveWebhookContext(ctx context.Context, token string, id string) (err error, code int) {
+	return invokeVoidGrpc(ctx, this.conn, token, grpcapi.MethodRemoveWebhook, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) ListWebhookDeliveries(token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	return this.ListWebhookDeliveriesContext(context.TODO(), token, webhookId, options)
+}
+
+func (this *GrpcClientImpl) ListWebhookDeliveriesContext(ctx context.Context, token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	return invokeGrpc[[]model.WebhookDelivery](ctx, this.conn, token, grpcapi.MethodListWebhookDeliveries, grpcapi.WebhookDeliveriesRequest{WebhookId: webhookId, Options: options})
+}
+
+func (this *GrpcClientImpl) RetryWebhookDelivery(token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	return this.RetryWebhookDeliveryContext(context.TODO(), token, webhookId, deliveryId)
+}
+
+func (this *GrpcClientImpl) RetryWebhookDeliveryContext(ctx context.Context, token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	return invokeGrpc[model.WebhookDelivery](ctx, this.conn, token, grpcapi.MethodRetryWebhookDelivery, grpcapi.WebhookDeliveryRequest{WebhookId: webhookId, DeliveryId: deliveryId})
+}
+
+func (this *GrpcClientImpl) ListServiceCredentials(token string) (result []model.ServiceCredential, err error, code int) {
+	return this.ListServiceCredentialsContext(context.TODO(), token)
+}
+
+func (this *GrpcClientImpl) ListServiceCredentialsContext(ctx context.Context, token string) (result []model.ServiceCredential, err error, code int) {
+	return invokeGrpc[[]model.ServiceCredential](ctx, this.conn, token, grpcapi.MethodListServiceCredentials, grpcapi.Empty{})
+}
+
+func (this *GrpcClientImpl) GetServiceCredential(token string, id string) (result model.ServiceCredential, err error, code int) {
+	return this.GetServiceCredentialContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) GetServiceCredentialContext(ctx context.Context, token string, id string) (result model.ServiceCredential, err error, code int) {
+	return invokeGrpc[model.ServiceCredential](ctx, this.conn, token, grpcapi.MethodGetServiceCredential, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) CreateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.CreateServiceCredentialContext(context.TODO(), token, credential)
+}
+
+func (this *GrpcClientImpl) CreateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	return invokeGrpc[model.ServiceCredentialWithKey](ctx, this.conn, token, grpcapi.MethodCreateServiceCredential, credential)
+}
+
+func (this *GrpcClientImpl) UpdateServiceCredential(token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	return this.UpdateServiceCredentialContext(context.TODO(), token, credential)
+}
+
+func (this *GrpcClientImpl) UpdateServiceCredentialContext(ctx context.Context, token string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	return invokeGrpc[model.ServiceCredential](ctx, this.conn, token, grpcapi.MethodUpdateServiceCredential, credential)
+}
+
+func (this *GrpcClientImpl) RenewServiceCredentialKey(token string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.RenewServiceCredentialKeyContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) RenewServiceCredentialKeyContext(ctx context.Context, token string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	return invokeGrpc[model.ServiceCredentialWithKey](ctx, this.conn, token, grpcapi.MethodRenewServiceCredentialKey, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) RemoveServiceCredential(token string, id string) (err error, code int) {
+	return this.RemoveServiceCredentialContext(context.TODO(), token, id)
+}
+
+func (this *GrpcClientImpl) RemoveServiceCredentialContext(ctx context.Context, token string, id string) (err error, code int) {
+	return invokeVoidGrpc(ctx, this.conn, token, grpcapi.MethodRemoveServiceCredential, grpcapi.IdRequest{Id: id})
+}
+
+func (this *GrpcClientImpl) CheckPermission(token string, topicId string, id string, permissions ...Permission) (access bool, err error, code int) {
+	return this.CheckPermissionContext(context.TODO(), token, topicId, id, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ...Permission) (access bool, err error, code int) {
+	return this.CheckPermissionWithModeContext(ctx, token, topicId, id, PermissionModeAll, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckPermissionWithMode(token string, topicId string, id string, mode PermissionMode, permissions ...Permission) (access bool, err error, code int) {
+	return this.CheckPermissionWithModeContext(context.TODO(), token, topicId, id, mode, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckPermissionWithModeContext(ctx context.Context, token string, topicId string, id string, mode PermissionMode, permissions ...Permission) (access bool, err error, code int) {
+	return invokeGrpc[bool](ctx, this.conn, token, grpcapi.MethodCheckPermission, grpcapi.CheckRequest{
+		TopicId:     topicId,
+		Id:          id,
+		Permissions: PermissionList(permissions).Encode(),
+		Mode:        mode,
+	})
+}
+
+func (this *GrpcClientImpl) CheckMultiplePermissions(token string, topicId string, ids []string, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsContext(context.TODO(), token, topicId, ids, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckMultiplePermissionsContext(ctx context.Context, token string, topicId string, ids []string, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsWithModeContext(ctx, token, topicId, ids, PermissionModeAll, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckMultiplePermissionsWithMode(token string, topicId string, ids []string, mode PermissionMode, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsWithModeContext(context.TODO(), token, topicId, ids, mode, permissions...)
+}
+
+func (this *GrpcClientImpl) CheckMultiplePermissionsWithModeContext(ctx context.Context, token string, topicId string, ids []string, mode PermissionMode, permissions ...Permission) (access map[string]bool, err error, code int) {
+	return invokeGrpc[map[string]bool](ctx, this.conn, token, grpcapi.MethodCheckMultiplePermissions, CheckQuery{
+		TopicId:     topicId,
+		Ids:         ids,
+		Permissions: PermissionList(permissions).Encode(),
+		Mode:        mode,
+	})
+}
+
+func (this *GrpcClientImpl) CheckPermissionsBatch(token string, queries []CheckQuery) (access map[string]map[string]bool, err error, code int) {
+	return this.CheckPermissionsBatchContext(context.TODO(), token, queries)
+}
+
+func (this *GrpcClientImpl) CheckPermissionsBatchContext(ctx context.Context, token string, queries []CheckQuery) (access map[string]map[string]bool, err error, code int) {
+	return invokeGrpc[map[string]map[string]bool](ctx, this.conn, token, grpcapi.MethodCheckPermissionsBatch, queries)
+}
+
+func (this *GrpcClientImpl) ListAccessibleResourceIds(token string, topicId string, options ListOptions, permissions ...Permission) (ids []string, err error, code int) {
+	return this.ListAccessibleResourceIdsContext(context.TODO(), token, topicId, options, permissions...)
+}
+
+func (this *GrpcClientImpl) ListAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions, permissions ...Permission) (ids []string, err error, code int) {
+	return this.ListAccessibleResourceIdsWithModeContext(ctx, token, topicId, options, PermissionModeAll, permissions...)
+}
+
+func (this *GrpcClientImpl) ListAccessibleResourceIdsWithMode(token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (ids []string, err error, code int) {
+	return this.ListAccessibleResourceIdsWithModeContext(context.TODO(), token, topicId, options, mode, permissions...)
+}
+
+func (this *GrpcClientImpl) ListAccessibleResourceIdsWithModeContext(ctx context.Context, token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (ids []string, err error, code int) {
+	return invokeGrpc[[]string](ctx, this.conn, token, grpcapi.MethodListAccessibleResourceIds, grpcapi.AccessibleRequest{
+		TopicId:     topicId,
+		Options:     options,
+		Permissions: PermissionList(permissions).Encode(),
+		Mode:        mode,
+	})
+}
+
+func (this *GrpcClientImpl) CountAccessibleResourceIds(token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (count int64, err error, code int) {
+	return this.CountAccessibleResourceIdsContext(context.TODO(), token, topicId, options, mode, permissions...)
+}
+
+func (this *GrpcClientImpl) CountAccessibleResourceIdsContext(ctx context.Context, token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) (count int64, err error, code int) {
+	return invokeGrpc[int64](ctx, this.conn, token, grpcapi.MethodCountAccessibleResourceIds, grpcapi.AccessibleRequest{
+		TopicId:     topicId,
+		Options:     options,
+		Permissions: PermissionList(permissions).Encode(),
+		Mode:        mode,
+	})
+}
+
+func (this *GrpcClientImpl) ListComputedPermissions(token string, topic string, ids []string) (result []model.ComputedPermissions, err error, code int) {
+	return this.ListComputedPermissionsContext(context.TODO(), token, topic, ids)
+}
+
+func (this *GrpcClientImpl) ListComputedPermissionsContext(ctx context.Context, token string, topic string, ids []string) (result []model.ComputedPermissions, err error, code int) {
+	return invokeGrpc[[]model.ComputedPermissions](ctx, this.conn, token, grpcapi.MethodListComputedPermissions, grpcapi.ResourcesRequest{TopicId: topic, Ids: ids})
+}
+
+func (this *GrpcClientImpl) ExplainPermission(token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int) {
+	return this.ExplainPermissionContext(context.TODO(), token, topicId, id, onBehalf, permissions...)
+}
+
+func (this *GrpcClientImpl) ExplainPermissionContext(ctx context.Context, token string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int) {
+	return invokeGrpc[model.Explanation](ctx, this.conn, token, grpcapi.MethodExplainPermission, grpcapi.ExplainRequest{
+		TopicId:     topicId,
+		Id:          id,
+		OnBehalf:    onBehalf,
+		Permissions: PermissionList(permissions).Encode(),
+	})
+}
+
+func (this *GrpcClientImpl) ListResourcesWithAdminPermission(token string, topicId string, options ListOptions) (result []Resource, err error, code int) {
+	return this.ListResourcesWithAdminPermissionContext(context.TODO(), token, topicId, options)
+}
+
+func (this *GrpcClientImpl) ListResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options ListOptions) (result []Resource, err error, code int) {
+	return invokeGrpc[[]Resource](ctx, this.conn, token, grpcapi.MethodListResourcesWithAdminPermission, grpcapi.ListRequest{TopicId: topicId, Options: options})
+}
+
+func (this *GrpcClientImpl) CountResourcesWithAdminPermission(token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return this.CountResourcesWithAdminPermissionContext(context.TODO(), token, topicId, options)
+}
+
+func (this *GrpcClientImpl) CountResourcesWithAdminPermissionContext(ctx context.Context, token string, topicId string, options ListOptions) (count int64, err error, code int) {
+	return invokeGrpc[int64](ctx, this.conn, token, grpcapi.MethodCountResourcesWithAdminPermission, grpcapi.ListRequest{TopicId: topicId, Options: options})
+}
+
+func (this *GrpcClientImpl) GetResource(token string, topicId string, id string) (result Resource, err error, code int) {
+	return this.GetResourceContext(context.TODO(), token, topicId, id)
+}
+
+func (this *GrpcClientImpl) GetResourceContext(ctx context.Context, token string, topicId string, id string) (result Resource, err error, code int) {
+	return invokeGrpc[Resource](ctx, this.conn, token, grpcapi.MethodGetResource, grpcapi.ResourceRequest{TopicId: topicId, Id: id})
+}
+
+func (this *GrpcClientImpl) RemoveResource(token string, topicId string, id string, options ...WriteOptions) (err error, code int) {
+	return this.RemoveResourceContext(context.TODO(), token, topicId, id, options...)
+}
+
+func (this *GrpcClientImpl) RemoveResourceContext(ctx context.Context, token string, topicId string, id string, options ...WriteOptions) (err error, code int) {
+	return invokeVoidGrpc(ctx, this.conn, token, grpcapi.MethodRemoveResource, grpcapi.ResourceRequest{
+		TopicId:         topicId,
+		Id:              id,
+		ExpectedVersion: grpcapi.ExpectedVersion(options),
+	})
+}
+
+func (this *GrpcClientImpl) SetPermission(token string, topicId string, id string, permissions ResourcePermissions, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return this.SetPermissionContext(context.TODO(), token, topicId, id, permissions, options...)
+}
+
+func (this *GrpcClientImpl) SetPermissionContext(ctx context.Context, token string, topicId string, id string, permissions ResourcePermissions, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return invokeGrpc[ResourcePermissions](ctx, this.conn, token, grpcapi.MethodSetPermission, grpcapi.SetPermissionRequest{
+		TopicId:         topicId,
+		Id:              id,
+		Permissions:     permissions,
+		ExpectedVersion: grpcapi.ExpectedVersion(options),
+	})
+}
+
+func (this *GrpcClientImpl) SetPermissionsBulk(token string, topicId string, items []BulkPermissionsItem) (result []BulkPermissionsResult, err error, code int) {
+	return this.SetPermissionsBulkContext(context.TODO(), token, topicId, items)
+}
+
+func (this *GrpcClientImpl) SetPermissionsBulkContext(ctx context.Context, token string, topicId string, items []BulkPermissionsItem) (result []BulkPermissionsResult, err error, code int) {
+	return invokeGrpc[[]BulkPermissionsResult](ctx, this.conn, token, grpcapi.MethodSetPermissionsBulk, grpcapi.BulkRequest{TopicId: topicId, Items: items})
+}
+
+func (this *GrpcClientImpl) PatchPermission(token string, topicId string, id string, patch PermissionsPatch, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return this.PatchPermissionContext(context.TODO(), token, topicId, id, patch, options...)
+}
+
+func (this *GrpcClientImpl) PatchPermissionContext(ctx context.Context, token string, topicId string, id string, patch PermissionsPatch, options ...WriteOptions) (result ResourcePermissions, err error, code int) {
+	return invokeGrpc[ResourcePermissions](ctx, this.conn, token, grpcapi.MethodPatchPermission, grpcapi.PatchPermissionRequest{
+		TopicId:         topicId,
+		Id:              id,
+		Patch:           patch,
+		ExpectedVersion: grpcapi.ExpectedVersion(options),
+	})
+}
+
+func (this *GrpcClientImpl) ListPermissionVersions(token string, topicId string, id string, options ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	return this.ListPermissionVersionsContext(context.TODO(), token, topicId, id, options)
+}
+
+func (this *GrpcClientImpl) ListPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, options ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	return invokeGrpc[[]model.PermissionsVersion](ctx, this.conn, token, grpcapi.MethodListPermissionVersions, grpcapi.ListRequest{TopicId: topicId, Id: id, Options: options})
+}
+
+func (this *GrpcClientImpl) DiffPermissionVersions(token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	return this.DiffPermissionVersionsContext(context.TODO(), token, topicId, id, fromVersion, toVersion)
+}
+
+func (this *GrpcClientImpl) DiffPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	return invokeGrpc[model.PermissionsDiff](ctx, this.conn, token, grpcapi.MethodDiffPermissionVersions, grpcapi.DiffRequest{TopicId: topicId, Id: id, FromVersion: fromVersion, ToVersion: toVersion})
+}
+
+func (this *GrpcClientImpl) RollbackPermissions(token string, topicId string, id string, version int64) (result ResourcePermissions, err error, code int) {
+	return this.RollbackPermissionsContext(context.TODO(), token, topicId, id, version)
+}
+
+func (this *GrpcClientImpl) RollbackPermissionsContext(ctx context.Context, token string, topicId string, id string, version int64) (result ResourcePermissions, err error, code int) {
+	return invokeGrpc[ResourcePermissions](ctx, this.conn, token, grpcapi.MethodRollbackPermissions, grpcapi.RollbackRequest{TopicId: topicId, Id: id, Version: version})
+}
+
+// WatchPermissionChanges streams the permission changes of resources in the topic, until the iteration is stopped.
+// the stream is held open until the returned iterator is used and stopped.
+func (this *GrpcClientImpl) WatchPermissionChanges(token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	return this.WatchPermissionChangesContext(context.TODO(), token, topicId, options)
+}
+
+// WatchPermissionChangesContext streams the permission changes of resources in the topic, until ctx is done or the iteration is stopped.
+// errors are handled like by ClientImpl.WatchPermissionChangesContext.
+func (this *GrpcClientImpl) WatchPermissionChangesContext(ctx context.Context, token string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	ctx, cancel := context.WithCancel(grpcOutgoingContext(ctx, token))
+	trailer := metadata.MD{}
+	stream, err := this.conn.NewStream(ctx, &grpcapi.ServiceDesc.Streams[0], grpcapi.FullMethod(grpcapi.MethodWatchPermissionChanges), grpc.CallContentSubtype(grpcapi.CodecName), grpc.Trailer(&trailer))
+	if err != nil {
+		cancel()
+		err, code = grpcapi.FromError(err, trailer)
+		return nil, err, code
+	}
+	err = stream.SendMsg(grpcapi.WatchRequest{
+		TopicId:     topicId,
+		ResumeToken: options.ResumeToken,
+		Permissions: options.Permissions.Encode(),
+		Mode:        options.Mode,
+	})
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err == nil {
+		//the server sends the header after the watch is established; without header, the stream ended with an error
+		var header metadata.MD
+		header, err = stream.Header()
+		if err == nil && header == nil {
+			err = stream.RecvMsg(&model.ChangeEvent{})
+			if err == nil || err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+		}
+	}
+	if err != nil {
+		cancel()
+		err, code = grpcapi.FromError(err, stream.Trailer())
+		return nil, err, code
+	}
+	return func(yield func(model.ChangeEvent, error) bool) {
+		defer cancel()
+		for {
+			event := model.ChangeEvent{}
+			err := stream.RecvMsg(&event)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				err, code := grpcapi.FromError(err, stream.Trailer())
+				if code == http.StatusGone {
+					err = model.ErrResumeTokenExpired
+				}
+				yield(model.ChangeEvent{}, err)
+				return
+			}
+			if !yield(event, nil) {
+				return
+			}
+		}
+	}, nil, http.StatusOK
+}
+
+func grpcOutgoingContext(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, grpcapi.AuthorizationMetadata, token, grpcapi.VersionMetadata, ClientVersion)
+}
+
+func invokeGrpc[T any](ctx context.Context, conn grpc.ClientConnInterface, token string, method string, req any) (result T, err error, code int) {
+	trailer := metadata.MD{}
+	err = conn.Invoke(grpcOutgoingContext(ctx, token), grpcapi.FullMethod(method), req, &result, grpc.CallContentSubtype(grpcapi.CodecName), grpc.Trailer(&trailer))
+	err, code = grpcapi.FromError(err, trailer)
+	return result, err, code
+}
+
+func invokeVoidGrpc(ctx context.Context, conn grpc.ClientConnInterface, token string, method string, req any) (err error, code int) {
+	_, err, code = invokeGrpc[grpcapi.Empty](ctx, conn, token, method, req)
+	return err, code
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+type PermissionsVersion = model.PermissionsVersion
+type PermissionsDiff = model.PermissionsDiff
+type PermissionsChange = model.PermissionsChange
+
+// ListPermissionVersions lists the stored permission versions of a resource, newest first
+// requesting user must have admin right on the resource or topic
+func (this *ClientImpl) ListPermissionVersions(token string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	return this.ListPermissionVersionsContext(context.TODO(), token, topicId, id, options)
+}
+
+func (this *ClientImpl) ListPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	query := url.Values{}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(options.Limit, 10))
+	}
+	if options.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(options.Offset, 10))
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/manage/%v/%v/versions?%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), query.Encode()), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]model.PermissionsVersion](ctx, token, req)
+}
+
+// DiffPermissionVersions returns the changes between two permission versions of a resource
+func (this *ClientImpl) DiffPermissionVersions(token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	return this.DiffPermissionVersionsContext(context.TODO(), token, topicId, id, fromVersion, toVersion)
+}
+
+func (this *ClientImpl) DiffPermissionVersionsContext(ctx context.Context, token string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	query := url.Values{}
+	query.Set("from", strconv.FormatInt(fromVersion, 10))
+	query.Set("to", strconv.FormatInt(toVersion, 10))
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/manage/%v/%v/versions/diff?%v", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), query.Encode()), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.PermissionsDiff](ctx, token, req)
+}
+
+// RollbackPermissions sets the permissions of a resource to a stored version
+// the rollback is checked and published like a SetPermission call
+func (this *ClientImpl) RollbackPermissions(token string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int) {
+	return this.RollbackPermissionsContext(context.TODO(), token, topicId, id, version)
+}
+
+func (this *ClientImpl) RollbackPermissionsContext(ctx context.Context, token string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%v/manage/%v/%v/versions/%v/rollback", this.serverUrl, url.PathEscape(topicId), url.PathEscape(id), version), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.ResourcePermissions](ctx, token, req)
+}
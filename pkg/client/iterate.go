@@ -0,0 +1,94 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"iter"
+)
+
+// DefaultPageSize is used by the Iterate functions if ListOptions.Limit is not set
+const DefaultPageSize int64 = 1000
+
+// IterateTopics lists all topics page by page, using cursors; options.Limit is used as page size.
+// the iteration stops after the first error.
+func IterateTopics(ctx context.Context, client Client, token string, options ListOptions) iter.Seq2[Topic, error] {
+	return iteratePages(options, func(options ListOptions) ([]Topic, error, int) {
+		return client.ListTopicsContext(ctx, token, options)
+	}, func(topic Topic) string {
+		return topic.Id
+	})
+}
+
+// IterateAdminResourceIds lists all resource ids of the topic page by page, using cursors; options.Limit is used as page size.
+// the iteration stops after the first error.
+func IterateAdminResourceIds(ctx context.Context, client Client, token string, topicId string, options ListOptions) iter.Seq2[string, error] {
+	return iteratePages(options, func(options ListOptions) ([]string, error, int) {
+		return client.AdminListResourceIdsContext(ctx, token, topicId, options)
+	}, func(id string) string {
+		return id
+	})
+}
+
+// IterateAccessibleResourceIds lists all accessible resource ids of the topic page by page, using cursors; options.Limit is used as page size.
+// the iteration stops after the first error.
+func IterateAccessibleResourceIds(ctx context.Context, client Client, token string, topicId string, options ListOptions, mode PermissionMode, permissions ...Permission) iter.Seq2[string, error] {
+	return iteratePages(options, func(options ListOptions) ([]string, error, int) {
+		return client.ListAccessibleResourceIdsWithModeContext(ctx, token, topicId, options, mode, permissions...)
+	}, func(id string) string {
+		return id
+	})
+}
+
+// IterateResourcesWithAdminPermission lists all resources of the topic with admin permission page by page, using cursors; options.Limit is used as page size.
+// the iteration stops after the first error.
+func IterateResourcesWithAdminPermission(ctx context.Context, client Client, token string, topicId string, options ListOptions) iter.Seq2[Resource, error] {
+	return iteratePages(options, func(options ListOptions) ([]Resource, error, int) {
+		return client.ListResourcesWithAdminPermissionContext(ctx, token, topicId, options)
+	}, func(resource Resource) string {
+		return resource.Id
+	})
+}
+
+func iteratePages[T any](options ListOptions, list func(options ListOptions) ([]T, error, int), id func(element T) string) iter.Seq2[T, error] {
+	if options.Limit <= 0 {
+		options.Limit = DefaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		for {
+			page, err, _ := list(options)
+			if err != nil {
+				var element T
+				yield(element, err)
+				return
+			}
+			for _, element := range page {
+				if !yield(element, nil) {
+					return
+				}
+			}
+			if len(page) == 0 {
+				return
+			}
+			next, ok := options.NextPage(len(page), id(page[len(page)-1]))
+			if !ok {
+				return
+			}
+			options = next
+		}
+	}
+}
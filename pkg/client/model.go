@@ -19,7 +19,12 @@ package client
 import "github.com/SENERGY-Platform/permissions-v2/pkg/model"
 
 type ListOptions = model.ListOptions
+
+const SortById = model.SortById
+const SortByTimestamp = model.SortByTimestamp
+
 type GetOptions = model.GetOptions
+type WriteOptions = model.WriteOptions
 type Topic = model.Topic
 
 type Permission = model.Permission
@@ -30,9 +35,24 @@ const Administrate = model.Administrate // user may delete resource; user may ch
 const Execute = model.Execute           //user may use the resource (e.g. cmd to device; read device data; read database)
 
 type PermissionList = model.PermissionList
+type PermissionMode = model.PermissionMode
+
+const PermissionModeAll = model.PermissionModeAll //all permissions must be granted
+const PermissionModeAny = model.PermissionModeAny //at least one of the permissions must be granted
+
 type GroupPermissions = model.GroupPermissions
 type Resource = model.Resource
 type ResourcePermissions = model.ResourcePermissions
 type PermissionsMap = model.PermissionsMap
+type PermissionsPatch = model.PermissionsPatch
+type BulkPermissionsItem = model.BulkPermissionsItem
+type BulkPermissionsResult = model.BulkPermissionsResult
+type CheckQuery = model.CheckQuery
+
+type SharingPolicy = model.SharingPolicy
+type SharingPolicyViolation = model.SharingPolicyViolation
+type SharingPolicyError = model.SharingPolicyError //returned by SetPermission, PatchPermission and RollbackPermissions; use errors.As
+
+var ErrSharingPolicyViolation = model.ErrSharingPolicyViolation
 
 const ClientVersion = model.ClientVersion
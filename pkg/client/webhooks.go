@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+type Webhook = model.Webhook
+type WebhookEventType = model.WebhookEventType
+type WebhookPayload = model.WebhookPayload
+type WebhookDelivery = model.WebhookDelivery
+type WebhookDeliveryStatus = model.WebhookDeliveryStatus
+type WebhookDeliveryListOptions = model.WebhookDeliveryListOptions
+
+const WebhookEventSetResource = model.WebhookEventSetResource
+const WebhookEventRemoveResource = model.WebhookEventRemoveResource
+const WebhookEventSetTopic = model.WebhookEventSetTopic
+const WebhookEventRemoveTopic = model.WebhookEventRemoveTopic
+
+func (this *ClientImpl) ListWebhooks(token string) (result []model.Webhook, err error, code int) {
+	return this.ListWebhooksContext(context.TODO(), token)
+}
+
+func (this *ClientImpl) ListWebhooksContext(ctx context.Context, token string) (result []model.Webhook, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, this.serverUrl+"/admin/webhooks", nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]model.Webhook](ctx, token, req)
+}
+
+func (this *ClientImpl) GetWebhook(token string, id string) (result model.Webhook, err error, code int) {
+	return this.GetWebhookContext(context.TODO(), token, id)
+}
+
+func (this *ClientImpl) GetWebhookContext(ctx context.Context, token string, id string) (result model.Webhook, err error, code int) {
+	req, err := http.NewRequest(http.MethodGet, this.serverUrl+"/admin/webhooks/"+url.PathEscape(id), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.Webhook](ctx, token, req)
+}
+
+func (this *ClientImpl) SetWebhook(token string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	return this.SetWebhookContext(context.TODO(), token, webhook)
+}
+
+func (this *ClientImpl) SetWebhookContext(ctx context.Context, token string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	body, err := json.Marshal(webhook)
+	if err != nil {
+		return result, err, 0
+	}
+	method, endpoint := http.MethodPut, this.serverUrl+"/admin/webhooks/"+url.PathEscape(webhook.Id)
+	if webhook.Id == "" {
+		method, endpoint = http.MethodPost, this.serverUrl+"/admin/webhooks"
+	}
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.Webhook](ctx, token, req)
+}
+
+func (this *ClientImpl) RemoveWebhook(token string, id string) (err error, code int) {
+	return this.RemoveWebhookContext(context.TODO(), token, id)
+}
+
+func (this *ClientImpl) RemoveWebhookContext(ctx context.Context, token string, id string) (err error, code int) {
+	req, err := http.NewRequest(http.MethodDelete, this.serverUrl+"/admin/webhooks/"+url.PathEscape(id), nil)
+	if err != nil {
+		return err, 0
+	}
+	return doVoidWithContext(ctx, token, req)
+}
+
+func (this *ClientImpl) ListWebhookDeliveries(token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	return this.ListWebhookDeliveriesContext(context.TODO(), token, webhookId, options)
+}
+
+func (this *ClientImpl) ListWebhookDeliveriesContext(ctx context.Context, token string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	query := url.Values{}
+	if options.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(options.Limit, 10))
+	}
+	if options.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(options.Offset, 10))
+	}
+	if options.Status != "" {
+		query.Set("status", string(options.Status))
+	}
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/admin/webhooks/%v/deliveries?%v", this.serverUrl, url.PathEscape(webhookId), query.Encode()), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[[]model.WebhookDelivery](ctx, token, req)
+}
+
+func (this *ClientImpl) RetryWebhookDelivery(token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	return this.RetryWebhookDeliveryContext(context.TODO(), token, webhookId, deliveryId)
+}
+
+func (this *ClientImpl) RetryWebhookDeliveryContext(ctx context.Context, token string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%v/admin/webhooks/%v/deliveries/%v/retry", this.serverUrl, url.PathEscape(webhookId), url.PathEscape(deliveryId)), nil)
+	if err != nil {
+		return result, err, 0
+	}
+	return doWithContext[model.WebhookDelivery](ctx, token, req)
+}
+
+// VerifyWebhookSignature may be used by webhook receivers to check the signature headers of a callback against the raw request body.
+// callbacks with a timestamp older than maxAge are rejected; maxAge <= 0 disables the check.
+func VerifyWebhookSignature(secret string, header http.Header, body []byte, maxAge time.Duration) error {
+	timestamp, err := strconv.ParseInt(header.Get(model.WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %v header: %w", model.WebhookTimestampHeader, err)
+	}
+	if maxAge > 0 && time.Since(time.Unix(timestamp, 0)) > maxAge {
+		return errors.New("webhook timestamp is too old")
+	}
+	signature, ok := strings.CutPrefix(header.Get(model.WebhookSignatureHeader), "sha256=")
+	if !ok {
+		return fmt.Errorf("missing or unknown %v header", model.WebhookSignatureHeader)
+	}
+	if !hmac.Equal([]byte(signature), []byte(model.SignWebhook(secret, timestamp, body))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
@@ -35,6 +35,7 @@ import (
 
 type Config struct {
 	Port            string `json:"port"`
+	GrpcPort        string `json:"grpc_port"` //optional; "" or "-" disables the grpc api
 	Debug           bool   `json:"debug"`
 	EnableSwaggerUi bool   `json:"enable_swagger_ui"`
 	EditForward     string `json:"edit_forward"`
@@ -42,18 +43,51 @@ type Config struct {
 
 	KafkaUrl string `json:"kafka_url"`
 
-	MongoUrl                   string `json:"mongo_url"`
-	MongoDatabase              string `json:"mongo_database"`
-	MongoPermissionsCollection string `json:"mongo_permissions_collection"`
-	MongoTopicsCollection      string `json:"mongo_topics_collection"`
+	MongoUrl                       string `json:"mongo_url"`
+	MongoDatabase                  string `json:"mongo_database"`
+	MongoPermissionsCollection     string `json:"mongo_permissions_collection"`
+	MongoTopicsCollection          string `json:"mongo_topics_collection"`
+	MongoOutboxCollection          string `json:"mongo_outbox_collection"`
+	MongoOutboxStateCollection     string `json:"mongo_outbox_state_collection"`
+	MongoAuditCollection           string `json:"mongo_audit_collection"`
+	MongoHistoryCollection         string `json:"mongo_history_collection"`
+	MongoWebhookCollection         string `json:"mongo_webhook_collection"`
+	MongoWebhookDeliveryCollection string `json:"mongo_webhook_delivery_collection"`
+	MongoCredentialCollection      string `json:"mongo_credential_collection"`
+
+	// Deprecated: deletions are published with the outbox (MongoOutboxCollection); the setting is ignored
+	MongoDeletionsCollection string `json:"mongo_deletions_collection"`
 
 	MigrateFromMongoUrl string `json:"migrate_from_mongo_url"`
 
-	SyncCheckInterval Duration `json:"sync_check_interval"`
-	SyncAgeLimit      Duration `json:"sync_age_limit"`
+	SyncCheckInterval Duration `json:"sync_check_interval"` //interval in which the outbox relay checks for pending kafka publishes (in addition to local changes)
+	OutboxMaxBackoff  Duration `json:"outbox_max_backoff"`  //max wait time between retries of a failed kafka publish
+
+	// Deprecated: the outbox relay publishes every pending change, independent of its age; the setting is ignored
+	SyncAgeLimit Duration `json:"sync_age_limit"`
+
+	ExpirySweepInterval Duration `json:"expiry_sweep_interval"` //interval in which expired time-bound permissions are removed; 0 disables the sweeper
 
 	OnlyAdminsMayEditRolePermissions bool `json:"only_admins_may_edit_role_permissions"`
 
+	BulkMaxItems int `json:"bulk_max_items"` //max number of items in one bulk permissions write; 0 disables the limit
+
+	ChangeFeedBufferSize       int      `json:"change_feed_buffer_size"`        //number of recent local changes, a change feed subscription may resume from
+	ChangeFeedUseChangeStreams bool     `json:"change_feed_use_change_streams"` //read the change feed from mongodb change streams (requires a replica set); needed if multiple instances handle writes
+	ChangeFeedHeartbeat        Duration `json:"change_feed_heartbeat"`          //interval of keep-alive comments in change feed streams; 0 disables them
+
+	WebhookCheckInterval     Duration `json:"webhook_check_interval"`     //interval in which due webhook retries and deliveries of other instances are sent (in addition to local changes)
+	WebhookTimeout           Duration `json:"webhook_timeout"`            //timeout of one webhook callback; default 10s
+	WebhookMaxAttempts       int      `json:"webhook_max_attempts"`       //attempts until a delivery is marked as failed; default 10
+	WebhookMaxBackoff        Duration `json:"webhook_max_backoff"`        //max wait time between retries of a webhook callback
+	WebhookDeliveryRetention Duration `json:"webhook_delivery_retention"` //finished deliveries are removed from the delivery log after this time; 0 keeps them
+
+	JwksUrl             string   `json:"jwks_url"`              //optional; verifies token signatures with the keys of the jwks endpoint (e.g. keycloak .../protocol/openid-connect/certs); "" or "-" disables the verification
+	JwksRefreshInterval Duration `json:"jwks_refresh_interval"` //interval in which the cached keys are reloaded; unknown key ids trigger an earlier reload; default 1h
+	JwtIssuer           string   `json:"jwt_issuer"`            //optional; expected iss claim of verified tokens
+	JwtAudience         string   `json:"jwt_audience"`          //optional; verified tokens must contain the audience in the aud claim
+	JwtTrustedNetworks  []string `json:"jwt_trusted_networks"`  //optional; cidrs of internal networks, whose requests may use unverified tokens; forwarded requests are only trusted if all forwarding addresses are trusted
+
 	UserManagementUrl string `json:"user_management_url"`
 
 	ApiDocsProviderBaseUrl string `json:"api_docs_provider_base_url"`
@@ -0,0 +1,69 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/google/uuid"
+)
+
+func (this *Controller) ListAuditEntries(tokenStr string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	return this.ListAuditEntriesContext(context.TODO(), tokenStr, options)
+}
+
+func (this *Controller) ListAuditEntriesContext(ctx context.Context, tokenStr string, options model.AuditListOptions) (result []model.AuditEntry, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, "")
+	if err != nil {
+		return result, err, code
+	}
+	if !token.IsAdmin() {
+		return result, errors.New("only admins may read the audit log"), http.StatusForbidden
+	}
+	result, err = this.db.ListAuditEntries(this.getTimeoutContext(ctx), options)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// audit stores an audit entry for a change, that happened at t.
+// the change itself is already stored, so failures are only logged.
+func (this *Controller) audit(ctx context.Context, t time.Time, entry model.AuditEntry) {
+	entry.Id = uuid.NewString()
+	entry.Timestamp = t.UnixMilli()
+	err := this.db.AppendAuditEntry(this.getTimeoutContext(ctx), entry)
+	if err != nil {
+		this.config.GetLogger().ErrorContext(ctx, "unable to store audit entry", "error", err, "operation", entry.Operation, "topicId", entry.TopicId, "id", entry.ResourceId)
+	}
+}
+
+// getStoredPermissions returns the currently stored (own) permissions of a resource or nil if the resource does not exist
+func (this *Controller) getStoredPermissions(ctx context.Context, topicId string, id string) (*model.ResourcePermissions, error) {
+	resource, err := this.db.GetResource(this.getTimeoutContext(ctx), topicId, id, model.GetOptions{})
+	if errors.Is(err, model.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resource.ResourcePermissions, nil
+}
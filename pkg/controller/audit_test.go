@@ -0,0 +1,155 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestAuditLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	initial := model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner},
+	}
+	updated := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{"user": {Read: true}},
+	}
+
+	t.Run("changes", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "audit"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//unchanged topics are not audited
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "audit"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "audit", "r1", initial)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestToken, "audit", "r1", updated)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, _ = ctrl.RemoveResource(TestAdminToken, "audit", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, _ = ctrl.RemoveTopic(TestAdminToken, "audit")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		entries, err, _ := ctrl.ListAuditEntries(TestAdminToken, model.AuditListOptions{TopicId: "audit"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		operations := []model.AuditOperation{}
+		for _, entry := range entries {
+			if entry.Id == "" || entry.Timestamp == 0 {
+				t.Errorf("missing id or timestamp: %#v", entry)
+			}
+			operations = append(operations, entry.Operation)
+		}
+		expected := []model.AuditOperation{
+			model.AuditOperationRemoveTopic,
+			model.AuditOperationRemoveResource,
+			model.AuditOperationSetPermission,
+			model.AuditOperationSetPermission,
+			model.AuditOperationSetTopic,
+		}
+		if !reflect.DeepEqual(operations, expected) {
+			t.Errorf("\n%#v\n%#v", operations, expected)
+			return
+		}
+
+		userChange := entries[2]
+		if userChange.UserId != TestTokenUser || userChange.ResourceId != "r1" {
+			t.Errorf("%#v", userChange)
+		}
+		if userChange.PreviousPermissions == nil || !reflect.DeepEqual(userChange.PreviousPermissions.UserPermissions, initial.UserPermissions) || len(userChange.PreviousPermissions.RolePermissions) != 0 {
+			t.Errorf("%#v", userChange.PreviousPermissions)
+		}
+		if userChange.NewPermissions == nil || !reflect.DeepEqual(userChange.NewPermissions.RolePermissions, updated.RolePermissions) {
+			t.Errorf("%#v", userChange.NewPermissions)
+		}
+		if entries[3].PreviousPermissions != nil {
+			t.Errorf("creation should not have previous permissions: %#v", entries[3])
+		}
+		if entries[1].NewPermissions != nil || entries[1].PreviousPermissions == nil {
+			t.Errorf("%#v", entries[1])
+		}
+		if entries[0].PreviousTopic == nil || entries[0].NewTopic != nil || entries[4].NewTopic == nil || entries[4].PreviousTopic != nil {
+			t.Errorf("%#v", entries)
+		}
+	})
+
+	t.Run("filter and paginate", func(t *testing.T) {
+		entries, err, _ := ctrl.ListAuditEntries(TestAdminToken, model.AuditListOptions{UserId: TestTokenUser})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(entries) != 1 || entries[0].Operation != model.AuditOperationSetPermission {
+			t.Errorf("%#v", entries)
+		}
+		entries, err, _ = ctrl.ListAuditEntries(TestAdminToken, model.AuditListOptions{Operation: model.AuditOperationSetPermission, Limit: 1, Offset: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(entries) != 1 || entries[0].UserId == TestTokenUser {
+			t.Errorf("%#v", entries)
+		}
+	})
+
+	t.Run("only admins", func(t *testing.T) {
+		_, err, code := ctrl.ListAuditEntries(TestToken, model.AuditListOptions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+	})
+}
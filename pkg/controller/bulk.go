@@ -0,0 +1,231 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func (this *Controller) SetPermissionsBulk(tokenStr string, topicId string, items []model.BulkPermissionsItem) (result []model.BulkPermissionsResult, err error, code int) {
+	return this.SetPermissionsBulkContext(context.TODO(), tokenStr, topicId, items)
+}
+
+// SetPermissionsBulkContext checks and stores the permissions of many resources of one topic.
+// each item is checked like a SetPermission call; valid items are stored with one database write and published in batches.
+// err and code describe the request as a whole; the outcome of each item is described by the result with the same index.
+func (this *Controller) SetPermissionsBulkContext(ctx context.Context, tokenStr string, topicId string, items []model.BulkPermissionsItem) (result []model.BulkPermissionsResult, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeManage, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	if max := this.config.BulkMaxItems; max > 0 && len(items) > max {
+		return result, errors.New("too many items"), http.StatusRequestEntityTooLarge
+	}
+	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if !exists {
+		return result, errors.New("unknown topic"), http.StatusNotFound
+	}
+	//delegated topic admins are not affected by the topic default denies and the edit restrictions of checkEditPermission
+	delegatedAdmin := isTopicAdmin(token, topic)
+	if !delegatedAdmin && isDeniedByTopicDefaults(token, topic, model.PermissionList{model.Administrate}) {
+		return result, errors.New("access denied"), http.StatusForbidden
+	}
+	topicAdmin := delegatedAdmin
+	if !topicAdmin {
+		topicAdmin, err = this.checkTopicDefaultPermission(token, topic, model.PermissionList{model.Administrate})
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+	}
+
+	result = make([]model.BulkPermissionsResult, len(items))
+	pureIds := make([]string, len(items))
+	for i, item := range items {
+		result[i].Id = item.Id
+		pureIds[i], _ = idmodifier.SplitModifier(item.Id)
+	}
+
+	//resource admin permissions and previous permissions are read with one query each;
+	//the edit checks of all items share the parent checks and the user-management request
+	resourceAdmin := map[string]bool{}
+	if !topicAdmin {
+		resourceAdmin, err = this.db.CheckMultipleResourcePermissions(this.getTimeoutContext(ctx), topicId, pureIds, token.GetUserId(), token.GetRoles(), token.GetGroups(), model.Administrate)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+	}
+	previousResources, err := this.db.AdminListResources(this.getTimeoutContext(ctx), topicId, model.ListOptions{Ids: pureIds})
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	previous := map[string]*model.ResourcePermissions{}
+	for _, resource := range previousResources {
+		previous[resource.Id] = &resource.ResourcePermissions
+	}
+
+	cache := &editCheckCache{}
+	resources := []model.Resource{}
+	creates := []bool{}
+	indexes := []int{}
+	known := map[string]bool{}
+	for i, item := range items {
+		pureId := pureIds[i]
+		if item.Id == "" {
+			result[i].Code, result[i].Error = http.StatusBadRequest, "missing id"
+			continue
+		}
+		if known[pureId] {
+			result[i].Code, result[i].Error = http.StatusBadRequest, "id is contained more than once"
+			continue
+		}
+		known[pureId] = true
+		//the creation policy of the topic may allow the creation of new resources
+		create := !topicAdmin && !resourceAdmin[pureId] && previous[pureId] == nil &&
+			topic.CreationPolicy != nil && topic.CreationPolicy.Allows(token.GetRoles(), token.GetGroups())
+		if !topicAdmin && !resourceAdmin[pureId] && !create {
+			result[i].Code, result[i].Error = http.StatusForbidden, "access denied"
+			continue
+		}
+		permissions := item.Permissions
+		if !delegatedAdmin {
+			var code int
+			permissions, err, code = this.checkEditPermissionOfCreator(ctx, token, topic, previous[pureId], item.Permissions, create, cache)
+			if err != nil {
+				result[i].Code, result[i].Error = code, err.Error()
+				var violation *model.SharingPolicyError
+				if errors.As(err, &violation) {
+					result[i].Violations = violation.Violations
+				}
+				continue
+			}
+		}
+		if create {
+			permissions = topic.CreationPolicy.Apply(token.GetUserId(), permissions)
+		}
+		if !permissions.Valid() {
+			result[i].Code, result[i].Error = http.StatusBadRequest, "invalid permissions"
+			continue
+		}
+		err = topic.ValidateResourcePermissions(permissions)
+		if err != nil {
+			result[i].Code, result[i].Error = http.StatusBadRequest, err.Error()
+			continue
+		}
+		resources = append(resources, model.Resource{Id: pureId, TopicId: topic.Id, ResourcePermissions: permissions, Creator: token.GetUserId()})
+		creates = append(creates, create)
+		indexes = append(indexes, i)
+	}
+	if len(resources) == 0 {
+		return result, nil, http.StatusOK
+	}
+
+	//if published, the outbox events of all resources are relayed to kafka in batches
+	now := time.Now()
+	versions, errs := this.setResources(ctx, token.GetUserId(), topic, resources, creates, now)
+	for j, resource := range resources {
+		i := indexes[j]
+		if errs[j] != nil {
+			result[i].Code, result[i].Error = http.StatusInternalServerError, errs[j].Error()
+			if errors.Is(errs[j], model.ErrInvalidParent) {
+				result[i].Code = http.StatusBadRequest
+			}
+			if errors.Is(errs[j], model.ErrVersionMismatch) {
+				result[i].Code = http.StatusPreconditionFailed
+			}
+			continue
+		}
+		result[i].Code = http.StatusOK
+		result[i].Version = versions[j]
+		this.audit(ctx, now, model.AuditEntry{
+			UserId:              token.GetUserId(),
+			Operation:           model.AuditOperationBulkSetPermission,
+			TopicId:             resource.TopicId,
+			ResourceId:          resource.Id,
+			PreviousPermissions: previous[resource.Id],
+			NewPermissions:      &resource.ResourcePermissions,
+		})
+	}
+	this.triggerOutboxRelay()
+	return result, nil, http.StatusOK
+}
+
+// setResources stores the resources with one write and returns the new version or the error of each resource.
+// the resources are stored one by one (in order, so that parents precede their children), if the write is rejected because of an invalid parent,
+// to only reject the resources with an invalid parent, or if resources are created by the creation policy (creates), which must not exist at the time of the write.
+func (this *Controller) setResources(ctx context.Context, actor string, topic model.Topic, resources []model.Resource, creates []bool, now time.Time) (versions []int64, errs []error) {
+	errs = make([]error, len(resources))
+	if !slices.Contains(creates, true) {
+		var err error
+		versions, err = this.db.SetResources(this.getTimeoutContext(ctx), resources, now, !topic.PublishesToKafka(), this.newBulkChange(actor, now, resources...))
+		if err == nil {
+			return versions, errs
+		}
+		if !errors.Is(err, model.ErrInvalidParent) {
+			for j := range resources {
+				errs[j] = err
+			}
+			return make([]int64, len(resources)), errs
+		}
+	}
+	versions = make([]int64, len(resources))
+	for j, resource := range resources {
+		if !creates[j] {
+			var temp []int64
+			temp, errs[j] = this.db.SetResources(this.getTimeoutContext(ctx), []model.Resource{resource}, now, !topic.PublishesToKafka(), this.newBulkChange(actor, now, resource))
+			if errs[j] == nil {
+				versions[j] = temp[0]
+			}
+			continue
+		}
+		errs[j] = this.db.SetResourceIfVersion(this.getTimeoutContext(ctx), resource, now, !topic.PublishesToKafka(), 0, this.newBulkChange(actor, now, resource))
+		if errs[j] != nil {
+			continue
+		}
+		//the resource is stored; a failed read only omits the version in the result
+		created, err := this.db.GetResource(this.getTimeoutContext(ctx), resource.TopicId, resource.Id, model.GetOptions{})
+		if err == nil {
+			versions[j] = created.Version
+		}
+	}
+	return versions, errs
+}
+
+// newBulkChange returns the change of a bulk write with a set_resource webhook payload per resource
+func (this *Controller) newBulkChange(actor string, now time.Time, resources ...model.Resource) model.Change {
+	change := this.newChange(actor, model.AuditOperationBulkSetPermission)
+	for _, resource := range resources {
+		change.Webhooks = append(change.Webhooks, model.WebhookPayload{
+			Event:       model.WebhookEventSetResource,
+			Timestamp:   now.UnixMilli(),
+			UserId:      actor,
+			TopicId:     resource.TopicId,
+			ResourceId:  resource.Id,
+			Permissions: &resource.ResourcePermissions,
+		})
+	}
+	return change
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestSetPermissionsBulk(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}}
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{BulkMaxItems: 5}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+
+	codes := func(results []model.BulkPermissionsResult) (result []int) {
+		for _, r := range results {
+			result = append(result, r.Code)
+		}
+		return result
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "bulk", PublishToKafkaTopic: "bulk"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("create as admin", func(t *testing.T) {
+		results, err, code := ctrl.SetPermissionsBulk(TestAdminToken, "bulk", []model.BulkPermissionsItem{
+			{Id: "a", Permissions: permissions},
+			{Id: "b", Permissions: permissions},
+			{Id: "c", Permissions: permissions},
+		})
+		if err != nil || code != http.StatusOK {
+			t.Error(err, code)
+			return
+		}
+		if !reflect.DeepEqual(codes(results), []int{200, 200, 200}) {
+			t.Errorf("%#v", results)
+			return
+		}
+		for _, r := range results {
+			if r.Version != 1 {
+				t.Errorf("expected version 1, got %#v", r)
+			}
+		}
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(producer.Batches, []int{3}) {
+			t.Errorf("expected one batch of 3 messages, got %#v", producer.Batches)
+		}
+		for _, id := range []string{"a", "b", "c"} {
+			if len(producer.Produced["bulk"][id]) != 1 {
+				t.Errorf("expected one message for %v, got %#v", id, producer.Produced["bulk"][id])
+			}
+		}
+		versions, err, _ := ctrl.ListPermissionVersions(TestToken, "bulk", "a", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 1 || versions[0].Operation != model.AuditOperationBulkSetPermission {
+			t.Errorf("%#v", versions)
+		}
+	})
+
+	t.Run("per item results", func(t *testing.T) {
+		results, err, code := ctrl.SetPermissionsBulk(TestToken, "bulk", []model.BulkPermissionsItem{
+			{Id: "a", Permissions: permissions},
+			{Id: "a", Permissions: permissions},
+			{Id: "b", Permissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true}}}},
+			{Id: "unknown", Permissions: permissions},
+			{Id: "c", Permissions: permissions},
+		})
+		if err != nil || code != http.StatusOK {
+			t.Error(err, code)
+			return
+		}
+		if !reflect.DeepEqual(codes(results), []int{200, 400, 400, 403, 200}) {
+			t.Errorf("%#v", results)
+			return
+		}
+		if results[0].Version != 2 || results[4].Version != 2 {
+			t.Errorf("%#v", results)
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "bulk", "b")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.Version != 1 {
+			t.Errorf("invalid item should not be stored: %#v", resource)
+		}
+	})
+
+	t.Run("invalid parent", func(t *testing.T) {
+		child := func(parent string) model.ResourcePermissions {
+			return model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "bulk", Id: parent}}
+		}
+		results, err, code := ctrl.SetPermissionsBulk(TestAdminToken, "bulk", []model.BulkPermissionsItem{
+			{Id: "p", Permissions: permissions},
+			{Id: "p-child", Permissions: child("p")},
+			{Id: "orphan", Permissions: child("unknown")},
+			{Id: "a", Permissions: permissions},
+		})
+		if err != nil || code != http.StatusOK {
+			t.Error(err, code)
+			return
+		}
+		if !reflect.DeepEqual(codes(results), []int{200, 200, 400, 200}) {
+			t.Errorf("%#v", results)
+			return
+		}
+		if results[0].Version != 1 || results[1].Version != 1 || results[3].Version != 3 {
+			t.Errorf("%#v", results)
+		}
+		_, err, code = ctrl.GetResource(TestAdminToken, "bulk", "orphan")
+		if code != http.StatusNotFound {
+			t.Errorf("invalid item should not be stored: %v %v", err, code)
+		}
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "bulk", "p-child")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.EffectivePermissions == nil || !resource.EffectivePermissions.UserPermissions[TestTokenUser].Administrate {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("request errors", func(t *testing.T) {
+		_, _, code := ctrl.SetPermissionsBulk(TestAdminToken, "unknown", []model.BulkPermissionsItem{{Id: "a", Permissions: permissions}})
+		if code != http.StatusNotFound {
+			t.Errorf("expected not found, got %v", code)
+		}
+		items := []model.BulkPermissionsItem{}
+		for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+			items = append(items, model.BulkPermissionsItem{Id: id, Permissions: permissions})
+		}
+		_, _, code = ctrl.SetPermissionsBulk(TestAdminToken, "bulk", items)
+		if code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected request entity too large, got %v", code)
+		}
+	})
+}
+
+func TestSetPermissionsBulkUserManagement(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := atomic.Int64{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user-list" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		requests.Add(1)
+		json.NewEncoder(w).Encode([]User{{Id: TestTokenUser}, {Id: "teammate"}})
+	}))
+	defer server.Close()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{UserManagementUrl: server.URL}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	withUsers := func(users ...string) model.ResourcePermissions {
+		result := model.ResourcePermissions{
+			UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+			GroupPermissions: map[string]model.PermissionsMap{},
+			RolePermissions:  map[string]model.PermissionsMap{},
+		}
+		for _, user := range users {
+			result.UserPermissions[user] = model.PermissionsMap{Read: true}
+		}
+		return result
+	}
+
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "bulk"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "bulk", id, withUsers())
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	results, err, code := ctrl.SetPermissionsBulk(TestToken, "bulk", []model.BulkPermissionsItem{
+		{Id: "a", Permissions: withUsers("teammate")},
+		{Id: "b", Permissions: withUsers("teammate")},
+		{Id: "c", Permissions: withUsers("teammate")},
+		{Id: "d", Permissions: withUsers("stranger")},
+	})
+	if err != nil || code != http.StatusOK {
+		t.Error(err, code)
+		return
+	}
+	codes := []int{}
+	for _, r := range results {
+		codes = append(codes, r.Code)
+	}
+	if !reflect.DeepEqual(codes, []int{200, 200, 200, 400}) {
+		t.Errorf("%#v", results)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("expected one user-management request, got %v", requests.Load())
+	}
+}
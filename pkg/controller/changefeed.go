@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/google/uuid"
+)
+
+const defaultChangeFeedBufferSize = 1000
+
+var errStopWatch = errors.New("stop watch")
+
+func (this *Controller) WatchPermissionChanges(tokenStr string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	return this.WatchPermissionChangesContext(context.TODO(), tokenStr, topicId, options)
+}
+
+// WatchPermissionChangesContext returns the permission changes of resources in the topic, until ctx is done or the iteration is stopped.
+// changes are watched from the time of the call (or after options.ResumeToken); a stream error is yielded as last element.
+func (this *Controller) WatchPermissionChangesContext(ctx context.Context, tokenStr string, topicId string, options model.ChangeFeedOptions) (events iter.Seq2[model.ChangeEvent, error], err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return nil, err, code
+	}
+	mode, err := model.PermissionModeFromString(string(options.Mode))
+	if err != nil {
+		return nil, err, http.StatusBadRequest
+	}
+	permissions := options.Permissions
+	if len(permissions) == 0 {
+		permissions = model.PermissionList{model.Administrate}
+	}
+
+	//admins of the topic receive all changes with details
+	full, _, err, code := this.checkTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
+	if err != nil {
+		return nil, err, code
+	}
+	topicAccess, denied, _, err, code := this.checkTopicDefaultPermissionWithTopics(ctx, token, nil, topicId, mode, permissions)
+	if err != nil {
+		return nil, err, code
+	}
+	if denied && !full {
+		return nil, errors.New("access denied"), http.StatusForbidden
+	}
+
+	var watch model.VersionWatch
+	if this.config.ChangeFeedUseChangeStreams {
+		watch, err = this.db.WatchPermissionsVersions(ctx, options.ResumeToken)
+	} else {
+		watch, err = this.changes.watch(ctx, options.ResumeToken)
+	}
+	if errors.Is(err, model.ErrResumeTokenExpired) {
+		return nil, err, http.StatusGone
+	}
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+
+	return func(yield func(model.ChangeEvent, error) bool) {
+		err := watch(func(resumeToken string, version model.PermissionsVersion) error {
+			if version.TopicId != topicId {
+				return nil
+			}
+			event := model.ChangeEvent{Token: resumeToken, PermissionsVersion: version}
+			if !full {
+				access, lost, err := this.getChangeFeedAccess(ctx, token, version, topicAccess, mode, permissions)
+				if err != nil {
+					return err
+				}
+				if !access && !lost {
+					return nil
+				}
+				event = event.WithoutDetails()
+				event.AccessLost = lost
+			}
+			if !yield(event, nil) {
+				return errStopWatch
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopWatch) {
+			yield(model.ChangeEvent{}, err)
+		}
+	}, nil, http.StatusOK
+}
+
+// getChangeFeedAccess checks the permissions of a subscriber without admin rights on the topic for the changed resource.
+// the effective permissions of the version are checked, because the resource may have changed since; removed resources are checked with the previous version.
+// if the version denies the access, lost is true if the previous version of the resource granted it.
+func (this *Controller) getChangeFeedAccess(ctx context.Context, token jwt.Token, version model.PermissionsVersion, topicAccess bool, mode model.PermissionMode, permissions model.PermissionList) (access bool, lost bool, err error) {
+	if topicAccess {
+		return true, false, nil
+	}
+	if !version.Deleted && hasVersionAccess(token, version, mode, permissions) {
+		return true, false, nil
+	}
+	previous, err := this.db.GetPermissionsVersion(this.getTimeoutContext(ctx), version.TopicId, version.Id, version.Version-1)
+	if errors.Is(err, model.ErrNotFound) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	if previous.Deleted || !hasVersionAccess(token, previous, mode, permissions) {
+		return false, false, nil
+	}
+	if version.Deleted {
+		return true, false, nil
+	}
+	return false, true, nil
+}
+
+func hasVersionAccess(token jwt.Token, version model.PermissionsVersion, mode model.PermissionMode, permissions model.PermissionList) bool {
+	user, roles, groups := token.GetUserId(), token.GetRoles(), token.GetGroups()
+	effective := version.Effective()
+	return mode.Matches(permissions, func(permission model.Permission) bool {
+		return effective.IsGranted(user, roles, groups, permission) && !effective.IsDenied(user, roles, groups, permission)
+	})
+}
+
+// changeFeed distributes the permission versions stored by this instance to change feed subscriptions.
+// the latest versions are buffered, to let subscriptions resume after reconnects.
+// resume tokens consist of the feed id and a sequence number; tokens of other instances or restarted instances are unknown.
+type changeFeed struct {
+	id      string
+	size    int
+	mux     sync.Mutex
+	seq     int64                      //sequence number of the latest version
+	buffer  []model.PermissionsVersion //latest versions; the last element has the sequence number seq
+	changed chan struct{}              //closed and replaced on every published version
+}
+
+func newChangeFeed(size int) *changeFeed {
+	if size <= 0 {
+		size = defaultChangeFeedBufferSize
+	}
+	return &changeFeed{id: uuid.NewString(), size: size, changed: make(chan struct{})}
+}
+
+func (this *changeFeed) publish(version model.PermissionsVersion) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.seq++
+	this.buffer = append(this.buffer, version)
+	if len(this.buffer) > this.size {
+		this.buffer = this.buffer[len(this.buffer)-this.size:]
+	}
+	close(this.changed)
+	this.changed = make(chan struct{})
+}
+
+func (this *changeFeed) token(seq int64) string {
+	return this.id + "." + strconv.FormatInt(seq, 10)
+}
+
+// parseToken returns the sequence number of the token or model.ErrResumeTokenExpired, if the feed can not continue after it
+func (this *changeFeed) parseToken(token string) (seq int64, err error) {
+	id, seqStr, ok := strings.Cut(token, ".")
+	if !ok || id != this.id {
+		return 0, model.ErrResumeTokenExpired
+	}
+	seq, err = strconv.ParseInt(seqStr, 10, 64)
+	if err != nil {
+		return 0, model.ErrResumeTokenExpired
+	}
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if seq < this.seq-int64(len(this.buffer)) || seq > this.seq {
+		return 0, model.ErrResumeTokenExpired
+	}
+	return seq, nil
+}
+
+// watch has the signature of database.Database.WatchPermissionsVersions.
+// subscriptions, that fall behind by more than the buffer size, end with model.ErrResumeTokenExpired.
+func (this *changeFeed) watch(ctx context.Context, resumeToken string) (watch model.VersionWatch, err error) {
+	var last int64
+	if resumeToken == "" {
+		this.mux.Lock()
+		last = this.seq
+		this.mux.Unlock()
+	} else {
+		last, err = this.parseToken(resumeToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return func(handler func(token string, version model.PermissionsVersion) error) error {
+		for {
+			this.mux.Lock()
+			first := this.seq - int64(len(this.buffer)) + 1
+			if last+1 < first {
+				this.mux.Unlock()
+				return model.ErrResumeTokenExpired
+			}
+			pending := slices.Clone(this.buffer[last+1-first:])
+			changed := this.changed
+			this.mux.Unlock()
+
+			for _, version := range pending {
+				last++
+				err := handler(this.token(last), version)
+				if err != nil {
+					return err
+				}
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+			}
+		}
+	}, nil
+}
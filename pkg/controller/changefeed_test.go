@@ -0,0 +1,350 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestChangeFeed(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		testChangeFeed(t, configuration.Config{})
+	})
+	t.Run("change streams", func(t *testing.T) {
+		testChangeFeed(t, configuration.Config{ChangeFeedUseChangeStreams: true})
+	})
+}
+
+func testChangeFeed(t *testing.T, config configuration.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, config, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for _, topic := range []string{"feed", "other"} {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: topic})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	adminEvents, err, _ := ctrl.WatchPermissionChangesContext(ctx, TestAdminToken, "feed", model.ChangeFeedOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	adminChanges := collectChanges(t, adminEvents)
+	userEvents, err, _ := ctrl.WatchPermissionChangesContext(ctx, TestToken, "feed", model.ChangeFeedOptions{Permissions: model.PermissionList{model.Read}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	userChanges := collectChanges(t, userEvents)
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "r1", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "r2", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{"owner": owner}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "other", "r3", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	err, _ = ctrl.RemoveResource(TestAdminToken, "feed", "r1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var first model.ChangeEvent
+	t.Run("admin", func(t *testing.T) {
+		events := receiveChanges(t, adminChanges, 3)
+		if len(events) != 3 {
+			return
+		}
+		first = events[0]
+		if events[0].Id != "r1" || events[0].Version != 1 || events[0].Deleted || !events[0].Permissions.UserPermissions[TestTokenUser].Administrate {
+			t.Errorf("%#v", events[0])
+		}
+		if events[1].Id != "r2" || events[1].Version != 1 || events[1].Deleted {
+			t.Errorf("%#v", events[1])
+		}
+		if events[2].Id != "r1" || events[2].Version != 2 || !events[2].Deleted || events[2].Operation != model.AuditOperationRemoveResource {
+			t.Errorf("%#v", events[2])
+		}
+		for _, event := range events {
+			if event.Token == "" || event.TopicId != "feed" {
+				t.Errorf("%#v", event)
+			}
+		}
+	})
+
+	t.Run("user", func(t *testing.T) {
+		events := receiveChanges(t, userChanges, 2)
+		if len(events) != 2 {
+			return
+		}
+		if events[0].Id != "r1" || events[0].Version != 1 || events[0].Permissions.UserPermissions != nil || events[0].UserId != "" {
+			t.Errorf("%#v", events[0])
+		}
+		if events[1].Id != "r1" || !events[1].Deleted {
+			t.Errorf("%#v", events[1])
+		}
+		select {
+		case event := <-userChanges:
+			t.Errorf("unexpected event %#v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		subCtx, subCancel := context.WithCancel(ctx)
+		defer subCancel()
+		events, err, _ := ctrl.WatchPermissionChangesContext(subCtx, TestAdminToken, "feed", model.ChangeFeedOptions{ResumeToken: first.Token})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		received := receiveChanges(t, collectChanges(t, events), 2)
+		if len(received) != 2 || received[0].Id != "r2" || received[1].Id != "r1" || !received[1].Deleted {
+			t.Errorf("%#v", received)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err, code := ctrl.WatchPermissionChangesContext(ctx, TestAdminToken, "feed", model.ChangeFeedOptions{ResumeToken: "unknown"})
+		if err == nil || code != http.StatusGone {
+			t.Errorf("expected gone, got %v %v", err, code)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		_, err, code := ctrl.WatchPermissionChangesContext(ctx, TestToken, "unknown", model.ChangeFeedOptions{})
+		if err == nil || code != http.StatusNotFound {
+			t.Errorf("expected not found, got %v %v", err, code)
+		}
+		_, err, code = ctrl.WatchPermissionChangesContext(ctx, TestToken, "feed", model.ChangeFeedOptions{Permissions: model.PermissionList{'s'}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v %v", err, code)
+		}
+		_, err, code = ctrl.WatchPermissionChangesContext(ctx, TestToken, "feed", model.ChangeFeedOptions{Mode: "some"})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v %v", err, code)
+		}
+	})
+}
+
+func TestChangeFeedAccessLost(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		testChangeFeedAccessLost(t, configuration.Config{})
+	})
+	t.Run("change streams", func(t *testing.T) {
+		testChangeFeedAccessLost(t, configuration.Config{ChangeFeedUseChangeStreams: true})
+	})
+}
+
+func testChangeFeedAccessLost(t *testing.T, config configuration.Config) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, config, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "feed"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	userEvents, err, _ := ctrl.WatchPermissionChangesContext(ctx, TestToken, "feed", model.ChangeFeedOptions{Permissions: model.PermissionList{model.Read}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	userChanges := collectChanges(t, userEvents)
+
+	owner := map[string]model.PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}}
+	withUser := func(users map[string]model.PermissionsMap, user string) map[string]model.PermissionsMap {
+		result := map[string]model.PermissionsMap{user: {Read: true}}
+		for k, v := range users {
+			result[k] = v
+		}
+		return result
+	}
+
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "parent", model.ResourcePermissions{UserPermissions: withUser(owner, TestTokenUser)})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "child", model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "feed", Id: "parent"}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("inherited access", func(t *testing.T) {
+		events := receiveChanges(t, userChanges, 2)
+		if len(events) != 2 {
+			return
+		}
+		if events[0].Id != "parent" || events[0].Version != 1 || events[0].AccessLost {
+			t.Errorf("%#v", events[0])
+		}
+		if events[1].Id != "child" || events[1].Version != 1 || events[1].AccessLost || events[1].EffectivePermissions != nil {
+			t.Errorf("%#v", events[1])
+		}
+	})
+
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "parent", model.ResourcePermissions{UserPermissions: owner})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("lost access", func(t *testing.T) {
+		events := receiveChanges(t, userChanges, 2)
+		if len(events) != 2 {
+			return
+		}
+		ids := map[string]bool{}
+		for _, event := range events {
+			ids[event.Id] = true
+			if !event.AccessLost || event.Version != 2 || event.Permissions.UserPermissions != nil {
+				t.Errorf("%#v", event)
+			}
+		}
+		if !ids["parent"] || !ids["child"] {
+			t.Errorf("%#v", events)
+		}
+	})
+
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "feed", "parent", model.ResourcePermissions{UserPermissions: withUser(owner, "other")})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("no further changes", func(t *testing.T) {
+		select {
+		case event := <-userChanges:
+			t.Errorf("unexpected event %#v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+}
+
+func TestChangeFeedBuffer(t *testing.T) {
+	feed := newChangeFeed(2)
+	for i := 0; i < 3; i++ {
+		feed.publish(model.PermissionsVersion{Id: "r", Version: int64(i + 1)})
+	}
+	_, err := feed.parseToken(feed.token(0))
+	if err != model.ErrResumeTokenExpired {
+		t.Errorf("expected ErrResumeTokenExpired, got %v", err)
+	}
+	_, err = feed.parseToken(newChangeFeed(2).token(1))
+	if err != model.ErrResumeTokenExpired {
+		t.Errorf("expected ErrResumeTokenExpired, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := feed.watch(ctx, feed.token(1))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	versions := []int64{}
+	err = watch(func(token string, version model.PermissionsVersion) error {
+		versions = append(versions, version.Version)
+		if len(versions) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(versions) != 2 || versions[0] != 2 || versions[1] != 3 {
+		t.Errorf("%#v", versions)
+	}
+
+	//subscriptions, which fall behind, end with ErrResumeTokenExpired
+	watch, err = feed.watch(context.Background(), feed.token(3))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	for i := 0; i < 3; i++ {
+		feed.publish(model.PermissionsVersion{Id: "r", Version: int64(i + 4)})
+	}
+	err = watch(func(token string, version model.PermissionsVersion) error {
+		return nil
+	})
+	if err != model.ErrResumeTokenExpired {
+		t.Errorf("expected ErrResumeTokenExpired, got %v", err)
+	}
+}
+
+func collectChanges(t *testing.T, events iter.Seq2[model.ChangeEvent, error]) <-chan model.ChangeEvent {
+	result := make(chan model.ChangeEvent, 100)
+	go func() {
+		for event, err := range events {
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			result <- event
+		}
+	}()
+	return result
+}
+
+func receiveChanges(t *testing.T, changes <-chan model.ChangeEvent, count int) (result []model.ChangeEvent) {
+	for len(result) < count {
+		select {
+		case event := <-changes:
+			result = append(result, event)
+		case <-time.After(2 * time.Second):
+			t.Errorf("timeout after %v of %v events", len(result), count)
+			return result
+		}
+	}
+	return result
+}
@@ -20,11 +20,11 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/idmodifier"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
-	"golang.org/x/exp/slices"
 )
 
 func (this *Controller) CheckPermission(tokenStr string, topicId string, id string, permissions ...model.Permission) (access bool, err error, code int) {
@@ -32,33 +32,48 @@ func (this *Controller) CheckPermission(tokenStr string, topicId string, id stri
 }
 
 func (this *Controller) CheckPermissionContext(ctx context.Context, tokenStr string, topicId string, id string, permissions ...model.Permission) (access bool, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	return this.CheckPermissionWithModeContext(ctx, tokenStr, topicId, id, model.PermissionModeAll, permissions...)
+}
+
+func (this *Controller) CheckPermissionWithMode(tokenStr string, topicId string, id string, mode model.PermissionMode, permissions ...model.Permission) (access bool, err error, code int) {
+	return this.CheckPermissionWithModeContext(context.TODO(), tokenStr, topicId, id, mode, permissions...)
+}
+
+func (this *Controller) CheckPermissionWithModeContext(ctx context.Context, tokenStr string, topicId string, id string, mode model.PermissionMode, permissions ...model.Permission) (access bool, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
 	if err != nil {
-		return false, err, http.StatusUnauthorized
+		return false, err, code
 	}
-	return this.checkPermission(token, ctx, topicId, id, permissions...)
+	return this.checkPermissionWithMode(token, ctx, topicId, id, mode, permissions...)
 }
 
 func (this *Controller) checkPermission(token jwt.Token, ctx context.Context, topicId string, id string, permissions ...model.Permission) (access bool, err error, code int) {
-	access, err, code = this.CheckTopicDefaultPermissionContext(ctx, token, topicId, permissions)
+	return this.checkPermissionWithMode(token, ctx, topicId, id, model.PermissionModeAll, permissions...)
+}
+
+func (this *Controller) checkPermissionWithMode(token jwt.Token, ctx context.Context, topicId string, id string, mode model.PermissionMode, permissions ...model.Permission) (access bool, err error, code int) {
+	access, denied, resourcePermissions, err, code := this.checkTopicDefaultPermissionWithTopics(ctx, token, nil, topicId, mode, permissions)
 	if err != nil {
-		if code >= 500 {
+		if code >= 500 || errors.Is(err, model.ErrUnknownPermission) {
 			return access, err, code
 		} else {
 			//we don't want to tell scrapers if a resource id exists or not
 			return false, nil, http.StatusOK
 		}
 	}
+	if denied {
+		return false, nil, http.StatusOK
+	}
 	if access {
 		return true, nil, http.StatusOK
 	}
 
 	pureId, _ := idmodifier.SplitModifier(id)
-	access, err = this.db.CheckResourcePermissions(this.getTimeoutContext(ctx), topicId, pureId, token.GetUserId(), token.GetRoles(), token.GetGroups(), permissions...)
+	accessMap, err := this.db.CheckMultipleResourcePermissionsWithMode(this.getTimeoutContext(ctx), topicId, []string{pureId}, token.GetUserId(), token.GetRoles(), token.GetGroups(), mode, resourcePermissions...)
 	if err != nil {
-		return access, err, http.StatusInternalServerError
+		return false, err, http.StatusInternalServerError
 	}
-	return access, nil, http.StatusOK
+	return accessMap[pureId], nil, http.StatusOK
 }
 
 func (this *Controller) CheckMultiplePermissions(tokenStr string, topicId string, ids []string, permissions ...model.Permission) (accessMap map[string]bool, err error, code int) {
@@ -66,10 +81,73 @@ func (this *Controller) CheckMultiplePermissions(tokenStr string, topicId string
 }
 
 func (this *Controller) CheckMultiplePermissionsContext(ctx context.Context, tokenStr string, topicId string, ids []string, permissions ...model.Permission) (accessMap map[string]bool, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	return this.CheckMultiplePermissionsWithModeContext(ctx, tokenStr, topicId, ids, model.PermissionModeAll, permissions...)
+}
+
+func (this *Controller) CheckMultiplePermissionsWithMode(tokenStr string, topicId string, ids []string, mode model.PermissionMode, permissions ...model.Permission) (accessMap map[string]bool, err error, code int) {
+	return this.CheckMultiplePermissionsWithModeContext(context.TODO(), tokenStr, topicId, ids, mode, permissions...)
+}
+
+func (this *Controller) CheckMultiplePermissionsWithModeContext(ctx context.Context, tokenStr string, topicId string, ids []string, mode model.PermissionMode, permissions ...model.Permission) (accessMap map[string]bool, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return accessMap, err, code
+	}
+	return this.checkMultiplePermissions(ctx, token, nil, topicId, ids, mode, permissions...)
+}
+
+func (this *Controller) CheckPermissionsBatch(tokenStr string, queries []model.CheckQuery) (result map[string]map[string]bool, err error, code int) {
+	return this.CheckPermissionsBatchContext(context.TODO(), tokenStr, queries)
+}
+
+// CheckPermissionsBatchContext checks the queries like CheckMultiplePermissionsContext calls with the same token.
+// each topic is read only once. the result maps topic ids to the access maps of the queries;
+// if multiple queries of the same topic contain an id, all of them must grant access.
+func (this *Controller) CheckPermissionsBatchContext(ctx context.Context, tokenStr string, queries []model.CheckQuery) (result map[string]map[string]bool, err error, code int) {
+	topicIds := []string{}
+	for _, query := range queries {
+		topicIds = append(topicIds, query.TopicId)
+	}
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicIds...)
 	if err != nil {
-		return accessMap, err, http.StatusUnauthorized
+		return result, err, code
+	}
+	topics := topicCache{}
+	result = map[string]map[string]bool{}
+	for _, query := range queries {
+		permissionsStr := query.Permissions
+		if permissionsStr == "" {
+			permissionsStr = "r"
+		}
+		permissions, err := model.PermissionListFromString(permissionsStr)
+		if err != nil {
+			return nil, err, http.StatusBadRequest
+		}
+		mode, err := model.PermissionModeFromString(string(query.Mode))
+		if err != nil {
+			return nil, err, http.StatusBadRequest
+		}
+		accessMap, err, code := this.checkMultiplePermissions(ctx, token, topics, query.TopicId, query.Ids, mode, permissions...)
+		if err != nil {
+			return nil, err, code
+		}
+		topicResult, ok := result[query.TopicId]
+		if !ok {
+			topicResult = map[string]bool{}
+			result[query.TopicId] = topicResult
+		}
+		for id, access := range accessMap {
+			if previous, ok := topicResult[id]; ok {
+				access = access && previous
+			}
+			topicResult[id] = access
+		}
 	}
+	return result, nil, http.StatusOK
+}
+
+// checkMultiplePermissions reads the topic from topics, if not nil
+func (this *Controller) checkMultiplePermissions(ctx context.Context, token jwt.Token, topics topicCache, topicId string, ids []string, mode model.PermissionMode, permissions ...model.Permission) (accessMap map[string]bool, err error, code int) {
 	pureIdList := []string{}
 	pureIdToIds := map[string][]string{}
 	for _, id := range ids {
@@ -79,14 +157,14 @@ func (this *Controller) CheckMultiplePermissionsContext(ctx context.Context, tok
 	}
 	var pureAccess map[string]bool
 
-	access, err, code := this.CheckTopicDefaultPermissionContext(ctx, token, topicId, permissions)
+	access, denied, resourcePermissions, err, code := this.checkTopicDefaultPermissionWithTopics(ctx, token, topics, topicId, mode, permissions)
 	if err != nil {
 		return accessMap, err, code
 	}
-	if access {
+	if access || denied {
 		pureAccess, err = this.db.CheckMultipleResourcePermissions(this.getTimeoutContext(ctx), topicId, pureIdList, token.GetUserId(), token.GetRoles(), token.GetGroups())
 	} else {
-		pureAccess, err = this.db.CheckMultipleResourcePermissions(this.getTimeoutContext(ctx), topicId, pureIdList, token.GetUserId(), token.GetRoles(), token.GetGroups(), permissions...)
+		pureAccess, err = this.db.CheckMultipleResourcePermissionsWithMode(this.getTimeoutContext(ctx), topicId, pureIdList, token.GetUserId(), token.GetRoles(), token.GetGroups(), mode, resourcePermissions...)
 	}
 	if err != nil {
 		return accessMap, err, http.StatusInternalServerError
@@ -94,7 +172,7 @@ func (this *Controller) CheckMultiplePermissionsContext(ctx context.Context, tok
 	accessMap = map[string]bool{}
 	for key, value := range pureAccess {
 		for _, id := range pureIdToIds[key] {
-			accessMap[id] = value
+			accessMap[id] = value && !denied
 		}
 	}
 	return accessMap, err, http.StatusOK
@@ -105,9 +183,9 @@ func (this *Controller) ListComputedPermissions(tokenStr string, topicId string,
 }
 
 func (this *Controller) ListComputedPermissionsContext(ctx context.Context, tokenStr string, topicId string, ids []string) (result []model.ComputedPermissions, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
+		return result, err, code
 	}
 	isAdmin := token.IsAdmin()
 	if ids == nil {
@@ -132,14 +210,18 @@ func (this *Controller) ListComputedPermissionsContext(ctx context.Context, toke
 	done := map[string]bool{}
 	for _, resource := range resources {
 		if isAdmin {
+			all := model.PermissionsMap{
+				Read:         true,
+				Write:        true,
+				Execute:      true,
+				Administrate: true,
+			}
+			for _, custom := range topic.CustomPermissions {
+				all = all.With(model.Permission(custom.Letter[0]), true)
+			}
 			result = append(result, model.ComputedPermissions{
-				Id: resource.Id,
-				PermissionsMap: model.PermissionsMap{
-					Read:         true,
-					Write:        true,
-					Execute:      true,
-					Administrate: true,
-				},
+				Id:             resource.Id,
+				PermissionsMap: all,
 			})
 		} else {
 			result = append(result, model.ComputedPermissions{
@@ -166,84 +248,49 @@ func (this *Controller) ListComputedPermissionsContext(ctx context.Context, toke
 }
 
 func ComputePermissionsMap(token jwt.Token, resource model.Resource, defaultPerm model.ResourcePermissions) (result model.PermissionsMap) {
-	result = resource.UserPermissions[token.GetUserId()]
+	user := token.GetUserId()
+	roles := token.GetRoles()
+	groups := token.GetGroups()
 
-	defaultForUser, ok := defaultPerm.UserPermissions[token.GetUserId()]
-	if ok {
-		if defaultForUser.Read {
-			result.Read = true
-		}
-		if defaultForUser.Write {
-			result.Write = true
-		}
-		if defaultForUser.Execute {
-			result.Execute = true
-		}
-		if defaultForUser.Administrate {
-			result.Administrate = true
-		}
-	}
+	resourcePermissions := computeEffectivePermissions(resource.Effective(), user, roles, groups)
+	defaultPermissions := computeEffectivePermissions(defaultPerm, user, roles, groups)
+	result = grantAll(resourcePermissions, defaultPermissions)
 
-	for _, role := range token.GetRoles() {
-		defaultForRole, ok := defaultPerm.RolePermissions[role]
-		if ok {
-			if defaultForRole.Read {
-				result.Read = true
-			}
-			if defaultForRole.Write {
-				result.Write = true
-			}
-			if defaultForRole.Execute {
-				result.Execute = true
-			}
-			if defaultForRole.Administrate {
-				result.Administrate = true
-			}
-		}
+	//topic default denies override the resource permissions
+	return removeDenied(result, defaultPerm, user, roles, groups)
+}
 
-		rolePermissions := resource.RolePermissions[role]
-		if rolePermissions.Read {
-			result.Read = true
-		}
-		if rolePermissions.Write {
-			result.Write = true
-		}
-		if rolePermissions.Execute {
-			result.Execute = true
-		}
-		if rolePermissions.Administrate {
-			result.Administrate = true
-		}
+// computeEffectivePermissions returns the permissions granted to the user, roles and groups, without the permissions denied by the same ResourcePermissions
+func computeEffectivePermissions(permissions model.ResourcePermissions, user string, roles []string, groups []string) (result model.PermissionsMap) {
+	grants := []model.PermissionsMap{permissions.UserPermissions[user]}
+	for _, role := range roles {
+		grants = append(grants, permissions.RolePermissions[role])
 	}
-	for _, group := range token.GetGroups() {
-		defaultForGroup, ok := defaultPerm.GroupPermissions[group]
-		if ok {
-			if defaultForGroup.Read {
-				result.Read = true
-			}
-			if defaultForGroup.Write {
-				result.Write = true
-			}
-			if defaultForGroup.Execute {
-				result.Execute = true
-			}
-			if defaultForGroup.Administrate {
-				result.Administrate = true
-			}
+	for _, group := range groups {
+		grants = append(grants, permissions.GroupPermissions[group])
+	}
+	now := time.Now()
+	for _, grant := range grants {
+		if !grant.IsActive(now) {
+			continue
 		}
+		result = grantAll(result, grant)
+	}
+	return removeDenied(result, permissions, user, roles, groups)
+}
 
-		groupPermissions := resource.GroupPermissions[group]
-		if groupPermissions.Read {
-			result.Read = true
-		}
-		if groupPermissions.Write {
-			result.Write = true
-		}
-		if groupPermissions.Execute {
-			result.Execute = true
-		}
-		if groupPermissions.Administrate {
-			result.Administrate = true
+// grantAll returns the union of the permissions of both maps, without time limits
+func grantAll(a model.PermissionsMap, b model.PermissionsMap) (result model.PermissionsMap) {
+	for _, permission := range append(a.List(), b.List()...) {
+		result = result.With(permission, true)
+	}
+	return result
+}
+
+func removeDenied(result model.PermissionsMap, permissions model.ResourcePermissions, user string, roles []string, groups []string) model.PermissionsMap {
+	for _, permission := range result.List() {
+		if permissions.IsDenied(user, roles, groups, permission) {
+			result = result.With(permission, false)
 		}
 	}
 	return result
@@ -254,25 +301,95 @@ func (this *Controller) CheckTopicDefaultPermission(token jwt.Token, topicId str
 }
 
 func (this *Controller) CheckTopicDefaultPermissionContext(ctx context.Context, token jwt.Token, topicId string, permissions model.PermissionList) (access bool, err error, code int) {
+	access, _, err, code = this.checkTopicDefaultPermissionContext(ctx, token, topicId, permissions)
+	return access, err, code
+}
+
+// checkTopicDefaultPermissionContext returns access == true if the topic default permissions grant all permissions
+// and denied == true if the topic default permissions deny at least one permission, which overrides all resource permissions of the topic
+func (this *Controller) checkTopicDefaultPermissionContext(ctx context.Context, token jwt.Token, topicId string, permissions model.PermissionList) (access bool, denied bool, err error, code int) {
+	access, denied, _, err, code = this.checkTopicDefaultPermissionWithTopics(ctx, token, nil, topicId, model.PermissionModeAll, permissions)
+	return access, denied, err, code
+}
+
+// checkTopicDefaultPermissionWithTopics is checkTopicDefaultPermissionContext with an optional topicCache and a permission mode.
+// resourcePermissions are the permissions, which remain to be checked on resource level;
+// in mode any, permissions denied by the topic defaults can not be granted by resources and are removed.
+func (this *Controller) checkTopicDefaultPermissionWithTopics(ctx context.Context, token jwt.Token, topics topicCache, topicId string, mode model.PermissionMode, permissions model.PermissionList) (access bool, denied bool, resourcePermissions model.PermissionList, err error, code int) {
 	access = token.IsAdmin()
 	if access {
-		return true, nil, http.StatusOK
+		return true, false, permissions, nil, http.StatusOK
 	}
-	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	topic, exists, err := this.getTopicWithCache(ctx, topics, topicId)
 	if err != nil {
-		return access, err, http.StatusInternalServerError
+		return access, false, permissions, err, http.StatusInternalServerError
 	}
 	if !exists {
-		return access, errors.New("unknown topic"), http.StatusNotFound
+		return access, false, permissions, errors.New("unknown topic"), http.StatusNotFound
+	}
+	err = topic.ValidatePermissions(permissions)
+	if err != nil {
+		return access, false, permissions, err, http.StatusBadRequest
 	}
-	access, err = this.checkTopicDefaultPermission(token, topic, permissions)
+	denied = isDeniedByTopicDefaultsWithMode(token, topic, mode, permissions)
+	access, err = this.checkTopicDefaultPermissionWithMode(token, topic, mode, permissions)
 	if err != nil {
-		return access, err, http.StatusInternalServerError
+		return access, denied, permissions, err, http.StatusInternalServerError
 	}
-	return access, nil, http.StatusOK
+	resourcePermissions = permissions
+	if mode == model.PermissionModeAny {
+		resourcePermissions = model.PermissionList{}
+		for _, permission := range permissions {
+			if !isDeniedByTopicDefaults(token, topic, model.PermissionList{permission}) {
+				resourcePermissions = append(resourcePermissions, permission)
+			}
+		}
+	}
+	return access, denied, resourcePermissions, nil, http.StatusOK
+}
+
+// topicCache stores the topics read by one request; the zero value nil disables caching
+type topicCache map[string]cachedTopic
+
+type cachedTopic struct {
+	topic  model.Topic
+	exists bool
+}
+
+func (this *Controller) getTopicWithCache(ctx context.Context, topics topicCache, topicId string) (topic model.Topic, exists bool, err error) {
+	if cached, ok := topics[topicId]; ok {
+		return cached.topic, cached.exists, nil
+	}
+	topic, exists, err = this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	if err != nil {
+		return topic, exists, err
+	}
+	if topics != nil {
+		topics[topicId] = cachedTopic{topic: topic, exists: exists}
+	}
+	return topic, exists, nil
+}
+
+func isDeniedByTopicDefaults(token jwt.Token, topic model.Topic, permissions model.PermissionList) bool {
+	return isDeniedByTopicDefaultsWithMode(token, topic, model.PermissionModeAll, permissions)
+}
+
+// isDeniedByTopicDefaultsWithMode returns true if the topic default denies leave no way to match the permissions in the mode
+// (mode all: at least one permission is denied; mode any: all permissions are denied)
+func isDeniedByTopicDefaultsWithMode(token jwt.Token, topic model.Topic, mode model.PermissionMode, permissions model.PermissionList) bool {
+	if token.IsAdmin() || len(permissions) == 0 {
+		return false
+	}
+	return !mode.Matches(permissions, func(permission model.Permission) bool {
+		return !topic.DefaultPermissions.IsDenied(token.GetUserId(), token.GetRoles(), token.GetGroups(), permission)
+	})
 }
 
 func (this *Controller) checkTopicDefaultPermission(token jwt.Token, topic model.Topic, permissions model.PermissionList) (access bool, err error) {
+	return this.checkTopicDefaultPermissionWithMode(token, topic, model.PermissionModeAll, permissions)
+}
+
+func (this *Controller) checkTopicDefaultPermissionWithMode(token jwt.Token, topic model.Topic, mode model.PermissionMode, permissions model.PermissionList) (access bool, err error) {
 	access = token.IsAdmin()
 	if access {
 		return true, nil
@@ -280,69 +397,7 @@ func (this *Controller) checkTopicDefaultPermission(token jwt.Token, topic model
 	user := token.GetUserId()
 	groups := token.GetGroups()
 	roles := token.GetRoles()
-	for _, permission := range permissions {
-		switch permission {
-		case model.Read:
-			if topic.DefaultPermissions.UserPermissions[user].Read {
-				continue
-			}
-			if slices.ContainsFunc(roles, func(role string) bool {
-				return topic.DefaultPermissions.RolePermissions[role].Read
-			}) {
-				continue
-			}
-			if slices.ContainsFunc(groups, func(group string) bool {
-				return topic.DefaultPermissions.GroupPermissions[group].Read
-			}) {
-				continue
-			}
-			return false, nil
-		case model.Administrate:
-			if topic.DefaultPermissions.UserPermissions[user].Administrate {
-				continue
-			}
-			if slices.ContainsFunc(roles, func(role string) bool {
-				return topic.DefaultPermissions.RolePermissions[role].Administrate
-			}) {
-				continue
-			}
-			if slices.ContainsFunc(groups, func(group string) bool {
-				return topic.DefaultPermissions.GroupPermissions[group].Administrate
-			}) {
-				continue
-			}
-			return false, nil
-		case model.Write:
-			if topic.DefaultPermissions.UserPermissions[user].Write {
-				continue
-			}
-			if slices.ContainsFunc(roles, func(role string) bool {
-				return topic.DefaultPermissions.RolePermissions[role].Write
-			}) {
-				continue
-			}
-			if slices.ContainsFunc(groups, func(group string) bool {
-				return topic.DefaultPermissions.GroupPermissions[group].Write
-			}) {
-				continue
-			}
-			return false, nil
-		case model.Execute:
-			if topic.DefaultPermissions.UserPermissions[user].Execute {
-				continue
-			}
-			if slices.ContainsFunc(roles, func(role string) bool {
-				return topic.DefaultPermissions.RolePermissions[role].Execute
-			}) {
-				continue
-			}
-			if slices.ContainsFunc(groups, func(group string) bool {
-				return topic.DefaultPermissions.GroupPermissions[group].Execute
-			}) {
-				continue
-			}
-			return false, nil
-		}
-	}
-	return true, nil
+	return mode.Matches(permissions, func(permission model.Permission) bool {
+		return topic.DefaultPermissions.IsGranted(user, roles, groups, permission) && !topic.DefaultPermissions.IsDenied(user, roles, groups, permission)
+	}), nil
 }
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+// topicCountingMock counts the GetTopic calls
+type topicCountingMock struct {
+	*mock.Mock
+	getTopicCalls atomic.Int64
+}
+
+func (this *topicCountingMock) GetTopic(ctx context.Context, id string) (result model.Topic, exists bool, err error) {
+	this.getTopicCalls.Add(1)
+	return this.Mock.GetTopic(ctx, id)
+}
+
+func TestCheckPermissionsBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	db := &topicCountingMock{Mock: mock.New()}
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, db, &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("init", func(t *testing.T) {
+		for _, topic := range []string{"devices", "locations"} {
+			_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: topic})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		set := func(topic string, id string, permissions model.PermissionsMap) {
+			_, err, _ = ctrl.SetPermission(TestAdminToken, topic, id, model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"owner":       {Read: true, Write: true, Execute: true, Administrate: true},
+					TestTokenUser: permissions,
+				},
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		set("devices", "d1", model.PermissionsMap{Read: true, Execute: true})
+		set("devices", "d2", model.PermissionsMap{Read: true})
+		set("locations", "l1", model.PermissionsMap{Read: true})
+		set("locations", "l2", model.PermissionsMap{})
+	})
+
+	t.Run("check", func(t *testing.T) {
+		db.getTopicCalls.Store(0)
+		result, err, code := ctrl.CheckPermissionsBatch(TestToken, []model.CheckQuery{
+			{TopicId: "devices", Ids: []string{"d1", "d2$modifier"}},
+			{TopicId: "locations", Ids: []string{"l1", "l2", "unknown"}, Permissions: "r"},
+			{TopicId: "devices", Ids: []string{"d1", "d2"}, Permissions: "x"},
+		})
+		if err != nil || code != http.StatusOK {
+			t.Error(err, code)
+			return
+		}
+		expected := map[string]map[string]bool{
+			"devices":   {"d1": true, "d2$modifier": true, "d2": false},
+			"locations": {"l1": true, "l2": false},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+		if calls := db.getTopicCalls.Load(); calls != 2 {
+			t.Errorf("expected each topic to be read once, got %v calls", calls)
+		}
+	})
+
+	t.Run("unknown topic", func(t *testing.T) {
+		_, _, code := ctrl.CheckPermissionsBatch(TestToken, []model.CheckQuery{
+			{TopicId: "devices", Ids: []string{"d1"}},
+			{TopicId: "unknown", Ids: []string{"x"}},
+		})
+		if code != http.StatusNotFound {
+			t.Errorf("expected not found, got %v", code)
+		}
+	})
+
+	t.Run("invalid permissions", func(t *testing.T) {
+		_, _, code := ctrl.CheckPermissionsBatch(TestToken, []model.CheckQuery{{TopicId: "devices", Ids: []string{"d1"}, Permissions: "r?"}})
+		if code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v", code)
+		}
+	})
+}
@@ -24,10 +24,13 @@ import (
 	"time"
 
 	"github.com/SENERGY-Platform/developer-notifications/pkg/client"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/auth"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/kafka"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/database"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+	"github.com/google/uuid"
 )
 
 type Controller struct {
@@ -37,6 +40,13 @@ type Controller struct {
 	producerMux      sync.Mutex
 	producer         map[string]kafka.Producer
 	producerProvider kafka.Provider
+	outboxRelayId    string
+	outboxRelayMux   sync.Mutex
+	outboxTrigger    chan struct{}
+	changes          *changeFeed
+	webhookMux       sync.Mutex
+	webhookTrigger   chan struct{}
+	verifier         *auth.Verifier
 }
 
 type DB = database.Database
@@ -54,17 +64,29 @@ func NewWithDependencies(ctx context.Context, config configuration.Config, db DB
 	if producerProvider == nil {
 		producerProvider = kafka.NewKafkaProducerProvider()
 	}
-	result := &Controller{config: config, db: db, producer: map[string]kafka.Producer{}, producerProvider: producerProvider}
+	verifier, err := auth.New(config)
+	if err != nil {
+		return nil, err
+	}
+	result := &Controller{
+		config:           config,
+		db:               db,
+		producer:         map[string]kafka.Producer{},
+		producerProvider: producerProvider,
+		outboxRelayId:    uuid.NewString(),
+		outboxTrigger:    make(chan struct{}, 1),
+		changes:          newChangeFeed(config.ChangeFeedBufferSize),
+		webhookTrigger:   make(chan struct{}, 1),
+		verifier:         verifier,
+	}
 	if config.DevNotifierUrl != "" {
 		result.notifier = client.New(config.DevNotifierUrl)
 	} else {
 		result.notifier = LogNotifier{log: config.GetLogger()}
 	}
-	err := result.RetryPublishOfUnsyncedResourcesContext(ctx)
-	if err != nil {
-		return nil, err
-	}
-	result.StartSyncLoop(ctx)
+	result.StartOutboxRelay(ctx)
+	result.StartExpirySweeper(ctx)
+	result.StartWebhookDispatcher(ctx)
 	return result, nil
 }
 
@@ -77,6 +99,11 @@ func (this *Controller) getTimeoutContext(parent ...context.Context) context.Con
 	return ctx
 }
 
+// parseToken verifies the token, if a jwks url is configured (see auth.Verifier)
+func (this *Controller) parseToken(ctx context.Context, tokenStr string) (jwt.Token, error) {
+	return this.verifier.Parse(ctx, tokenStr)
+}
+
 func (this *Controller) notifyError(info error) {
 	err := this.notifier.SendMessage(client.Message{
 		Sender: "github.com/SENERGY-Platform/permissions-v2",
@@ -89,15 +116,30 @@ func (this *Controller) notifyError(info error) {
 	}
 }
 
-func (this *Controller) publishPermission(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions) error {
-	if topic.PublishToKafkaTopic == "" || topic.PublishToKafkaTopic == "-" {
+func (this *Controller) publishPermission(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) error {
+	if !topic.PublishesToKafka() {
 		return nil
 	}
 	producer, err := this.getProducer(topic)
 	if err != nil {
 		return err
 	}
-	err = producer.SendPermissions(this.getTimeoutContext(ctx), topic, id, permissions)
+	err = producer.SendPermissions(this.getTimeoutContext(ctx), topic, id, permissions, owner)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *Controller) publishDeletion(ctx context.Context, topic model.Topic, id string) error {
+	if !topic.PublishesToKafka() {
+		return nil
+	}
+	producer, err := this.getProducer(topic)
+	if err != nil {
+		return err
+	}
+	err = producer.SendDelete(this.getTimeoutContext(ctx), topic, id)
 	if err != nil {
 		return err
 	}
@@ -121,54 +163,3 @@ func (this *Controller) getProducer(topic model.Topic) (producer kafka.Producer,
 	this.producer[topic.PublishToKafkaTopic] = producer
 	return producer, nil
 }
-
-func (this *Controller) RetryPublishOfUnsyncedResources() error {
-	return this.RetryPublishOfUnsyncedResourcesContext(context.TODO())
-}
-
-func (this *Controller) RetryPublishOfUnsyncedResourcesContext(ctx context.Context) error {
-	list, err := this.db.ListUnsyncedResources(this.getTimeoutContext(ctx))
-	if err != nil {
-		return err
-	}
-	for _, e := range list {
-		this.config.GetLogger().InfoContext(ctx, "retry to publish resource to kafka", "topicId", e.TopicId, "id", e.Id)
-		topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), e.TopicId)
-		if err != nil {
-			this.config.GetLogger().WarnContext(ctx, "RetryPublishOfUnsyncedResources: unable to get topic", "topicId", e.TopicId, "error", err)
-			continue
-		}
-		if !exists {
-			continue
-		}
-		err = this.publishPermission(ctx, topic, e.Id, e.ResourcePermissions)
-		if err != nil {
-			this.config.GetLogger().WarnContext(ctx, "RetryPublishOfUnsyncedResources: unable to publishPermission()", "topicId", e.TopicId, "id", e.Id, "error", err)
-			continue
-		}
-		err = this.db.MarkResourceAsSynced(this.getTimeoutContext(ctx), topic.Id, e.Id)
-		if err != nil {
-			this.config.GetLogger().WarnContext(ctx, "RetryPublishOfUnsyncedResources: unable to mark resource as synced", "topicId", e.TopicId, "id", e.Id, "error", err)
-		}
-	}
-	return nil
-}
-
-func (this *Controller) StartSyncLoop(ctx context.Context) {
-	dur := this.config.SyncCheckInterval.GetDuration()
-	if dur == 0 {
-		return
-	}
-	ticker := time.NewTicker(dur)
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				this.config.GetLogger().InfoContext(ctx, fmt.Sprint("refresh unsynced resources:", this.RetryPublishOfUnsyncedResourcesContext(ctx)))
-			case <-ctx.Done():
-				ticker.Stop()
-				return
-			}
-		}
-	}()
-}
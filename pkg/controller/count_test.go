@@ -0,0 +1,104 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestCountResources(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	set := func(topic string, id string, permissions model.PermissionsMap) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, topic, id, model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: permissions},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "count"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "count-defaults", DefaultPermissions: model.ResourcePermissions{
+		RolePermissions: map[string]model.PermissionsMap{"user": {Read: true}},
+	}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	set("count", "1", model.PermissionsMap{Read: true})
+	set("count", "2", model.PermissionsMap{Read: true, Administrate: true})
+	set("count", "3", model.PermissionsMap{Execute: true})
+	set("count", "4", model.PermissionsMap{})
+	set("count-defaults", "1", model.PermissionsMap{})
+	set("count-defaults", "2", model.PermissionsMap{})
+
+	check := func(t *testing.T, expected int64, count int64, err error) {
+		t.Helper()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != expected {
+			t.Errorf("expected %v, got %v", expected, count)
+		}
+	}
+
+	t.Run("admin", func(t *testing.T) {
+		count, err, _ := ctrl.AdminCountResourceIds(TestAdminToken, "count", model.ListOptions{})
+		check(t, 4, count, err)
+		_, err, _ = ctrl.AdminCountResourceIds(TestToken, "count", model.ListOptions{})
+		if err == nil {
+			t.Error("expected error for non admin user")
+		}
+	})
+
+	t.Run("accessible", func(t *testing.T) {
+		count, err, _ := ctrl.CountAccessibleResourceIds(TestToken, "count", model.ListOptions{}, model.PermissionModeAll, model.Read)
+		check(t, 2, count, err)
+		count, err, _ = ctrl.CountAccessibleResourceIds(TestToken, "count", model.ListOptions{}, model.PermissionModeAny, model.Read, model.Execute)
+		check(t, 3, count, err)
+		count, err, _ = ctrl.CountAccessibleResourceIds(TestToken, "count-defaults", model.ListOptions{}, model.PermissionModeAll, model.Read)
+		check(t, 2, count, err)
+		count, err, _ = ctrl.CountAccessibleResourceIds(TestToken, "count-defaults", model.ListOptions{}, model.PermissionModeAll, model.Write)
+		check(t, 0, count, err)
+	})
+
+	t.Run("admin permission", func(t *testing.T) {
+		count, err, _ := ctrl.CountResourcesWithAdminPermission(TestToken, "count", model.ListOptions{})
+		check(t, 1, count, err)
+		list, err, _ := ctrl.ListResourcesWithAdminPermission(TestToken, "count", model.ListOptions{})
+		check(t, int64(len(list)), count, err)
+	})
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestCreationPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}}
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	//TestToken has the role "user"
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{
+		Id:                  "dashboards",
+		PublishToKafkaTopic: "dashboards",
+		CreationPolicy: &model.ResourceCreationPolicy{
+			Roles: []string{"user"},
+			InitialPermissions: model.ResourcePermissions{
+				RolePermissions: map[string]model.PermissionsMap{"admin": {Read: true, Write: true, Execute: true, Administrate: true}},
+				UserPermissions: map[string]model.PermissionsMap{"support": {Read: true}},
+			},
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "devices", CreationPolicy: &model.ResourceCreationPolicy{Roles: []string{"developer"}}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("create", func(t *testing.T) {
+		result, err, _ := ctrl.SetPermission(TestToken, "dashboards", "d1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"support": {Read: true, Write: true}},
+			UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result.UserPermissions[TestTokenUser], model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}) {
+			t.Errorf("creator should be admin: %#v", result)
+		}
+		if _, denied := result.UserDenies[TestTokenUser]; denied {
+			t.Errorf("creator should not be denied: %#v", result)
+		}
+		if !result.UserPermissions["support"].Write {
+			t.Errorf("entries of the creator should override initial permissions: %#v", result)
+		}
+		if !result.RolePermissions["admin"].Administrate {
+			t.Errorf("initial permissions should be added: %#v", result)
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "dashboards", "d1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.Creator != TestTokenUser {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("update keeps creator", func(t *testing.T) {
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "dashboards", "d1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource.UserPermissions["other"] = model.PermissionsMap{Read: true}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "dashboards", "d1", resource.ResourcePermissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource, err, _ = ctrl.GetResource(TestAdminToken, "dashboards", "d1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.Creator != TestTokenUser || resource.Version != 2 {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("published owner", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if owner := producer.GetOwner("dashboards", "d1"); owner != TestTokenUser {
+			t.Errorf("%#v", owner)
+		}
+	})
+
+	t.Run("existing resources are not taken over", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermission(TestAdminToken, "dashboards", "d2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"other": {Read: true, Write: true, Execute: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.SetPermission(TestToken, "dashboards", "d2", model.ResourcePermissions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "dashboards", "d2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//admins are recorded as creator of the resources they create
+		if resource.Creator == TestTokenUser || resource.Creator == "" {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("not allowed by policy", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestToken, "devices", "d1", model.ResourcePermissions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err = ctrl.db.GetResource(ctx, "devices", "d1", model.GetOptions{})
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("bulk", func(t *testing.T) {
+		results, err, _ := ctrl.SetPermissionsBulk(TestToken, "dashboards", []model.BulkPermissionsItem{
+			{Id: "d3", Permissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{"support": {Read: true, Write: true}}}},
+			{Id: "d2", Permissions: model.ResourcePermissions{}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(results) != 2 || results[0].Code != http.StatusOK || results[0].Version != 1 || results[1].Code != http.StatusForbidden {
+			t.Errorf("%#v", results)
+			return
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "dashboards", "d3")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.Creator != TestTokenUser || !resource.UserPermissions[TestTokenUser].Administrate || !resource.UserPermissions["support"].Write || !resource.RolePermissions["admin"].Administrate {
+			t.Errorf("%#v", resource)
+		}
+		results, err, _ = ctrl.SetPermissionsBulk(TestToken, "devices", []model.BulkPermissionsItem{{Id: "d3", Permissions: model.ResourcePermissions{}}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(results) != 1 || results[0].Code != http.StatusForbidden {
+			t.Errorf("%#v", results)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err, code := ctrl.SetTopic(TestAdminToken, model.Topic{Id: "invalid", CreationPolicy: &model.ResourceCreationPolicy{
+			Roles:              []string{"user"},
+			InitialPermissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{"support": {Custom: map[string]bool{"s": true}}}},
+		}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+// authenticate parses the token of a user or the key of a service credential (see model.ServiceCredentialAuthPrefix).
+// service credentials need the scope and access to all topicIds; an empty scope rejects service credentials.
+// the token of a service credential has the admin role and the user id model.ServiceCredentialUserPrefix + credential id.
+func (this *Controller) authenticate(ctx context.Context, tokenStr string, scope model.ServiceCredentialScope, topicIds ...string) (token jwt.Token, err error, code int) {
+	if !model.IsServiceCredentialAuth(tokenStr) {
+		token, err = this.parseToken(ctx, tokenStr)
+		if err != nil {
+			return token, err, http.StatusUnauthorized
+		}
+		return token, nil, http.StatusOK
+	}
+	id, key, err := model.ParseServiceCredentialAuth(tokenStr)
+	if err != nil {
+		return token, err, http.StatusUnauthorized
+	}
+	credential, err := this.db.GetServiceCredential(this.getTimeoutContext(ctx), id)
+	if errors.Is(err, model.ErrNotFound) {
+		return token, errors.New("invalid service credential"), http.StatusUnauthorized
+	}
+	if err != nil {
+		return token, err, http.StatusInternalServerError
+	}
+	if subtle.ConstantTimeCompare([]byte(credential.KeyHash), []byte(model.HashServiceCredentialKey(key))) != 1 {
+		return token, errors.New("invalid service credential"), http.StatusUnauthorized
+	}
+	if credential.Disabled {
+		return token, fmt.Errorf("service credential '%v' is disabled", credential.Id), http.StatusUnauthorized
+	}
+	if scope == "" {
+		return token, fmt.Errorf("service credential '%v' may not be used for this operation", credential.Id), http.StatusForbidden
+	}
+	if !credential.Allows(scope, topicIds...) {
+		return token, fmt.Errorf("service credential '%v' has no %v access to the topic", credential.Id, scope), http.StatusForbidden
+	}
+	return jwt.Token{
+		Token:       tokenStr,
+		Sub:         model.ServiceCredentialUserPrefix + credential.Id,
+		Username:    credential.Id,
+		RealmAccess: map[string][]string{"roles": {"admin"}},
+	}, nil, http.StatusOK
+}
+
+func (this *Controller) checkCredentialAdmin(ctx context.Context, tokenStr string) (err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, "")
+	if err != nil {
+		return err, code
+	}
+	if !token.IsAdmin() {
+		return errors.New("only admins may manage service credentials"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
+
+func (this *Controller) ListServiceCredentials(tokenStr string) (result []model.ServiceCredential, err error, code int) {
+	return this.ListServiceCredentialsContext(context.TODO(), tokenStr)
+}
+
+func (this *Controller) ListServiceCredentialsContext(ctx context.Context, tokenStr string) (result []model.ServiceCredential, err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.ListServiceCredentials(this.getTimeoutContext(ctx))
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	for i := range result {
+		result[i].KeyHash = ""
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) GetServiceCredential(tokenStr string, id string) (result model.ServiceCredential, err error, code int) {
+	return this.GetServiceCredentialContext(context.TODO(), tokenStr, id)
+}
+
+func (this *Controller) GetServiceCredentialContext(ctx context.Context, tokenStr string, id string) (result model.ServiceCredential, err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.GetServiceCredential(this.getTimeoutContext(ctx), id)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	result.KeyHash = ""
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) CreateServiceCredential(tokenStr string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.CreateServiceCredentialContext(context.TODO(), tokenStr, credential)
+}
+
+// CreateServiceCredentialContext stores a new credential and returns it with its key; the key can not be read later
+func (this *Controller) CreateServiceCredentialContext(ctx context.Context, tokenStr string, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	err = credential.Validate()
+	if err != nil {
+		return result, fmt.Errorf("invalid service credential: %w", err), http.StatusBadRequest
+	}
+	_, err = this.db.GetServiceCredential(this.getTimeoutContext(ctx), credential.Id)
+	if err == nil {
+		return result, fmt.Errorf("service credential '%v' already exists", credential.Id), http.StatusConflict
+	}
+	if !errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusInternalServerError
+	}
+	credential.CreatedAt = time.Now().UnixMilli()
+	return this.storeServiceCredentialWithNewKey(ctx, credential)
+}
+
+func (this *Controller) UpdateServiceCredential(tokenStr string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	return this.UpdateServiceCredentialContext(context.TODO(), tokenStr, credential)
+}
+
+// UpdateServiceCredentialContext updates description, topics, scopes and the disabled flag; the key stays valid
+func (this *Controller) UpdateServiceCredentialContext(ctx context.Context, tokenStr string, credential model.ServiceCredential) (result model.ServiceCredential, err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	err = credential.Validate()
+	if err != nil {
+		return result, fmt.Errorf("invalid service credential: %w", err), http.StatusBadRequest
+	}
+	existing, err := this.db.GetServiceCredential(this.getTimeoutContext(ctx), credential.Id)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	credential.CreatedAt = existing.CreatedAt
+	credential.KeyHash = existing.KeyHash
+	err = this.db.SetServiceCredential(this.getTimeoutContext(ctx), credential)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	credential.KeyHash = ""
+	return credential, nil, http.StatusOK
+}
+
+func (this *Controller) RenewServiceCredentialKey(tokenStr string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	return this.RenewServiceCredentialKeyContext(context.TODO(), tokenStr, id)
+}
+
+// RenewServiceCredentialKeyContext replaces the key of the credential; the previous key is invalid immediately
+func (this *Controller) RenewServiceCredentialKeyContext(ctx context.Context, tokenStr string, id string) (result model.ServiceCredentialWithKey, err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	credential, err := this.db.GetServiceCredential(this.getTimeoutContext(ctx), id)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return this.storeServiceCredentialWithNewKey(ctx, credential)
+}
+
+func (this *Controller) RemoveServiceCredential(tokenStr string, id string) (err error, code int) {
+	return this.RemoveServiceCredentialContext(context.TODO(), tokenStr, id)
+}
+
+// RemoveServiceCredentialContext revokes the credential
+func (this *Controller) RemoveServiceCredentialContext(ctx context.Context, tokenStr string, id string) (err error, code int) {
+	err, code = this.checkCredentialAdmin(ctx, tokenStr)
+	if err != nil {
+		return err, code
+	}
+	err = this.db.RemoveServiceCredential(this.getTimeoutContext(ctx), id)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+func (this *Controller) storeServiceCredentialWithNewKey(ctx context.Context, credential model.ServiceCredential) (result model.ServiceCredentialWithKey, err error, code int) {
+	key, err := model.NewServiceCredentialKey(credential.Id)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	credential.KeyHash = model.HashServiceCredentialKey(key)
+	err = this.db.SetServiceCredential(this.getTimeoutContext(ctx), credential)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	credential.KeyHash = ""
+	return model.ServiceCredentialWithKey{ServiceCredential: credential, Key: key}, nil, http.StatusOK
+}
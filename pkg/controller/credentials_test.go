@@ -0,0 +1,271 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestServiceCredentials(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, topic := range []string{"devices", "hubs"} {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: topic})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	owner := model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+
+	var checker, manager, topicAdmin model.ServiceCredentialWithKey
+
+	t.Run("create", func(t *testing.T) {
+		_, err, code := ctrl.CreateServiceCredential(TestToken, model.ServiceCredential{Id: "checker", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "invalid.id", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "unknown-scope", Scopes: []model.ServiceCredentialScope{"foo"}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+		checker, err, _ = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "checker", TopicIds: []string{"devices"}, Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if checker.Key == "" || checker.KeyHash != "" || checker.CreatedAt == 0 {
+			t.Errorf("%#v", checker)
+		}
+		_, err, code = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "checker", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err == nil || code != http.StatusConflict {
+			t.Error(err, code)
+		}
+		manager, err, _ = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "manager", TopicIds: []string{"devices"}, Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeManage}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		topicAdmin, err, _ = ctrl.CreateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "topic-admin", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeTopicAdmin}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err, _ := ctrl.ListServiceCredentials(TestAdminToken)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 3 || list[0].Id != "checker" || list[0].KeyHash != "" {
+			t.Errorf("%#v", list)
+		}
+	})
+
+	checkerToken := func() string { return model.ServiceCredentialAuthPrefix + checker.Key }
+	managerToken := func() string { return model.ServiceCredentialAuthPrefix + manager.Key }
+	topicAdminToken := func() string { return model.ServiceCredentialAuthPrefix + topicAdmin.Key }
+
+	t.Run("manage scope", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermission(managerToken(), "devices", "d1", owner)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.SetPermission(managerToken(), "hubs", "h1", owner)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetTopic(managerToken(), model.Topic{Id: "devices"})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		//manage includes check
+		access, err, _ := ctrl.CheckPermission(managerToken(), "devices", "d1", model.Read)
+		if err != nil || !access {
+			t.Error(access, err)
+		}
+	})
+
+	t.Run("check scope", func(t *testing.T) {
+		access, err, _ := ctrl.CheckPermission(checkerToken(), "devices", "d1", model.Administrate)
+		if err != nil || !access {
+			t.Error(access, err)
+		}
+		ids, err, _ := ctrl.AdminListResourceIds(checkerToken(), "devices", model.ListOptions{})
+		if err != nil || len(ids) != 1 {
+			t.Error(ids, err)
+		}
+		_, err, code := ctrl.CheckPermission(checkerToken(), "hubs", "h1", model.Read)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.CheckPermissionsBatch(checkerToken(), []model.CheckQuery{{TopicId: "devices", Ids: []string{"d1"}}, {TopicId: "hubs", Ids: []string{"h1"}}})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetPermission(checkerToken(), "devices", "d2", owner)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		err, code = ctrl.RemoveResource(checkerToken(), "devices", "d1")
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("topic admin scope", func(t *testing.T) {
+		_, err, _ := ctrl.SetTopic(topicAdminToken(), model.Topic{Id: "hubs", PublishToKafkaTopic: "hubs"})
+		if err != nil {
+			t.Error(err)
+		}
+		topics, err, _ := ctrl.ListTopics(topicAdminToken(), model.ListOptions{})
+		if err != nil || len(topics) != 2 {
+			t.Error(topics, err)
+		}
+		//topic restricted credentials may not use operations without topic
+		_, err, code := ctrl.ListTopics(checkerToken(), model.ListOptions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.GetResource(topicAdminToken(), "devices", "d1")
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("admin operations", func(t *testing.T) {
+		_, err, code := ctrl.ListServiceCredentials(topicAdminToken())
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.ListWebhooks(managerToken())
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.ListAuditEntries(managerToken(), model.AuditListOptions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("audit actor", func(t *testing.T) {
+		entries, err, _ := ctrl.ListAuditEntries(TestAdminToken, model.AuditListOptions{TopicId: "devices", ResourceId: "d1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(entries) != 1 || entries[0].UserId != model.ServiceCredentialUserPrefix+"manager" {
+			t.Errorf("%#v", entries)
+		}
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, token := range []string{
+			model.ServiceCredentialAuthPrefix + "checker.wrong",
+			model.ServiceCredentialAuthPrefix + "unknown.key",
+			model.ServiceCredentialAuthPrefix + "checker",
+			model.ServiceCredentialAuthPrefix,
+		} {
+			_, err, code := ctrl.CheckPermission(token, "devices", "d1", model.Read)
+			if err == nil || code != http.StatusUnauthorized {
+				t.Error(token, err, code)
+			}
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated, err, _ := ctrl.UpdateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "checker", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}, Disabled: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if updated.CreatedAt != checker.CreatedAt {
+			t.Errorf("%#v", updated)
+		}
+		_, err, code := ctrl.CheckPermission(checkerToken(), "devices", "d1", model.Read)
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, _ = ctrl.UpdateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "checker", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//the key stays valid and the topic restriction is removed
+		_, err, _ = ctrl.CheckPermission(checkerToken(), "hubs", "h1", model.Read)
+		if err != nil {
+			t.Error(err)
+		}
+		_, err, code = ctrl.UpdateServiceCredential(TestAdminToken, model.ServiceCredential{Id: "unknown", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}})
+		if err == nil || code != http.StatusNotFound {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("renew key", func(t *testing.T) {
+		previous := checkerToken()
+		checker, err, _ = ctrl.RenewServiceCredentialKey(TestAdminToken, "checker")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.CheckPermission(previous, "devices", "d1", model.Read)
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, _ = ctrl.CheckPermission(checkerToken(), "devices", "d1", model.Read)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err, _ := ctrl.RemoveServiceCredential(TestAdminToken, "checker")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.CheckPermission(checkerToken(), "devices", "d1", model.Read)
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.GetServiceCredential(TestAdminToken, "checker")
+		if err == nil || code != http.StatusNotFound {
+			t.Error(err, code)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestCustomPermissions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}}
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	const share = model.Permission('s')
+	const control = model.Permission('c')
+	const history = model.Permission('h')
+
+	t.Run("invalid topics", func(t *testing.T) {
+		for _, custom := range [][]model.CustomPermission{
+			{{Letter: "r", Description: "collides with read"}},
+			{{Letter: "sh", Description: "more than one letter"}},
+			{{Letter: "1", Description: "no letter"}},
+			{{Letter: "s", Description: "share"}, {Letter: "s", Description: "duplicate"}},
+		} {
+			_, err, code := ctrl.SetTopic(TestAdminToken, model.Topic{Id: "invalid-custom", CustomPermissions: custom})
+			if err == nil || code != http.StatusBadRequest {
+				t.Errorf("expected bad request for %#v, got %v %v", custom, err, code)
+			}
+		}
+		_, err, code := ctrl.SetTopic(TestAdminToken, model.Topic{
+			Id: "invalid-custom",
+			DefaultPermissions: model.ResourcePermissions{
+				RolePermissions: map[string]model.PermissionsMap{"user": {Custom: map[string]bool{"s": true}}},
+			},
+		})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request for undeclared default permission, got %v %v", err, code)
+		}
+	})
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{
+			Id:                  "custom",
+			PublishToKafkaTopic: "custom",
+			CustomPermissions: []model.CustomPermission{
+				{Letter: "s", Description: "share the resource with other users"},
+				{Letter: "c", Description: "control the resource"},
+				{Letter: "h", Description: "read the history of the resource"},
+			},
+			DefaultPermissions: model.ResourcePermissions{
+				RolePermissions: map[string]model.PermissionsMap{"user": {Custom: map[string]bool{"h": true}}},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "custom", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				"owner":       {Read: true, Write: true, Execute: true, Administrate: true},
+				TestTokenUser: {Read: true, Custom: map[string]bool{"s": true, "c": true}},
+			},
+			UserDenies: map[string]model.PermissionsMap{TestTokenUser: {Custom: map[string]bool{"c": true}}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "custom", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("undeclared permission", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestAdminToken, "custom", "r3", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": {Administrate: true, Custom: map[string]bool{"z": true}}},
+		})
+		if !errors.Is(err, model.ErrUnknownPermission) || code != http.StatusBadRequest {
+			t.Errorf("expected unknown permission, got %v %v", err, code)
+		}
+		_, err, code = ctrl.CheckPermission(TestToken, "custom", "r1", 'z')
+		if !errors.Is(err, model.ErrUnknownPermission) || code != http.StatusBadRequest {
+			t.Errorf("expected unknown permission, got %v %v", err, code)
+		}
+		_, err, code = ctrl.ListAccessibleResourceIds(TestToken, "custom", model.ListOptions{}, 'z')
+		if !errors.Is(err, model.ErrUnknownPermission) || code != http.StatusBadRequest {
+			t.Errorf("expected unknown permission, got %v %v", err, code)
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		for _, c := range []struct {
+			permissions model.PermissionList
+			expected    bool
+		}{
+			{permissions: model.PermissionList{model.Read}, expected: true},
+			{permissions: model.PermissionList{share}, expected: true},
+			{permissions: model.PermissionList{model.Read, share}, expected: true},
+			{permissions: model.PermissionList{control}, expected: false},
+			{permissions: model.PermissionList{history}, expected: true},
+			{permissions: model.PermissionList{model.Write, share}, expected: false},
+		} {
+			access, err, _ := ctrl.CheckPermission(TestToken, "custom", "r1", c.permissions...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%v: expected %v, got %v", c.permissions.Encode(), c.expected, access)
+			}
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "custom", model.ListOptions{}, share)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"r1"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err, _ = ctrl.ListAccessibleResourceIds(TestToken, "custom", model.ListOptions{}, control)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("computed", func(t *testing.T) {
+		result, err, _ := ctrl.ListComputedPermissions(TestToken, "custom", []string{"r1", "r2"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.ComputedPermissions{
+			{Id: "r1", PermissionsMap: model.PermissionsMap{Read: true, Custom: map[string]bool{"s": true, "h": true}}},
+			{Id: "r2", PermissionsMap: model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true, Custom: map[string]bool{"h": true}}},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", result, expected)
+		}
+		result, err, _ = ctrl.ListComputedPermissions(TestAdminToken, "custom", []string{"r1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !result[0].Has(share) || !result[0].Has(control) || !result[0].Has(history) {
+			t.Errorf("%#v", result)
+		}
+	})
+
+	t.Run("get and publish", func(t *testing.T) {
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "custom", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !resource.UserPermissions[TestTokenUser].Has(share) || !resource.UserDenies[TestTokenUser].Has(control) {
+			t.Errorf("%#v", resource)
+		}
+
+		ctrl.RelayOutbox()
+		producer.mux.Lock()
+		defer producer.mux.Unlock()
+		published := producer.Produced["custom"]["r1"]
+		if len(published) == 0 {
+			t.Error("expected published permissions")
+			return
+		}
+		if !published[len(published)-1].UserPermissions[TestTokenUser].Has(share) {
+			t.Errorf("%#v", published[len(published)-1])
+		}
+	})
+}
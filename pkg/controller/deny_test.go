@@ -0,0 +1,216 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestDenies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "resource-denies"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{
+			Id: "topic-denies",
+			DefaultPermissions: model.ResourcePermissions{
+				RolePermissions: map[string]model.PermissionsMap{"user": {Read: true}},
+				UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Execute: true}},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "resource-denies", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+			RolePermissions: map[string]model.PermissionsMap{"user": {Read: true, Write: true, Execute: true}},
+			UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Write: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "topic-denies", "t1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("invalid with denied admin", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "resource-denies", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": {Administrate: true}},
+			UserDenies:      map[string]model.PermissionsMap{"owner": {Administrate: true}},
+		})
+		if err == nil {
+			t.Error("expected error")
+			return
+		}
+	})
+
+	t.Run("check resource denies", func(t *testing.T) {
+		for _, c := range []struct {
+			permission model.Permission
+			expected   bool
+		}{
+			{permission: model.Read, expected: true},
+			{permission: model.Write, expected: false},
+			{permission: model.Execute, expected: true},
+		} {
+			access, err, _ := ctrl.CheckPermission(TestToken, "resource-denies", "r1", c.permission)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%v: expected %v, got %v", string(c.permission), c.expected, access)
+			}
+		}
+		access, err, _ := ctrl.CheckPermission(TestAdminToken, "resource-denies", "r1", model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("admins should not be affected by denies")
+		}
+	})
+
+	t.Run("list resource denies", func(t *testing.T) {
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "resource-denies", model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"r1"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err, _ = ctrl.ListAccessibleResourceIds(TestToken, "resource-denies", model.ListOptions{}, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("computed resource denies", func(t *testing.T) {
+		result, err, _ := ctrl.ListComputedPermissions(TestToken, "resource-denies", []string{"r1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.ComputedPermissions{{Id: "r1", PermissionsMap: model.PermissionsMap{Read: true, Execute: true}}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("check topic denies", func(t *testing.T) {
+		access, err, _ := ctrl.CheckPermission(TestToken, "topic-denies", "t1", model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if access {
+			t.Error("topic deny should override resource grant")
+		}
+		access, err, _ = ctrl.CheckPermission(TestToken, "topic-denies", "t1", model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("expected write access")
+		}
+		accessMap, err, _ := ctrl.CheckMultiplePermissions(TestToken, "topic-denies", []string{"t1", "unknown"}, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(accessMap, map[string]bool{"t1": false}) {
+			t.Errorf("%#v", accessMap)
+		}
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "topic-denies", model.ListOptions{}, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("computed topic denies", func(t *testing.T) {
+		result, err, _ := ctrl.ListComputedPermissions(TestToken, "topic-denies", []string{"t1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.ComputedPermissions{{Id: "t1", PermissionsMap: model.PermissionsMap{Read: true, Write: true, Administrate: true}}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("denies are kept on updates without denies", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "resource-denies", "r3", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Administrate: true}},
+			UserDenies:      map[string]model.PermissionsMap{"other": {Read: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestToken, "resource-denies", "r3", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "resource-denies", "r3")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.UserDenies, map[string]model.PermissionsMap{"other": {Read: true}}) {
+			t.Errorf("%#v", resource.UserDenies)
+		}
+	})
+}
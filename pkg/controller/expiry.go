@@ -0,0 +1,102 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+const expirySweepBatchSize = 100
+
+// StartExpirySweeper removes expired time-bound permissions every config.ExpirySweepInterval
+func (this *Controller) StartExpirySweeper(ctx context.Context) {
+	dur := this.config.ExpirySweepInterval.GetDuration()
+	if dur <= 0 {
+		return
+	}
+	ticker := time.NewTicker(dur)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := this.SweepExpiredPermissionsContext(ctx)
+				if err != nil {
+					this.config.GetLogger().WarnContext(ctx, "unable to sweep expired permissions", "error", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (this *Controller) SweepExpiredPermissions() error {
+	return this.SweepExpiredPermissionsContext(context.TODO())
+}
+
+// SweepExpiredPermissionsContext removes expired time-bound entries and drops the valid_from limit of entries that became active.
+// updated resources are stored like any other permissions change, which publishes the new rights to kafka.
+func (this *Controller) SweepExpiredPermissionsContext(ctx context.Context) error {
+	now := time.Now().Truncate(time.Millisecond)
+	topics := map[string]model.Topic{}
+	//the result set shrinks with each update, so the next batch is listed after the last listed resource instead of by offset;
+	//resources with only inherited entries due (which change with the update of their parent) are skipped
+	after := model.ResourceReference{}
+	for {
+		resources, err := this.db.ListResourcesWithDueValidityChange(this.getTimeoutContext(ctx), now, after, expirySweepBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(resources) == 0 {
+			return nil
+		}
+		last := resources[len(resources)-1]
+		after = model.ResourceReference{TopicId: last.TopicId, Id: last.Id}
+		for _, resource := range resources {
+			permissions, changed := resource.RemoveExpired(now)
+			if !changed {
+				continue
+			}
+			topic, ok := topics[resource.TopicId]
+			if !ok {
+				var exists bool
+				topic, exists, err = this.db.GetTopic(this.getTimeoutContext(ctx), resource.TopicId)
+				if err != nil {
+					return err
+				}
+				if !exists {
+					topic = model.Topic{Id: resource.TopicId}
+				}
+				topics[resource.TopicId] = topic
+			}
+			resource.ResourcePermissions = permissions
+			//a concurrent change of the resource is not overwritten; the resource is handled by the next sweep, if still due
+			_, err = this.setPermission(ctx, topic, resource, "", model.AuditOperationExpirySweep, resource.Version)
+			if errors.Is(err, model.ErrVersionMismatch) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
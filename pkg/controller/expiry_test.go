@@ -0,0 +1,189 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestTimeBoundPermissions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{
+		Produced: map[string]map[string][]model.ResourcePermissions{},
+	}
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Millisecond).UTC()
+	future := time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC()
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+
+	resources := map[string]model.ResourcePermissions{
+		"expired": {UserPermissions: map[string]model.PermissionsMap{
+			"owner":       owner,
+			TestTokenUser: {Read: true, Write: true, ValidUntil: &past},
+		}},
+		"pending": {UserPermissions: map[string]model.PermissionsMap{
+			"owner":       owner,
+			TestTokenUser: {Read: true, Write: true, ValidFrom: &future},
+		}},
+		"active": {UserPermissions: map[string]model.PermissionsMap{
+			"owner":       owner,
+			TestTokenUser: {Read: true, Write: true, ValidFrom: &past, ValidUntil: &future},
+		}},
+		"role": {
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner},
+			RolePermissions: map[string]model.PermissionsMap{"user": {Read: true, ValidUntil: &future}},
+		},
+		"expired-deny": {
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true}},
+			UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Read: true, ValidUntil: &past}},
+		},
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "contracts", PublishToKafkaTopic: "contracts"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for id, permissions := range resources {
+			_, err, _ = ctrl.SetPermission(TestAdminToken, "contracts", id, permissions)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("expiring admin is invalid", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "contracts", "invalid", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": {Administrate: true, ValidUntil: &future}},
+		})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		accessMap, err, _ := ctrl.CheckMultiplePermissions(TestToken, "contracts", []string{"expired", "pending", "active", "role", "expired-deny"}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]bool{"expired": false, "pending": false, "active": true, "role": true, "expired-deny": true}
+		if !reflect.DeepEqual(accessMap, expected) {
+			t.Errorf("\n%#v\n%#v", expected, accessMap)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "contracts", model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []string{"active", "expired-deny", "role"}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("\n%#v\n%#v", expected, ids)
+		}
+	})
+
+	t.Run("computed", func(t *testing.T) {
+		result, err, _ := ctrl.ListComputedPermissions(TestToken, "contracts", []string{"expired", "pending", "active"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.ComputedPermissions{
+			{Id: "active", PermissionsMap: model.PermissionsMap{Read: true, Write: true}},
+			{Id: "expired", PermissionsMap: model.PermissionsMap{}},
+			{Id: "pending", PermissionsMap: model.PermissionsMap{}},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("sweep", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = ctrl.SweepExpiredPermissions()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]model.ResourcePermissions{
+			"expired": {UserPermissions: map[string]model.PermissionsMap{"owner": owner}},
+			"pending": resources["pending"],
+			"active": {UserPermissions: map[string]model.PermissionsMap{
+				"owner":       owner,
+				TestTokenUser: {Read: true, Write: true, ValidUntil: &future},
+			}},
+			"role": resources["role"],
+			"expired-deny": {
+				UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true}},
+				UserDenies:      map[string]model.PermissionsMap{},
+			},
+		}
+		for id, e := range expected {
+			resource, err, _ := ctrl.GetResource(TestAdminToken, "contracts", id)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(resource.UserPermissions, e.UserPermissions) || len(resource.UserDenies) != len(e.UserDenies) {
+				t.Errorf("%v:\n%#v\n%#v", id, e, resource.ResourcePermissions)
+			}
+		}
+	})
+
+	t.Run("publish sweep result", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		producer.mux.Lock()
+		defer producer.mux.Unlock()
+		for id, count := range map[string]int{"expired": 2, "pending": 1, "active": 2, "role": 1, "expired-deny": 2} {
+			if len(producer.Produced["contracts"][id]) != count {
+				t.Errorf("%v: %#v", id, producer.Produced["contracts"][id])
+			}
+		}
+		published := producer.Produced["contracts"]["expired"]
+		if _, ok := published[len(published)-1].UserPermissions[TestTokenUser]; ok {
+			t.Errorf("%#v", published)
+		}
+	})
+}
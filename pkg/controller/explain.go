@@ -0,0 +1,177 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func (this *Controller) ExplainPermission(tokenStr string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int) {
+	return this.ExplainPermissionContext(context.TODO(), tokenStr, topicId, id, onBehalf, permissions...)
+}
+
+// ExplainPermissionContext returns the decision of CheckPermission and the rules, that contributed to it, for each permission.
+// admins may explain the decision for another user, role and group set (onBehalf != nil).
+func (this *Controller) ExplainPermissionContext(ctx context.Context, tokenStr string, topicId string, id string, onBehalf *model.ExplainSubject, permissions ...model.Permission) (result model.Explanation, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	subject := model.ExplainSubject{
+		UserId:   token.GetUserId(),
+		RoleIds:  token.GetRoles(),
+		GroupIds: token.GetGroups(),
+	}
+	if onBehalf != nil {
+		if !token.IsAdmin() {
+			return result, errors.New("only admins may explain permissions on behalf of other users"), http.StatusForbidden
+		}
+		subject = *onBehalf
+		token = jwt.Token{Sub: subject.UserId, RealmAccess: map[string][]string{"roles": subject.RoleIds}, Groups: subject.GroupIds}
+	}
+	if subject.RoleIds == nil {
+		subject.RoleIds = []string{}
+	}
+	if subject.GroupIds == nil {
+		subject.GroupIds = []string{}
+	}
+
+	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if !exists {
+		return result, errors.New("unknown topic"), http.StatusNotFound
+	}
+	err = topic.ValidatePermissions(permissions)
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
+
+	pureId, _ := idmodifier.SplitModifier(id)
+	resource, err := this.db.GetResource(this.getTimeoutContext(ctx), topicId, pureId, model.GetOptions{})
+	if errors.Is(err, model.ErrNotFound) {
+		//unknown resources are explained by the topic defaults only
+		resource, err = model.Resource{Id: pureId, TopicId: topicId}, nil
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+
+	//the decision is made by the same check as CheckPermission; the rules of each permission only explain it
+	access, err, code := this.checkPermission(token, ctx, topicId, id, permissions...)
+	if err != nil {
+		return result, err, code
+	}
+	result = model.Explanation{
+		TopicId:     topicId,
+		Id:          id,
+		Subject:     subject,
+		Access:      access,
+		Permissions: []model.PermissionExplanation{},
+	}
+	now := time.Now()
+	for _, permission := range permissions {
+		result.Permissions = append(result.Permissions, explainPermission(topic, resource, subject, token.IsAdmin(), permission, now))
+	}
+	return result, nil, http.StatusOK
+}
+
+// explainPermission mirrors the decision of checkPermission for the single permission:
+// admins are always granted; topic default denies override every grant; topic default grants override resource denies;
+// resource grants are overridden by resource denies.
+// topic default grants only override resource denies, if they grant all checked permissions;
+// the combined decision is therefore not the conjunction of the single decisions (see model.Explanation.Access).
+func explainPermission(topic model.Topic, resource model.Resource, subject model.ExplainSubject, isAdmin bool, permission model.Permission, now time.Time) (result model.PermissionExplanation) {
+	result = model.PermissionExplanation{
+		Permission: string(permission),
+		Rules:      []model.ExplanationRule{},
+	}
+	if isAdmin {
+		result.Rules = append(result.Rules, model.ExplanationRule{Source: model.RuleSourceAdmin, Effect: model.RuleEffectGrant})
+	}
+	topicDenies := matchingRules(topic.DefaultPermissions.UserDenies, topic.DefaultPermissions.GroupDenies, topic.DefaultPermissions.RoleDenies, nil, model.RuleSourceTopicDefault, model.RuleEffectDeny, subject, permission, now)
+	topicGrants := matchingRules(topic.DefaultPermissions.UserPermissions, topic.DefaultPermissions.GroupPermissions, topic.DefaultPermissions.RolePermissions, nil, model.RuleSourceTopicDefault, model.RuleEffectGrant, subject, permission, now)
+
+	effective := resource.Effective()
+	var own *model.ResourcePermissions
+	if resource.EffectivePermissions != nil {
+		own = &resource.ResourcePermissions
+	}
+	var ownDenies, ownGrants []map[string]model.PermissionsMap
+	if own != nil {
+		ownDenies = []map[string]model.PermissionsMap{own.UserDenies, own.GroupDenies, own.RoleDenies}
+		ownGrants = []map[string]model.PermissionsMap{own.UserPermissions, own.GroupPermissions, own.RolePermissions}
+	}
+	resourceDenies := matchingRules(effective.UserDenies, effective.GroupDenies, effective.RoleDenies, ownDenies, model.RuleSourceResource, model.RuleEffectDeny, subject, permission, now)
+	resourceGrants := matchingRules(effective.UserPermissions, effective.GroupPermissions, effective.RolePermissions, ownGrants, model.RuleSourceResource, model.RuleEffectGrant, subject, permission, now)
+
+	result.Rules = append(result.Rules, topicDenies...)
+	result.Rules = append(result.Rules, topicGrants...)
+	result.Rules = append(result.Rules, resourceDenies...)
+	result.Rules = append(result.Rules, resourceGrants...)
+
+	switch {
+	case isAdmin:
+		result.Granted = true
+	case len(topicDenies) > 0:
+		result.Granted = false
+	case len(topicGrants) > 0:
+		result.Granted = true
+	default:
+		result.Granted = len(resourceGrants) > 0 && len(resourceDenies) == 0
+	}
+	return result
+}
+
+// matchingRules returns a rule for each active entry of the subject, which contains the permission.
+// if own is set (user, group and role maps of the resource itself), entries missing in own are marked as inherited.
+func matchingRules(users map[string]model.PermissionsMap, groups map[string]model.PermissionsMap, roles map[string]model.PermissionsMap, own []map[string]model.PermissionsMap, source string, effect string, subject model.ExplainSubject, permission model.Permission, now time.Time) (result []model.ExplanationRule) {
+	check := func(entries map[string]model.PermissionsMap, ownIndex int, kind string, ids []string) {
+		for _, id := range ids {
+			entry, ok := entries[id]
+			if !ok || !entry.Has(permission) || !entry.IsActive(now) {
+				continue
+			}
+			inherited := false
+			if own != nil {
+				_, isOwn := own[ownIndex][id]
+				inherited = !isOwn
+			}
+			result = append(result, model.ExplanationRule{
+				Source:     source,
+				Kind:       kind,
+				Subject:    id,
+				Effect:     effect,
+				Inherited:  inherited,
+				ValidFrom:  entry.ValidFrom,
+				ValidUntil: entry.ValidUntil,
+			})
+		}
+	}
+	check(users, 0, model.RuleKindUser, []string{subject.UserId})
+	check(groups, 1, model.RuleKindGroup, subject.GroupIds)
+	check(roles, 2, model.RuleKindRole, subject.RoleIds)
+	return result
+}
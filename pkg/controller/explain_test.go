@@ -0,0 +1,188 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestExplainPermission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{
+			Id: "explain",
+			DefaultPermissions: model.ResourcePermissions{
+				RolePermissions: map[string]model.PermissionsMap{"user": {Read: true}},
+				GroupDenies:     map[string]model.PermissionsMap{"blocked": {Read: true}},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "explain", "parent", model.ResourcePermissions{
+			UserPermissions:  map[string]model.PermissionsMap{"owner": owner},
+			GroupPermissions: map[string]model.PermissionsMap{"g1": {Execute: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "explain", "child", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Write: true, Execute: true}},
+			UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Execute: true}},
+			Parent:          &model.ResourceReference{TopicId: "explain", Id: "parent"},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("own token", func(t *testing.T) {
+		result, err, _ := ctrl.ExplainPermission(TestToken, "explain", "child", nil, model.Read, model.Write, model.Execute, model.Administrate)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := model.Explanation{
+			TopicId: "explain",
+			Id:      "child",
+			Subject: model.ExplainSubject{UserId: TestTokenUser, RoleIds: []string{"user"}, GroupIds: []string{}},
+			Access:  false,
+			Permissions: []model.PermissionExplanation{
+				{Permission: "r", Granted: true, Rules: []model.ExplanationRule{
+					{Source: model.RuleSourceTopicDefault, Kind: model.RuleKindRole, Subject: "user", Effect: model.RuleEffectGrant},
+				}},
+				{Permission: "w", Granted: true, Rules: []model.ExplanationRule{
+					{Source: model.RuleSourceResource, Kind: model.RuleKindUser, Subject: TestTokenUser, Effect: model.RuleEffectGrant},
+				}},
+				{Permission: "x", Granted: false, Rules: []model.ExplanationRule{
+					{Source: model.RuleSourceResource, Kind: model.RuleKindUser, Subject: TestTokenUser, Effect: model.RuleEffectDeny},
+					{Source: model.RuleSourceResource, Kind: model.RuleKindUser, Subject: TestTokenUser, Effect: model.RuleEffectGrant},
+				}},
+				{Permission: "a", Granted: false, Rules: []model.ExplanationRule{}},
+			},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", result, expected)
+		}
+		for _, p := range result.Permissions {
+			access, err, _ := ctrl.CheckPermission(TestToken, "explain", "child", model.Permission(p.Permission[0]))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != p.Granted {
+				t.Errorf("%v: explanation (%v) differs from check (%v)", p.Permission, p.Granted, access)
+			}
+		}
+	})
+
+	t.Run("mixed topic and resource grants", func(t *testing.T) {
+		//read is granted by the topic defaults and write by the resource; the check needs one source for both
+		result, err, _ := ctrl.ExplainPermission(TestToken, "explain", "child", nil, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		access, err, _ := ctrl.CheckPermission(TestToken, "explain", "child", model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if access || result.Access != access {
+			t.Errorf("explanation (%v) differs from check (%v)", result.Access, access)
+		}
+		if !result.Permissions[0].Granted || !result.Permissions[1].Granted {
+			t.Errorf("%#v", result.Permissions)
+		}
+
+		result, err, _ = ctrl.ExplainPermission(TestAdminToken, "explain", "child", &model.ExplainSubject{UserId: TestTokenUser, RoleIds: []string{"user"}}, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Access {
+			t.Errorf("on behalf explanation differs from check: %#v", result)
+		}
+	})
+
+	t.Run("on behalf", func(t *testing.T) {
+		result, err, _ := ctrl.ExplainPermission(TestAdminToken, "explain", "child", &model.ExplainSubject{UserId: "u2", GroupIds: []string{"g1", "blocked"}, RoleIds: []string{"user"}}, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.PermissionExplanation{
+			{Permission: "r", Granted: false, Rules: []model.ExplanationRule{
+				{Source: model.RuleSourceTopicDefault, Kind: model.RuleKindGroup, Subject: "blocked", Effect: model.RuleEffectDeny},
+				{Source: model.RuleSourceTopicDefault, Kind: model.RuleKindRole, Subject: "user", Effect: model.RuleEffectGrant},
+			}},
+			{Permission: "x", Granted: true, Rules: []model.ExplanationRule{
+				{Source: model.RuleSourceResource, Kind: model.RuleKindGroup, Subject: "g1", Effect: model.RuleEffectGrant, Inherited: true},
+			}},
+		}
+		if !reflect.DeepEqual(result.Permissions, expected) {
+			t.Errorf("\n%#v\n%#v", result.Permissions, expected)
+		}
+
+		result, err, _ = ctrl.ExplainPermission(TestAdminToken, "explain", "child", &model.ExplainSubject{UserId: "u3", RoleIds: []string{"admin"}}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !result.Access || len(result.Permissions) != 1 || result.Permissions[0].Rules[0].Source != model.RuleSourceAdmin {
+			t.Errorf("%#v", result)
+		}
+	})
+
+	t.Run("on behalf as user", func(t *testing.T) {
+		_, err, code := ctrl.ExplainPermission(TestToken, "explain", "child", &model.ExplainSubject{UserId: "owner"}, model.Read)
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		result, err, _ := ctrl.ExplainPermission(TestToken, "explain", "unknown", nil, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Access || !result.Permissions[0].Granted || result.Permissions[1].Granted {
+			t.Errorf("%#v", result)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestResourceHierarchy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{
+		Produced: map[string]map[string][]model.ResourcePermissions{},
+	}
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+
+	t.Run("init", func(t *testing.T) {
+		for _, topic := range []string{"locations", "hubs", "devices"} {
+			_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: topic, PublishToKafkaTopic: topic})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "locations", "l1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "hubs", "h1", model.ResourcePermissions{
+			Parent: &model.ResourceReference{TopicId: "locations", Id: "l1"},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "devices", "d1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {}},
+			Parent:          &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "devices", "d2", model.ResourcePermissions{
+			Parent: &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestAdminToken, "devices", "d4", model.ResourcePermissions{
+			Parent: &model.ResourceReference{TopicId: "hubs", Id: "unknown"},
+		})
+		if !errors.Is(err, model.ErrInvalidParent) || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("import children before parents", func(t *testing.T) {
+		err, _ := ctrl.Import(TestAdminToken, model.ImportExport{Permissions: []model.Resource{
+			{TopicId: "devices", Id: "i2", ResourcePermissions: model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "devices", Id: "i1"}}},
+			{TopicId: "devices", Id: "i1", ResourcePermissions: model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "hubs", Id: "i0"}}},
+			{TopicId: "hubs", Id: "i0", ResourcePermissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{"owner": owner}}},
+		}}, model.ImportExportOptions{IncludePermissions: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, code := ctrl.Import(TestAdminToken, model.ImportExport{Permissions: []model.Resource{
+			{TopicId: "devices", Id: "i3", ResourcePermissions: model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "hubs", Id: "unknown"}}},
+		}}, model.ImportExportOptions{IncludePermissions: true})
+		if !errors.Is(err, model.ErrInvalidParent) || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("no inherited admin", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestAdminToken, "devices", "d4", model.ResourcePermissions{
+			UserDenies: map[string]model.PermissionsMap{"owner": {Administrate: true}},
+			Parent:     &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+		})
+		if !errors.Is(err, model.ErrInvalidParent) || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	checkRead := func(t *testing.T, expected map[string]bool) {
+		accessMap, err, _ := ctrl.CheckMultiplePermissions(TestToken, "devices", []string{"d1", "d2"}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(accessMap, expected) {
+			t.Errorf("\n%#v\n%#v", expected, accessMap)
+		}
+	}
+
+	t.Run("check inherited", func(t *testing.T) {
+		checkRead(t, map[string]bool{"d1": false, "d2": true})
+		access, err, _ := ctrl.CheckPermission(TestToken, "hubs", "h1", model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("expected inherited read access to h1")
+		}
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "devices", model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"d2"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("get resource", func(t *testing.T) {
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "devices", "d2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(resource.UserPermissions) != 0 || resource.EffectivePermissions == nil || !reflect.DeepEqual(resource.EffectivePermissions.UserPermissions, map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true}}) {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("parent change", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "locations", "l1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true, Write: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		access, err, _ := ctrl.CheckPermission(TestToken, "devices", "d2", model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("expected inherited write access to d2")
+		}
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		producer.mux.Lock()
+		defer producer.mux.Unlock()
+		published := producer.Produced["devices"]["d2"]
+		if len(published) == 0 || !published[len(published)-1].UserPermissions[TestTokenUser].Write {
+			t.Errorf("%#v", published)
+		}
+		published = producer.Produced["hubs"]["h1"]
+		if len(published) == 0 || !published[len(published)-1].UserPermissions[TestTokenUser].Write {
+			t.Errorf("%#v", published)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestAdminToken, "locations", "l1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner},
+			Parent:          &model.ResourceReference{TopicId: "devices", Id: "d2"},
+		})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("parent needs admin permission", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "devices", "d3", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.SetPermission(TestToken, "devices", "d3", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner},
+			Parent:          &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+		})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("remove parent", func(t *testing.T) {
+		err, _ = ctrl.RemoveResource(TestAdminToken, "locations", "l1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//h1 is detached from l1 and keeps the inherited entries as own entries
+		checkRead(t, map[string]bool{"d1": false, "d2": true})
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "hubs", "h1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.HasParent() || !reflect.DeepEqual(resource.UserPermissions, map[string]model.PermissionsMap{"owner": owner, TestTokenUser: {Read: true, Write: true}}) {
+			t.Errorf("%#v", resource)
+		}
+		resource, err, _ = ctrl.GetResource(TestAdminToken, "devices", "d2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.Parent, &model.ResourceReference{TopicId: "hubs", Id: "h1"}) || !resource.Effective().HasAdminUser() {
+			t.Errorf("%#v", resource)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/idmodifier"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
+)
+
+func (this *Controller) ListPermissionVersions(tokenStr string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	return this.ListPermissionVersionsContext(context.TODO(), tokenStr, topicId, id, options)
+}
+
+func (this *Controller) ListPermissionVersionsContext(ctx context.Context, tokenStr string, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	pureId, _ := idmodifier.SplitModifier(id)
+	err, code = this.checkHistoryAccess(ctx, token, topicId, pureId)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.ListPermissionsVersions(this.getTimeoutContext(ctx), topicId, pureId, options)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) DiffPermissionVersions(tokenStr string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	return this.DiffPermissionVersionsContext(context.TODO(), tokenStr, topicId, id, fromVersion, toVersion)
+}
+
+func (this *Controller) DiffPermissionVersionsContext(ctx context.Context, tokenStr string, topicId string, id string, fromVersion int64, toVersion int64) (result model.PermissionsDiff, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	pureId, _ := idmodifier.SplitModifier(id)
+	err, code = this.checkHistoryAccess(ctx, token, topicId, pureId)
+	if err != nil {
+		return result, err, code
+	}
+	from, err, code := this.getPermissionsVersion(ctx, topicId, pureId, fromVersion)
+	if err != nil {
+		return result, err, code
+	}
+	to, err, code := this.getPermissionsVersion(ctx, topicId, pureId, toVersion)
+	if err != nil {
+		return result, err, code
+	}
+	result = model.PermissionsDiff{
+		TopicId: topicId,
+		Id:      pureId,
+		From:    fromVersion,
+		To:      toVersion,
+	}
+	result.Changes, result.Parent = from.Permissions.Diff(to.Permissions)
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) RollbackPermissions(tokenStr string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int) {
+	return this.RollbackPermissionsContext(context.TODO(), tokenStr, topicId, id, version)
+}
+
+// RollbackPermissionsContext restores the permissions of a stored version.
+// the rollback is handled like a SetPermission call; it is published to kafka and stored as a new version.
+func (this *Controller) RollbackPermissionsContext(ctx context.Context, tokenStr string, topicId string, id string, version int64) (result model.ResourcePermissions, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeManage, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	pureId, _ := idmodifier.SplitModifier(id)
+	err, code = this.checkHistoryAccess(ctx, token, topicId, pureId)
+	if err != nil {
+		return result, err, code
+	}
+	snapshot, err, code := this.getPermissionsVersion(ctx, topicId, pureId, version)
+	if err != nil {
+		return result, err, code
+	}
+	if snapshot.Deleted {
+		return result, errors.New("version marks the removal of the resource and can not be restored"), http.StatusBadRequest
+	}
+	return this.setPermissionWithToken(ctx, token, topicId, id, snapshot.ForRollback(), model.AuditOperationRollback, model.WriteOptions{})
+}
+
+// checkHistoryAccess allows access to the history of a resource for users with admin permissions on the resource or topic.
+// the history of removed resources is only available by topic default permissions or admin tokens.
+func (this *Controller) checkHistoryAccess(ctx context.Context, token jwt.Token, topicId string, id string) (err error, code int) {
+	access, denied, err, code := this.checkTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
+	if err != nil {
+		return err, code
+	}
+	if denied {
+		return errors.New("access denied"), http.StatusForbidden
+	}
+	if access {
+		return nil, http.StatusOK
+	}
+	access, err = this.db.CheckResourcePermissions(this.getTimeoutContext(ctx), topicId, id, token.GetUserId(), token.GetRoles(), token.GetGroups(), model.Administrate)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if !access {
+		return errors.New("access denied"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
+
+func (this *Controller) getPermissionsVersion(ctx context.Context, topicId string, id string, version int64) (result model.PermissionsVersion, err error, code int) {
+	result, err = this.db.GetPermissionsVersion(this.getTimeoutContext(ctx), topicId, id, version)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, errors.New("unknown version"), http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+// newChange describes a write of the actor for the permission versions, which are recorded by the database in the same transaction.
+// recorded versions are published to the local change feed, unless the feed is read from change streams.
+func (this *Controller) newChange(actor string, operation model.AuditOperation) model.Change {
+	change := model.Change{UserId: actor, Operation: operation}
+	if !this.config.ChangeFeedUseChangeStreams {
+		change.Recorded = func(versions []model.PermissionsVersion) {
+			for _, version := range versions {
+				this.changes.publish(version)
+			}
+		}
+	}
+	return change
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestPermissionHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}}
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	withTeam := model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{
+			TestTokenUser: owner,
+			"teammate":    {Read: true, Execute: true},
+		},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+	withoutTeam := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "history", PublishToKafkaTopic: "history"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "history", "r1", withTeam)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestToken, "history", "r1", withoutTeam)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "history", "r2", withTeam)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, _ = ctrl.RemoveResource(TestAdminToken, "history", "r2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		versions, err, _ := ctrl.ListPermissionVersions(TestToken, "history", "r1", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+			t.Errorf("%#v", versions)
+			return
+		}
+		if versions[0].UserId != TestTokenUser || versions[0].Operation != model.AuditOperationSetPermission || versions[0].Timestamp == 0 {
+			t.Errorf("%#v", versions[0])
+		}
+		if !reflect.DeepEqual(versions[1].Permissions.UserPermissions, withTeam.UserPermissions) {
+			t.Errorf("%#v", versions[1].Permissions)
+		}
+		versions, err, _ = ctrl.ListPermissionVersions(TestToken, "history", "r1", model.ListOptions{Limit: 1, Offset: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 1 || versions[0].Version != 1 {
+			t.Errorf("%#v", versions)
+		}
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		diff, err, _ := ctrl.DiffPermissionVersions(TestToken, "history", "r1", 1, 2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		teammate := withTeam.UserPermissions["teammate"]
+		expected := []model.PermissionsChange{{Field: "user_permissions", Key: "teammate", Before: &teammate}}
+		if !reflect.DeepEqual(diff.Changes, expected) || diff.Parent != nil || diff.From != 1 || diff.To != 2 {
+			t.Errorf("%#v", diff)
+		}
+		_, err, code := ctrl.DiffPermissionVersions(TestToken, "history", "r1", 1, 42)
+		if err == nil || code != http.StatusNotFound {
+			t.Errorf("expected not found, got %v %v", err, code)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		result, err, _ := ctrl.RollbackPermissions(TestToken, "history", "r1", 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result.UserPermissions, withTeam.UserPermissions) {
+			t.Errorf("%#v", result)
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "history", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.UserPermissions, withTeam.UserPermissions) {
+			t.Errorf("%#v", resource)
+		}
+		versions, err, _ := ctrl.ListPermissionVersions(TestToken, "history", "r1", model.ListOptions{Limit: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 1 || versions[0].Version != 3 || versions[0].Operation != model.AuditOperationRollback {
+			t.Errorf("%#v", versions)
+		}
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		published := producer.Produced["history"]["r1"]
+		if len(published) != 3 || !reflect.DeepEqual(published[2].UserPermissions, withTeam.UserPermissions) {
+			t.Errorf("%#v", published)
+		}
+	})
+
+	t.Run("removed resource", func(t *testing.T) {
+		versions, err, _ := ctrl.ListPermissionVersions(TestAdminToken, "history", "r2", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 2 || !versions[0].Deleted || versions[0].Operation != model.AuditOperationRemoveResource {
+			t.Errorf("%#v", versions)
+			return
+		}
+		_, err, code := ctrl.RollbackPermissions(TestAdminToken, "history", "r2", 2)
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request, got %v %v", err, code)
+		}
+		_, err, code = ctrl.ListPermissionVersions(TestToken, "history", "r2", model.ListOptions{})
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+		_, err, _ = ctrl.RollbackPermissions(TestAdminToken, "history", "r2", 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "history", "r2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.UserPermissions, withTeam.UserPermissions) {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("removed topic", func(t *testing.T) {
+		err, _ := ctrl.RemoveTopic(TestAdminToken, "history")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		versions, err, _ := ctrl.ListPermissionVersions(TestAdminToken, "history", "r1", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 4 || versions[0].Version != 4 || !versions[0].Deleted || versions[0].Operation != model.AuditOperationRemoveTopic {
+			t.Errorf("%#v", versions)
+		}
+	})
+}
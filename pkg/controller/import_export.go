@@ -32,12 +32,19 @@ func (this *Controller) Export(token string, options model.ImportExportOptions)
 }
 
 func (this *Controller) ExportContext(ctx context.Context, token string, options model.ImportExportOptions) (result model.ImportExport, err error, code int) {
-	jwtToken, err := jwt.Parse(token)
+	jwtToken, err := this.parseToken(ctx, token)
 	if err != nil {
 		return result, err, http.StatusBadRequest
 	}
 	if !jwtToken.IsAdmin() {
-		return result, errors.New("only admins may export"), http.StatusForbidden
+		//delegated topic admins may only export their topics
+		options.FilterTopics, err = this.getTopicAdminFilter(ctx, jwtToken, options.FilterTopics)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		if len(options.FilterTopics) == 0 {
+			return result, errors.New("only admins may export"), http.StatusForbidden
+		}
 	}
 	result = model.ImportExport{}
 
@@ -82,18 +89,25 @@ func (this *Controller) Import(token string, importModel model.ImportExport, opt
 }
 
 func (this *Controller) ImportContext(ctx context.Context, token string, importModel model.ImportExport, options model.ImportExportOptions) (err error, code int) {
-	jwtToken, err := jwt.Parse(token)
+	jwtToken, err := this.parseToken(ctx, token)
 	if err != nil {
 		return err, http.StatusBadRequest
 	}
 	if !jwtToken.IsAdmin() {
-		return errors.New("only admins may import"), http.StatusForbidden
+		//delegated topic admins may only import their topics; checked before anything is stored
+		access, err := this.checkTopicAdminForImport(ctx, jwtToken, importModel, options)
+		if err != nil {
+			return err, http.StatusInternalServerError
+		}
+		if !access {
+			return errors.New("only admins may import"), http.StatusForbidden
+		}
 	}
 
 	if options.IncludeTopicConfig {
 		for _, topic := range importModel.Topics {
 			if options.FilterTopics == nil || slices.Contains(options.FilterTopics, topic.Id) {
-				_, err, code = this.SetTopicContext(ctx, token, topic)
+				_, err, code = this.setTopic(ctx, jwtToken, topic, model.AuditOperationImport)
 				if err != nil {
 					return err, code
 				}
@@ -104,7 +118,7 @@ func (this *Controller) ImportContext(ctx context.Context, token string, importM
 	if options.IncludePermissions {
 		topicCache := map[string]model.Topic{}
 
-		for _, resource := range importModel.Permissions {
+		for _, resource := range parentsFirst(importModel.Permissions) {
 			if (options.FilterTopics == nil || slices.Contains(options.FilterTopics, resource.TopicId)) && (options.FilterResourceId == nil || slices.Contains(options.FilterResourceId, resource.Id)) {
 				if !resource.Valid() {
 					return fmt.Errorf("invalid resource topic=%v id=%v", resource.TopicId, resource.Id), http.StatusBadRequest
@@ -122,8 +136,15 @@ func (this *Controller) ImportContext(ctx context.Context, token string, importM
 					}
 					topicCache[resource.TopicId] = topic
 				}
+				err = topic.ValidateResourcePermissions(resource.ResourcePermissions)
+				if err != nil {
+					return fmt.Errorf("invalid resource topic=%v id=%v: %w", resource.TopicId, resource.Id, err), http.StatusBadRequest
+				}
 
-				err = this.setPermission(ctx, topic, resource)
+				_, err = this.setPermission(ctx, topic, resource, jwtToken.GetUserId(), model.AuditOperationImport, 0)
+				if errors.Is(err, model.ErrInvalidParent) {
+					return fmt.Errorf("invalid resource topic=%v id=%v: %w", resource.TopicId, resource.Id, err), http.StatusBadRequest
+				}
 				if err != nil {
 					return err, http.StatusInternalServerError
 				}
@@ -133,3 +154,78 @@ func (this *Controller) ImportContext(ctx context.Context, token string, importM
 
 	return nil, http.StatusOK
 }
+
+// parentsFirst returns the resources ordered by their depth in the hierarchy of the given resources,
+// so that parents, which are part of the import, are stored before their children; the order is stable otherwise
+func parentsFirst(resources []model.Resource) []model.Resource {
+	parents := map[model.ResourceReference]model.ResourceReference{}
+	for _, resource := range resources {
+		if resource.HasParent() {
+			parents[model.ResourceReference{TopicId: resource.TopicId, Id: resource.Id}] = *resource.Parent
+		}
+	}
+	depth := func(ref model.ResourceReference) (result int) {
+		//cycles are limited by the number of resources and rejected by the database
+		for parent, ok := parents[ref]; ok && result < len(resources); parent, ok = parents[parent] {
+			result++
+		}
+		return result
+	}
+	result := slices.Clone(resources)
+	slices.SortStableFunc(result, func(a, b model.Resource) int {
+		return depth(model.ResourceReference{TopicId: a.TopicId, Id: a.Id}) - depth(model.ResourceReference{TopicId: b.TopicId, Id: b.Id})
+	})
+	return result
+}
+
+// getTopicAdminFilter returns the topics of the filter (nil -> all topics), of which the token is a delegated admin
+// returns nil if a topic of a non nil filter is not administrated by the token
+func (this *Controller) getTopicAdminFilter(ctx context.Context, token jwt.Token, filter []string) (result []string, err error) {
+	topics, err := this.db.ListTopics(this.getTimeoutContext(ctx), model.ListOptions{Ids: filter})
+	if err != nil {
+		return nil, err
+	}
+	result = []string{}
+	for _, topic := range topics {
+		if isTopicAdmin(token, topic) {
+			result = append(result, topic.Id)
+		}
+	}
+	for _, topicId := range filter {
+		if !slices.Contains(result, topicId) {
+			return nil, nil
+		}
+	}
+	return result, nil
+}
+
+// checkTopicAdminForImport returns true if the token is a delegated admin of all topics, which would be changed by the import
+func (this *Controller) checkTopicAdminForImport(ctx context.Context, token jwt.Token, importModel model.ImportExport, options model.ImportExportOptions) (bool, error) {
+	topicIds := []string{}
+	if options.IncludeTopicConfig {
+		for _, topic := range importModel.Topics {
+			if options.FilterTopics == nil || slices.Contains(options.FilterTopics, topic.Id) {
+				topicIds = append(topicIds, topic.Id)
+			}
+		}
+	}
+	if options.IncludePermissions {
+		for _, resource := range importModel.Permissions {
+			if (options.FilterTopics == nil || slices.Contains(options.FilterTopics, resource.TopicId)) && (options.FilterResourceId == nil || slices.Contains(options.FilterResourceId, resource.Id)) {
+				topicIds = append(topicIds, resource.TopicId)
+			}
+		}
+	}
+	slices.Sort(topicIds)
+	topicIds = slices.Compact(topicIds)
+	if len(topicIds) == 0 {
+		return false, nil
+	}
+	for _, topicId := range topicIds {
+		access, err := this.checkTopicAdmin(ctx, token, topicId)
+		if err != nil || !access {
+			return false, err
+		}
+	}
+	return true, nil
+}
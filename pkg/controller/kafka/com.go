@@ -24,7 +24,14 @@ import (
 
 type Producer interface {
 	Close() error
-	SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions) (err error)
+	SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) (err error) //owner is the creator of the resource; may be empty
+	SendDelete(ctx context.Context, topic model.Topic, id string) (err error)
+}
+
+// BatchProducer is optionally implemented by producers, which are able to send the permissions of multiple resources with one write
+// the owner of each resource is model.Resource.Creator
+type BatchProducer interface {
+	SendPermissionsBatch(ctx context.Context, topic model.Topic, resources []model.Resource) (err error)
 }
 
 type Provider interface {
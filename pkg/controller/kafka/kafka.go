@@ -38,8 +38,9 @@ func NewKafkaProducerProvider() *KafkaProducerProvider {
 type KafkaProducerProvider struct{}
 
 type KafkaProducer struct {
-	config configuration.Config
-	writer *kafka.Writer
+	config      configuration.Config
+	writer      *kafka.Writer
+	batchWriter *kafka.Writer
 }
 
 func (this *KafkaProducerProvider) GetProducer(config configuration.Config, topic model.Topic) (result Producer, err error) {
@@ -50,37 +51,99 @@ func (this *KafkaProducerProvider) GetProducer(config configuration.Config, topi
 			config.GetLogger().Warn("unable to create topic", "topicId", topic.Id, "topic", topic.PublishToKafkaTopic, "error", err)
 		}
 	}
-	return &KafkaProducer{config: config, writer: NewKafkaWriter(config, topic)}, nil
+	return &KafkaProducer{config: config, writer: NewKafkaWriter(config, topic), batchWriter: NewKafkaBatchWriter(config, topic)}, nil
 }
 
 func (this *KafkaProducer) Close() (err error) {
 	if this.writer != nil {
 		err = errors.Join(err, this.writer.Close())
 	}
+	if this.batchWriter != nil {
+		err = errors.Join(err, this.batchWriter.Close())
+	}
 	return err
 }
 
-func (this *KafkaProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions) (err error) {
+func (this *KafkaProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) (err error) {
 	if this.writer == nil {
 		this.config.GetLogger().WarnContext(ctx, "unable to send message to nil topic kafka writer (topic may be disabled by config.DisabledTopicConsumers)")
 		return nil
 	}
+	message, err := this.permissionsMessage(ctx, topic, id, permissions, owner)
+	if err != nil {
+		return err
+	}
+	return this.writer.WriteMessages(ctx, message)
+}
+
+// SendPermissionsBatch sends the permissions of all resources with one write; messages of the same resource keep their order
+func (this *KafkaProducer) SendPermissionsBatch(ctx context.Context, topic model.Topic, resources []model.Resource) (err error) {
+	if this.batchWriter == nil {
+		this.config.GetLogger().WarnContext(ctx, "unable to send messages to nil topic kafka writer (topic may be disabled by config.DisabledTopicConsumers)")
+		return nil
+	}
+	messages := make([]kafka.Message, 0, len(resources))
+	for _, resource := range resources {
+		message, err := this.permissionsMessage(ctx, topic, resource.Id, resource.ResourcePermissions, resource.Creator)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	return this.batchWriter.WriteMessages(ctx, messages...)
+}
+
+func (this *KafkaProducer) permissionsMessage(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) (message kafka.Message, err error) {
 	cmd := Command{
 		Command: "RIGHTS",
 		Id:      id,
 		Rights:  permissionsToRights(permissions),
+		Owner:   owner,
 	}
 	var temp []byte
 	temp, err = json.Marshal(cmd)
 	if err != nil {
-		return err
+		return message, err
 	}
 	key := id + "/rights"
 	this.config.GetLogger().DebugContext(ctx, "produce", "topic", topic.PublishToKafkaTopic, "id", id, "key", key, "message", string(temp))
-	return this.writer.WriteMessages(ctx, kafka.Message{
+	return kafka.Message{
 		Key:   []byte(key),
 		Value: temp,
 		Time:  time.Now(),
+	}, nil
+}
+
+func (this *KafkaProducer) SendDelete(ctx context.Context, topic model.Topic, id string) (err error) {
+	if this.writer == nil {
+		this.config.GetLogger().WarnContext(ctx, "unable to send message to nil topic kafka writer (topic may be disabled by config.DisabledTopicConsumers)")
+		return nil
+	}
+	cmd := Command{
+		Command: "DELETE",
+		Id:      id,
+	}
+	var temp []byte
+	temp, err = json.Marshal(cmd)
+	if err != nil {
+		return err
+	}
+	this.config.GetLogger().DebugContext(ctx, "produce", "topic", topic.PublishToKafkaTopic, "id", id, "key", id, "message", string(temp))
+	err = this.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(id),
+		Value: temp,
+		Time:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	//tombstone to let kafka compaction remove the last rights command of the resource
+	key := id + "/rights"
+	this.config.GetLogger().DebugContext(ctx, "produce tombstone", "topic", topic.PublishToKafkaTopic, "id", id, "key", key)
+	return this.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(key),
+		Value: nil,
+		Time:  time.Now(),
 	})
 }
 
@@ -99,6 +162,19 @@ func NewKafkaWriter(config configuration.Config, topic model.Topic) *kafka.Write
 	return writer
 }
 
+// NewKafkaBatchWriter creates a writer for SendPermissionsBatch, which combines up to KafkaBatchSize messages per partition in one produce request
+func NewKafkaBatchWriter(config configuration.Config, topic model.Topic) *kafka.Writer {
+	writer := NewKafkaWriter(config, topic)
+	writer.BatchSize = KafkaBatchSize
+	writer.BatchTimeout = kafkaBatchTimeout
+	return writer
+}
+
+const KafkaBatchSize = 100
+
+// all messages of a batch are passed to the writer at once; the timeout only delays partitions with less than KafkaBatchSize messages
+const kafkaBatchTimeout = 10 * time.Millisecond
+
 func InitKafkaTopic(bootstrapUrl string, partitionNumber int, topics ...string) (err error) {
 	if partitionNumber == 0 {
 		partitionNumber = 1
@@ -186,50 +262,82 @@ func (this Command) String() string {
 	return string(temp)
 }
 
+// ResourcePermissions are the rights of a resource, as expected by consumers of the rights command.
+// denied user permissions are removed from UserRights. group and role denies can not be represented as rights,
+// because they apply to users, who may be granted the permission by another entry; they are published in the deny fields
+// and must be evaluated by the consumer.
 type ResourcePermissions struct {
 	UserRights           map[string]Right `json:"user_rights"`
 	GroupRights          map[string]Right `json:"group_rights"`
 	KeycloakGroupsRights map[string]Right `json:"keycloak_groups_rights"`
+
+	UserDenies           map[string]Right `json:"user_denies,omitempty"`
+	GroupDenies          map[string]Right `json:"group_denies,omitempty"` //role denies; named like GroupRights
+	KeycloakGroupsDenies map[string]Right `json:"keycloak_groups_denies,omitempty"`
 }
 
 type Right struct {
-	Read         bool `json:"read"`
-	Write        bool `json:"write"`
-	Execute      bool `json:"execute"`
-	Administrate bool `json:"administrate"`
+	Read         bool            `json:"read"`
+	Write        bool            `json:"write"`
+	Execute      bool            `json:"execute"`
+	Administrate bool            `json:"administrate"`
+	Custom       map[string]bool `json:"custom,omitempty"` //custom permissions declared by the topic; key is the permission letter
+}
+
+// without returns the right without the permissions set in deny
+func (this Right) without(deny Right) Right {
+	result := Right{
+		Read:         this.Read && !deny.Read,
+		Write:        this.Write && !deny.Write,
+		Execute:      this.Execute && !deny.Execute,
+		Administrate: this.Administrate && !deny.Administrate,
+	}
+	for key, value := range this.Custom {
+		if value && !deny.Custom[key] {
+			if result.Custom == nil {
+				result.Custom = map[string]bool{}
+			}
+			result.Custom[key] = true
+		}
+	}
+	return result
 }
 
 func permissionsToRights(permissions model.ResourcePermissions) *ResourcePermissions {
+	//consumers of the rights command don't know time-bound entries; inactive entries are omitted and published by the expiry sweeper when they become active
+	now := time.Now()
 	result := ResourcePermissions{
-		UserRights:           map[string]Right{},
-		GroupRights:          map[string]Right{},
-		KeycloakGroupsRights: map[string]Right{},
+		UserRights:           toRights(permissions.UserPermissions, now),
+		GroupRights:          toRights(permissions.RolePermissions, now),
+		KeycloakGroupsRights: toRights(permissions.GroupPermissions, now),
+		UserDenies:           toRights(permissions.UserDenies, now),
+		GroupDenies:          toRights(permissions.RoleDenies, now),
+		KeycloakGroupsDenies: toRights(permissions.GroupDenies, now),
 	}
-	for user, perm := range permissions.UserPermissions {
-		result.UserRights[user] = Right{
-			Read:         perm.Read,
-			Write:        perm.Write,
-			Execute:      perm.Execute,
-			Administrate: perm.Administrate,
+	for user, deny := range result.UserDenies {
+		if right, ok := result.UserRights[user]; ok {
+			result.UserRights[user] = right.without(deny)
 		}
 	}
-	for group, perm := range permissions.RolePermissions {
-		result.GroupRights[group] = Right{
-			Read:         perm.Read,
-			Write:        perm.Write,
-			Execute:      perm.Execute,
-			Administrate: perm.Administrate,
+	return &result
+}
+
+// toRights returns the active entries of the permissions map
+func toRights(permissions map[string]model.PermissionsMap, now time.Time) map[string]Right {
+	result := map[string]Right{}
+	for key, perm := range permissions {
+		if !perm.IsActive(now) {
+			continue
 		}
-	}
-	for group, perm := range permissions.GroupPermissions {
-		result.KeycloakGroupsRights[group] = Right{
+		result[key] = Right{
 			Read:         perm.Read,
 			Write:        perm.Write,
 			Execute:      perm.Execute,
 			Administrate: perm.Administrate,
+			Custom:       perm.Custom,
 		}
 	}
-	return &result
+	return result
 }
 
 func rightsToPermissions(permissions *ResourcePermissions) model.ResourcePermissions {
@@ -244,6 +352,7 @@ func rightsToPermissions(permissions *ResourcePermissions) model.ResourcePermiss
 				Write:        perm.Write,
 				Execute:      perm.Execute,
 				Administrate: perm.Administrate,
+				Custom:       perm.Custom,
 			}
 		}
 		for group, perm := range permissions.GroupRights {
@@ -252,6 +361,7 @@ func rightsToPermissions(permissions *ResourcePermissions) model.ResourcePermiss
 				Write:        perm.Write,
 				Execute:      perm.Execute,
 				Administrate: perm.Administrate,
+				Custom:       perm.Custom,
 			}
 		}
 	}
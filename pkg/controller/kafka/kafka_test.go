@@ -0,0 +1,58 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestPermissionsToRightsDenies(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	rights := permissionsToRights(model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{
+			"owner":  {Read: true, Write: true, Execute: true, Administrate: true},
+			"user":   {Read: true, Write: true, Custom: map[string]bool{"s": true, "t": true}},
+			"future": {Read: true, Write: true},
+		},
+		RolePermissions: map[string]model.PermissionsMap{"user": {Read: true}},
+		UserDenies: map[string]model.PermissionsMap{
+			"user":   {Write: true, Custom: map[string]bool{"s": true}},
+			"future": {Write: true, ValidFrom: &future},
+			"other":  {Read: true},
+		},
+		RoleDenies: map[string]model.PermissionsMap{"guest": {Read: true}},
+	})
+	if !reflect.DeepEqual(rights.UserRights["user"], Right{Read: true, Custom: map[string]bool{"t": true}}) {
+		t.Errorf("denied user permissions should be removed: %#v", rights.UserRights["user"])
+	}
+	if !rights.UserRights["owner"].Administrate || !rights.UserRights["future"].Write {
+		t.Errorf("%#v", rights.UserRights)
+	}
+	if _, ok := rights.UserRights["other"]; ok {
+		t.Errorf("denies should not add rights: %#v", rights.UserRights)
+	}
+	if !rights.UserDenies["other"].Read || !rights.GroupDenies["guest"].Read || len(rights.KeycloakGroupsDenies) != 0 {
+		t.Errorf("%#v", rights)
+	}
+	if _, ok := rights.UserDenies["future"]; ok {
+		t.Errorf("inactive denies should be omitted: %#v", rights.UserDenies)
+	}
+}
@@ -38,6 +38,14 @@ func (this *VoidProducer) Close() (err error) {
 	return nil
 }
 
-func (this *VoidProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions) (err error) {
+func (this *VoidProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) (err error) {
+	return nil
+}
+
+func (this *VoidProducer) SendPermissionsBatch(ctx context.Context, topic model.Topic, resources []model.Resource) (err error) {
+	return nil
+}
+
+func (this *VoidProducer) SendDelete(ctx context.Context, topic model.Topic, id string) (err error) {
 	return nil
 }
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestListSearchAndSort(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "search"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	//created in this order, to differ from the id order
+	for _, id := range []string{"device-3", "device-1", "service-2", "device-2"} {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "search", id, model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		options  model.ListOptions
+		expected []string
+	}{
+		{"default", model.ListOptions{}, []string{"device-1", "device-2", "device-3", "service-2"}},
+		{"prefix", model.ListOptions{IdPrefix: "device-"}, []string{"device-1", "device-2", "device-3"}},
+		{"desc", model.ListOptions{SortDesc: true}, []string{"service-2", "device-3", "device-2", "device-1"}},
+		{"desc cursor", model.ListOptions{SortDesc: true, Limit: 2, Cursor: model.NextCursor("device-3")}, []string{"device-2", "device-1"}},
+		{"timestamp", model.ListOptions{SortBy: model.SortByTimestamp}, []string{"device-3", "device-1", "service-2", "device-2"}},
+		{"timestamp desc", model.ListOptions{SortBy: model.SortByTimestamp, SortDesc: true, IdPrefix: "device-"}, []string{"device-2", "device-1", "device-3"}},
+		{"timestamp offset", model.ListOptions{SortBy: model.SortByTimestamp, Limit: 2, Offset: 1}, []string{"device-1", "service-2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, err, _ := ctrl.ListAccessibleResourceIds(TestToken, "search", test.options, model.Read)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("\n%#v\n%#v", test.expected, ids)
+			}
+			resources, err, _ := ctrl.ListResourcesWithAdminPermission(TestToken, "search", test.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids = []string{}
+			for _, resource := range resources {
+				ids = append(ids, resource.Id)
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("\n%#v\n%#v", test.expected, ids)
+			}
+			ids, err, _ = ctrl.AdminListResourceIds(TestAdminToken, "search", test.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("\n%#v\n%#v", test.expected, ids)
+			}
+		})
+	}
+
+	t.Run("count", func(t *testing.T) {
+		count, err, _ := ctrl.CountAccessibleResourceIds(TestToken, "search", model.ListOptions{IdPrefix: "device-", Limit: 1}, model.PermissionModeAll, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 3 {
+			t.Errorf("expected 3, got %v", count)
+		}
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		options := model.ListOptions{IdRegex: "-2$"}
+		expected := []string{"device-2", "service-2"}
+		ids, err, _ := ctrl.ListAccessibleResourceIds(TestAdminToken, "search", options, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("\n%#v\n%#v", expected, ids)
+		}
+		ids, err, _ = ctrl.AdminListResourceIds(TestAdminToken, "search", options)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, expected) {
+			t.Errorf("\n%#v\n%#v", expected, ids)
+		}
+		_, err, code := ctrl.ListAccessibleResourceIds(TestToken, "search", options, model.Read)
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+		_, err, code = ctrl.CountAccessibleResourceIds(TestToken, "search", options, model.PermissionModeAll, model.Read)
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+		_, err, code = ctrl.ListResourcesWithAdminPermission(TestToken, "search", options)
+		if err == nil || code != http.StatusForbidden {
+			t.Errorf("expected forbidden, got %v %v", err, code)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, options := range []model.ListOptions{
+			{IdRegex: "("},
+			{IdRegex: strings.Repeat("a", model.MaxIdRegexLength+1)},
+			{IdPrefix: "a", IdRegex: "b"},
+			{SortBy: "name"},
+			{SortBy: model.SortByTimestamp, Cursor: model.NextCursor("device-1")},
+		} {
+			_, err, code := ctrl.ListAccessibleResourceIds(TestToken, "search", options, model.Read)
+			if err == nil || code != http.StatusBadRequest {
+				t.Errorf("%#v: expected bad request, got %v %v", options, err, code)
+			}
+		}
+	})
+}
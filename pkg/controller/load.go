@@ -62,6 +62,11 @@ func (this *Controller) AdminLoadFromPermissionSearchContext(ctx context.Context
 		this.config.GetLogger().InfoContext(ctx, "AdminLoadFromPermissionSearch Dry-Run Start")
 		defer this.config.GetLogger().InfoContext(ctx, "AdminLoadFromPermissionSearch Dry-Run End")
 	}
+	//the token is used for the permission-search requests and identifies the acting user in the audit log
+	actor := ""
+	if token, err := this.parseToken(ctx, req.Token); err == nil {
+		actor = token.GetUserId()
+	}
 	limit := 100
 	offset := 0
 	for {
@@ -81,7 +86,7 @@ func (this *Controller) AdminLoadFromPermissionSearchContext(ctx context.Context
 			return updateCount, err, http.StatusInternalServerError
 		}
 		for _, element := range list {
-			updated, err := this.handlePermissionSearchExport(ctx, req, topic, element)
+			updated, err := this.handlePermissionSearchExport(ctx, req, topic, element, actor)
 			if err != nil {
 				return updateCount, err, http.StatusInternalServerError
 			}
@@ -96,7 +101,7 @@ func (this *Controller) AdminLoadFromPermissionSearchContext(ctx context.Context
 	}
 }
 
-func (this *Controller) handlePermissionSearchExport(ctx context.Context, req model.AdminLoadPermSearchRequest, topic model.Topic, element PermissionSearchResponseElement) (updated bool, err error) {
+func (this *Controller) handlePermissionSearchExport(ctx context.Context, req model.AdminLoadPermSearchRequest, topic model.Topic, element PermissionSearchResponseElement, actor string) (updated bool, err error) {
 	timeout := this.getTimeoutContext(ctx)
 	resource, err := this.db.GetResource(timeout, req.TopicId, element.ResourceId, model.GetOptions{})
 	if err != nil && !errors.Is(err, model.ErrNotFound) {
@@ -138,7 +143,7 @@ func (this *Controller) handlePermissionSearchExport(ctx context.Context, req mo
 			fmt.Println(string(buf))
 			return true, nil //dry run is counted
 		} else {
-			err = this.setPermission(ctx, topic, resource)
+			_, err = this.setPermission(ctx, topic, resource, actor, model.AuditOperationLoadPermissionSearch, 0)
 			if err != nil {
 				return true, err
 			}
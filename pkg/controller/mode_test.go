@@ -0,0 +1,161 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestPermissionModes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	set := func(topic string, id string, permissions model.PermissionsMap) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, topic, id, model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": owner, TestTokenUser: permissions},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "modes"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "modes-defaults", DefaultPermissions: model.ResourcePermissions{
+			RolePermissions: map[string]model.PermissionsMap{"user": {Execute: true}},
+			UserDenies:      map[string]model.PermissionsMap{TestTokenUser: {Read: true}},
+		}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		set("modes", "r", model.PermissionsMap{Read: true})
+		set("modes", "x", model.PermissionsMap{Execute: true})
+		set("modes", "none", model.PermissionsMap{})
+		set("modes-defaults", "rw", model.PermissionsMap{Read: true, Write: true})
+		set("modes-defaults", "r", model.PermissionsMap{Read: true})
+	})
+
+	t.Run("check", func(t *testing.T) {
+		tests := []struct {
+			topic       string
+			id          string
+			mode        model.PermissionMode
+			permissions model.PermissionList
+			expected    bool
+		}{
+			{"modes", "r", model.PermissionModeAll, model.PermissionList{model.Read, model.Execute}, false},
+			{"modes", "r", model.PermissionModeAny, model.PermissionList{model.Read, model.Execute}, true},
+			{"modes", "x", model.PermissionModeAny, model.PermissionList{model.Read, model.Execute}, true},
+			{"modes", "none", model.PermissionModeAny, model.PermissionList{model.Read, model.Execute}, false},
+			//granted by topic defaults
+			{"modes-defaults", "r", model.PermissionModeAny, model.PermissionList{model.Write, model.Execute}, true},
+			{"modes-defaults", "r", model.PermissionModeAll, model.PermissionList{model.Write, model.Execute}, false},
+			//read is denied by topic defaults, write is still granted by the resource
+			{"modes-defaults", "rw", model.PermissionModeAny, model.PermissionList{model.Read, model.Write}, true},
+			{"modes-defaults", "rw", model.PermissionModeAll, model.PermissionList{model.Read, model.Write}, false},
+			{"modes-defaults", "r", model.PermissionModeAny, model.PermissionList{model.Read, model.Write}, false},
+		}
+		for _, test := range tests {
+			access, err, _ := ctrl.CheckPermissionWithMode(TestToken, test.topic, test.id, test.mode, test.permissions...)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != test.expected {
+				t.Errorf("%v/%v %v %v: expected %v, got %v", test.topic, test.id, test.mode, test.permissions.Encode(), test.expected, access)
+			}
+		}
+	})
+
+	t.Run("check multiple", func(t *testing.T) {
+		result, err, _ := ctrl.CheckMultiplePermissionsWithMode(TestToken, "modes", []string{"r", "x", "none"}, model.PermissionModeAny, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]bool{"r": true, "x": true, "none": false}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+		result, err, _ = ctrl.CheckMultiplePermissionsWithMode(TestToken, "modes-defaults", []string{"rw", "r"}, model.PermissionModeAny, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected = map[string]bool{"rw": true, "r": false}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("check batch", func(t *testing.T) {
+		result, err, _ := ctrl.CheckPermissionsBatch(TestToken, []model.CheckQuery{{TopicId: "modes", Ids: []string{"r", "x"}, Permissions: "rx", Mode: model.PermissionModeAny}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]map[string]bool{"modes": {"r": true, "x": true}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ids, err, _ := ctrl.ListAccessibleResourceIdsWithMode(TestToken, "modes", model.ListOptions{}, model.PermissionModeAny, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"r", "x"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err, _ = ctrl.ListAccessibleResourceIdsWithMode(TestToken, "modes", model.ListOptions{}, model.PermissionModeAll, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+		ids, err, _ = ctrl.ListAccessibleResourceIdsWithMode(TestToken, "modes-defaults", model.ListOptions{}, model.PermissionModeAny, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"rw"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+}
@@ -0,0 +1,259 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/kafka"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+const outboxBatchSize = 100
+const outboxLeaseDuration = 5 * time.Minute
+const outboxMinBackoff = time.Second
+
+// StartOutboxRelay publishes pending outbox events after local changes and every config.SyncCheckInterval
+func (this *Controller) StartOutboxRelay(ctx context.Context) {
+	var ticker <-chan time.Time
+	if dur := this.config.SyncCheckInterval.GetDuration(); dur > 0 {
+		t := time.NewTicker(dur)
+		ticker = t.C
+		go func() {
+			<-ctx.Done()
+			t.Stop()
+		}()
+	}
+	go func() {
+		for {
+			err := this.RelayOutboxContext(ctx)
+			if err != nil {
+				this.config.GetLogger().WarnContext(ctx, "unable to relay outbox", "error", err)
+			}
+			select {
+			case <-ticker:
+			case <-this.outboxTrigger:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (this *Controller) triggerOutboxRelay() {
+	select {
+	case this.outboxTrigger <- struct{}{}:
+	default:
+		//relay is already triggered
+	}
+}
+
+func (this *Controller) RelayOutbox() error {
+	return this.RelayOutboxContext(context.TODO())
+}
+
+// RelayOutboxContext publishes all pending outbox events in order.
+// if the publishing of an event fails, later events with the same key are held back until the retry succeeds.
+func (this *Controller) RelayOutboxContext(ctx context.Context) error {
+	this.outboxRelayMux.Lock()
+	defer this.outboxRelayMux.Unlock()
+
+	leaseStart := time.Now()
+	ok, err := this.db.AcquireOutboxLease(this.getTimeoutContext(ctx), this.outboxRelayId, outboxLeaseDuration)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		//outbox is relayed by another instance
+		return nil
+	}
+	//the lease is released after each run, so that the relay triggered by a change on another instance does not wait for the next tick
+	defer func() {
+		err := this.db.ReleaseOutboxLease(this.getTimeoutContext(ctx), this.outboxRelayId)
+		if err != nil {
+			this.config.GetLogger().WarnContext(ctx, "unable to release outbox lease", "error", err)
+		}
+	}()
+
+	blocked := map[string]bool{}
+	webhooks := this.newWebhookList(ctx)
+	var lastSeq int64 = 0
+	for {
+		events, err := this.db.ListOutboxEvents(this.getTimeoutContext(ctx), lastSeq, outboxBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		//consecutive permission updates of the same topic are published as one batch
+		pending := []model.OutboxEvent{}
+		for _, event := range events {
+			lastSeq = event.Seq
+			if len(pending) > 0 && !joinsOutboxBatch(pending, event) {
+				err = this.relayOutboxBatch(ctx, pending, blocked, webhooks)
+				if err != nil {
+					return err
+				}
+				pending = pending[:0]
+			}
+			key := event.Key()
+			if blocked[key] {
+				continue
+			}
+			if event.NextAttempt > time.Now().UnixMilli() {
+				blocked[key] = true
+				continue
+			}
+			if time.Since(leaseStart) > outboxLeaseDuration/2 {
+				leaseStart = time.Now()
+				ok, err = this.db.AcquireOutboxLease(this.getTimeoutContext(ctx), this.outboxRelayId, outboxLeaseDuration)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+			pending = append(pending, event)
+		}
+		err = this.relayOutboxBatch(ctx, pending, blocked, webhooks)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Deprecated: use RelayOutbox; unsynced resources are replaced by the outbox
+func (this *Controller) RetryPublishOfUnsyncedResources() error {
+	return this.RelayOutbox()
+}
+
+// Deprecated: use RelayOutboxContext; unsynced resources are replaced by the outbox
+func (this *Controller) RetryPublishOfUnsyncedResourcesContext(ctx context.Context) error {
+	return this.RelayOutboxContext(ctx)
+}
+
+func joinsOutboxBatch(batch []model.OutboxEvent, event model.OutboxEvent) bool {
+	first := batch[0]
+	return first.Command == model.OutboxCommandSetPermissions &&
+		event.Command == model.OutboxCommandSetPermissions &&
+		first.Topic.Id == event.Topic.Id &&
+		first.Topic.PublishToKafkaTopic == event.Topic.PublishToKafkaTopic
+}
+
+// relayOutboxBatch publishes the events and removes them from the outbox.
+// if the publishing fails, all events are scheduled for a retry and their keys are blocked.
+func (this *Controller) relayOutboxBatch(ctx context.Context, events []model.OutboxEvent, blocked map[string]bool, webhooks func() ([]model.Webhook, error)) (err error) {
+	if len(events) == 0 {
+		return nil
+	}
+	var publishErr error
+	if len(events) == 1 {
+		publishErr = this.publishOutboxEvent(ctx, events[0], webhooks)
+	} else {
+		publishErr = this.publishPermissionsBatch(ctx, events[0].Topic, events)
+	}
+	if publishErr != nil {
+		notify := false
+		for _, event := range events {
+			blocked[event.Key()] = true
+			attempts := event.Attempts + 1
+			this.config.GetLogger().WarnContext(ctx, "unable to publish outbox event", "topicId", event.Topic.Id, "id", event.Id, "topic", event.Topic.PublishToKafkaTopic, "attempts", attempts, "error", publishErr)
+			notify = notify || attempts == 1
+			err = this.db.SetOutboxEventRetry(this.getTimeoutContext(ctx), event.Seq, attempts, time.Now().Add(this.getOutboxBackoff(attempts)), publishErr.Error())
+			if err != nil {
+				return err
+			}
+		}
+		if notify && events[0].Command == model.OutboxCommandWebhook {
+			this.notifyError(fmt.Errorf("unable to create webhook deliveries of %v event; will be retried", events[0].Webhook.Event))
+		} else if notify {
+			this.notifyError(fmt.Errorf("unable to publish permissions update to %v; publish will be retried", events[0].Topic.PublishToKafkaTopic))
+		}
+		return nil
+	}
+	for _, event := range events {
+		err = this.db.RemoveOutboxEvent(this.getTimeoutContext(ctx), event.Seq)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *Controller) publishOutboxEvent(ctx context.Context, event model.OutboxEvent, webhooks func() ([]model.Webhook, error)) error {
+	switch event.Command {
+	case model.OutboxCommandSetPermissions:
+		return this.publishPermission(ctx, event.Topic, event.Id, event.Permissions, event.Owner)
+	case model.OutboxCommandDelete:
+		return this.publishDeletion(ctx, event.Topic, event.Id)
+	case model.OutboxCommandWebhook:
+		list, err := webhooks()
+		if err != nil {
+			return err
+		}
+		return this.createWebhookDeliveries(ctx, event, list)
+	default:
+		this.config.GetLogger().ErrorContext(ctx, "unknown outbox command; event will be dropped", "command", event.Command, "seq", event.Seq)
+		return nil
+	}
+}
+
+// publishPermissionsBatch publishes the permissions of all events with one write, if the producer supports batches
+func (this *Controller) publishPermissionsBatch(ctx context.Context, topic model.Topic, events []model.OutboxEvent) error {
+	if !topic.PublishesToKafka() {
+		return nil
+	}
+	producer, err := this.getProducer(topic)
+	if err != nil {
+		return err
+	}
+	batchProducer, ok := producer.(kafka.BatchProducer)
+	if !ok {
+		for _, event := range events {
+			err = producer.SendPermissions(this.getTimeoutContext(ctx), topic, event.Id, event.Permissions, event.Owner)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	resources := make([]model.Resource, 0, len(events))
+	for _, event := range events {
+		resources = append(resources, model.Resource{Id: event.Id, TopicId: event.Topic.Id, ResourcePermissions: event.Permissions, Creator: event.Owner})
+	}
+	return batchProducer.SendPermissionsBatch(this.getTimeoutContext(ctx), topic, resources)
+}
+
+func (this *Controller) getOutboxBackoff(attempts int64) time.Duration {
+	return getBackoff(attempts, this.config.OutboxMaxBackoff.GetDuration())
+}
+
+// getBackoff doubles the wait time with every attempt, starting at one second; maxBackoff <= 0 limits the wait time to about one hour
+func getBackoff(attempts int64, maxBackoff time.Duration) time.Duration {
+	backoff := outboxMinBackoff
+	for i := int64(1); i < attempts && backoff < time.Hour; i++ {
+		backoff = backoff * 2
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestPatchPermission(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user-list" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]User{{Id: TestTokenUser}, {Id: "teammate"}})
+	}))
+	defer server.Close()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{UserManagementUrl: server.URL}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	reader := model.PermissionsMap{Read: true}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "patch"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "patch", "r1", model.ResourcePermissions{
+			UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner, "other": reader},
+			GroupPermissions: map[string]model.PermissionsMap{"g1": reader},
+			RolePermissions:  map[string]model.PermissionsMap{"user": reader},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("add and remove entries", func(t *testing.T) {
+		_, err, _ := ctrl.PatchPermission(TestToken, "patch", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{"teammate": &reader},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err, _ := ctrl.PatchPermission(TestToken, "patch", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{"other": nil},
+			RolePermissions: map[string]*model.PermissionsMap{"user": {Read: true, Execute: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expectedUsers := map[string]model.PermissionsMap{TestTokenUser: owner, "teammate": reader}
+		if !reflect.DeepEqual(result.UserPermissions, expectedUsers) {
+			t.Errorf("%#v", result.UserPermissions)
+		}
+		resource, err, _ := ctrl.GetResource(TestToken, "patch", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.UserPermissions, expectedUsers) ||
+			!reflect.DeepEqual(resource.GroupPermissions, map[string]model.PermissionsMap{"g1": reader}) ||
+			!reflect.DeepEqual(resource.RolePermissions, map[string]model.PermissionsMap{"user": {Read: true, Execute: true}}) {
+			t.Errorf("%#v", resource)
+		}
+	})
+
+	t.Run("edit rules", func(t *testing.T) {
+		_, err, code := ctrl.PatchPermission(TestToken, "patch", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{"stranger": &reader},
+		})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request for user outside of the group, got %v %v", err, code)
+		}
+		_, err, code = ctrl.PatchPermission(TestToken, "patch", "r1", model.PermissionsPatch{
+			GroupPermissions: map[string]*model.PermissionsMap{"g2": &reader},
+		})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request for group without the requesting user, got %v %v", err, code)
+		}
+		_, err, code = ctrl.PatchPermission(TestToken, "patch", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{TestTokenUser: nil},
+		})
+		if err == nil || code != http.StatusBadRequest {
+			t.Errorf("expected bad request for removal of the last admin, got %v %v", err, code)
+		}
+	})
+
+	t.Run("unknown resource", func(t *testing.T) {
+		_, err, code := ctrl.PatchPermission(TestAdminToken, "patch", "unknown", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{TestTokenUser: &owner},
+		})
+		if err == nil || code != http.StatusNotFound {
+			t.Errorf("expected not found, got %v %v", err, code)
+		}
+	})
+
+	t.Run("history", func(t *testing.T) {
+		versions, err, _ := ctrl.ListPermissionVersions(TestToken, "patch", "r1", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 3 || versions[0].Operation != model.AuditOperationPatchPermission {
+			t.Errorf("%#v", versions)
+		}
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"reflect"
 	"slices"
@@ -32,18 +33,39 @@ import (
 	"github.com/SENERGY-Platform/service-commons/pkg/jwt"
 )
 
+// validateListOptions validates the options and restricts IdRegex to admins,
+// because the regular expression is evaluated by the database
+func validateListOptions(token jwt.Token, options model.ListOptions) (err error, code int) {
+	err = options.Validate()
+	if err != nil {
+		return err, http.StatusBadRequest
+	}
+	if options.IdRegex != "" && !token.IsAdmin() {
+		return errors.New("only admins may use id_regex"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
+
 func (this *Controller) AdminListResourceIds(tokenStr string, topicId string, options model.ListOptions) (ids []string, err error, code int) {
 	return this.AdminListResourceIdsContext(context.TODO(), tokenStr, topicId, options)
 }
 
 func (this *Controller) AdminListResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (ids []string, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
 	if err != nil {
-		return ids, err, http.StatusUnauthorized
+		return ids, err, code
+	}
+	access, err := this.checkTopicAdmin(ctx, token, topicId)
+	if err != nil {
+		return ids, err, http.StatusInternalServerError
 	}
-	if !token.IsAdmin() {
+	if !access {
 		return ids, errors.New("only admins may use this method"), http.StatusUnauthorized
 	}
+	err, code = validateListOptions(token, options)
+	if err != nil {
+		return ids, err, code
+	}
 	ids, err = this.db.AdminListResourceIds(this.getTimeoutContext(ctx), topicId, options)
 	if err != nil {
 		code = http.StatusInternalServerError
@@ -53,23 +75,65 @@ func (this *Controller) AdminListResourceIdsContext(ctx context.Context, tokenSt
 	return ids, err, code
 }
 
+func (this *Controller) AdminCountResourceIds(tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int) {
+	return this.AdminCountResourceIdsContext(context.TODO(), tokenStr, topicId, options)
+}
+
+func (this *Controller) AdminCountResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return count, err, code
+	}
+	access, err := this.checkTopicAdmin(ctx, token, topicId)
+	if err != nil {
+		return count, err, http.StatusInternalServerError
+	}
+	if !access {
+		return count, errors.New("only admins may use this method"), http.StatusUnauthorized
+	}
+	err, code = validateListOptions(token, options)
+	if err != nil {
+		return count, err, code
+	}
+	count, err = this.db.AdminCountResources(this.getTimeoutContext(ctx), topicId, options)
+	if err != nil {
+		return count, err, http.StatusInternalServerError
+	}
+	return count, nil, http.StatusOK
+}
+
 func (this *Controller) ListAccessibleResourceIds(tokenStr string, topicId string, options model.ListOptions, permission ...model.Permission) (ids []string, err error, code int) {
 	return this.ListAccessibleResourceIdsContext(context.TODO(), tokenStr, topicId, options, permission...)
 }
 
 func (this *Controller) ListAccessibleResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions, permission ...model.Permission) (ids []string, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	return this.ListAccessibleResourceIdsWithModeContext(ctx, tokenStr, topicId, options, model.PermissionModeAll, permission...)
+}
+
+func (this *Controller) ListAccessibleResourceIdsWithMode(tokenStr string, topicId string, options model.ListOptions, mode model.PermissionMode, permission ...model.Permission) (ids []string, err error, code int) {
+	return this.ListAccessibleResourceIdsWithModeContext(context.TODO(), tokenStr, topicId, options, mode, permission...)
+}
+
+func (this *Controller) ListAccessibleResourceIdsWithModeContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions, mode model.PermissionMode, permission ...model.Permission) (ids []string, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return ids, err, code
+	}
+	err, code = validateListOptions(token, options)
 	if err != nil {
-		return ids, err, http.StatusUnauthorized
+		return ids, err, code
 	}
-	access, err, code := this.CheckTopicDefaultPermissionContext(ctx, token, topicId, permission)
+	access, denied, resourcePermissions, err, code := this.checkTopicDefaultPermissionWithTopics(ctx, token, nil, topicId, mode, permission)
 	if err != nil {
 		return ids, err, code
 	}
+	if denied {
+		return []string{}, nil, http.StatusOK
+	}
 	if access {
 		ids, err = this.db.AdminListResourceIds(this.getTimeoutContext(ctx), topicId, options)
 	} else {
-		ids, err = this.db.ListResourceIdsByPermissions(this.getTimeoutContext(ctx), topicId, token.GetUserId(), token.GetRoles(), token.GetGroups(), options, permission...)
+		ids, err = this.db.ListResourceIdsByPermissionsWithMode(this.getTimeoutContext(ctx), topicId, token.GetUserId(), token.GetRoles(), token.GetGroups(), options, mode, resourcePermissions...)
 	}
 	if err != nil {
 		code = http.StatusInternalServerError
@@ -79,20 +143,59 @@ func (this *Controller) ListAccessibleResourceIdsContext(ctx context.Context, to
 	return ids, err, code
 }
 
+// CountAccessibleResourceIds returns the number of resources, which would be listed by ListAccessibleResourceIdsWithMode without paging
+func (this *Controller) CountAccessibleResourceIds(tokenStr string, topicId string, options model.ListOptions, mode model.PermissionMode, permission ...model.Permission) (count int64, err error, code int) {
+	return this.CountAccessibleResourceIdsContext(context.TODO(), tokenStr, topicId, options, mode, permission...)
+}
+
+func (this *Controller) CountAccessibleResourceIdsContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions, mode model.PermissionMode, permission ...model.Permission) (count int64, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return count, err, code
+	}
+	err, code = validateListOptions(token, options)
+	if err != nil {
+		return count, err, code
+	}
+	access, denied, resourcePermissions, err, code := this.checkTopicDefaultPermissionWithTopics(ctx, token, nil, topicId, mode, permission)
+	if err != nil {
+		return count, err, code
+	}
+	if denied {
+		return 0, nil, http.StatusOK
+	}
+	if access {
+		count, err = this.db.AdminCountResources(this.getTimeoutContext(ctx), topicId, options)
+	} else {
+		count, err = this.db.CountResourcesByPermissions(this.getTimeoutContext(ctx), topicId, token.GetUserId(), token.GetRoles(), token.GetGroups(), options, mode, resourcePermissions...)
+	}
+	if err != nil {
+		return count, err, http.StatusInternalServerError
+	}
+	return count, nil, http.StatusOK
+}
+
 func (this *Controller) ListResourcesWithAdminPermission(tokenStr string, topicId string, options model.ListOptions) (result []model.Resource, err error, code int) {
 	return this.ListResourcesWithAdminPermissionContext(context.TODO(), tokenStr, topicId, options)
 }
 
 func (this *Controller) ListResourcesWithAdminPermissionContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (result []model.Resource, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	err, code = validateListOptions(token, options)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
+		return result, err, code
 	}
 
-	access, err, code := this.CheckTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
+	access, denied, err, code := this.checkTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
 	if err != nil {
 		return result, err, code
 	}
+	if denied {
+		return []model.Resource{}, nil, http.StatusOK
+	}
 	if access {
 		result, err = this.db.AdminListResources(this.getTimeoutContext(ctx), topicId, options)
 	} else {
@@ -107,46 +210,112 @@ func (this *Controller) ListResourcesWithAdminPermissionContext(ctx context.Cont
 	return result, err, code
 }
 
-func (this *Controller) RemoveResource(tokenStr string, topicId string, id string) (err error, code int) {
-	return this.RemoveResourceContext(context.TODO(), tokenStr, topicId, id)
+// CountResourcesWithAdminPermission returns the number of resources, which would be listed by ListResourcesWithAdminPermission without paging
+func (this *Controller) CountResourcesWithAdminPermission(tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int) {
+	return this.CountResourcesWithAdminPermissionContext(context.TODO(), tokenStr, topicId, options)
+}
+
+func (this *Controller) CountResourcesWithAdminPermissionContext(ctx context.Context, tokenStr string, topicId string, options model.ListOptions) (count int64, err error, code int) {
+	return this.CountAccessibleResourceIdsContext(ctx, tokenStr, topicId, options, model.PermissionModeAll, model.Administrate)
+}
+
+func (this *Controller) RemoveResource(tokenStr string, topicId string, id string, options ...model.WriteOptions) (err error, code int) {
+	return this.RemoveResourceContext(context.TODO(), tokenStr, topicId, id, options...)
 }
 
-func (this *Controller) RemoveResourceContext(ctx context.Context, tokenStr string, topicId string, id string) (err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+func (this *Controller) RemoveResourceContext(ctx context.Context, tokenStr string, topicId string, id string, options ...model.WriteOptions) (err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeManage, topicId)
 	if err != nil {
-		return err, http.StatusUnauthorized
+		return err, code
 	}
 
-	access := token.IsAdmin()
-	if !access {
+	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if !isTopicAdmin(token, topic) {
 		return errors.New("access denied: only admin roles may delete resources"), http.StatusForbidden
 	}
+	if !exists {
+		topic = model.Topic{Id: topicId}
+	}
 
-	err = this.db.DeleteResource(this.getTimeoutContext(ctx), topicId, id)
+	err = this.removeResource(ctx, topic, id, token.GetUserId(), model.AuditOperationRemoveResource, writeOptions(options).ExpectedVersion)
+	if errors.Is(err, model.ErrVersionMismatch) {
+		return err, http.StatusPreconditionFailed
+	}
 	if err != nil {
 		return err, http.StatusInternalServerError
 	}
 	return nil, http.StatusOK
 }
 
+// removeResource removes the resource; if expectedVersion is not 0, the stored resource must have this version
+func (this *Controller) removeResource(ctx context.Context, topic model.Topic, id string, actor string, operation model.AuditOperation, expectedVersion int64) (err error) {
+	publish := topic.PublishesToKafka()
+
+	previous, err := this.getStoredPermissions(ctx, topic.Id, id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	change := this.newChange(actor, operation)
+	if previous != nil {
+		change.Webhooks = []model.WebhookPayload{{
+			Event:      model.WebhookEventRemoveResource,
+			Timestamp:  now.UnixMilli(),
+			UserId:     actor,
+			TopicId:    topic.Id,
+			ResourceId: id,
+		}}
+	}
+	if expectedVersion != 0 {
+		err = this.db.DeleteResourceIfVersion(this.getTimeoutContext(ctx), topic, id, now, !publish, expectedVersion, change)
+	} else {
+		err = this.db.DeleteResource(this.getTimeoutContext(ctx), topic, id, now, !publish, change)
+	}
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		this.audit(ctx, now, model.AuditEntry{
+			UserId:              actor,
+			Operation:           operation,
+			TopicId:             topic.Id,
+			ResourceId:          id,
+			PreviousPermissions: previous,
+		})
+	}
+	//children of the resource may be published, even if the topic is not; webhooks are notified by the outbox relay
+	this.triggerOutboxRelay()
+	return nil
+}
+
 func (this *Controller) GetResource(tokenStr string, topicId string, id string) (result model.Resource, err error, code int) {
 	return this.GetResourceContext(context.TODO(), tokenStr, topicId, id)
 }
 
 func (this *Controller) GetResourceContext(ctx context.Context, tokenStr string, topicId string, id string) (result model.Resource, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeCheck, topicId)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
+		return result, err, code
 	}
 	pureId, _ := idmodifier.SplitModifier(id)
 
-	access, err, code := this.CheckTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
+	topicAdmin, err := this.checkTopicAdmin(ctx, token, topicId)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	access, denied, err, code := this.checkTopicDefaultPermissionContext(ctx, token, topicId, model.PermissionList{model.Administrate})
 	if err != nil {
 		return result, err, code
 	}
+	if denied && !topicAdmin {
+		return result, errors.New("access denied"), http.StatusForbidden
+	}
 
 	result, err = this.db.GetResource(this.getTimeoutContext(ctx), topicId, pureId, model.GetOptions{
-		CheckPermission: !access, //admins may access without stored permission
+		CheckPermission: !access && !topicAdmin, //admins may access without stored permission
 		UserId:          token.GetUserId(),
 		RoleIds:         token.GetRoles(),
 		GroupIds:        token.GetGroups(),
@@ -165,16 +334,64 @@ func (this *Controller) GetResourceContext(ctx context.Context, tokenStr string,
 	return result, nil, http.StatusOK
 }
 
-func (this *Controller) SetPermission(tokenStr string, topicId string, id string, permissions model.ResourcePermissions) (result model.ResourcePermissions, err error, code int) {
-	return this.SetPermissionContext(context.TODO(), tokenStr, topicId, id, permissions)
+func (this *Controller) SetPermission(tokenStr string, topicId string, id string, permissions model.ResourcePermissions, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int) {
+	return this.SetPermissionContext(context.TODO(), tokenStr, topicId, id, permissions, options...)
 }
 
-func (this *Controller) SetPermissionContext(ctx context.Context, tokenStr string, topicId string, id string, permissions model.ResourcePermissions) (result model.ResourcePermissions, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+func (this *Controller) SetPermissionContext(ctx context.Context, tokenStr string, topicId string, id string, permissions model.ResourcePermissions, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeManage, topicId)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
+		return result, err, code
 	}
+	return this.setPermissionWithToken(ctx, token, topicId, id, permissions, model.AuditOperationSetPermission, writeOptions(options))
+}
+
+func (this *Controller) PatchPermission(tokenStr string, topicId string, id string, patch model.PermissionsPatch, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int) {
+	return this.PatchPermissionContext(context.TODO(), tokenStr, topicId, id, patch, options...)
+}
 
+// PatchPermissionContext applies the patch to the stored permissions of an existing resource.
+// the result is checked and stored like the permissions of a SetPermission call.
+func (this *Controller) PatchPermissionContext(ctx context.Context, tokenStr string, topicId string, id string, patch model.PermissionsPatch, options ...model.WriteOptions) (result model.ResourcePermissions, err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeManage, topicId)
+	if err != nil {
+		return result, err, code
+	}
+	pureId, _ := idmodifier.SplitModifier(id)
+	opt := writeOptions(options)
+	anyVersion := opt.ExpectedVersion == 0 || opt.ExpectedVersion == model.ExpectExisting
+	for i := 0; i < patchAttempts; i++ {
+		current, err := this.db.GetResource(this.getTimeoutContext(ctx), topicId, pureId, model.GetOptions{})
+		if errors.Is(err, model.ErrNotFound) && opt.ExpectedVersion == model.ExpectExisting {
+			return result, model.ErrVersionMismatch, http.StatusPreconditionFailed
+		}
+		if errors.Is(err, model.ErrNotFound) {
+			return result, errors.New("unknown resource"), http.StatusNotFound
+		}
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		//without expected version, the patch is only stored if the read version is unchanged, to not overwrite concurrent changes of other entries
+		version := opt.ExpectedVersion
+		if anyVersion {
+			version = current.Version
+		}
+		result, err, code = this.setPermissionWithToken(ctx, token, topicId, id, patch.Apply(current.ResourcePermissions), model.AuditOperationPatchPermission, model.WriteOptions{ExpectedVersion: version, OnStored: opt.OnStored})
+		if anyVersion && errors.Is(err, model.ErrVersionMismatch) {
+			continue
+		}
+		return result, err, code
+	}
+	return result, err, code
+}
+
+// number of attempts of a patch without expected version, if concurrent changes modified the resource
+const patchAttempts = 3
+
+// setPermissionWithToken checks the access of the token and the validity of the permissions before they are stored with setPermission
+// if options.ExpectedVersion is not 0, the stored resource must have this version; options.OnStored is called with the new version
+func (this *Controller) setPermissionWithToken(ctx context.Context, token jwt.Token, topicId string, id string, permissions model.ResourcePermissions, operation model.AuditOperation, options model.WriteOptions) (result model.ResourcePermissions, err error, code int) {
+	expectedVersion := options.ExpectedVersion
 	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
@@ -183,82 +400,248 @@ func (this *Controller) SetPermissionContext(ctx context.Context, tokenStr strin
 		return result, errors.New("unknown topic"), http.StatusNotFound
 	}
 
-	access, err := this.checkTopicDefaultPermission(token, topic, model.PermissionList{model.Administrate})
-	if err != nil {
-		return result, err, http.StatusInternalServerError
+	//delegated topic admins are not affected by the topic default denies and the edit restrictions of checkEditPermission
+	topicAdmin := isTopicAdmin(token, topic)
+	if !topicAdmin && isDeniedByTopicDefaults(token, topic, model.PermissionList{model.Administrate}) {
+		return result, errors.New("access denied"), http.StatusForbidden
+	}
+	access := topicAdmin
+	if !access {
+		access, err = this.checkTopicDefaultPermission(token, topic, model.PermissionList{model.Administrate})
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
 	}
 
 	pureId, _ := idmodifier.SplitModifier(id)
+	create := false
 	if !access {
 		access, err := this.db.CheckResourcePermissions(this.getTimeoutContext(ctx), topicId, pureId, token.GetUserId(), token.GetRoles(), token.GetGroups(), model.Administrate)
 		if err != nil {
 			return result, err, http.StatusInternalServerError
 		}
 		if !access {
+			create, err = this.mayCreateResource(ctx, token, topic, pureId)
+			if err != nil {
+				return result, err, http.StatusInternalServerError
+			}
+		}
+		if !access && !create {
 			return result, errors.New("access denied"), http.StatusForbidden
 		}
 	}
 
+	if !topicAdmin {
+		stored, err := this.getStoredPermissions(ctx, topicId, id)
+		if err != nil {
+			return result, err, http.StatusInternalServerError
+		}
+		permissions, err, code = this.checkEditPermissionOfCreator(ctx, token, topic, stored, permissions, create, &editCheckCache{})
+		if err != nil {
+			return result, err, code
+		}
+	}
+	if create {
+		permissions = topic.CreationPolicy.Apply(token.GetUserId(), permissions)
+		if expectedVersion == 0 {
+			//a concurrently created resource must not be overwritten
+			expectedVersion = expectNewResource
+		}
+	}
 	if !permissions.Valid() {
 		return result, errors.New("invalid permissions"), http.StatusBadRequest
 	}
-	permissions, err, code = this.checkEditPermissionWithContext(ctx, token, topic.Id, id, permissions)
+	err = topic.ValidateResourcePermissions(permissions)
 	if err != nil {
-		return result, err, code
+		return result, err, http.StatusBadRequest
 	}
 
-	err = this.setPermission(ctx, topic, model.Resource{
+	version, err := this.setPermission(ctx, topic, model.Resource{
 		Id:                  pureId,
 		TopicId:             topic.Id,
 		ResourcePermissions: permissions,
-	})
+	}, token.GetUserId(), operation, expectedVersion)
+	if errors.Is(err, model.ErrInvalidParent) {
+		return result, err, http.StatusBadRequest
+	}
+	if errors.Is(err, model.ErrVersionMismatch) {
+		return result, err, http.StatusPreconditionFailed
+	}
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	if options.OnStored != nil {
+		options.OnStored(version)
+	}
 	return permissions, nil, http.StatusOK
 }
 
-func (this *Controller) setPermission(ctx context.Context, topic model.Topic, resource model.Resource) (err error) {
-	publish := topic.PublishToKafkaTopic != "" && topic.PublishToKafkaTopic != "-"
+// mayCreateResource returns true if the creation policy of the topic allows the token to create the resource and the resource does not exist
+func (this *Controller) mayCreateResource(ctx context.Context, token jwt.Token, topic model.Topic, id string) (bool, error) {
+	if topic.CreationPolicy == nil || !topic.CreationPolicy.Allows(token.GetRoles(), token.GetGroups()) {
+		return false, nil
+	}
+	_, err := this.db.GetResource(this.getTimeoutContext(ctx), topic.Id, id, model.GetOptions{})
+	if errors.Is(err, model.ErrNotFound) {
+		return true, nil
+	}
+	return false, err
+}
 
-	err = this.db.SetResource(this.getTimeoutContext(ctx), resource, time.Now(), !publish)
+// checkEditPermissionOfCreator checks the permissions with checkStoredEditPermission against the stored permissions (nil for new resources);
+// if create is true, the entry of the creator is set by the creation policy and is not limited by the sharing policy
+func (this *Controller) checkEditPermissionOfCreator(ctx context.Context, token jwt.Token, topic model.Topic, stored *model.ResourcePermissions, permissions model.ResourcePermissions, create bool, cache *editCheckCache) (result model.ResourcePermissions, err error, code int) {
+	own, hasOwn := permissions.UserPermissions[token.GetUserId()]
+	if create && hasOwn {
+		permissions.UserPermissions = maps.Clone(permissions.UserPermissions)
+		delete(permissions.UserPermissions, token.GetUserId())
+	}
+	current := model.ResourcePermissions{}
+	if stored != nil {
+		current = *stored
+	}
+	result, err, code = this.checkStoredEditPermission(ctx, token, topic, current, permissions, cache)
 	if err != nil {
-		return err
+		return result, err, code
+	}
+	if create && hasOwn {
+		result.UserPermissions[token.GetUserId()] = own
 	}
+	return result, nil, code
+}
 
-	if publish {
-		err = this.publishPermission(ctx, topic, resource.Id, resource.ResourcePermissions)
-		if err != nil {
-			this.config.GetLogger().WarnContext(ctx, "unable to publish permissions update", "topic", topic.PublishToKafkaTopic)
-			this.notifyError(fmt.Errorf("unable to publish permissions update to %v; publish will be retried", topic.PublishToKafkaTopic))
-			return nil
-		} else {
-			err = this.db.MarkResourceAsSynced(this.getTimeoutContext(ctx), topic.Id, resource.Id)
-			if err != nil {
-				this.config.GetLogger().WarnContext(ctx, "unable to mark resource as synced", "topicId", topic.Id, "resourceId", resource.Id)
+// expectNewResource may be used as expectedVersion of setPermission, to only store resources, which do not exist yet
+const expectNewResource int64 = -2
+
+// setPermission stores the resource and returns its new version; if expectedVersion is not 0, the stored resource must have this version.
+// resource.Creator defaults to the actor and is only stored by the database, if the resource is new.
+func (this *Controller) setPermission(ctx context.Context, topic model.Topic, resource model.Resource, actor string, operation model.AuditOperation, expectedVersion int64) (version int64, err error) {
+	publish := topic.PublishesToKafka()
+	if resource.Creator == "" {
+		resource.Creator = actor
+	}
+
+	previous, err := this.getStoredPermissions(ctx, resource.TopicId, resource.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	//if published, the change is stored together with an outbox event, which is relayed to kafka asynchronously
+	//changes of inherited permissions of children are added to the outbox by the database, depending on the topics of the children
+	//the version of the change and the webhook notification are stored in the same transaction
+	now := time.Now()
+	change := this.newChange(actor, operation)
+	change.Webhooks = []model.WebhookPayload{{
+		Event:       model.WebhookEventSetResource,
+		Timestamp:   now.UnixMilli(),
+		UserId:      actor,
+		TopicId:     resource.TopicId,
+		ResourceId:  resource.Id,
+		Permissions: &resource.ResourcePermissions,
+	}}
+	//the new version is the recorded version of the resource itself; the others are versions of its descendants
+	feed := change.Recorded
+	change.Recorded = func(versions []model.PermissionsVersion) {
+		for _, recorded := range versions {
+			if recorded.TopicId == resource.TopicId && recorded.Id == resource.Id {
+				version = recorded.Version
 			}
 		}
+		if feed != nil {
+			feed(versions)
+		}
+	}
+	switch expectedVersion {
+	case 0:
+		err = this.db.SetResource(this.getTimeoutContext(ctx), resource, now, !publish, change)
+	case expectNewResource:
+		err = this.db.SetResourceIfVersion(this.getTimeoutContext(ctx), resource, now, !publish, 0, change)
+	default:
+		err = this.db.SetResourceIfVersion(this.getTimeoutContext(ctx), resource, now, !publish, expectedVersion, change)
+	}
+	if err != nil {
+		return 0, err
+	}
+	this.audit(ctx, now, model.AuditEntry{
+		UserId:              actor,
+		Operation:           operation,
+		TopicId:             resource.TopicId,
+		ResourceId:          resource.Id,
+		PreviousPermissions: previous,
+		NewPermissions:      &resource.ResourcePermissions,
+	})
+	this.triggerOutboxRelay()
+	return version, nil
+}
+
+// writeOptions returns the first options; no options mean no version check
+func writeOptions(options []model.WriteOptions) model.WriteOptions {
+	if len(options) == 0 {
+		return model.WriteOptions{}
 	}
-	return err
+	return options[0]
 }
 
 func (this *Controller) checkEditPermission(token jwt.Token, topicId string, id string, permissions model.ResourcePermissions) (permissionsWithMissingApplied model.ResourcePermissions, err error, code int) {
-	return this.checkEditPermissionWithContext(context.TODO(), token, topicId, id, permissions)
+	return this.checkEditPermissionWithContext(context.TODO(), token, model.Topic{Id: topicId}, id, permissions)
 }
 
-func (this *Controller) checkEditPermissionWithContext(ctx context.Context, token jwt.Token, topicId string, id string, permissions model.ResourcePermissions) (permissionsWithMissingApplied model.ResourcePermissions, err error, code int) {
+// checkEditPermissionWithContext checks the changes of a user, who is no admin, and applies the stored values the user may not remove.
+// a violation of the sharing policy of the topic returns a *model.SharingPolicyError.
+func (this *Controller) checkEditPermissionWithContext(ctx context.Context, token jwt.Token, topic model.Topic, id string, permissions model.ResourcePermissions) (permissionsWithMissingApplied model.ResourcePermissions, err error, code int) {
 	if token.IsAdmin() {
 		return permissions, nil, http.StatusOK
 	}
-	current, err := this.db.GetResource(this.getTimeoutContext(ctx), topicId, id, model.GetOptions{})
+	current, err := this.db.GetResource(this.getTimeoutContext(ctx), topic.Id, id, model.GetOptions{})
 	if err != nil && !errors.Is(err, model.ErrNotFound) {
 		return permissions, err, http.StatusInternalServerError
 	}
+	return this.checkStoredEditPermission(ctx, token, topic, current.ResourcePermissions, permissions, &editCheckCache{})
+}
+
+// editCheckCache holds the parent checks and the user-management response of checkStoredEditPermission,
+// so that the checks of many resources in one request share them
+type editCheckCache struct {
+	parentAccess     map[model.ResourceReference]bool
+	usersInSameGroup []User
+	usersLoaded      bool
+}
+
+// checkStoredEditPermission is checkEditPermissionWithContext for already loaded current permissions (empty for new resources)
+func (this *Controller) checkStoredEditPermission(ctx context.Context, token jwt.Token, topic model.Topic, current model.ResourcePermissions, permissions model.ResourcePermissions, cache *editCheckCache) (permissionsWithMissingApplied model.ResourcePermissions, err error, code int) {
+	if token.IsAdmin() {
+		return permissions, nil, http.StatusOK
+	}
 
 	if permissions.RolePermissions == nil && current.RolePermissions != nil {
 		permissions.RolePermissions = current.RolePermissions
 	}
 
+	//clients unaware of parents must not remove them
+	if permissions.Parent == nil {
+		permissions.Parent = current.Parent
+	}
+	if permissions.HasParent() && (!current.HasParent() || *permissions.Parent != *current.Parent) {
+		access, err, code := this.checkParentAccess(ctx, token, *permissions.Parent, cache)
+		if err != nil {
+			return permissions, err, code
+		}
+		if !access {
+			return permissions, errors.New("missing admin permission on parent"), http.StatusForbidden
+		}
+	}
+
+	//clients unaware of denies must not remove them
+	if permissions.UserDenies == nil {
+		permissions.UserDenies = current.UserDenies
+	}
+	if permissions.GroupDenies == nil {
+		permissions.GroupDenies = current.GroupDenies
+	}
+	if permissions.RoleDenies == nil {
+		permissions.RoleDenies = current.RoleDenies
+	}
+
 	if current.RolePermissions == nil {
 		current.RolePermissions = map[string]model.PermissionsMap{}
 	}
@@ -269,6 +652,15 @@ func (this *Controller) checkEditPermissionWithContext(ctx context.Context, toke
 	if this.config.OnlyAdminsMayEditRolePermissions && !token.IsAdmin() && !reflect.DeepEqual(current.RolePermissions, permissions.RolePermissions) {
 		return permissions, errors.New("only admins may edit role permissions"), http.StatusForbidden
 	}
+	if this.config.OnlyAdminsMayEditRolePermissions && !token.IsAdmin() && len(current.RoleDenies)+len(permissions.RoleDenies) > 0 && !reflect.DeepEqual(current.RoleDenies, permissions.RoleDenies) {
+		return permissions, errors.New("only admins may edit role denies"), http.StatusForbidden
+	}
+	if topic.SharingPolicy != nil {
+		err = topic.SharingPolicy.Check(current, permissions)
+		if err != nil {
+			return permissions, err, http.StatusForbidden
+		}
+	}
 
 	if current.UserPermissions == nil {
 		current.UserPermissions = map[string]model.PermissionsMap{}
@@ -294,7 +686,7 @@ func (this *Controller) checkEditPermissionWithContext(ctx context.Context, toke
 		}
 	}
 	if len(addedUsers) > 0 && this.config.UserManagementUrl != "" && this.config.UserManagementUrl != "-" {
-		usersInSameGroup, err := this.getUsersInSameGroup(token)
+		usersInSameGroup, err := this.getUsersInSameGroupCached(token, cache)
 		if err != nil {
 			return permissions, err, http.StatusInternalServerError
 		}
@@ -310,6 +702,35 @@ func (this *Controller) checkEditPermissionWithContext(ctx context.Context, toke
 	return permissions, nil, http.StatusOK
 }
 
+// checkParentAccess checks the admin permission of the token on the parent; results are cached per parent
+func (this *Controller) checkParentAccess(ctx context.Context, token jwt.Token, parent model.ResourceReference, cache *editCheckCache) (access bool, err error, code int) {
+	if access, ok := cache.parentAccess[parent]; ok {
+		return access, nil, http.StatusOK
+	}
+	access, err, code = this.checkPermission(token, ctx, parent.TopicId, parent.Id, model.Administrate)
+	if err != nil {
+		return access, err, code
+	}
+	if cache.parentAccess == nil {
+		cache.parentAccess = map[model.ResourceReference]bool{}
+	}
+	cache.parentAccess[parent] = access
+	return access, nil, code
+}
+
+// getUsersInSameGroupCached requests the user-management at most once per cache
+func (this *Controller) getUsersInSameGroupCached(token jwt.Token, cache *editCheckCache) (users []User, err error) {
+	if cache.usersLoaded {
+		return cache.usersInSameGroup, nil
+	}
+	users, err = this.getUsersInSameGroup(token)
+	if err != nil {
+		return nil, err
+	}
+	cache.usersInSameGroup, cache.usersLoaded = users, true
+	return users, nil
+}
+
 type User struct {
 	Id   string `json:"id"`
 	Name string `json:"username"`
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestSharingPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	policy := &model.SharingPolicy{
+		MaxUserPermissions: &model.PermissionsMap{Read: true, Execute: true},
+		MaxRolePermissions: &model.PermissionsMap{Read: true},
+		AllowedRoles:       []string{"viewer"},
+	}
+	_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "shared", SharingPolicy: policy})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_, err, _ = ctrl.SetPermission(TestAdminToken, "shared", "r1", model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{
+			TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true},
+			"editor":      {Read: true, Write: true},
+		},
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	expectViolations := func(t *testing.T, err error, code int, expected ...model.SharingPolicyViolation) {
+		t.Helper()
+		if code != http.StatusForbidden || !errors.Is(err, model.ErrSharingPolicyViolation) {
+			t.Errorf("%v %v", code, err)
+			return
+		}
+		var violation *model.SharingPolicyError
+		if !errors.As(err, &violation) {
+			t.Errorf("%T", err)
+			return
+		}
+		if !reflect.DeepEqual(violation.Violations, expected) {
+			t.Errorf("\n%#v\n%#v", violation.Violations, expected)
+		}
+	}
+
+	t.Run("invalid policy", func(t *testing.T) {
+		_, err, code := ctrl.SetTopic(TestAdminToken, model.Topic{Id: "invalid", SharingPolicy: &model.SharingPolicy{MaxUserPermissions: &model.PermissionsMap{Administrate: true}}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "invalid", SharingPolicy: &model.SharingPolicy{MaxGroupPermissions: &model.PermissionsMap{Custom: map[string]bool{"s": true}}}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("allowed grants", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermission(TestToken, "shared", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"editor":      {Read: true, Write: true}, //unchanged entries may exceed the policy
+				"viewer":      {Read: true, Execute: true},
+			},
+			RolePermissions: map[string]model.PermissionsMap{"viewer": {Read: true}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("reduce", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermission(TestToken, "shared", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"editor":      {Read: true},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("exceeding user permissions", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestToken, "shared", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"editor":      {Read: true, Write: true},
+				"other":       {Read: true, Write: true, Administrate: true},
+			},
+		})
+		expectViolations(t, err, code,
+			model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "editor", Permissions: "w"},
+			model.SharingPolicyViolation{Rule: model.SharingRuleAllowAdminDelegation, Kind: model.RuleKindUser, Subject: "other", Permissions: "a"},
+			model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "other", Permissions: "w"},
+		)
+	})
+
+	t.Run("roles and groups", func(t *testing.T) {
+		_, err, code := ctrl.SetPermission(TestToken, "shared", "r1", model.ResourcePermissions{
+			UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+			GroupPermissions: map[string]model.PermissionsMap{"g1": {Read: true}},
+			RolePermissions:  map[string]model.PermissionsMap{"viewer": {Read: true, Write: true}, "developer": {Read: true}},
+		})
+		expectViolations(t, err, code,
+			model.SharingPolicyViolation{Rule: model.SharingRuleAllowGroups, Kind: model.RuleKindGroup, Subject: "g1", Permissions: "r"},
+			model.SharingPolicyViolation{Rule: model.SharingRuleAllowedRoles, Kind: model.RuleKindRole, Subject: "developer", Permissions: "r"},
+			model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindRole, Subject: "viewer", Permissions: "w"},
+		)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		_, err, code := ctrl.PatchPermission(TestToken, "shared", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{"other": {Administrate: true}},
+		})
+		expectViolations(t, err, code, model.SharingPolicyViolation{Rule: model.SharingRuleAllowAdminDelegation, Kind: model.RuleKindUser, Subject: "other", Permissions: "a"})
+	})
+
+	t.Run("bulk", func(t *testing.T) {
+		result, err, _ := ctrl.SetPermissionsBulk(TestToken, "shared", []model.BulkPermissionsItem{
+			{Id: "r1", Permissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner, "other": {Execute: true}}}},
+			{Id: "r1x", Permissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner}}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result[0].Code != http.StatusOK || result[0].Violations != nil {
+			t.Errorf("%#v", result[0])
+		}
+		if result[1].Code != http.StatusForbidden || result[1].Violations != nil {
+			t.Errorf("%#v", result[1])
+		}
+		result, err, _ = ctrl.SetPermissionsBulk(TestToken, "shared", []model.BulkPermissionsItem{
+			{Id: "r1", Permissions: model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner, "other": {Write: true}}}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result[0].Code != http.StatusForbidden || !reflect.DeepEqual(result[0].Violations, []model.SharingPolicyViolation{{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "other", Permissions: "w"}}) {
+			t.Errorf("%#v", result[0])
+		}
+	})
+
+	t.Run("time-bound grants", func(t *testing.T) {
+		until := time.Now().Add(time.Hour).Truncate(time.Second)
+		later := until.Add(time.Hour)
+		_, err, _ := ctrl.SetPermission(TestAdminToken, "shared", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"temp":        {Read: true, Write: true, ValidUntil: &until},
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		earlier := until.Add(-time.Minute)
+		_, err, _ = ctrl.SetPermission(TestToken, "shared", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"temp":        {Read: true, Write: true, ValidUntil: &earlier}, //shortened time frames are allowed
+			},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.SetPermission(TestToken, "shared", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"temp":        {Read: true, Write: true},
+			},
+		})
+		expectViolations(t, err, code, model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "temp", Permissions: "w"})
+		_, err, code = ctrl.SetPermission(TestToken, "shared", "r2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				TestTokenUser: owner,
+				"temp":        {Read: true, Write: true, ValidUntil: &later},
+			},
+		})
+		expectViolations(t, err, code, model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "temp", Permissions: "w"})
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermission(TestAdminToken, "shared", "r1", model.ResourcePermissions{
+			UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner, "other": owner},
+			GroupPermissions: map[string]model.PermissionsMap{"g1": owner},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("creation", func(t *testing.T) {
+		_, err, _ := ctrl.SetTopic(TestAdminToken, model.Topic{
+			Id:             "dashboards",
+			CreationPolicy: &model.ResourceCreationPolicy{Roles: []string{"user"}},
+			SharingPolicy:  &model.SharingPolicy{MaxUserPermissions: &model.PermissionsMap{Read: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err, _ := ctrl.SetPermission(TestToken, "dashboards", "d1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: owner, "other": {Read: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result.UserPermissions[TestTokenUser], owner) || !reflect.DeepEqual(result.UserPermissions["other"], model.PermissionsMap{Read: true}) {
+			t.Errorf("%#v", result)
+		}
+		_, err, code := ctrl.SetPermission(TestToken, "dashboards", "d2", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"other": {Read: true, Execute: true}},
+		})
+		expectViolations(t, err, code, model.SharingPolicyViolation{Rule: model.SharingRuleMaxPermissions, Kind: model.RuleKindUser, Subject: "other", Permissions: "x"})
+	})
+}
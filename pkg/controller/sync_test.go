@@ -28,6 +28,7 @@ import (
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/kafka"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/database"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/tests/docker"
 )
@@ -38,6 +39,16 @@ type MockProducerProvider struct {
 type MockProducer struct {
 	Err      error
 	Produced map[string]map[string][]model.ResourcePermissions
+	Owners   map[string]map[string]string //last owner per kafka topic and resource id
+	Deleted  map[string][]string
+	Batches  []int //sizes of the batches sent with SendPermissionsBatch
+	mux      sync.Mutex
+}
+
+func (this *MockProducer) SetErr(err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.Err = err
 }
 
 func (this *MockProducer) GetProducer(config configuration.Config, topic model.Topic) (result kafka.Producer, err error) {
@@ -48,7 +59,9 @@ func (this *MockProducer) Close() (err error) {
 	return nil
 }
 
-func (this *MockProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions) (err error) {
+func (this *MockProducer) SendPermissions(ctx context.Context, topic model.Topic, id string, permissions model.ResourcePermissions, owner string) (err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
 	if this.Err != nil {
 		return this.Err
 	}
@@ -56,6 +69,53 @@ func (this *MockProducer) SendPermissions(ctx context.Context, topic model.Topic
 		this.Produced[topic.PublishToKafkaTopic] = map[string][]model.ResourcePermissions{}
 	}
 	this.Produced[topic.PublishToKafkaTopic][id] = append(this.Produced[topic.PublishToKafkaTopic][id], permissions)
+	this.setOwner(topic, id, owner)
+	return nil
+}
+
+func (this *MockProducer) setOwner(topic model.Topic, id string, owner string) {
+	if this.Owners == nil {
+		this.Owners = map[string]map[string]string{}
+	}
+	if _, ok := this.Owners[topic.PublishToKafkaTopic]; !ok {
+		this.Owners[topic.PublishToKafkaTopic] = map[string]string{}
+	}
+	this.Owners[topic.PublishToKafkaTopic][id] = owner
+}
+
+func (this *MockProducer) GetOwner(kafkaTopic string, id string) string {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	return this.Owners[kafkaTopic][id]
+}
+
+func (this *MockProducer) SendPermissionsBatch(ctx context.Context, topic model.Topic, resources []model.Resource) (err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.Err != nil {
+		return this.Err
+	}
+	if _, ok := this.Produced[topic.PublishToKafkaTopic]; !ok {
+		this.Produced[topic.PublishToKafkaTopic] = map[string][]model.ResourcePermissions{}
+	}
+	for _, resource := range resources {
+		this.Produced[topic.PublishToKafkaTopic][resource.Id] = append(this.Produced[topic.PublishToKafkaTopic][resource.Id], resource.ResourcePermissions)
+		this.setOwner(topic, resource.Id, resource.Creator)
+	}
+	this.Batches = append(this.Batches, len(resources))
+	return nil
+}
+
+func (this *MockProducer) SendDelete(ctx context.Context, topic model.Topic, id string) (err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	if this.Err != nil {
+		return this.Err
+	}
+	if this.Deleted == nil {
+		this.Deleted = map[string][]string{}
+	}
+	this.Deleted[topic.PublishToKafkaTopic] = append(this.Deleted[topic.PublishToKafkaTopic], id)
 	return nil
 }
 
@@ -73,7 +133,7 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 
 	config.Debug = true
 	config.DevNotifierUrl = ""
-	config.SyncAgeLimit.SetDuration(time.Second)
+	config.SyncCheckInterval.SetDuration(0)
 
 	dockerPort, _, err := docker.MongoDB(ctx, wg)
 	if err != nil {
@@ -99,6 +159,32 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 		return
 	}
 
+	testRetryPublishOfUnsyncedResources(t, ctrl, producer)
+}
+
+func TestRetryPublishOfUnsyncedResourcesWithMock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{
+		Err:      errors.New("test error"),
+		Produced: map[string]map[string][]model.ResourcePermissions{},
+	}
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	testRetryPublishOfUnsyncedResources(t, ctrl, producer)
+}
+
+func testRetryPublishOfUnsyncedResources(t *testing.T, ctrl *Controller, producer *MockProducer) {
+	var err error
+	permissions1 := model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Administrate: true}}}
+	permissions2 := model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Administrate: true, Read: true}}}
+
 	t.Run("create topic", func(t *testing.T) {
 		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{
 			Id:                  "a",
@@ -111,7 +197,12 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 	})
 
 	t.Run("set permissions", func(t *testing.T) {
-		_, err, _ = ctrl.SetPermission(TestAdminToken, "a", "a1", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Administrate: true}}})
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "a", "a1", permissions1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = ctrl.RelayOutbox()
 		if err != nil {
 			t.Error(err)
 			return
@@ -129,10 +220,8 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 		}
 	})
 
-	time.Sleep(2 * time.Second)
-
-	t.Run("check unsynced", func(t *testing.T) {
-		list, err := ctrl.db.ListUnsyncedResources(nil)
+	t.Run("check outbox after failed publish", func(t *testing.T) {
+		list, err := ctrl.db.ListOutboxEvents(nil, 0, 0)
 		if err != nil {
 			t.Error(err)
 			return
@@ -141,34 +230,60 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 			t.Error(list)
 			return
 		}
-		if list[0].Id != "a1" {
-			t.Error(list)
+		if list[0].Id != "a1" || list[0].Attempts != 1 || list[0].LastError != "test error" {
+			t.Errorf("%#v", list[0])
 			return
 		}
 	})
 
-	t.Run("retry", func(t *testing.T) {
-		producer.Err = nil
-		err = ctrl.RetryPublishOfUnsyncedResources()
+	t.Run("update permissions while retry is pending", func(t *testing.T) {
+		producer.SetErr(nil)
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "a", "a1", permissions2)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "a", "a2", permissions1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = ctrl.RelayOutbox()
 		if err != nil {
 			t.Error(err)
 			return
 		}
 	})
 
-	t.Run("check permissions", func(t *testing.T) {
-		access, err, _ := ctrl.CheckPermission(TestToken, "a", "a1", model.Administrate)
+	t.Run("check published while retry is pending", func(t *testing.T) {
+		list, err := ctrl.db.ListOutboxEvents(nil, 0, 0)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		if !access {
-			t.Error("expected access")
+		//both updates of a1 wait for the backoff of the first update; a2 is published
+		if len(list) != 2 || list[0].Id != "a1" || list[1].Id != "a1" {
+			t.Error(list)
+			return
+		}
+		if len(producer.Produced["b"]["a1"]) != 0 || len(producer.Produced["b"]["a2"]) != 1 {
+			t.Error(producer.Produced)
+			return
 		}
 	})
 
-	t.Run("check unsynced", func(t *testing.T) {
-		list, err := ctrl.db.ListUnsyncedResources(nil)
+	time.Sleep(2 * time.Second)
+
+	t.Run("retry", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("check outbox after retry", func(t *testing.T) {
+		list, err := ctrl.db.ListOutboxEvents(nil, 0, 0)
 		if err != nil {
 			t.Error(err)
 			return
@@ -180,21 +295,16 @@ func TestRetryPublishOfUnsyncedResources(t *testing.T) {
 	})
 
 	t.Run("check published", func(t *testing.T) {
-		if !reflect.DeepEqual(producer.Produced, map[string]map[string][]model.ResourcePermissions{
+		expected := map[string]map[string][]model.ResourcePermissions{
 			"b": {
-				"a1": {
-					{
-						UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Administrate: true}},
-						GroupPermissions: map[string]model.PermissionsMap{},
-						RolePermissions:  map[string]model.PermissionsMap{},
-					},
-				},
+				"a1": {permissions1, permissions2},
+				"a2": {permissions1},
 			},
-		}) {
-
+		}
+		if !reflect.DeepEqual(producer.Produced, expected) {
+			t.Errorf("\na:%#v\ne:%#v\n", producer.Produced, expected)
 		}
 	})
-
 }
 
 func TestPermissionsSetAndCheck(t *testing.T) {
@@ -300,42 +410,42 @@ func TestPermissionsSetAndCheck(t *testing.T) {
 	t.Run("check topics after init", func(t *testing.T) {
 		t.Run("set permission", func(t *testing.T) {
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic1", "a1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic2", "b1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic3", "c1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic4", "d1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic5", "e1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic6", "f1", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
@@ -408,7 +518,7 @@ func TestPermissionsSetAndCheck(t *testing.T) {
 			t.Error(err)
 			return
 		}
-		err = db.DeleteTopic(ctrl.getTimeoutContext(), "topic3")
+		err = db.DeleteTopic(ctrl.getTimeoutContext(), model.Topic{Id: "topic3"}, time.Now(), true)
 		if err != nil {
 			t.Error(err)
 			return
@@ -441,7 +551,7 @@ func TestPermissionsSetAndCheck(t *testing.T) {
 			return
 		}
 
-		err = db.DeleteTopic(ctrl.getTimeoutContext(), "topic6")
+		err = db.DeleteTopic(ctrl.getTimeoutContext(), model.Topic{Id: "topic6"}, time.Now(), true)
 		if err != nil {
 			t.Error(err)
 			return
@@ -461,56 +571,56 @@ func TestPermissionsSetAndCheck(t *testing.T) {
 	t.Run("check topics after update", func(t *testing.T) {
 		t.Run("set permission", func(t *testing.T) {
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic1", "a2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic2", "b2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic3_2", "c2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic3", "c2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err == nil {
 				t.Error("expected error")
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic4", "d2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic5", "e2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic6", "f2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err == nil {
 				t.Error("expected error")
 				return
 			}
 			_, err, _ = ctrl.SetPermission(TestAdminToken, "topic6_2", "f2", model.ResourcePermissions{
-				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+				UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
@@ -599,3 +709,84 @@ const TestTokenUser = "testOwner"
 const TestToken = `Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJqdGkiOiIwOGM0N2E4OC0yYzc5LTQyMGYtODEwNC02NWJkOWViYmU0MWUiLCJleHAiOjE1NDY1MDcyMzMsIm5iZiI6MCwiaWF0IjoxNTQ2NTA3MTczLCJpc3MiOiJodHRwOi8vbG9jYWxob3N0OjgwMDEvYXV0aC9yZWFsbXMvbWFzdGVyIiwiYXVkIjoiZnJvbnRlbmQiLCJzdWIiOiJ0ZXN0T3duZXIiLCJ0eXAiOiJCZWFyZXIiLCJhenAiOiJmcm9udGVuZCIsIm5vbmNlIjoiOTJjNDNjOTUtNzViMC00NmNmLTgwYWUtNDVkZDk3M2I0YjdmIiwiYXV0aF90aW1lIjoxNTQ2NTA3MDA5LCJzZXNzaW9uX3N0YXRlIjoiNWRmOTI4ZjQtMDhmMC00ZWI5LTliNjAtM2EwYWUyMmVmYzczIiwiYWNyIjoiMCIsImFsbG93ZWQtb3JpZ2lucyI6WyIqIl0sInJlYWxtX2FjY2VzcyI6eyJyb2xlcyI6WyJ1c2VyIl19LCJyZXNvdXJjZV9hY2Nlc3MiOnsibWFzdGVyLXJlYWxtIjp7InJvbGVzIjpbInZpZXctcmVhbG0iLCJ2aWV3LWlkZW50aXR5LXByb3ZpZGVycyIsIm1hbmFnZS1pZGVudGl0eS1wcm92aWRlcnMiLCJpbXBlcnNvbmF0aW9uIiwiY3JlYXRlLWNsaWVudCIsIm1hbmFnZS11c2VycyIsInF1ZXJ5LXJlYWxtcyIsInZpZXctYXV0aG9yaXphdGlvbiIsInF1ZXJ5LWNsaWVudHMiLCJxdWVyeS11c2VycyIsIm1hbmFnZS1ldmVudHMiLCJtYW5hZ2UtcmVhbG0iLCJ2aWV3LWV2ZW50cyIsInZpZXctdXNlcnMiLCJ2aWV3LWNsaWVudHMiLCJtYW5hZ2UtYXV0aG9yaXphdGlvbiIsIm1hbmFnZS1jbGllbnRzIiwicXVlcnktZ3JvdXBzIl19LCJhY2NvdW50Ijp7InJvbGVzIjpbIm1hbmFnZS1hY2NvdW50IiwibWFuYWdlLWFjY291bnQtbGlua3MiLCJ2aWV3LXByb2ZpbGUiXX19LCJyb2xlcyI6WyJ1c2VyIl19.ykpuOmlpzj75ecSI6cHbCATIeY4qpyut2hMc1a67Ycg`
 
 const TestAdminToken = `Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.eyJleHAiOjEwMDAwMDAwMDAsImlhdCI6MTAwMDAwMDAwMCwiYXV0aF90aW1lIjoxMDAwMDAwMDAwLCJpc3MiOiJpbnRlcm5hbCIsImF1ZCI6W10sInN1YiI6ImRkNjllYTBkLWY1NTMtNDMzNi04MGYzLTdmNDU2N2Y4NWM3YiIsInR5cCI6IkJlYXJlciIsImF6cCI6ImZyb250ZW5kIiwicmVhbG1fYWNjZXNzIjp7InJvbGVzIjpbImFkbWluIiwiZGV2ZWxvcGVyIiwidXNlciJdfSwicmVzb3VyY2VfYWNjZXNzIjp7Im1hc3Rlci1yZWFsbSI6eyJyb2xlcyI6W119LCJCYWNrZW5kLXJlYWxtIjp7InJvbGVzIjpbXX0sImFjY291bnQiOnsicm9sZXMiOltdfX0sInJvbGVzIjpbImFkbWluIiwiZGV2ZWxvcGVyIiwidXNlciJdLCJuYW1lIjoiU2VwbCBBZG1pbiIsInByZWZlcnJlZF91c2VybmFtZSI6InNlcGwiLCJnaXZlbl9uYW1lIjoiU2VwbCIsImxvY2FsZSI6ImVuIiwiZmFtaWx5X25hbWUiOiJBZG1pbiIsImVtYWlsIjoic2VwbEBzZXBsLmRlIn0.HZyG6n-BfpnaPAmcDoSEh0SadxUx-w4sEt2RVlQ9e5I`
+
+func TestPublishDeletions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	producer := &MockProducer{
+		Produced: map[string]map[string][]model.ResourcePermissions{},
+		Deleted:  map[string][]string{},
+	}
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), producer)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("create topics", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "a", PublishToKafkaTopic: "ka"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "b", PublishToKafkaTopic: "kb"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "c"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("set permissions", func(t *testing.T) {
+		for _, topic := range []string{"a", "b", "c"} {
+			for _, id := range []string{"1", "2", "3"} {
+				_, err, _ = ctrl.SetPermission(TestAdminToken, topic, id, model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Administrate: true}}})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}
+	})
+
+	t.Run("remove resources", func(t *testing.T) {
+		for _, topic := range []string{"a", "c"} {
+			err, _ = ctrl.RemoveResource(TestAdminToken, topic, "2")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("remove topics", func(t *testing.T) {
+		for _, topic := range []string{"b", "c"} {
+			err, _ = ctrl.RemoveTopic(TestAdminToken, topic)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("check deletions", func(t *testing.T) {
+		err = ctrl.RelayOutbox()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string][]string{
+			"ka": {"2"},
+			"kb": {"1", "2", "3"},
+		}
+		if !reflect.DeepEqual(producer.Deleted, expected) {
+			t.Errorf("\na:%#v\ne:%#v\n", producer.Deleted, expected)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestTopicAdmins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	//TestTokenUser administrates dashboards by user id and processes by role, but not devices
+	topics := []model.Topic{
+		{Id: "dashboards", AdminUsers: []string{TestTokenUser}},
+		{Id: "processes", AdminRoles: []string{"user"}},
+		{Id: "devices"},
+	}
+	for _, topic := range topics {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, topic)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	//resources without permissions of TestTokenUser
+	other := model.ResourcePermissions{
+		UserPermissions: map[string]model.PermissionsMap{"other": {Read: true, Write: true, Execute: true, Administrate: true}},
+	}
+	for _, topic := range []string{"dashboards", "processes", "devices"} {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, topic, "r1", other)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	t.Run("topic config", func(t *testing.T) {
+		topic, err, _ := ctrl.GetTopic(TestToken, "dashboards")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		topic.CustomPermissions = []model.CustomPermission{{Letter: "s", Description: "share"}}
+		topic.AdminUsers = append(topic.AdminUsers, "colleague")
+		_, err, _ = ctrl.SetTopic(TestToken, topic)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		stored, err, _ := ctrl.GetTopic(TestAdminToken, "dashboards")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(stored.CustomPermissions) != 1 || !slices.Equal(stored.AdminUsers, []string{TestTokenUser, "colleague"}) {
+			t.Errorf("%#v", stored)
+		}
+
+		_, err, code := ctrl.SetTopic(TestToken, model.Topic{Id: "devices", AdminUsers: []string{TestTokenUser}})
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.GetTopic(TestToken, "devices")
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetTopic(TestToken, model.Topic{Id: "new", AdminUsers: []string{TestTokenUser}})
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		topic = stored
+		topic.PublishToKafkaTopic = "dashboards"
+		_, err, code = ctrl.SetTopic(TestToken, topic)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		err, code = ctrl.RemoveTopic(TestToken, "dashboards")
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "invalid", AdminRoles: []string{""}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for _, topic := range []string{"dashboards", "processes"} {
+			ids, err, _ := ctrl.AdminListResourceIds(TestToken, topic, model.ListOptions{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !slices.Equal(ids, []string{"r1"}) {
+				t.Error(topic, ids)
+			}
+			count, err, _ := ctrl.AdminCountResourceIds(TestToken, topic, model.ListOptions{})
+			if err != nil || count != 1 {
+				t.Error(topic, count, err)
+			}
+		}
+		_, err, code := ctrl.AdminListResourceIds(TestToken, "devices", model.ListOptions{})
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("fix permissions", func(t *testing.T) {
+		resource, err, _ := ctrl.GetResource(TestToken, "dashboards", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource.UserPermissions["new-owner"] = model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+		_, err, _ = ctrl.SetPermission(TestToken, "dashboards", "r1", resource.ResourcePermissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.PatchPermission(TestToken, "processes", "r1", model.PermissionsPatch{
+			UserPermissions: map[string]*model.PermissionsMap{"new-owner": {Read: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		access, err, _ := ctrl.CheckPermission(TestToken, "dashboards", "r1", model.Read)
+		if err != nil || access {
+			t.Error("topic admins should not gain resource permissions", access, err)
+		}
+		_, err, code := ctrl.GetResource(TestToken, "devices", "r1")
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetPermission(TestToken, "devices", "r1", other)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("export and import", func(t *testing.T) {
+		export, err, _ := ctrl.Export(TestToken, model.ImportExportOptions{IncludeTopicConfig: true, IncludePermissions: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(export.Topics) != 2 || len(export.Permissions) != 2 || export.Topics[0].Id != "dashboards" || export.Topics[1].Id != "processes" {
+			t.Errorf("%#v", export)
+		}
+		_, err, code := ctrl.Export(TestToken, model.ImportExportOptions{IncludePermissions: true, FilterTopics: []string{"dashboards", "devices"}})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+
+		export.Permissions[0].UserPermissions["imported"] = model.PermissionsMap{Read: true}
+		err, _ = ctrl.Import(TestToken, export, model.ImportExportOptions{IncludeTopicConfig: true, IncludePermissions: true})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource, err, _ := ctrl.GetResource(TestAdminToken, "dashboards", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if _, ok := resource.UserPermissions["imported"]; !ok {
+			t.Errorf("%#v", resource)
+		}
+
+		err, code = ctrl.Import(TestToken, model.ImportExport{Permissions: []model.Resource{{Id: "r2", TopicId: "devices", ResourcePermissions: other}}}, model.ImportExportOptions{IncludePermissions: true})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err, _ := ctrl.RemoveResource(TestToken, "dashboards", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, code := ctrl.RemoveResource(TestToken, "devices", "r1")
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		ids, err, _ := ctrl.AdminListResourceIds(TestAdminToken, "dashboards", model.ListOptions{})
+		if err != nil || len(ids) != 0 {
+			t.Error(ids, err)
+		}
+	})
+}
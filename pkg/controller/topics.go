@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/SENERGY-Platform/developer-notifications/pkg/client"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
@@ -32,13 +33,17 @@ func (this *Controller) ListTopics(tokenStr string, options model.ListOptions) (
 }
 
 func (this *Controller) ListTopicsContext(ctx context.Context, tokenStr string, options model.ListOptions) (result []model.Topic, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeTopicAdmin)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
+		return result, err, code
 	}
 	if !token.IsAdmin() {
 		return result, errors.New("only admins may manage topics"), http.StatusUnauthorized
 	}
+	err = options.Validate()
+	if err != nil {
+		return result, err, http.StatusBadRequest
+	}
 	timeout := this.getTimeoutContext(ctx)
 	result, err = this.db.ListTopics(timeout, options)
 	if err != nil {
@@ -52,12 +57,9 @@ func (this *Controller) GetTopic(tokenStr string, id string) (result model.Topic
 }
 
 func (this *Controller) GetTopicContext(ctx context.Context, tokenStr string, id string) (result model.Topic, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeTopicAdmin, id)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
-	}
-	if !token.IsAdmin() {
-		return result, errors.New("only admins may manage topics"), http.StatusUnauthorized
+		return result, err, code
 	}
 	timeout := this.getTimeoutContext(ctx)
 	var exists bool
@@ -65,6 +67,9 @@ func (this *Controller) GetTopicContext(ctx context.Context, tokenStr string, id
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	if !isTopicAdmin(token, result) {
+		return model.Topic{}, errors.New("only admins may manage topics"), http.StatusUnauthorized
+	}
 	if !exists {
 		return result, errors.New("topic does not exist"), http.StatusNotFound
 	}
@@ -76,9 +81,9 @@ func (this *Controller) RemoveTopic(tokenStr string, id string) (err error, code
 }
 
 func (this *Controller) RemoveTopicContext(ctx context.Context, tokenStr string, id string) (err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeTopicAdmin, id)
 	if err != nil {
-		return err, http.StatusUnauthorized
+		return err, code
 	}
 	if !token.IsAdmin() {
 		return errors.New("only admins may manage topics"), http.StatusUnauthorized
@@ -94,11 +99,39 @@ func (this *Controller) RemoveTopicContext(ctx context.Context, tokenStr string,
 		this.config.GetLogger().ErrorContext(ctx, "unable to send notification", "error", err)
 	}
 
-	timeout := this.getTimeoutContext(ctx)
-	err = this.db.DeleteTopic(timeout, id)
+	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), id)
 	if err != nil {
 		return err, http.StatusInternalServerError
 	}
+	if !exists {
+		topic = model.Topic{Id: id}
+	}
+	publish := topic.PublishesToKafka()
+
+	now := time.Now()
+	change := this.newChange(token.GetUserId(), model.AuditOperationRemoveTopic)
+	if exists {
+		change.Webhooks = []model.WebhookPayload{{
+			Event:     model.WebhookEventRemoveTopic,
+			Timestamp: now.UnixMilli(),
+			UserId:    token.GetUserId(),
+			TopicId:   topic.Id,
+		}}
+	}
+	err = this.db.DeleteTopic(this.getTimeoutContext(ctx), topic, now, !publish, change)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	if exists {
+		this.audit(ctx, now, model.AuditEntry{
+			UserId:        token.GetUserId(),
+			Operation:     model.AuditOperationRemoveTopic,
+			TopicId:       topic.Id,
+			PreviousTopic: &topic,
+		})
+	}
+	//children of resources in the topic may be published, even if the topic is not; webhooks are notified by the outbox relay
+	this.triggerOutboxRelay()
 	return nil, http.StatusOK
 }
 
@@ -107,28 +140,38 @@ func (this *Controller) SetTopic(tokenStr string, topic model.Topic) (result mod
 }
 
 func (this *Controller) SetTopicContext(ctx context.Context, tokenStr string, topic model.Topic) (result model.Topic, err error, code int) {
-	token, err := jwt.Parse(tokenStr)
+	token, err, code := this.authenticate(ctx, tokenStr, model.ServiceCredentialScopeTopicAdmin, topic.Id)
 	if err != nil {
-		return result, err, http.StatusUnauthorized
-	}
-	if !token.IsAdmin() {
-		return result, errors.New("only admins may manage topics"), http.StatusUnauthorized
+		return result, err, code
 	}
+	return this.setTopic(ctx, token, topic, model.AuditOperationSetTopic)
+}
 
+// setTopic stores the topic; delegated topic admins may update existing topics, but only platform admins may create topics or change their kafka settings
+func (this *Controller) setTopic(ctx context.Context, token jwt.Token, topic model.Topic, operation model.AuditOperation) (result model.Topic, err error, code int) {
 	if topic.Id == "" {
 		topic.Id = topic.PublishToKafkaTopic
 	}
 
-	err = topic.Validate()
-	if err != nil {
-		return result, fmt.Errorf("invalid topic: %w", err), http.StatusBadRequest
-	}
-
 	timeout := this.getTimeoutContext(ctx)
 	old, exists, err := this.db.GetTopic(timeout, topic.Id)
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	if !token.IsAdmin() {
+		if !exists || !isTopicAdmin(token, old) {
+			return result, errors.New("only admins may manage topics"), http.StatusUnauthorized
+		}
+		if !old.KafkaSettingsEqual(topic) {
+			return result, errors.New("only platform admins may change the kafka settings of a topic"), http.StatusForbidden
+		}
+	}
+
+	err = topic.Validate()
+	if err != nil {
+		return result, fmt.Errorf("invalid topic: %w", err), http.StatusBadRequest
+	}
+
 	if exists && old.Equal(topic) {
 		return old, nil, http.StatusAccepted
 	}
@@ -143,10 +186,49 @@ func (this *Controller) SetTopicContext(ctx context.Context, tokenStr string, to
 		this.config.GetLogger().ErrorContext(ctx, "unable to send notification", "error", err)
 	}
 
-	err = this.db.SetTopic(timeout, topic)
+	now := time.Now()
+	err = this.db.SetTopic(timeout, topic, model.Change{
+		UserId:    token.GetUserId(),
+		Operation: operation,
+		Webhooks: []model.WebhookPayload{{
+			Event:     model.WebhookEventSetTopic,
+			Timestamp: now.UnixMilli(),
+			UserId:    token.GetUserId(),
+			TopicId:   topic.Id,
+			Topic:     &topic,
+		}},
+	})
 	if err != nil {
 		return result, err, http.StatusInternalServerError
 	}
+	entry := model.AuditEntry{
+		UserId:    token.GetUserId(),
+		Operation: operation,
+		TopicId:   topic.Id,
+		NewTopic:  &topic,
+	}
+	if exists {
+		entry.PreviousTopic = &old
+	}
+	this.audit(ctx, now, entry)
+	this.triggerOutboxRelay()
 
 	return topic, nil, http.StatusOK
 }
+
+// isTopicAdmin returns true for platform admins and delegated admins of the topic (model.Topic.AdminUsers, AdminRoles and AdminGroups)
+func isTopicAdmin(token jwt.Token, topic model.Topic) bool {
+	return token.IsAdmin() || topic.IsTopicAdmin(token.GetUserId(), token.GetRoles(), token.GetGroups())
+}
+
+// checkTopicAdmin is isTopicAdmin for a topic id; unknown topics have no delegated admins
+func (this *Controller) checkTopicAdmin(ctx context.Context, token jwt.Token, topicId string) (bool, error) {
+	if token.IsAdmin() {
+		return true, nil
+	}
+	topic, exists, err := this.db.GetTopic(this.getTimeoutContext(ctx), topicId)
+	if err != nil || !exists {
+		return false, err
+	}
+	return isTopicAdmin(token, topic), nil
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/auth"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+func TestTokenVerification(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kid": "key",
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	sign := func(sub string, roles ...string) string {
+		token := jwtv5.NewWithClaims(jwtv5.SigningMethodRS256, jwtv5.MapClaims{
+			"sub":          sub,
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"iss":          "issuer",
+			"realm_access": map[string]interface{}{"roles": roles},
+		})
+		token.Header["kid"] = "key"
+		result, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + result
+	}
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{JwksUrl: jwks.URL, JwtIssuer: "issuer"}, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	adminToken := sign("admin", "admin")
+	userToken := sign(TestTokenUser, "user")
+
+	t.Run("unverified admin token", func(t *testing.T) {
+		_, err, code := ctrl.SetTopic(TestAdminToken, model.Topic{Id: "verified"})
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("verified admin token", func(t *testing.T) {
+		_, err, _ := ctrl.SetTopic(adminToken, model.Topic{Id: "verified"})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unverified admin token from trusted network", func(t *testing.T) {
+		_, err, _ := ctrl.SetPermissionContext(auth.WithTrustedNetwork(ctx), TestAdminToken, "verified", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unverified user token", func(t *testing.T) {
+		_, err, code := ctrl.CheckPermission(TestToken, "verified", "r1", model.Read)
+		if err == nil || code != http.StatusUnauthorized {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("verified user token", func(t *testing.T) {
+		access, err, _ := ctrl.CheckPermission(userToken, "verified", "r1", model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("expected access")
+		}
+	})
+}
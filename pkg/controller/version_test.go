@@ -0,0 +1,156 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestResourceVersions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctrl, err := NewWithDependencies(ctx, configuration.Config{}, mock.New(), &MockProducer{Produced: map[string]map[string][]model.ResourcePermissions{}})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: owner},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+
+	getVersion := func(id string) int64 {
+		resource, err, _ := ctrl.GetResource(TestToken, "versions", id)
+		if err != nil {
+			t.Error(err)
+		}
+		return resource.Version
+	}
+
+	t.Run("init", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "versions"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "versions", "r1", permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("r1"); version != 1 {
+			t.Errorf("expected version 1, got %v", version)
+		}
+	})
+
+	t.Run("set", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestToken, "versions", "r1", permissions, model.WriteOptions{ExpectedVersion: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.SetPermission(TestToken, "versions", "r1", permissions, model.WriteOptions{ExpectedVersion: 1})
+		if !errors.Is(err, model.ErrVersionMismatch) || code != http.StatusPreconditionFailed {
+			t.Errorf("expected precondition failed, got %v %v", err, code)
+		}
+		//without expected version, writes are unconditional
+		_, err, _ = ctrl.SetPermission(TestToken, "versions", "r1", permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("r1"); version != 3 {
+			t.Errorf("expected version 3, got %v", version)
+		}
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		patch := model.PermissionsPatch{RolePermissions: map[string]*model.PermissionsMap{"user": {Read: true}}}
+		_, err, code := ctrl.PatchPermission(TestToken, "versions", "r1", patch, model.WriteOptions{ExpectedVersion: 2})
+		if code != http.StatusPreconditionFailed {
+			t.Errorf("expected precondition failed, got %v %v", err, code)
+		}
+		_, err, _ = ctrl.PatchPermission(TestToken, "versions", "r1", patch, model.WriteOptions{ExpectedVersion: 3})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("r1"); version != 4 {
+			t.Errorf("expected version 4, got %v", version)
+		}
+	})
+
+	t.Run("inherited changes", func(t *testing.T) {
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "versions", "child", model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "versions", Id: "r1"}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestToken, "versions", "r1", permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("child"); version != 2 {
+			t.Errorf("expected version 2, got %v", version)
+		}
+		versions, err, _ := ctrl.ListPermissionVersions(TestAdminToken, "versions", "child", model.ListOptions{Limit: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 1 || versions[0].Version != 2 || versions[0].Operation != model.AuditOperationInheritedChange || versions[0].UserId != TestTokenUser || versions[0].EffectivePermissions == nil {
+			t.Errorf("%#v", versions)
+		}
+		//unchanged inherited permissions keep the version
+		_, err, _ = ctrl.SetPermission(TestToken, "versions", "r1", permissions)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("child"); version != 2 {
+			t.Errorf("expected version 2, got %v", version)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err, code := ctrl.RemoveResource(TestAdminToken, "versions", "r1", model.WriteOptions{ExpectedVersion: 5})
+		if code != http.StatusPreconditionFailed {
+			t.Errorf("expected precondition failed, got %v %v", err, code)
+		}
+		err, _ = ctrl.RemoveResource(TestAdminToken, "versions", "r1", model.WriteOptions{ExpectedVersion: 6})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, code = ctrl.RemoveResource(TestAdminToken, "versions", "r1", model.WriteOptions{ExpectedVersion: 6})
+		if code != http.StatusPreconditionFailed {
+			t.Errorf("expected precondition failed for removed resource, got %v %v", err, code)
+		}
+	})
+}
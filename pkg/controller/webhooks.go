@@ -0,0 +1,414 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"github.com/google/uuid"
+)
+
+const webhookBatchSize = 100
+const webhookLeaseDuration = 5 * time.Minute
+const defaultWebhookTimeout = 10 * time.Second
+const defaultWebhookMaxAttempts = 10
+
+func (this *Controller) ListWebhooks(tokenStr string) (result []model.Webhook, err error, code int) {
+	return this.ListWebhooksContext(context.TODO(), tokenStr)
+}
+
+func (this *Controller) ListWebhooksContext(ctx context.Context, tokenStr string) (result []model.Webhook, err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.ListWebhooks(this.getTimeoutContext(ctx))
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	for i := range result {
+		result[i].Secret = ""
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) GetWebhook(tokenStr string, id string) (result model.Webhook, err error, code int) {
+	return this.GetWebhookContext(context.TODO(), tokenStr, id)
+}
+
+func (this *Controller) GetWebhookContext(ctx context.Context, tokenStr string, id string) (result model.Webhook, err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.GetWebhook(this.getTimeoutContext(ctx), id)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	result.Secret = ""
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) SetWebhook(tokenStr string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	return this.SetWebhookContext(context.TODO(), tokenStr, webhook)
+}
+
+// SetWebhookContext creates (empty id) or updates a webhook; an empty secret keeps the stored secret
+func (this *Controller) SetWebhookContext(ctx context.Context, tokenStr string, webhook model.Webhook) (result model.Webhook, err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	if webhook.Id == "" {
+		webhook.Id = uuid.NewString()
+	} else if webhook.Secret == "" {
+		existing, err := this.db.GetWebhook(this.getTimeoutContext(ctx), webhook.Id)
+		if err != nil && !errors.Is(err, model.ErrNotFound) {
+			return result, err, http.StatusInternalServerError
+		}
+		webhook.Secret = existing.Secret
+	}
+	err = webhook.Validate()
+	if err != nil {
+		return result, fmt.Errorf("invalid webhook: %w", err), http.StatusBadRequest
+	}
+	err = this.db.SetWebhook(this.getTimeoutContext(ctx), webhook)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	webhook.Secret = ""
+	return webhook, nil, http.StatusOK
+}
+
+func (this *Controller) RemoveWebhook(tokenStr string, id string) (err error, code int) {
+	return this.RemoveWebhookContext(context.TODO(), tokenStr, id)
+}
+
+// RemoveWebhookContext removes the webhook and its deliveries
+func (this *Controller) RemoveWebhookContext(ctx context.Context, tokenStr string, id string) (err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return err, code
+	}
+	err = this.db.RemoveWebhook(this.getTimeoutContext(ctx), id)
+	if err != nil {
+		return err, http.StatusInternalServerError
+	}
+	return nil, http.StatusOK
+}
+
+func (this *Controller) ListWebhookDeliveries(tokenStr string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	return this.ListWebhookDeliveriesContext(context.TODO(), tokenStr, webhookId, options)
+}
+
+func (this *Controller) ListWebhookDeliveriesContext(ctx context.Context, tokenStr string, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.ListWebhookDeliveries(this.getTimeoutContext(ctx), webhookId, options)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) RetryWebhookDelivery(tokenStr string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	return this.RetryWebhookDeliveryContext(context.TODO(), tokenStr, webhookId, deliveryId)
+}
+
+// RetryWebhookDeliveryContext schedules a new attempt of a failed delivery; the attempts start again at 0
+func (this *Controller) RetryWebhookDeliveryContext(ctx context.Context, tokenStr string, webhookId string, deliveryId string) (result model.WebhookDelivery, err error, code int) {
+	err, code = this.checkWebhookAdmin(ctx, tokenStr)
+	if err != nil {
+		return result, err, code
+	}
+	result, err = this.db.GetWebhookDelivery(this.getTimeoutContext(ctx), webhookId, deliveryId)
+	if errors.Is(err, model.ErrNotFound) {
+		return result, err, http.StatusNotFound
+	}
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	if result.Status != model.WebhookDeliveryFailed {
+		return result, errors.New("only failed deliveries may be retried"), http.StatusBadRequest
+	}
+	result.Status = model.WebhookDeliveryPending
+	result.Attempts = 0
+	result.NextAttempt = time.Now().UnixMilli()
+	err = this.db.UpdateWebhookDelivery(this.getTimeoutContext(ctx), result)
+	if err != nil {
+		return result, err, http.StatusInternalServerError
+	}
+	this.triggerWebhookDispatch()
+	return result, nil, http.StatusOK
+}
+
+func (this *Controller) checkWebhookAdmin(ctx context.Context, tokenStr string) (err error, code int) {
+	token, err, code := this.authenticate(ctx, tokenStr, "")
+	if err != nil {
+		return err, code
+	}
+	if !token.IsAdmin() {
+		return errors.New("only admins may manage webhooks"), http.StatusForbidden
+	}
+	return nil, http.StatusOK
+}
+
+// newWebhookList returns a function, which lists the webhooks once; used to create the deliveries of all outbox events of a relay run
+func (this *Controller) newWebhookList(ctx context.Context) func() ([]model.Webhook, error) {
+	var webhooks []model.Webhook
+	loaded := false
+	return func() (result []model.Webhook, err error) {
+		if !loaded {
+			webhooks, err = this.db.ListWebhooks(this.getTimeoutContext(ctx))
+			if err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+		return webhooks, nil
+	}
+}
+
+// createWebhookDeliveries creates a delivery of the payload of the outbox event for every matching webhook.
+// the delivery ids are derived from the event, so that a repeated relay of the event creates no duplicates.
+func (this *Controller) createWebhookDeliveries(ctx context.Context, event model.OutboxEvent, webhooks []model.Webhook) error {
+	if event.Webhook == nil {
+		this.config.GetLogger().ErrorContext(ctx, "webhook outbox event without payload; event will be dropped", "seq", event.Seq)
+		return nil
+	}
+	payload := *event.Webhook
+	deliveries := []model.WebhookDelivery{}
+	for _, webhook := range webhooks {
+		if webhook.Matches(payload.Event, payload.TopicId) {
+			deliveries = append(deliveries, model.WebhookDelivery{
+				Id:          fmt.Sprintf("%v-%v", event.Seq, webhook.Id),
+				WebhookId:   webhook.Id,
+				Payload:     payload,
+				Status:      model.WebhookDeliveryPending,
+				CreatedAt:   payload.Timestamp,
+				NextAttempt: payload.Timestamp,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := this.db.AddWebhookDeliveries(this.getTimeoutContext(ctx), deliveries)
+	if err != nil {
+		return err
+	}
+	this.triggerWebhookDispatch()
+	return nil
+}
+
+// StartWebhookDispatcher sends due webhook deliveries after local changes and every config.WebhookCheckInterval
+func (this *Controller) StartWebhookDispatcher(ctx context.Context) {
+	var ticker <-chan time.Time
+	if dur := this.config.WebhookCheckInterval.GetDuration(); dur > 0 {
+		t := time.NewTicker(dur)
+		ticker = t.C
+		go func() {
+			<-ctx.Done()
+			t.Stop()
+		}()
+	}
+	go func() {
+		for {
+			err := this.DispatchWebhooksContext(ctx)
+			if err != nil {
+				this.config.GetLogger().WarnContext(ctx, "unable to dispatch webhooks", "error", err)
+			}
+			select {
+			case <-ticker:
+			case <-this.webhookTrigger:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (this *Controller) triggerWebhookDispatch() {
+	select {
+	case this.webhookTrigger <- struct{}{}:
+	default:
+		//dispatch is already triggered
+	}
+}
+
+func (this *Controller) DispatchWebhooks() error {
+	return this.DispatchWebhooksContext(context.TODO())
+}
+
+// DispatchWebhooksContext sends all due deliveries and removes finished deliveries older than config.WebhookDeliveryRetention.
+// failed attempts are retried with backoff, until config.WebhookMaxAttempts is reached.
+// after a failed attempt, the other due deliveries of the webhook are postponed without attempt, so that an unreachable endpoint does not delay the other webhooks.
+func (this *Controller) DispatchWebhooksContext(ctx context.Context) error {
+	this.webhookMux.Lock()
+	defer this.webhookMux.Unlock()
+
+	leaseStart := time.Now()
+	ok, err := this.db.AcquireWebhookLease(this.getTimeoutContext(ctx), this.outboxRelayId, webhookLeaseDuration)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		//webhooks are dispatched by another instance
+		return nil
+	}
+
+	if retention := this.config.WebhookDeliveryRetention.GetDuration(); retention > 0 {
+		err = this.db.RemoveWebhookDeliveriesBefore(this.getTimeoutContext(ctx), time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+	}
+
+	webhooks := map[string]*model.Webhook{}
+	postponed := map[string]int64{} //webhook id -> next attempt after a failed attempt in this run
+	for {
+		deliveries, err := this.db.ListDueWebhookDeliveries(this.getTimeoutContext(ctx), time.Now(), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			if time.Since(leaseStart) > webhookLeaseDuration/2 {
+				leaseStart = time.Now()
+				ok, err = this.db.AcquireWebhookLease(this.getTimeoutContext(ctx), this.outboxRelayId, webhookLeaseDuration)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return nil
+				}
+			}
+			webhook, known := webhooks[delivery.WebhookId]
+			if !known {
+				temp, err := this.db.GetWebhook(this.getTimeoutContext(ctx), delivery.WebhookId)
+				if err != nil && !errors.Is(err, model.ErrNotFound) {
+					return err
+				}
+				if err == nil {
+					webhook = &temp
+				}
+				webhooks[delivery.WebhookId] = webhook
+			}
+			if nextAttempt, failed := postponed[delivery.WebhookId]; failed {
+				delivery.NextAttempt = nextAttempt
+			} else {
+				delivery = this.deliverWebhook(ctx, webhook, delivery)
+				if webhook != nil && delivery.Status != model.WebhookDeliveryDelivered {
+					postponed[delivery.WebhookId] = this.getWebhookRetryTime(delivery)
+				}
+			}
+			err = this.db.UpdateWebhookDelivery(this.getTimeoutContext(ctx), delivery)
+			if err != nil && !errors.Is(err, model.ErrNotFound) {
+				return err
+			}
+		}
+		if len(deliveries) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// getWebhookRetryTime returns the time (unix milliseconds) after which the endpoint of the failed delivery is attempted again
+func (this *Controller) getWebhookRetryTime(delivery model.WebhookDelivery) int64 {
+	if delivery.Status == model.WebhookDeliveryPending {
+		return delivery.NextAttempt
+	}
+	return time.Now().Add(getBackoff(1, this.config.WebhookMaxBackoff.GetDuration())).UnixMilli()
+}
+
+// deliverWebhook sends the payload of the delivery and returns the delivery with the result of the attempt
+func (this *Controller) deliverWebhook(ctx context.Context, webhook *model.Webhook, delivery model.WebhookDelivery) model.WebhookDelivery {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttempt = now.UnixMilli()
+	delivery.LastStatusCode = 0
+	if webhook == nil {
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.LastError = "unknown webhook"
+		return delivery
+	}
+
+	code, err := this.sendWebhook(ctx, *webhook, delivery, now)
+	delivery.LastStatusCode = code
+	if err == nil {
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		return delivery
+	}
+	delivery.LastError = err.Error()
+	maxAttempts := int64(this.config.WebhookMaxAttempts)
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+	if delivery.Attempts >= maxAttempts {
+		delivery.Status = model.WebhookDeliveryFailed
+		this.config.GetLogger().WarnContext(ctx, "webhook delivery failed", "webhookId", webhook.Id, "deliveryId", delivery.Id, "attempts", delivery.Attempts, "error", err)
+		return delivery
+	}
+	delivery.NextAttempt = now.Add(getBackoff(delivery.Attempts, this.config.WebhookMaxBackoff.GetDuration())).UnixMilli()
+	return delivery
+}
+
+func (this *Controller) sendWebhook(ctx context.Context, webhook model.Webhook, delivery model.WebhookDelivery, now time.Time) (code int, err error) {
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return 0, err
+	}
+	timeout := this.config.WebhookTimeout.GetDuration()
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(model.WebhookEventHeader, string(delivery.Payload.Event))
+	req.Header.Set(model.WebhookDeliveryHeader, delivery.Id)
+	req.Header.Set(model.WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(model.WebhookSignatureHeader, "sha256="+model.SignWebhook(webhook.Secret, now.Unix(), body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected statuscode %v", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,388 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/database/mock"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+)
+
+func TestWebhooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := configuration.Config{WebhookMaxAttempts: 2}
+	config.WebhookMaxBackoff.SetDuration(10 * time.Millisecond)
+
+	ctrl, err := NewWithDependencies(ctx, config, mock.New(), &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	mux := sync.Mutex{}
+	received := []model.WebhookPayload{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		timestamp, _ := strconv.ParseInt(req.Header.Get(model.WebhookTimestampHeader), 10, 64)
+		if req.Header.Get(model.WebhookSignatureHeader) != "sha256="+model.SignWebhook("secret", timestamp, body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		payload := model.WebhookPayload{}
+		err := json.Unmarshal(body, &payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Header.Get(model.WebhookEventHeader) != string(payload.Event) {
+			http.Error(w, "event header mismatch", http.StatusBadRequest)
+			return
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		received = append(received, payload)
+	}))
+	defer receiver.Close()
+
+	failing := atomic.Bool{}
+	failing.Store(true)
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+
+	t.Run("admin only", func(t *testing.T) {
+		_, err, code := ctrl.SetWebhook(TestToken, model.Webhook{Url: receiver.URL, Secret: "secret"})
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.ListWebhooks(TestToken)
+		if err == nil || code != http.StatusForbidden {
+			t.Error(err, code)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		_, err, code := ctrl.SetWebhook(TestAdminToken, model.Webhook{Url: "ftp://example.com", Secret: "secret"})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetWebhook(TestAdminToken, model.Webhook{Url: receiver.URL})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+		_, err, code = ctrl.SetWebhook(TestAdminToken, model.Webhook{Url: receiver.URL, Secret: "secret", Events: []model.WebhookEventType{"unknown"}})
+		if err == nil || code != http.StatusBadRequest {
+			t.Error(err, code)
+		}
+	})
+
+	var flakyId string
+	t.Run("register", func(t *testing.T) {
+		result, err, _ := ctrl.SetWebhook(TestAdminToken, model.Webhook{Id: "all", Url: receiver.URL, Secret: "secret", TopicIds: []string{"webhook"}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Secret != "" {
+			t.Error("secret should not be returned")
+		}
+		result, err, _ = ctrl.SetWebhook(TestAdminToken, model.Webhook{Url: flaky.URL, Secret: "secret", Events: []model.WebhookEventType{model.WebhookEventSetResource}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if result.Id == "" {
+			t.Error("missing generated id")
+		}
+		flakyId = result.Id
+		//update without secret keeps the stored secret
+		_, err, _ = ctrl.SetWebhook(TestAdminToken, model.Webhook{Id: "all", Url: receiver.URL, TopicIds: []string{"webhook"}})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err, _ := ctrl.ListWebhooks(TestAdminToken)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 2 || slices.ContainsFunc(list, func(webhook model.Webhook) bool { return webhook.Secret != "" }) {
+			t.Errorf("%#v", list)
+		}
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "webhook"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetTopic(TestAdminToken, model.Topic{Id: "other"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, _ = ctrl.SetPermission(TestAdminToken, "webhook", "r1", model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
+		})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, _ = ctrl.RemoveResource(TestAdminToken, "webhook", "r1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err, _ = ctrl.RemoveTopic(TestAdminToken, "webhook")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("received", func(t *testing.T) {
+		expected := []model.WebhookEventType{model.WebhookEventSetTopic, model.WebhookEventSetResource, model.WebhookEventRemoveResource, model.WebhookEventRemoveTopic}
+		events := []model.WebhookEventType{}
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(50 * time.Millisecond) {
+			mux.Lock()
+			events = []model.WebhookEventType{}
+			for _, payload := range received {
+				events = append(events, payload.Event)
+			}
+			mux.Unlock()
+			if len(events) >= len(expected) {
+				break
+			}
+		}
+		slices.Sort(events)
+		slices.Sort(expected)
+		if !slices.Equal(events, expected) {
+			t.Errorf("%#v", events)
+		}
+		mux.Lock()
+		defer mux.Unlock()
+		for _, payload := range received {
+			if payload.TopicId != "webhook" || payload.UserId == "" || payload.Timestamp == 0 {
+				t.Errorf("%#v", payload)
+			}
+			if payload.Event == model.WebhookEventSetResource && (payload.ResourceId != "r1" || payload.Permissions == nil) {
+				t.Errorf("%#v", payload)
+			}
+		}
+	})
+
+	t.Run("repeated relay", func(t *testing.T) {
+		before, err, _ := ctrl.ListWebhookDeliveries(TestAdminToken, "all", model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		payload := model.WebhookPayload{Event: model.WebhookEventSetTopic, Timestamp: time.Now().UnixMilli(), TopicId: "webhook"}
+		event := model.OutboxEvent{Seq: 1000, Topic: model.Topic{Id: "webhook"}, Command: model.OutboxCommandWebhook, Webhook: &payload}
+		webhooks, err, _ := ctrl.ListWebhooks(TestAdminToken)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for i := 0; i < 2; i++ {
+			err = ctrl.createWebhookDeliveries(ctx, event, webhooks)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		after, err, _ := ctrl.ListWebhookDeliveries(TestAdminToken, "all", model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(after) != len(before)+1 {
+			t.Errorf("expected one new delivery, got %v", len(after)-len(before))
+		}
+	})
+
+	t.Run("failed delivery", func(t *testing.T) {
+		var deliveries []model.WebhookDelivery
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(50 * time.Millisecond) {
+			err = ctrl.DispatchWebhooks()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			deliveries, err, _ = ctrl.ListWebhookDeliveries(TestAdminToken, flakyId, model.WebhookDeliveryListOptions{Status: model.WebhookDeliveryFailed})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(deliveries) > 0 {
+				break
+			}
+		}
+		if len(deliveries) != 1 {
+			t.Errorf("%#v", deliveries)
+			return
+		}
+		delivery := deliveries[0]
+		if delivery.Attempts != 2 || delivery.LastStatusCode != http.StatusServiceUnavailable || !strings.Contains(delivery.LastError, "503") {
+			t.Errorf("%#v", delivery)
+		}
+
+		_, err, code := ctrl.RetryWebhookDelivery(TestAdminToken, flakyId, "unknown")
+		if err == nil || code != http.StatusNotFound {
+			t.Error(err, code)
+		}
+
+		failing.Store(false)
+		_, err, _ = ctrl.RetryWebhookDelivery(TestAdminToken, flakyId, delivery.Id)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(50 * time.Millisecond) {
+			deliveries, err, _ = ctrl.ListWebhookDeliveries(TestAdminToken, flakyId, model.WebhookDeliveryListOptions{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(deliveries) == 1 && deliveries[0].Status == model.WebhookDeliveryDelivered {
+				break
+			}
+		}
+		if len(deliveries) != 1 || deliveries[0].Status != model.WebhookDeliveryDelivered || deliveries[0].Attempts != 1 {
+			t.Errorf("%#v", deliveries)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err, _ = ctrl.RemoveWebhook(TestAdminToken, flakyId)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err, code := ctrl.GetWebhook(TestAdminToken, flakyId)
+		if err == nil || code != http.StatusNotFound {
+			t.Error(err, code)
+		}
+		deliveries, err, _ := ctrl.ListWebhookDeliveries(TestAdminToken, flakyId, model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(deliveries) != 0 {
+			t.Errorf("%#v", deliveries)
+		}
+	})
+}
+
+func TestWebhookDispatchPostponesFailedEndpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := configuration.Config{}
+	config.WebhookMaxBackoff.SetDuration(time.Minute)
+
+	db := mock.New()
+	ctrl, err := NewWithDependencies(ctx, config, db, &MockProducer{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	failedRequests := atomic.Int64{}
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		failedRequests.Add(1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	receivedRequests := atomic.Int64{}
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		receivedRequests.Add(1)
+	}))
+	defer up.Close()
+
+	for id, url := range map[string]string{"down": down.URL, "up": up.URL} {
+		_, err, _ = ctrl.SetWebhook(TestAdminToken, model.Webhook{Id: id, Url: url, Secret: "secret"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	deliveries := []model.WebhookDelivery{}
+	for i, webhookId := range []string{"down", "up", "down", "down", "up"} {
+		deliveries = append(deliveries, model.WebhookDelivery{
+			Id:        strconv.Itoa(i),
+			WebhookId: webhookId,
+			Payload:   model.WebhookPayload{Event: model.WebhookEventSetTopic, Timestamp: time.Now().UnixMilli(), TopicId: "webhook"},
+			Status:    model.WebhookDeliveryPending,
+			CreatedAt: time.Now().UnixMilli(),
+		})
+	}
+	err = db.AddWebhookDeliveries(ctx, deliveries)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = ctrl.DispatchWebhooks()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if failedRequests.Load() != 1 {
+		t.Errorf("expected one request to the failing endpoint, got %v", failedRequests.Load())
+	}
+	if receivedRequests.Load() != 2 {
+		t.Errorf("expected two requests to the available endpoint, got %v", receivedRequests.Load())
+	}
+
+	result, err, _ := ctrl.ListWebhookDeliveries(TestAdminToken, "down", model.WebhookDeliveryListOptions{})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	attempts := int64(0)
+	for _, delivery := range result {
+		attempts += delivery.Attempts
+		if delivery.Status != model.WebhookDeliveryPending || delivery.NextAttempt <= time.Now().UnixMilli() {
+			t.Errorf("%#v", delivery)
+		}
+	}
+	if len(result) != 3 || attempts != 1 {
+		t.Errorf("%#v", result)
+	}
+}
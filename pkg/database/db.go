@@ -25,26 +25,75 @@ import (
 )
 
 type Database interface {
-	MarkResourceAsSynced(ctx context.Context, topicId string, id string) error
-	SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool) (err error)
+	// SetResource, DeleteResource, SetResources, SetResourceIfVersion and DeleteResourceIfVersion record a model.PermissionsVersion
+	// of each written resource in the same transaction as the write; the optional change describes the write for these snapshots.
+	// the webhook payloads of the change are stored as outbox events in the same transaction (also by SetTopic and DeleteTopic).
+	SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool, change ...model.Change) (err error)
 	GetResource(ctx context.Context, topicId string, id string, options model.GetOptions) (resource model.Resource, err error)
-	DeleteResource(ctx context.Context, topicId string, id string) error
+	DeleteResource(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, change ...model.Change) error
 
-	ListUnsyncedResources(ctx context.Context) ([]model.Resource, error)
+	// SetResources stores all resources in one transaction (if supported by the database); versions contains the new version of each resource
+	SetResources(ctx context.Context, resources []model.Resource, t time.Time, synced bool, change ...model.Change) (versions []int64, err error)
+
+	// SetResourceIfVersion and DeleteResourceIfVersion return model.ErrVersionMismatch if the stored resource has not the expected version;
+	// model.ExpectExisting matches every existing resource
+	SetResourceIfVersion(ctx context.Context, r model.Resource, t time.Time, synced bool, expectedVersion int64, change ...model.Change) (err error)
+	DeleteResourceIfVersion(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, expectedVersion int64, change ...model.Change) error
+
+	ListOutboxEvents(ctx context.Context, afterSeq int64, limit int64) ([]model.OutboxEvent, error)
+	RemoveOutboxEvent(ctx context.Context, seq int64) error
+	SetOutboxEventRetry(ctx context.Context, seq int64, attempts int64, nextAttempt time.Time, lastError string) error
+	AcquireOutboxLease(ctx context.Context, holder string, duration time.Duration) (bool, error)
+	ReleaseOutboxLease(ctx context.Context, holder string) error
+
+	SetWebhook(ctx context.Context, webhook model.Webhook) error
+	GetWebhook(ctx context.Context, id string) (result model.Webhook, err error)
+	ListWebhooks(ctx context.Context) (result []model.Webhook, err error)
+	RemoveWebhook(ctx context.Context, id string) error
+	AddWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error //ignores deliveries with an already stored id
+	ListDueWebhookDeliveries(ctx context.Context, t time.Time, limit int64) (result []model.WebhookDelivery, err error)
+	UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	GetWebhookDelivery(ctx context.Context, webhookId string, id string) (result model.WebhookDelivery, err error)
+	ListWebhookDeliveries(ctx context.Context, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error)
+	RemoveWebhookDeliveriesBefore(ctx context.Context, t time.Time) error
+	AcquireWebhookLease(ctx context.Context, holder string, duration time.Duration) (bool, error)
+
+	SetServiceCredential(ctx context.Context, credential model.ServiceCredential) error
+	GetServiceCredential(ctx context.Context, id string) (result model.ServiceCredential, err error)
+	ListServiceCredentials(ctx context.Context) (result []model.ServiceCredential, err error)
+	RemoveServiceCredential(ctx context.Context, id string) error
+
+	AppendAuditEntry(ctx context.Context, entry model.AuditEntry) error
+	ListAuditEntries(ctx context.Context, options model.AuditListOptions) (result []model.AuditEntry, err error)
+
+	ListPermissionsVersions(ctx context.Context, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error)
+	GetPermissionsVersion(ctx context.Context, topicId string, id string, version int64) (result model.PermissionsVersion, err error)
+
+	// WatchPermissionsVersions starts to watch the versions stored after the version with the resume token ("" for versions stored from now on);
+	// returns model.ErrResumeTokenExpired if the token is unknown.
+	// the returned watch calls handler for each version and blocks until ctx is done or the handler returns an error.
+	WatchPermissionsVersions(ctx context.Context, resumeToken string) (watch model.VersionWatch, err error)
 
 	AdminListResourceIds(ctx context.Context, topicId string, options model.ListOptions) ([]string, error)
 	AdminListResources(ctx context.Context, topicId string, listOptions model.ListOptions) (result []model.Resource, err error)
+	AdminCountResources(ctx context.Context, topicId string, listOptions model.ListOptions) (count int64, err error)
 
 	ListResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) (result []model.Resource, err error)
 	ListResourceIdsByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) ([]string, error)
+	ListResourceIdsByPermissionsWithMode(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) ([]string, error)
+	CountResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (count int64, err error)
 
 	CheckMultipleResourcePermissions(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, permissions ...model.Permission) (result map[string]bool, err error)
+	CheckMultipleResourcePermissionsWithMode(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, mode model.PermissionMode, permissions ...model.Permission) (result map[string]bool, err error)
 	CheckResourcePermissions(ctx context.Context, topicId string, id string, userId string, roleIds []string, groupIds []string, permissions ...model.Permission) (result bool, err error)
 
-	SetTopic(ctx context.Context, topic model.Topic) error
+	// ListResourcesWithDueValidityChange lists up to limit resources sorted by topic and id, starting after the referenced resource (empty reference: from the start)
+	ListResourcesWithDueValidityChange(ctx context.Context, t time.Time, after model.ResourceReference, limit int64) (result []model.Resource, err error)
+
+	SetTopic(ctx context.Context, topic model.Topic, change ...model.Change) error
 	GetTopic(ctx context.Context, id string) (result model.Topic, exists bool, err error)
 	ListTopics(ctx context.Context, listOptions model.ListOptions) (result []model.Topic, err error)
-	DeleteTopic(ctx context.Context, id string) error
+	DeleteTopic(ctx context.Context, topic model.Topic, t time.Time, synced bool, change ...model.Change) error
 }
 
 func New(config configuration.Config) (Database, error) {
@@ -18,16 +18,18 @@ package database
 
 import (
 	"context"
+	"errors"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/tests/docker"
 	"reflect"
+	"slices"
 	"sync"
 	"testing"
 	"time"
 )
 
-func TestSyncMark(t *testing.T) {
+func TestOutbox(t *testing.T) {
 	wg := &sync.WaitGroup{}
 	defer wg.Wait()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -52,146 +54,1911 @@ func TestSyncMark(t *testing.T) {
 		return
 	}
 
+	topic := model.Topic{Id: "topic", PublishToKafkaTopic: "topic"}
+	topic2 := model.Topic{Id: "topic2", PublishToKafkaTopic: "topic2"}
+	permissions := model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{"u1": {Read: true, Administrate: true}}}
+
 	t.Run("init", func(t *testing.T) {
 		timeNow := time.Now()
-		timeOld := timeNow.Add(-1 * config.SyncAgeLimit.GetDuration()).Add(-1 * time.Minute)
 
-		err = db.SetResource(nil, model.Resource{
-			Id:      "a1",
-			TopicId: "topic",
-		}, timeNow, true)
+		err = db.SetTopic(nil, topic)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		err = db.SetResource(nil, model.Resource{
-			Id:      "a2",
-			TopicId: "topic",
-		}, timeNow, true)
+		err = db.SetTopic(nil, topic2)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		err = db.SetResource(nil, model.Resource{
-			Id:      "b1",
-			TopicId: "topic",
-		}, timeOld, false)
+		for _, r := range []model.Resource{
+			{Id: "a1", TopicId: "topic"},
+			{Id: "x1", TopicId: "topic2"},
+			{Id: "x2", TopicId: "topic2"},
+		} {
+			err = db.SetResource(nil, r, timeNow, true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+
+		err = db.SetResource(nil, model.Resource{Id: "b1", TopicId: "topic", ResourcePermissions: permissions}, timeNow, false)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		err = db.SetResource(nil, model.Resource{
-			Id:      "b2",
-			TopicId: "topic",
-		}, timeOld, false)
+		err = db.DeleteResource(nil, topic, "a1", timeNow, true)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		err = db.SetResource(nil, model.Resource{
-			Id:      "b3",
-			TopicId: "topic",
-		}, timeOld, false)
+		err = db.DeleteResource(nil, topic, "b1", timeNow, false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.DeleteTopic(nil, topic2, timeNow, false)
 		if err != nil {
 			t.Error(err)
 			return
 		}
+	})
+
+	removeTimestamps := func(list []model.OutboxEvent) []model.OutboxEvent {
+		for i, e := range list {
+			e.CreatedAt = 0
+			e.NextAttempt = 0
+			e.Topic.LastUpdateUnixTimestamp = 0
+			list[i] = e
+		}
+		return list
+	}
 
-		err = db.SetResource(nil, model.Resource{
-			Id:      "c1",
-			TopicId: "topic",
-		}, timeNow, false)
+	t.Run("check outbox after init", func(t *testing.T) {
+		expected := []model.OutboxEvent{
+			{Seq: 1, Topic: topic, Id: "b1", Command: model.OutboxCommandSetPermissions, Permissions: permissions},
+			{Seq: 2, Topic: topic, Id: "b1", Command: model.OutboxCommandDelete},
+			{Seq: 3, Topic: topic2, Id: "x1", Command: model.OutboxCommandDelete},
+			{Seq: 4, Topic: topic2, Id: "x2", Command: model.OutboxCommandDelete},
+		}
+		list, err := db.ListOutboxEvents(nil, 0, 0)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		err = db.SetResource(nil, model.Resource{
-			Id:      "c2",
-			TopicId: "topic",
-		}, timeNow, false)
+		list = removeTimestamps(list)
+		if !reflect.DeepEqual(list, expected) {
+			t.Errorf("\n%#v\n%#v\n", list, expected)
+			return
+		}
 	})
 
-	t.Run("check unsynced list afer init", func(t *testing.T) {
-		expected := []model.Resource{
-			{
-				Id:      "b1",
-				TopicId: "topic",
-				ResourcePermissions: model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{},
-					GroupPermissions: map[string]model.PermissionsMap{},
-					RolePermissions:  map[string]model.PermissionsMap{},
-				},
-			},
-			{
-				Id:      "b2",
-				TopicId: "topic",
-				ResourcePermissions: model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{},
-					GroupPermissions: map[string]model.PermissionsMap{},
-					RolePermissions:  map[string]model.PermissionsMap{},
-				},
-			},
-			{
-				Id:      "b3",
-				TopicId: "topic",
-				ResourcePermissions: model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{},
-					GroupPermissions: map[string]model.PermissionsMap{},
-					RolePermissions:  map[string]model.PermissionsMap{},
-				},
-			},
+	t.Run("retry and remove", func(t *testing.T) {
+		err = db.SetOutboxEventRetry(nil, 1, 1, time.Now(), "test")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.RemoveOutboxEvent(nil, 3)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("check outbox after retry and remove", func(t *testing.T) {
+		expected := []model.OutboxEvent{
+			{Seq: 2, Topic: topic, Id: "b1", Command: model.OutboxCommandDelete},
+			{Seq: 4, Topic: topic2, Id: "x2", Command: model.OutboxCommandDelete},
 		}
-		list, err := db.ListUnsyncedResources(nil)
+		list, err := db.ListOutboxEvents(nil, 1, 10)
 		if err != nil {
 			t.Error(err)
 			return
 		}
+		list = removeTimestamps(list)
 		if !reflect.DeepEqual(list, expected) {
 			t.Errorf("\n%#v\n%#v\n", list, expected)
 			return
 		}
+
+		list, err = db.ListOutboxEvents(nil, 0, 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Attempts != 1 || list[0].LastError != "test" {
+			t.Errorf("%#v\n", list)
+			return
+		}
 	})
 
-	t.Run("mark as synced", func(t *testing.T) {
-		err = db.MarkResourceAsSynced(nil, "topic", "b2")
+	t.Run("lease", func(t *testing.T) {
+		ok, err := db.AcquireOutboxLease(nil, "a", time.Second)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !ok {
+			t.Error("expected lease for a")
+			return
+		}
+		ok, err = db.AcquireOutboxLease(nil, "b", time.Second)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if ok {
+			t.Error("expected no lease for b")
+			return
+		}
+		ok, err = db.AcquireOutboxLease(nil, "a", time.Second)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !ok {
+			t.Error("expected renewed lease for a")
+			return
+		}
+		time.Sleep(2 * time.Second)
+		ok, err = db.AcquireOutboxLease(nil, "b", time.Second)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !ok {
+			t.Error("expected lease for b after expiration")
+			return
+		}
+		ok, err = db.AcquireOutboxLease(nil, "b", time.Minute)
+		if err != nil || !ok {
+			t.Error("expected renewed lease for b", err)
+			return
+		}
+		err = db.ReleaseOutboxLease(nil, "a")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ok, err = db.AcquireOutboxLease(nil, "a", time.Minute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if ok {
+			t.Error("expected no lease for a, if released by a non-holder")
+			return
+		}
+		err = db.ReleaseOutboxLease(nil, "b")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ok, err = db.AcquireOutboxLease(nil, "a", time.Minute)
 		if err != nil {
 			t.Error(err)
 			return
 		}
+		if !ok {
+			t.Error("expected lease for a after release by b")
+			return
+		}
 	})
+}
 
-	t.Run("check unsynced list after mark", func(t *testing.T) {
-		expected := []model.Resource{
-			{
-				Id:      "b1",
-				TopicId: "topic",
-				ResourcePermissions: model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{},
-					GroupPermissions: map[string]model.PermissionsMap{},
-					RolePermissions:  map[string]model.PermissionsMap{},
-				},
+func TestResourceDenies(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	resource := model.Resource{
+		Id:      "a",
+		TopicId: "device",
+		ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+				"u2": {Read: true, Write: true, Execute: false, Administrate: false},
 			},
-			{
-				Id:      "b3",
-				TopicId: "topic",
-				ResourcePermissions: model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{},
-					GroupPermissions: map[string]model.PermissionsMap{},
-					RolePermissions:  map[string]model.PermissionsMap{},
+			GroupPermissions: map[string]model.PermissionsMap{
+				"g1": {Read: true, Write: true, Execute: false, Administrate: false},
+			},
+			RolePermissions: map[string]model.PermissionsMap{
+				"r1": {Read: true, Write: true, Execute: false, Administrate: false},
+			},
+			UserDenies: map[string]model.PermissionsMap{
+				"u2": {Write: true},
+			},
+			GroupDenies: map[string]model.PermissionsMap{
+				"g2": {Read: true},
+			},
+			RoleDenies: map[string]model.PermissionsMap{
+				"r2": {Read: true, Write: true},
+			},
+		},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetResource(nil, resource, getTestTime(0), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResource(nil, model.Resource{
+			Id:      "b",
+			TopicId: "device",
+			ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+					"u2": {Read: true, Write: true, Execute: false, Administrate: false},
 				},
 			},
+		}, getTestTime(0), true)
+		if err != nil {
+			t.Error(err)
+			return
 		}
-		list, err := db.ListUnsyncedResources(nil)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		actual, err := db.GetResource(nil, "device", "a", model.GetOptions{})
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		if !reflect.DeepEqual(list, expected) {
-			t.Errorf("\n%#v\n%#v\n", list, expected)
+		if !reflect.DeepEqual(actual, resource) {
+			t.Errorf("\n%#v\n%#v", resource, actual)
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		cases := []struct {
+			user       string
+			roles      []string
+			groups     []string
+			permission model.Permission
+			expected   bool
+		}{
+			{user: "u2", permission: model.Read, expected: true},
+			{user: "u2", permission: model.Write, expected: false},
+			{user: "u3", groups: []string{"g1"}, permission: model.Read, expected: true},
+			{user: "u3", groups: []string{"g1", "g2"}, permission: model.Read, expected: false},
+			{user: "u3", groups: []string{"g1", "g2"}, permission: model.Write, expected: true},
+			{user: "u3", roles: []string{"r1"}, permission: model.Write, expected: true},
+			{user: "u3", roles: []string{"r1", "r2"}, permission: model.Write, expected: false},
+			{user: "u1", roles: []string{"r2"}, permission: model.Read, expected: false},
+			{user: "u1", roles: []string{"r2"}, permission: model.Administrate, expected: true},
+		}
+		for _, c := range cases {
+			access, err := db.CheckResourcePermissions(nil, "device", "a", c.user, c.roles, c.groups, c.permission)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%#v: got %v", c, access)
+			}
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ids, err := db.ListResourceIdsByPermissions(nil, "device", "u2", []string{}, []string{}, model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
 			return
 		}
+		if !reflect.DeepEqual(ids, []string{"a", "b"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u2", []string{}, []string{}, model.ListOptions{}, model.Read, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"b"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u3", []string{"r1", "r2"}, []string{}, model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+	})
+}
+
+func TestTimeBoundPermissions(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Millisecond).UTC()
+	future := time.Now().Add(time.Hour).Truncate(time.Millisecond).UTC()
+
+	resources := []model.Resource{
+		{
+			Id:      "a",
+			TopicId: "device",
+			ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+					"u2": {Read: true, Write: true, ValidUntil: &past},
+					"u3": {Read: true, ValidFrom: &past, ValidUntil: &future},
+				},
+				GroupPermissions: map[string]model.PermissionsMap{
+					"g1": {Read: true, ValidFrom: &future},
+				},
+				RolePermissions: map[string]model.PermissionsMap{
+					"r1": {Read: true, ValidUntil: &future},
+				},
+				UserDenies: map[string]model.PermissionsMap{
+					"u3": {Write: true, ValidUntil: &future},
+				},
+			},
+		},
+		{
+			Id:      "b",
+			TopicId: "device",
+			ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+					"u3": {Read: true, Write: true},
+				},
+				RolePermissions: map[string]model.PermissionsMap{
+					"r1": {Read: true, ValidFrom: &future},
+				},
+			},
+		},
+		{
+			Id:      "c",
+			TopicId: "device",
+			ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+				},
+			},
+		},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		for _, r := range resources {
+			err = db.SetResource(nil, r, getTestTime(0), true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		for _, expected := range resources {
+			actual, err := db.GetResource(nil, expected.TopicId, expected.Id, model.GetOptions{})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if expected.GroupPermissions == nil {
+				expected.GroupPermissions = map[string]model.PermissionsMap{}
+			}
+			if expected.RolePermissions == nil {
+				expected.RolePermissions = map[string]model.PermissionsMap{}
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("\n%#v\n%#v", expected, actual)
+			}
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		cases := []struct {
+			id         string
+			user       string
+			roles      []string
+			groups     []string
+			permission model.Permission
+			expected   bool
+		}{
+			{id: "a", user: "u2", permission: model.Read, expected: false},
+			{id: "a", user: "u3", permission: model.Read, expected: true},
+			{id: "a", user: "u4", groups: []string{"g1"}, permission: model.Read, expected: false},
+			{id: "a", user: "u4", roles: []string{"r1"}, permission: model.Read, expected: true},
+			{id: "b", user: "u4", roles: []string{"r1"}, permission: model.Read, expected: false},
+			{id: "b", user: "u3", permission: model.Write, expected: true},
+		}
+		for _, c := range cases {
+			access, err := db.CheckResourcePermissions(nil, "device", c.id, c.user, c.roles, c.groups, c.permission)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%#v: got %v", c, access)
+			}
+		}
 	})
 
+	t.Run("list", func(t *testing.T) {
+		ids, err := db.ListResourceIdsByPermissions(nil, "device", "u3", []string{}, []string{}, model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"a", "b"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u3", []string{}, []string{}, model.ListOptions{}, model.Write)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"b"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u4", []string{"r1"}, []string{"g1"}, model.ListOptions{}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"a"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("list due validity changes", func(t *testing.T) {
+		list, err := db.ListResourcesWithDueValidityChange(nil, time.Now(), model.ResourceReference{}, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Id != "a" {
+			t.Errorf("%#v", list)
+		}
+		list, err = db.ListResourcesWithDueValidityChange(nil, future.Add(time.Second), model.ResourceReference{}, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 2 || list[0].Id != "a" || list[1].Id != "b" {
+			t.Errorf("%#v", list)
+			return
+		}
+		list, err = db.ListResourcesWithDueValidityChange(nil, future.Add(time.Second), model.ResourceReference{TopicId: list[0].TopicId, Id: list[0].Id}, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Id != "b" {
+			t.Errorf("%#v", list)
+		}
+	})
+}
+
+func TestResourceHierarchy(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	owner := model.PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetTopic(nil, model.Topic{Id: "devices", PublishToKafkaTopic: "devices"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		for _, r := range []model.Resource{
+			{Id: "l1", TopicId: "locations", ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{"u1": owner, "u2": {Read: true, Write: true}},
+				RolePermissions: map[string]model.PermissionsMap{"r1": {Read: true}},
+			}},
+			{Id: "h1", TopicId: "hubs", ResourcePermissions: model.ResourcePermissions{
+				Parent: &model.ResourceReference{TopicId: "locations", Id: "l1"},
+			}},
+			{Id: "d1", TopicId: "devices", ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{"u2": {Read: true}},
+				Parent:          &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+			}},
+		} {
+			err = db.SetResource(nil, r, getTestTime(0), true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		cases := []struct {
+			topic      string
+			id         string
+			user       string
+			roles      []string
+			permission model.Permission
+			expected   bool
+		}{
+			{topic: "hubs", id: "h1", user: "u1", permission: model.Administrate, expected: true},
+			{topic: "devices", id: "d1", user: "u1", permission: model.Administrate, expected: true},
+			{topic: "devices", id: "d1", user: "u2", permission: model.Read, expected: true},
+			{topic: "devices", id: "d1", user: "u2", permission: model.Write, expected: false},
+			{topic: "devices", id: "d1", user: "u3", roles: []string{"r1"}, permission: model.Read, expected: true},
+		}
+		for _, c := range cases {
+			access, err := db.CheckResourcePermissions(nil, c.topic, c.id, c.user, c.roles, []string{}, c.permission)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%#v: got %v", c, access)
+			}
+		}
+		ids, err := db.ListResourceIdsByPermissions(nil, "devices", "u1", []string{}, []string{}, model.ListOptions{}, model.Administrate)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"d1"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		resource, err := db.GetResource(nil, "devices", "d1", model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(resource.UserPermissions, map[string]model.PermissionsMap{"u2": {Read: true}}) {
+			t.Errorf("%#v", resource)
+		}
+		if !reflect.DeepEqual(resource.Parent, &model.ResourceReference{TopicId: "hubs", Id: "h1"}) {
+			t.Errorf("%#v", resource.Parent)
+		}
+		if resource.EffectivePermissions == nil || !reflect.DeepEqual(resource.EffectivePermissions.UserPermissions, map[string]model.PermissionsMap{"u1": owner, "u2": {Read: true}}) {
+			t.Errorf("%#v", resource.EffectivePermissions)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "l1", TopicId: "locations", ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"u1": owner},
+			Parent:          &model.ResourceReference{TopicId: "devices", Id: "d1"},
+		}}, getTestTime(0), true)
+		if !errors.Is(err, model.ErrInvalidParent) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "d2", TopicId: "devices", ResourcePermissions: model.ResourcePermissions{
+			Parent: &model.ResourceReference{TopicId: "hubs", Id: "unknown"},
+		}}, getTestTime(0), true)
+		if !errors.Is(err, model.ErrInvalidParent) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("no effective admin", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "d2", TopicId: "devices", ResourcePermissions: model.ResourcePermissions{
+			UserDenies: map[string]model.PermissionsMap{"u1": {Administrate: true}},
+			Parent:     &model.ResourceReference{TopicId: "hubs", Id: "h1"},
+		}}, getTestTime(0), true)
+		if !errors.Is(err, model.ErrInvalidParent) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("parent change publishes children", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "l1", TopicId: "locations", ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"u1": owner, "u3": {Read: true}},
+		}}, getTestTime(10), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//only d1 is in a published topic
+		events, err := db.ListOutboxEvents(nil, 0, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(events) != 1 {
+			t.Errorf("%#v", events)
+			return
+		}
+		for _, event := range events {
+			if event.Id != "d1" || event.Topic.Id != "devices" {
+				t.Errorf("%#v", event)
+			}
+		}
+		if last := events[len(events)-1]; !last.Permissions.UserPermissions["u3"].Read || last.Permissions.UserPermissions["u2"].Write {
+			t.Errorf("%#v", last)
+		}
+	})
+
+	t.Run("remove parent", func(t *testing.T) {
+		err = db.DeleteResource(nil, model.Topic{Id: "hubs"}, "h1", getTestTime(20), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//d1 is detached from the removed parent and keeps its effective permissions as own permissions
+		access, err := db.CheckResourcePermissions(nil, "devices", "d1", "u1", []string{}, []string{}, model.Administrate)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !access {
+			t.Error("expected detached child to keep the inherited admin")
+		}
+		resource, err := db.GetResource(nil, "devices", "d1", model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.HasParent() || !resource.UserPermissions["u1"].Administrate {
+			t.Errorf("%#v", resource)
+		}
+	})
+}
+
+func TestCustomPermissions(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	resource := model.Resource{
+		Id:      "a",
+		TopicId: "device",
+		ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{
+				"u1": {Read: true, Write: true, Execute: true, Administrate: true, Custom: map[string]bool{"s": true, "c": true}},
+				"u2": {Read: true, Custom: map[string]bool{"s": true}},
+			},
+			GroupPermissions: map[string]model.PermissionsMap{
+				"g1": {Custom: map[string]bool{"c": true}},
+			},
+			RolePermissions: map[string]model.PermissionsMap{
+				"r1": {Read: true, Custom: map[string]bool{"s": true, "c": true}},
+			},
+			RoleDenies: map[string]model.PermissionsMap{
+				"r2": {Custom: map[string]bool{"c": true}},
+			},
+		},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetResource(nil, resource, getTestTime(0), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		validUntil := time.Now().Add(time.Hour)
+		err = db.SetResource(nil, model.Resource{
+			Id:      "b",
+			TopicId: "device",
+			ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{
+					"u1": {Read: true, Write: true, Execute: true, Administrate: true},
+					"u2": {Read: true, Custom: map[string]bool{"c": true}, ValidUntil: &validUntil},
+				},
+			},
+		}, getTestTime(0), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		actual, err := db.GetResource(nil, "device", "a", model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(actual, resource) {
+			t.Errorf("\n%#v\n%#v", resource, actual)
+		}
+	})
+
+	t.Run("check", func(t *testing.T) {
+		cases := []struct {
+			id         string
+			user       string
+			roles      []string
+			groups     []string
+			permission model.Permission
+			expected   bool
+		}{
+			{id: "a", user: "u2", permission: 's', expected: true},
+			{id: "a", user: "u2", permission: 'c', expected: false},
+			{id: "a", user: "u3", groups: []string{"g1"}, permission: 'c', expected: true},
+			{id: "a", user: "u3", roles: []string{"r1"}, permission: 'c', expected: true},
+			{id: "a", user: "u3", roles: []string{"r1", "r2"}, permission: 'c', expected: false},
+			{id: "a", user: "u3", roles: []string{"r1", "r2"}, permission: 's', expected: true},
+			{id: "b", user: "u2", permission: 'c', expected: true},
+			{id: "b", user: "u2", permission: 's', expected: false},
+		}
+		for _, c := range cases {
+			access, err := db.CheckResourcePermissions(nil, "device", c.id, c.user, c.roles, c.groups, c.permission)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if access != c.expected {
+				t.Errorf("%#v: got %v", c, access)
+			}
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		ids, err := db.ListResourceIdsByPermissions(nil, "device", "u2", []string{}, []string{}, model.ListOptions{}, 's')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"a"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u2", []string{}, []string{}, model.ListOptions{}, model.Read, 'c')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"b"}) {
+			t.Errorf("%#v", ids)
+		}
+		ids, err = db.ListResourceIdsByPermissions(nil, "device", "u3", []string{"r1", "r2"}, []string{}, model.ListOptions{}, 'c')
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(ids) != 0 {
+			t.Errorf("%#v", ids)
+		}
+	})
+}
+
+func TestAuditLog(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	permissions := &model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+	entries := []model.AuditEntry{
+		{Id: "1", Timestamp: getTestTime(1).UnixMilli(), UserId: "admin", Operation: model.AuditOperationSetTopic, TopicId: "device", NewTopic: &model.Topic{Id: "device"}},
+		{Id: "2", Timestamp: getTestTime(2).UnixMilli(), UserId: "admin", Operation: model.AuditOperationSetPermission, TopicId: "device", ResourceId: "a", NewPermissions: permissions},
+		{Id: "3", Timestamp: getTestTime(3).UnixMilli(), UserId: "u1", Operation: model.AuditOperationSetPermission, TopicId: "device", ResourceId: "a", PreviousPermissions: permissions, NewPermissions: permissions},
+		{Id: "4", Timestamp: getTestTime(4).UnixMilli(), UserId: "admin", Operation: model.AuditOperationRemoveResource, TopicId: "device", ResourceId: "a", PreviousPermissions: permissions},
+	}
+
+	t.Run("append", func(t *testing.T) {
+		for _, entry := range entries {
+			err = db.AppendAuditEntry(nil, entry)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		cases := []struct {
+			options  model.AuditListOptions
+			expected []string
+		}{
+			{options: model.AuditListOptions{}, expected: []string{"4", "3", "2", "1"}},
+			{options: model.AuditListOptions{Limit: 2, Offset: 1}, expected: []string{"3", "2"}},
+			{options: model.AuditListOptions{ResourceId: "a"}, expected: []string{"4", "3", "2"}},
+			{options: model.AuditListOptions{UserId: "u1"}, expected: []string{"3"}},
+			{options: model.AuditListOptions{Operation: model.AuditOperationSetPermission}, expected: []string{"3", "2"}},
+			{options: model.AuditListOptions{From: getTestTime(2).UnixMilli(), Until: getTestTime(4).UnixMilli()}, expected: []string{"3", "2"}},
+			{options: model.AuditListOptions{TopicId: "unknown"}, expected: []string{}},
+		}
+		for _, c := range cases {
+			result, err := db.ListAuditEntries(nil, c.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids := []string{}
+			for _, entry := range result {
+				ids = append(ids, entry.Id)
+			}
+			if !reflect.DeepEqual(ids, c.expected) {
+				t.Errorf("%#v: %#v", c.options, ids)
+			}
+		}
+		result, err := db.ListAuditEntries(nil, model.AuditListOptions{UserId: "u1"})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(result) != 1 || !reflect.DeepEqual(result[0], entries[2]) {
+			t.Errorf("\n%#v\n%#v", result, entries[2])
+		}
+	})
+}
+
+func TestWebhooks(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	webhook := model.Webhook{Id: "w1", Url: "http://localhost/hook", Secret: "secret", TopicIds: []string{"device"}, Events: []model.WebhookEventType{model.WebhookEventSetResource}}
+	deliveries := []model.WebhookDelivery{
+		{Id: "1", WebhookId: "w1", Payload: model.WebhookPayload{Event: model.WebhookEventSetResource, TopicId: "device", ResourceId: "a"}, Status: model.WebhookDeliveryPending, CreatedAt: getTestTime(1).UnixMilli(), NextAttempt: getTestTime(1).UnixMilli()},
+		{Id: "2", WebhookId: "w1", Payload: model.WebhookPayload{Event: model.WebhookEventSetResource, TopicId: "device", ResourceId: "b"}, Status: model.WebhookDeliveryPending, CreatedAt: getTestTime(2).UnixMilli(), NextAttempt: getTestTime(5).UnixMilli()},
+		{Id: "3", WebhookId: "w1", Payload: model.WebhookPayload{Event: model.WebhookEventSetResource, TopicId: "device", ResourceId: "c"}, Status: model.WebhookDeliveryPending, CreatedAt: getTestTime(3).UnixMilli(), NextAttempt: getTestTime(3).UnixMilli()},
+	}
+
+	deliveryIds := func(list []model.WebhookDelivery) []string {
+		ids := []string{}
+		for _, delivery := range list {
+			ids = append(ids, delivery.Id)
+		}
+		return ids
+	}
+
+	t.Run("set webhook", func(t *testing.T) {
+		err = db.SetWebhook(nil, webhook)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err := db.GetWebhook(nil, "w1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result, webhook) {
+			t.Errorf("\n%#v\n%#v", result, webhook)
+		}
+		_, err = db.GetWebhook(nil, "unknown")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("add deliveries", func(t *testing.T) {
+		err = db.AddWebhookDeliveries(nil, deliveries)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	})
+
+	t.Run("due deliveries", func(t *testing.T) {
+		result, err := db.ListDueWebhookDeliveries(nil, getTestTime(4), 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(deliveryIds(result), []string{"1", "3"}) {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+		delivery := result[0]
+		delivery.Status = model.WebhookDeliveryFailed
+		delivery.Attempts = 10
+		delivery.LastStatusCode = 500
+		delivery.LastError = "unexpected statuscode 500"
+		err = db.UpdateWebhookDelivery(nil, delivery)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		stored, err := db.GetWebhookDelivery(nil, "w1", "1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(stored, delivery) {
+			t.Errorf("\n%#v\n%#v", stored, delivery)
+		}
+		result, err = db.ListDueWebhookDeliveries(nil, getTestTime(6), 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(deliveryIds(result), []string{"2", "3"}) {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+	})
+
+	t.Run("list deliveries", func(t *testing.T) {
+		result, err := db.ListWebhookDeliveries(nil, "w1", model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(deliveryIds(result), []string{"3", "2", "1"}) {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+		result, err = db.ListWebhookDeliveries(nil, "w1", model.WebhookDeliveryListOptions{Status: model.WebhookDeliveryFailed})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(deliveryIds(result), []string{"1"}) {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		err = db.RemoveWebhookDeliveriesBefore(nil, getTestTime(10))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		result, err := db.ListWebhookDeliveries(nil, "w1", model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//pending deliveries are kept
+		if !reflect.DeepEqual(deliveryIds(result), []string{"3", "2"}) {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+	})
+
+	t.Run("remove webhook", func(t *testing.T) {
+		err = db.RemoveWebhook(nil, "w1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := db.ListWebhooks(nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 0 {
+			t.Errorf("%#v", list)
+		}
+		result, err := db.ListWebhookDeliveries(nil, "w1", model.WebhookDeliveryListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(result) != 0 {
+			t.Errorf("%#v", deliveryIds(result))
+		}
+	})
+}
+
+func TestServiceCredentials(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	credentials := []model.ServiceCredential{
+		{Id: "s2", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeCheck}, TopicIds: []string{"device"}, CreatedAt: getTestTime(1).UnixMilli(), KeyHash: "hash2"},
+		{Id: "s1", Scopes: []model.ServiceCredentialScope{model.ServiceCredentialScopeManage}, CreatedAt: getTestTime(2).UnixMilli(), KeyHash: "hash1"},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		for _, credential := range credentials {
+			err = db.SetServiceCredential(ctx, credential)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		result, err := db.GetServiceCredential(ctx, "s2")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result, credentials[0]) {
+			t.Errorf("\n%#v\n%#v", result, credentials[0])
+		}
+		_, err = db.GetServiceCredential(ctx, "unknown")
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Error(err)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		credentials[1].Disabled = true
+		err = db.SetServiceCredential(ctx, credentials[1])
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := db.ListServiceCredentials(ctx)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := []model.ServiceCredential{credentials[1], credentials[0]}
+		if !reflect.DeepEqual(list, expected) {
+			t.Errorf("\n%#v\n%#v", list, expected)
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		err = db.RemoveServiceCredential(ctx, "s1")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		list, err := db.ListServiceCredentials(ctx)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(list) != 1 || list[0].Id != "s2" {
+			t.Errorf("%#v", list)
+		}
+	})
+}
+
+func TestPermissionsHistory(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		GroupPermissions: map[string]model.PermissionsMap{"g1": {Read: true}},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+
+	t.Run("record", func(t *testing.T) {
+		recorded := []model.PermissionsVersion{}
+		change := func(userId string, operation model.AuditOperation) model.Change {
+			return model.Change{UserId: userId, Operation: operation, Recorded: func(versions []model.PermissionsVersion) {
+				recorded = append(recorded, versions...)
+			}}
+		}
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(1), true, change("admin", model.AuditOperationSetPermission))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResource(nil, model.Resource{Id: "b", TopicId: "device", ResourcePermissions: permissions}, getTestTime(2), true, change("admin", model.AuditOperationSetPermission))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResourceIfVersion(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(3), true, 1, change("u1", model.AuditOperationSetPermission))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.DeleteResource(nil, model.Topic{Id: "device"}, "a", getTestTime(4), true, change("admin", model.AuditOperationRemoveResource))
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		versions := []int64{}
+		for _, version := range recorded {
+			versions = append(versions, version.Version)
+		}
+		if !reflect.DeepEqual(versions, []int64{1, 1, 2, 3}) || !recorded[3].Deleted || recorded[3].UserId != "admin" {
+			t.Errorf("%#v", recorded)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		result, err := db.ListPermissionsVersions(nil, "device", "a", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		versions := []int64{}
+		for _, element := range result {
+			versions = append(versions, element.Version)
+		}
+		if !reflect.DeepEqual(versions, []int64{3, 2, 1}) {
+			t.Errorf("%#v", versions)
+		}
+		result, err = db.ListPermissionsVersions(nil, "device", "a", model.ListOptions{Limit: 1, Offset: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(result) != 1 || result[0].Version != 2 {
+			t.Errorf("%#v", result)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		result, err := db.GetPermissionsVersion(nil, "device", "a", 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(result.Permissions, permissions) || result.UserId != "admin" {
+			t.Errorf("%#v", result)
+		}
+		_, err = db.GetPermissionsVersion(nil, "device", "a", 4)
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+
+	t.Run("recreate", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(5), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resource, err := db.GetResource(nil, "device", "a", model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if resource.Version != 4 {
+			t.Errorf("expected version 4, got %v", resource.Version)
+		}
+		_, err = db.GetPermissionsVersion(nil, "device", "a", 4)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestResourceVersions(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+	getVersion := func(id string) int64 {
+		resource, err := db.GetResource(nil, "device", id, model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+		}
+		return resource.Version
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(1), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResourceIfVersion(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(2), true, 1)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResourceIfVersion(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(3), true, 1)
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			t.Errorf("expected version mismatch, got %v", err)
+		}
+		if version := getVersion("a"); version != 2 {
+			t.Errorf("expected version 2, got %v", version)
+		}
+		err = db.SetResourceIfVersion(nil, model.Resource{Id: "b", TopicId: "device", ResourcePermissions: permissions}, getTestTime(4), true, 1)
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			t.Errorf("expected version mismatch for unknown resource, got %v", err)
+		}
+		err = db.SetResourceIfVersion(nil, model.Resource{Id: "b", TopicId: "device", ResourcePermissions: permissions}, getTestTime(5), true, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("b"); version != 1 {
+			t.Errorf("expected version 1, got %v", version)
+		}
+	})
+
+	t.Run("inherited changes", func(t *testing.T) {
+		child := permissions
+		child.Parent = &model.ResourceReference{TopicId: "device", Id: "a"}
+		err = db.SetResource(nil, model.Resource{Id: "child", TopicId: "device", ResourcePermissions: child}, getTestTime(6), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		//unchanged inherited permissions keep the version
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(7), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("child"); version != 1 {
+			t.Errorf("expected version 1, got %v", version)
+		}
+		changed := permissions
+		changed.RolePermissions = map[string]model.PermissionsMap{"user": {Read: true}}
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: changed}, getTestTime(8), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if version := getVersion("child"); version != 2 {
+			t.Errorf("expected version 2, got %v", version)
+		}
+		versions, err := db.ListPermissionsVersions(nil, "device", "child", model.ListOptions{Limit: 1})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(versions) != 1 || versions[0].Version != 2 || versions[0].Operation != model.AuditOperationInheritedChange {
+			t.Errorf("%#v", versions)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err = db.DeleteResourceIfVersion(nil, model.Topic{Id: "device"}, "a", getTestTime(9), true, 3)
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			t.Errorf("expected version mismatch, got %v", err)
+		}
+		err = db.DeleteResourceIfVersion(nil, model.Topic{Id: "device"}, "a", getTestTime(10), true, 4)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, err = db.GetResource(nil, "device", "a", model.GetOptions{})
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+}
+
+func TestResourceCreator(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = db.SetTopic(nil, model.Topic{Id: "device", PublishToKafkaTopic: "device"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+	getCreator := func(id string) string {
+		resource, err := db.GetResource(nil, "device", id, model.GetOptions{})
+		if err != nil {
+			t.Error(err)
+		}
+		return resource.Creator
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions, Creator: "u1"}, getTestTime(1), false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions, Creator: "u2"}, getTestTime(2), false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if creator := getCreator("a"); creator != "u1" {
+			t.Errorf("expected creator u1, got %v", creator)
+		}
+	})
+
+	t.Run("bulk", func(t *testing.T) {
+		_, err = db.SetResources(nil, []model.Resource{
+			{Id: "a", TopicId: "device", ResourcePermissions: permissions, Creator: "u3"},
+			{Id: "b", TopicId: "device", ResourcePermissions: permissions, Creator: "u3"},
+		}, getTestTime(3), false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if creator := getCreator("a"); creator != "u1" {
+			t.Errorf("expected creator u1, got %v", creator)
+		}
+		if creator := getCreator("b"); creator != "u3" {
+			t.Errorf("expected creator u3, got %v", creator)
+		}
+	})
+
+	t.Run("outbox", func(t *testing.T) {
+		events, err := db.ListOutboxEvents(nil, 0, 10)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		owners := []string{}
+		for _, event := range events {
+			owners = append(owners, event.Id+":"+event.Owner)
+		}
+		expected := []string{"a:u1", "a:u1", "a:u1", "b:u3"}
+		if !reflect.DeepEqual(owners, expected) {
+			t.Errorf("\n%#v\n%#v", owners, expected)
+		}
+	})
+}
+
+func TestSetResources(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = db.SetTopic(nil, model.Topic{Id: "device", PublishToKafkaTopic: "device"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	permissions := model.ResourcePermissions{
+		UserPermissions:  map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		GroupPermissions: map[string]model.PermissionsMap{},
+		RolePermissions:  map[string]model.PermissionsMap{},
+	}
+
+	t.Run("set", func(t *testing.T) {
+		err = db.SetResource(nil, model.Resource{Id: "a", TopicId: "device", ResourcePermissions: permissions}, getTestTime(1), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		versions, err := db.SetResources(nil, []model.Resource{
+			{Id: "a", TopicId: "device", ResourcePermissions: permissions},
+			{Id: "b", TopicId: "device", ResourcePermissions: permissions},
+			{Id: "c", TopicId: "device", ResourcePermissions: model.ResourcePermissions{Parent: &model.ResourceReference{TopicId: "device", Id: "b"}}},
+		}, getTestTime(2), false)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(versions, []int64{2, 1, 1}) {
+			t.Errorf("%#v", versions)
+		}
+	})
+
+	t.Run("check inherited permissions of parent in same batch", func(t *testing.T) {
+		ok, err := db.CheckResourcePermissions(nil, "device", "c", "u1", nil, nil, model.Administrate)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !ok {
+			t.Error("expected inherited admin permission")
+		}
+	})
+
+	t.Run("check outbox", func(t *testing.T) {
+		events, err := db.ListOutboxEvents(nil, 0, 0)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		ids := []string{}
+		for i, event := range events {
+			if i > 0 && event.Seq <= events[i-1].Seq {
+				t.Errorf("unexpected order of events %#v", events)
+			}
+			ids = append(ids, event.Id)
+		}
+		//c is published twice: as part of the batch and after the update of the inherited permissions
+		if !reflect.DeepEqual(ids, []string{"a", "b", "c", "c"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("duplicate ids", func(t *testing.T) {
+		_, err = db.SetResources(nil, []model.Resource{
+			{Id: "d", TopicId: "device", ResourcePermissions: permissions},
+			{Id: "d", TopicId: "device", ResourcePermissions: permissions},
+		}, getTestTime(3), true)
+		if err == nil {
+			t.Error("expected error")
+			return
+		}
+		_, err = db.GetResource(nil, "device", "d", model.GetOptions{})
+		if !errors.Is(err, model.ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+}
+
+func TestPermissionModes(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	err = db.SetTopic(nil, model.Topic{Id: "device", PublishToKafkaTopic: "device"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	set := func(id string, permissions model.PermissionsMap, denies model.PermissionsMap) {
+		err = db.SetResource(nil, model.Resource{Id: id, TopicId: "device", ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"owner": {Read: true, Write: true, Execute: true, Administrate: true}},
+			RolePermissions: map[string]model.PermissionsMap{"r1": permissions},
+			UserDenies:      map[string]model.PermissionsMap{"u1": denies},
+		}}, getTestTime(1), true)
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	t.Run("init", func(t *testing.T) {
+		set("r", model.PermissionsMap{Read: true}, model.PermissionsMap{})
+		set("x", model.PermissionsMap{Execute: true}, model.PermissionsMap{})
+		set("rx-denied-r", model.PermissionsMap{Read: true, Execute: true}, model.PermissionsMap{Read: true})
+		set("r-denied-r", model.PermissionsMap{Read: true}, model.PermissionsMap{Read: true})
+		set("none", model.PermissionsMap{}, model.PermissionsMap{})
+	})
+
+	ids := []string{"r", "x", "rx-denied-r", "r-denied-r", "none"}
+
+	t.Run("check any", func(t *testing.T) {
+		result, err := db.CheckMultipleResourcePermissionsWithMode(nil, "device", ids, "u1", []string{"r1"}, []string{}, model.PermissionModeAny, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]bool{"r": true, "x": true, "rx-denied-r": true, "r-denied-r": false, "none": false}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("check all", func(t *testing.T) {
+		result, err := db.CheckMultipleResourcePermissionsWithMode(nil, "device", ids, "u1", []string{"r1"}, []string{}, model.PermissionModeAll, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		expected := map[string]bool{"r": false, "x": false, "rx-denied-r": false, "r-denied-r": false, "none": false}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("list any", func(t *testing.T) {
+		result, err := db.ListResourceIdsByPermissionsWithMode(nil, "device", "u1", []string{"r1"}, []string{}, model.ListOptions{}, model.PermissionModeAny, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		slices.Sort(result)
+		expected := []string{"r", "rx-denied-r", "x"}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("\n%#v\n%#v", expected, result)
+		}
+	})
+
+	t.Run("list all", func(t *testing.T) {
+		result, err := db.ListResourceIdsByPermissionsWithMode(nil, "device", "u1", []string{"r1"}, []string{}, model.ListOptions{}, model.PermissionModeAll, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(result) != 0 {
+			t.Errorf("%#v", result)
+		}
+	})
+
+	t.Run("count", func(t *testing.T) {
+		count, err := db.CountResourcesByPermissions(nil, "device", "u1", []string{"r1"}, []string{}, model.ListOptions{}, model.PermissionModeAny, model.Read, model.Execute)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 3 {
+			t.Errorf("expected 3, got %v", count)
+		}
+		count, err = db.CountResourcesByPermissions(nil, "device", "u1", []string{"r1"}, []string{}, model.ListOptions{}, model.PermissionModeAll, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 1 {
+			t.Errorf("expected 1, got %v", count)
+		}
+		count, err = db.AdminCountResources(nil, "device", model.ListOptions{})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 5 {
+			t.Errorf("expected 5, got %v", count)
+		}
+	})
+}
+
+func TestListCursor(t *testing.T) {
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := configuration.Load("../../config.json")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	port, _, err := docker.MongoDB(ctx, wg)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	config.MongoUrl = "mongodb://localhost:" + port
+
+	db, err := New(config)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	for _, topic := range []string{"t1", "t2", "t3"} {
+		err = db.SetTopic(nil, model.Topic{Id: topic})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		err = db.SetResource(nil, model.Resource{Id: id, TopicId: "t1", ResourcePermissions: model.ResourcePermissions{
+			UserPermissions: map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+		}}, getTestTime(1), true)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	t.Run("admin list", func(t *testing.T) {
+		ids, err := db.AdminListResourceIds(nil, "t1", model.ListOptions{Limit: 2, Cursor: model.NextCursor("a")})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"b", "c"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("admin list with ids", func(t *testing.T) {
+		resources, err := db.AdminListResources(nil, "t1", model.ListOptions{Ids: []string{"a", "c", "d"}, Cursor: model.NextCursor("b")})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(resources) != 2 || resources[0].Id != "c" || resources[1].Id != "d" {
+			t.Errorf("%#v", resources)
+		}
+	})
+
+	t.Run("list by permissions", func(t *testing.T) {
+		ids, err := db.ListResourceIdsByPermissions(nil, "t1", "u1", nil, nil, model.ListOptions{Cursor: model.NextCursor("c")}, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if !reflect.DeepEqual(ids, []string{"d"}) {
+			t.Errorf("%#v", ids)
+		}
+	})
+
+	t.Run("topics", func(t *testing.T) {
+		topics, err := db.ListTopics(nil, model.ListOptions{Limit: 1, Cursor: model.NextCursor("t1")})
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if len(topics) != 1 || topics[0].Id != "t2" {
+			t.Errorf("%#v", topics)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := db.AdminListResourceIds(nil, "t1", model.ListOptions{Cursor: "%"})
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("search and sort", func(t *testing.T) {
+		for i, id := range []string{"x-3", "x-1", "y-2"} {
+			err = db.SetResource(nil, model.Resource{Id: id, TopicId: "t2", ResourcePermissions: model.ResourcePermissions{
+				UserPermissions: map[string]model.PermissionsMap{"u1": {Read: true, Write: true, Execute: true, Administrate: true}},
+			}}, getTestTime(i), true)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+		tests := []struct {
+			options  model.ListOptions
+			expected []string
+		}{
+			{model.ListOptions{IdPrefix: "x-"}, []string{"x-1", "x-3"}},
+			{model.ListOptions{IdRegex: "-[12]$"}, []string{"x-1", "y-2"}},
+			{model.ListOptions{SortDesc: true}, []string{"y-2", "x-3", "x-1"}},
+			{model.ListOptions{SortDesc: true, Cursor: model.NextCursor("x-3")}, []string{"x-1"}},
+			{model.ListOptions{SortBy: model.SortByTimestamp}, []string{"x-3", "x-1", "y-2"}},
+			{model.ListOptions{SortBy: model.SortByTimestamp, SortDesc: true, Limit: 2}, []string{"y-2", "x-1"}},
+		}
+		for _, test := range tests {
+			ids, err := db.AdminListResourceIds(nil, "t2", test.options)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("%#v: %#v", test.options, ids)
+			}
+			ids, err = db.ListResourceIdsByPermissions(nil, "t2", "u1", nil, nil, test.options, model.Read)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !reflect.DeepEqual(ids, test.expected) {
+				t.Errorf("%#v: %#v", test.options, ids)
+			}
+		}
+		count, err := db.CountResourcesByPermissions(nil, "t2", "u1", nil, nil, model.ListOptions{IdPrefix: "x-", Limit: 1}, model.PermissionModeAll, model.Read)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if count != 2 {
+			t.Errorf("expected 2, got %v", count)
+		}
+	})
 }
 
 func TestResourcePermissionsWithRoles(t *testing.T) {
@@ -17,11 +17,15 @@
 package mock
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"reflect"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,182 +36,752 @@ func New() *Mock {
 }
 
 type Mock struct {
-	resources []ResourceWithTime
-	topics    []model.Topic
-	mux       sync.Mutex
+	resources   []ResourceWithTime
+	topics      []model.Topic
+	outbox      []model.OutboxEvent
+	outboxSeq   int64
+	audit       []model.AuditEntry
+	history     []model.PermissionsVersion
+	appended    chan struct{} //closed and replaced on every appended version
+	webhooks    []model.Webhook
+	deliveries  []model.WebhookDelivery
+	credentials []model.ServiceCredential
+	mux         sync.Mutex
 }
 
 type ResourceWithTime struct {
 	model.Resource
-	time time.Time
+	time      time.Time
+	ancestors []model.ResourceReference
 }
 
-func (this *Mock) MarkResourceAsSynced(ctx context.Context, topicId string, id string) error {
-	return nil
+// newResourceWithTime resolves the inherited permissions of the parent chain
+func (this *Mock) newResourceWithTime(r model.Resource, t time.Time) (result ResourceWithTime, err error) {
+	r.EffectivePermissions = nil
+	if !r.HasParent() {
+		r.Parent = nil
+		return ResourceWithTime{Resource: r, time: t}, nil
+	}
+	result = ResourceWithTime{Resource: r, time: t}
+	parent, found := this.getResource(r.Parent.TopicId, r.Parent.Id)
+	if !found {
+		return result, fmt.Errorf("%w: unknown parent %v/%v", model.ErrInvalidParent, r.Parent.TopicId, r.Parent.Id)
+	}
+	result.ancestors = append(result.ancestors, parent.ancestors...)
+	effective := r.Inherit(parent.Effective())
+	result.ancestors = append(result.ancestors, *r.Parent)
+	if slices.Contains(result.ancestors, model.ResourceReference{TopicId: r.TopicId, Id: r.Id}) {
+		return result, fmt.Errorf("%w: %v/%v would be its own ancestor", model.ErrInvalidParent, r.TopicId, r.Id)
+	}
+	if !effective.HasAdminUser() {
+		return result, fmt.Errorf("%w: %v/%v would have no admin user", model.ErrInvalidParent, r.TopicId, r.Id)
+	}
+	result.EffectivePermissions = &effective
+	return result, nil
+}
+
+func (this *Mock) getResource(topicId string, id string) (result ResourceWithTime, found bool) {
+	for _, element := range this.resources {
+		if element.TopicId == topicId && element.Id == id {
+			return element, true
+		}
+	}
+	return result, false
+}
+
+func (this *Mock) storeResource(r ResourceWithTime, synced bool) {
+	if !synced {
+		this.appendOutboxEvent(model.OutboxEvent{Topic: this.getTopic(r.TopicId), Id: r.Id, Command: model.OutboxCommandSetPermissions, Permissions: r.Effective(), Owner: r.Creator, CreatedAt: r.time.UnixMilli()})
+	}
+	for i, element := range this.resources {
+		if element.Id == r.Id && element.TopicId == r.TopicId {
+			this.resources[i] = r
+			return
+		}
+	}
+	this.resources = append(this.resources, r)
+}
+
+// updateDescendants recomputes the effective permissions of the matching resources; changed resources get the next version,
+// which is recorded with the user of the change and the operation model.AuditOperationInheritedChange
+func (this *Mock) updateDescendants(match func(element ResourceWithTime) bool, t time.Time, change model.Change) (recorded []model.PermissionsVersion, err error) {
+	descendants := []ResourceWithTime{}
+	for _, element := range this.resources {
+		if match(element) {
+			descendants = append(descendants, element)
+		}
+	}
+	slices.SortStableFunc(descendants, func(a, b ResourceWithTime) int {
+		return len(a.ancestors) - len(b.ancestors)
+	})
+	change.Operation = model.AuditOperationInheritedChange
+	for _, descendant := range descendants {
+		if descendant.HasParent() {
+			if _, parentExists := this.getResource(descendant.Parent.TopicId, descendant.Parent.Id); !parentExists {
+				//detached from the removed parent; the inherited entries become own entries
+				descendant.ResourcePermissions = descendant.Effective()
+				descendant.Parent = nil
+			}
+		}
+		updated, err := this.newResourceWithTime(descendant.Resource, t)
+		if err != nil {
+			return nil, err
+		}
+		stored, _ := this.getResource(descendant.TopicId, descendant.Id)
+		if reflect.DeepEqual(updated.ResourcePermissions, stored.ResourcePermissions) && reflect.DeepEqual(updated.EffectivePermissions, stored.EffectivePermissions) {
+			continue
+		}
+		updated.Version = this.nextVersion(updated.TopicId, updated.Id, descendant.Version)
+		this.storeResource(updated, !this.getTopic(updated.TopicId).PublishesToKafka())
+		version := newVersion(updated.Resource, t, change)
+		this.recordVersions(version)
+		recorded = append(recorded, version)
+	}
+	return recorded, nil
+}
+
+func isDescendantOf(topicId string, id string) func(element ResourceWithTime) bool {
+	return func(element ResourceWithTime) bool {
+		return slices.Contains(element.ancestors, model.ResourceReference{TopicId: topicId, Id: id})
+	}
+}
+
+func (this *Mock) appendOutboxEvent(event model.OutboxEvent) {
+	this.outboxSeq++
+	event.Seq = this.outboxSeq
+	this.outbox = append(this.outbox, event)
+}
+
+// appendWebhookEvents stores the webhook payloads of a successful change as outbox events; the caller must hold the lock
+func (this *Mock) appendWebhookEvents(change model.Change) {
+	for _, event := range change.WebhookEvents() {
+		this.appendOutboxEvent(event)
+	}
 }
 
-func (this *Mock) ListUnsyncedResources(ctx context.Context) ([]model.Resource, error) {
-	return []model.Resource{}, nil
+func (this *Mock) getTopic(id string) model.Topic {
+	for _, element := range this.topics {
+		if element.Id == id {
+			return element
+		}
+	}
+	return model.Topic{Id: id}
 }
 
-func (this *Mock) DeleteResource(ctx context.Context, topicId string, id string) error {
+func (this *Mock) ListOutboxEvents(ctx context.Context, afterSeq int64, limit int64) (result []model.OutboxEvent, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
-	this.resources = slices.DeleteFunc(this.resources, func(element ResourceWithTime) bool {
-		return element.Id == id && element.TopicId == topicId
+	for _, event := range this.outbox {
+		if event.Seq > afterSeq {
+			result = append(result, event)
+		}
+	}
+	return limitOffset(result, limit, 0), nil
+}
+
+func (this *Mock) RemoveOutboxEvent(ctx context.Context, seq int64) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.outbox = slices.DeleteFunc(this.outbox, func(event model.OutboxEvent) bool {
+		return event.Seq == seq
 	})
 	return nil
 }
 
-func (this *Mock) SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool) (err error) {
+func (this *Mock) SetOutboxEventRetry(ctx context.Context, seq int64, attempts int64, nextAttempt time.Time, lastError string) error {
 	this.mux.Lock()
 	defer this.mux.Unlock()
-	for i, element := range this.resources {
-		if element.Id == r.Id && element.TopicId == r.TopicId {
-			this.resources[i] = ResourceWithTime{
-				Resource: r,
-				time:     t,
-			}
-			return nil
+	for i, event := range this.outbox {
+		if event.Seq == seq {
+			event.Attempts = attempts
+			event.NextAttempt = nextAttempt.UnixMilli()
+			event.LastError = lastError
+			this.outbox[i] = event
 		}
 	}
-	this.resources = append(this.resources, ResourceWithTime{
-		Resource: r,
-		time:     t,
+	return nil
+}
+
+func (this *Mock) AcquireOutboxLease(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (this *Mock) ReleaseOutboxLease(ctx context.Context, holder string) error {
+	return nil
+}
+
+func (this *Mock) SetWebhook(ctx context.Context, webhook model.Webhook) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.webhooks = slices.DeleteFunc(this.webhooks, func(element model.Webhook) bool {
+		return element.Id == webhook.Id
 	})
+	this.webhooks = append(this.webhooks, webhook)
 	return nil
 }
 
-func (this *Mock) ListResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) (result []model.Resource, err error) {
+func (this *Mock) GetWebhook(ctx context.Context, id string) (result model.Webhook, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
-	for _, element := range this.resources {
-		if element.TopicId == topicId && checkPerms(element, userId, roleIds, groupIds, permissions...) {
-			result = append(result, element.Resource)
+	for _, webhook := range this.webhooks {
+		if webhook.Id == id {
+			return webhook, nil
 		}
 	}
-	slices.SortFunc(result, func(a, b model.Resource) int {
+	return result, model.ErrNotFound
+}
+
+func (this *Mock) ListWebhooks(ctx context.Context) (result []model.Webhook, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = slices.Clone(this.webhooks)
+	slices.SortFunc(result, func(a, b model.Webhook) int {
 		return strings.Compare(a.Id, b.Id)
 	})
-	return limitOffset(result, options.Limit, options.Offset), nil
+	return result, nil
 }
 
-func limitOffset[T any](list []T, limit int64, offset int64) (result []T) {
-	result = list
-	if offset > 0 {
-		if offset > int64(len(result)) {
-			result = []T{}
-		} else {
-			result = result[offset:]
+func (this *Mock) RemoveWebhook(ctx context.Context, id string) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.webhooks = slices.DeleteFunc(this.webhooks, func(element model.Webhook) bool {
+		return element.Id == id
+	})
+	this.deliveries = slices.DeleteFunc(this.deliveries, func(element model.WebhookDelivery) bool {
+		return element.WebhookId == id
+	})
+	return nil
+}
+
+func (this *Mock) AddWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for _, delivery := range deliveries {
+		if !slices.ContainsFunc(this.deliveries, func(element model.WebhookDelivery) bool { return element.Id == delivery.Id }) {
+			this.deliveries = append(this.deliveries, delivery)
 		}
 	}
-	if limit > 0 {
-		if limit < int64(len(result)) {
-			result = result[:limit]
+	return nil
+}
+
+func (this *Mock) ListDueWebhookDeliveries(ctx context.Context, t time.Time, limit int64) (result []model.WebhookDelivery, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = []model.WebhookDelivery{}
+	for _, delivery := range this.deliveries {
+		if delivery.Status == model.WebhookDeliveryPending && delivery.NextAttempt <= t.UnixMilli() {
+			result = append(result, delivery)
 		}
 	}
-	return result
+	//oldest first; deliveries are appended in order
+	return limitOffset(result, limit, 0), nil
 }
 
-func checkPerms(element ResourceWithTime, user string, roles []string, groups []string, permissions ...model.Permission) bool {
-	for _, p := range permissions {
-		if !checkPerm(element, user, roles, groups, p) {
-			return false
+func (this *Mock) UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for i, element := range this.deliveries {
+		if element.Id == delivery.Id {
+			this.deliveries[i] = delivery
+			return nil
 		}
 	}
-	return true
+	return model.ErrNotFound
 }
 
-func checkPerm(element ResourceWithTime, user string, roles []string, groups []string, permission model.Permission) bool {
-	switch permission {
-	case model.Read:
-		if element.UserPermissions[user].Read {
-			return true
-		}
-		for _, g := range groups {
-			if element.GroupPermissions[g].Read {
-				return true
-			}
+func (this *Mock) GetWebhookDelivery(ctx context.Context, webhookId string, id string) (result model.WebhookDelivery, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for _, delivery := range this.deliveries {
+		if delivery.WebhookId == webhookId && delivery.Id == id {
+			return delivery, nil
 		}
-		for _, g := range roles {
-			if element.RolePermissions[g].Read {
-				return true
-			}
+	}
+	return result, model.ErrNotFound
+}
+
+func (this *Mock) ListWebhookDeliveries(ctx context.Context, webhookId string, options model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = []model.WebhookDelivery{}
+	for _, delivery := range this.deliveries {
+		if delivery.WebhookId == webhookId && (options.Status == "" || delivery.Status == options.Status) {
+			result = append(result, delivery)
 		}
-	case model.Write:
-		if element.UserPermissions[user].Write {
-			return true
+	}
+	//newest first; deliveries are appended in order
+	slices.Reverse(result)
+	return limitOffset(result, options.Limit, options.Offset), nil
+}
+
+func (this *Mock) RemoveWebhookDeliveriesBefore(ctx context.Context, t time.Time) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.deliveries = slices.DeleteFunc(this.deliveries, func(element model.WebhookDelivery) bool {
+		return element.Status != model.WebhookDeliveryPending && element.CreatedAt < t.UnixMilli()
+	})
+	return nil
+}
+
+func (this *Mock) AcquireWebhookLease(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (this *Mock) AppendAuditEntry(ctx context.Context, entry model.AuditEntry) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.audit = append(this.audit, entry)
+	return nil
+}
+
+func (this *Mock) ListAuditEntries(ctx context.Context, listOptions model.AuditListOptions) (result []model.AuditEntry, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = []model.AuditEntry{}
+	for _, entry := range this.audit {
+		if (listOptions.TopicId == "" || entry.TopicId == listOptions.TopicId) &&
+			(listOptions.ResourceId == "" || entry.ResourceId == listOptions.ResourceId) &&
+			(listOptions.UserId == "" || entry.UserId == listOptions.UserId) &&
+			(listOptions.Operation == "" || entry.Operation == listOptions.Operation) &&
+			(listOptions.From == 0 || entry.Timestamp >= listOptions.From) &&
+			(listOptions.Until == 0 || entry.Timestamp < listOptions.Until) {
+			result = append(result, entry)
 		}
-		for _, g := range groups {
-			if element.GroupPermissions[g].Write {
-				return true
-			}
+	}
+	//newest first; entries are appended in order
+	slices.Reverse(result)
+	return limitOffset(result, listOptions.Limit, listOptions.Offset), nil
+}
+
+// recordVersions appends the versions to the history; the caller must hold the lock
+func (this *Mock) recordVersions(versions ...model.PermissionsVersion) {
+	this.history = append(this.history, versions...)
+	if this.appended != nil {
+		close(this.appended)
+		this.appended = nil
+	}
+}
+
+// nextVersion returns the version of the next change of a resource with the stored version; the caller must hold the lock
+func (this *Mock) nextVersion(topicId string, id string, stored int64) int64 {
+	version := stored
+	for _, element := range this.history {
+		if element.TopicId == topicId && element.Id == id && element.Version > version {
+			version = element.Version
 		}
-		for _, g := range roles {
-			if element.RolePermissions[g].Write {
-				return true
+	}
+	return version + 1
+}
+
+func newVersion(r model.Resource, t time.Time, change model.Change) model.PermissionsVersion {
+	return model.PermissionsVersion{
+		TopicId:              r.TopicId,
+		Id:                   r.Id,
+		Version:              r.Version,
+		Timestamp:            t.UnixMilli(),
+		UserId:               change.UserId,
+		Operation:            change.Operation,
+		Permissions:          r.ResourcePermissions,
+		EffectivePermissions: r.EffectivePermissions,
+	}
+}
+
+// changeOf returns the first change or an empty change
+func changeOf(change []model.Change) model.Change {
+	if len(change) == 0 {
+		return model.Change{}
+	}
+	return change[0]
+}
+
+// WatchPermissionsVersions uses the index of the version in the history as resume token
+func (this *Mock) WatchPermissionsVersions(ctx context.Context, resumeToken string) (watch model.VersionWatch, err error) {
+	this.mux.Lock()
+	next := len(this.history)
+	this.mux.Unlock()
+	if resumeToken != "" {
+		index, err := strconv.Atoi(resumeToken)
+		if err != nil || index < 0 || index >= next {
+			return nil, model.ErrResumeTokenExpired
+		}
+		next = index + 1
+	}
+	return func(handler func(token string, version model.PermissionsVersion) error) error {
+		for {
+			this.mux.Lock()
+			pending := slices.Clone(this.history[next:])
+			if this.appended == nil {
+				this.appended = make(chan struct{})
+			}
+			appended := this.appended
+			this.mux.Unlock()
+
+			for _, version := range pending {
+				err := handler(strconv.Itoa(next), version)
+				if err != nil {
+					return err
+				}
+				next++
+			}
+			if len(pending) > 0 {
+				continue
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-appended:
 			}
 		}
-	case model.Execute:
-		if element.UserPermissions[user].Execute {
-			return true
+	}, nil
+}
+
+func (this *Mock) ListPermissionsVersions(ctx context.Context, topicId string, id string, options model.ListOptions) (result []model.PermissionsVersion, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = []model.PermissionsVersion{}
+	for _, element := range this.history {
+		if element.TopicId == topicId && element.Id == id {
+			result = append(result, element)
 		}
-		for _, g := range groups {
-			if element.GroupPermissions[g].Execute {
-				return true
-			}
+	}
+	//newest first; versions are appended in order
+	slices.Reverse(result)
+	return limitOffset(result, options.Limit, options.Offset), nil
+}
+
+func (this *Mock) GetPermissionsVersion(ctx context.Context, topicId string, id string, version int64) (result model.PermissionsVersion, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	for _, element := range this.history {
+		if element.TopicId == topicId && element.Id == id && element.Version == version {
+			return element, nil
 		}
-		for _, g := range roles {
-			if element.RolePermissions[g].Execute {
-				return true
-			}
+	}
+	return result, model.ErrNotFound
+}
+
+func (this *Mock) DeleteResource(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, change ...model.Change) error {
+	this.mux.Lock()
+	recorded, err := this.deleteResource(topic, id, t, synced, changeOf(change))
+	if err == nil {
+		this.appendWebhookEvents(changeOf(change))
+	}
+	this.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+func (this *Mock) DeleteResourceIfVersion(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, expectedVersion int64, change ...model.Change) error {
+	this.mux.Lock()
+	version, exists := this.getVersion(topic.Id, id)
+	if !exists || expectedVersion != model.ExpectExisting && version != expectedVersion {
+		this.mux.Unlock()
+		return model.ErrVersionMismatch
+	}
+	recorded, err := this.deleteResource(topic, id, t, synced, changeOf(change))
+	if err == nil {
+		this.appendWebhookEvents(changeOf(change))
+	}
+	this.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+func (this *Mock) deleteResource(topic model.Topic, id string, t time.Time, synced bool, change model.Change) (recorded []model.PermissionsVersion, err error) {
+	version, exists := this.getVersion(topic.Id, id)
+	this.resources = slices.DeleteFunc(this.resources, func(element ResourceWithTime) bool {
+		return element.Id == id && element.TopicId == topic.Id
+	})
+	if !synced {
+		this.appendOutboxEvent(model.OutboxEvent{Topic: topic, Id: id, Command: model.OutboxCommandDelete, CreatedAt: t.UnixMilli()})
+	}
+	if exists {
+		recorded = []model.PermissionsVersion{{
+			TopicId:   topic.Id,
+			Id:        id,
+			Version:   this.nextVersion(topic.Id, id, version),
+			Timestamp: t.UnixMilli(),
+			UserId:    change.UserId,
+			Operation: change.Operation,
+			Deleted:   true,
+		}}
+		this.recordVersions(recorded...)
+	}
+	descendants, err := this.updateDescendants(isDescendantOf(topic.Id, id), t, change)
+	return append(recorded, descendants...), err
+}
+
+func (this *Mock) SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool, change ...model.Change) (err error) {
+	this.mux.Lock()
+	recorded, err := this.setResource(r, t, synced, changeOf(change))
+	if err == nil {
+		this.appendWebhookEvents(changeOf(change))
+	}
+	this.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+func (this *Mock) SetResourceIfVersion(ctx context.Context, r model.Resource, t time.Time, synced bool, expectedVersion int64, change ...model.Change) (err error) {
+	this.mux.Lock()
+	version, exists := this.getVersion(r.TopicId, r.Id)
+	if expectedVersion == model.ExpectExisting && !exists || expectedVersion != model.ExpectExisting && version != expectedVersion {
+		this.mux.Unlock()
+		return model.ErrVersionMismatch
+	}
+	recorded, err := this.setResource(r, t, synced, changeOf(change))
+	if err == nil {
+		this.appendWebhookEvents(changeOf(change))
+	}
+	this.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+func (this *Mock) SetResources(ctx context.Context, resources []model.Resource, t time.Time, synced bool, change ...model.Change) (versions []int64, err error) {
+	this.mux.Lock()
+	known := map[model.ResourceReference]bool{}
+	for _, r := range resources {
+		ref := model.ResourceReference{TopicId: r.TopicId, Id: r.Id}
+		if known[ref] {
+			this.mux.Unlock()
+			return nil, fmt.Errorf("resource %v/%v is contained more than once", r.TopicId, r.Id)
 		}
-	case model.Administrate:
-		if element.UserPermissions[user].Administrate {
-			return true
+		known[ref] = true
+	}
+	//restore the previous state if one of the resources can not be stored
+	resourcesBackup, outboxBackup, outboxSeqBackup, historyBackup := slices.Clone(this.resources), slices.Clone(this.outbox), this.outboxSeq, slices.Clone(this.history)
+	versions = make([]int64, 0, len(resources))
+	recorded := []model.PermissionsVersion{}
+	for _, r := range resources {
+		temp, err := this.setResource(r, t, synced, changeOf(change))
+		if err != nil {
+			this.resources, this.outbox, this.outboxSeq, this.history = resourcesBackup, outboxBackup, outboxSeqBackup, historyBackup
+			this.mux.Unlock()
+			return nil, err
 		}
-		for _, g := range groups {
-			if element.GroupPermissions[g].Administrate {
-				return true
+		recorded = append(recorded, temp...)
+		version, _ := this.getVersion(r.TopicId, r.Id)
+		versions = append(versions, version)
+	}
+	this.appendWebhookEvents(changeOf(change))
+	this.mux.Unlock()
+	changeOf(change).NotifyRecorded(recorded)
+	return versions, nil
+}
+
+// setResource stores the resource and records its version; the caller must hold the lock
+func (this *Mock) setResource(r model.Resource, t time.Time, synced bool, change model.Change) (recorded []model.PermissionsVersion, err error) {
+	version, exists := this.getVersion(r.TopicId, r.Id)
+	r.Version = this.nextVersion(r.TopicId, r.Id, version)
+	if exists {
+		//the creator is only stored for new resources
+		r.Creator = ""
+		for _, element := range this.resources {
+			if element.TopicId == r.TopicId && element.Id == r.Id {
+				r.Creator = element.Creator
 			}
 		}
-		for _, g := range roles {
-			if element.RolePermissions[g].Administrate {
-				return true
-			}
+	}
+	element, err := this.newResourceWithTime(r, t)
+	if err != nil {
+		return nil, err
+	}
+	this.storeResource(element, synced)
+	recorded = []model.PermissionsVersion{newVersion(element.Resource, t, change)}
+	this.recordVersions(recorded...)
+	descendants, err := this.updateDescendants(isDescendantOf(r.TopicId, r.Id), t, change)
+	return append(recorded, descendants...), err
+}
+
+// getVersion returns the version of the stored resource; 0 if the resource does not exist
+func (this *Mock) getVersion(topicId string, id string) (version int64, exists bool) {
+	for _, element := range this.resources {
+		if element.TopicId == topicId && element.Id == id {
+			return element.Version, true
 		}
 	}
-	return false
+	return 0, false
 }
 
-func (this *Mock) AdminListResourceIds(ctx context.Context, topicId string, listOptions model.ListOptions) (result []string, err error) {
+func (this *Mock) ListResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) (result []model.Resource, err error) {
+	return this.listResourcesByPermissions(topicId, userId, roleIds, groupIds, options, model.PermissionModeAll, permissions...)
+}
+
+func (this *Mock) listResourcesByPermissions(topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (result []model.Resource, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
+	list, err := this.listResources(topicId, options, func(element ResourceWithTime) bool {
+		return checkPerms(element, userId, roleIds, groupIds, mode, permissions...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range sortAndPage(list, options) {
+		result = append(result, element.Resource)
+	}
+	return result, nil
+}
+
+// listResources returns the unsorted resources of the topic, which match the id search of the options and the filter; the caller must hold the lock
+func (this *Mock) listResources(topicId string, options model.ListOptions, filter func(element ResourceWithTime) bool) (result []ResourceWithTime, err error) {
+	err = options.Validate()
+	if err != nil {
+		return nil, err
+	}
 	for _, element := range this.resources {
-		if element.TopicId == topicId {
-			result = append(result, element.Id)
+		if element.TopicId == topicId && options.MatchesId(element.Id) && filter(element) {
+			result = append(result, element)
 		}
 	}
-	sort.Strings(result)
-	return limitOffset(result, listOptions.Limit, listOptions.Offset), nil
+	return result, nil
 }
 
-func (this *Mock) AdminListResources(ctx context.Context, topicId string, listOptions model.ListOptions) (result []model.Resource, err error) {
+// sortAndPage sorts the resources by the options and applies cursor, offset and limit
+func sortAndPage(list []ResourceWithTime, options model.ListOptions) []ResourceWithTime {
+	slices.SortFunc(list, func(a, b ResourceWithTime) int {
+		result := 0
+		if options.SortBy == model.SortByTimestamp {
+			result = a.time.Compare(b.time)
+		}
+		if result == 0 {
+			result = strings.Compare(a.Id, b.Id)
+		}
+		if options.SortDesc {
+			result = -result
+		}
+		return result
+	})
+	list = afterCursor(list, options, func(element ResourceWithTime) string { return element.Id })
+	return limitOffset(list, options.Limit, options.Offset)
+}
+
+func (this *Mock) ListResourcesWithDueValidityChange(ctx context.Context, t time.Time, after model.ResourceReference, limit int64) (result []model.Resource, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
+	compare := func(a, b model.ResourceReference) int {
+		if result := strings.Compare(a.TopicId, b.TopicId); result != 0 {
+			return result
+		}
+		return strings.Compare(a.Id, b.Id)
+	}
 	for _, element := range this.resources {
-		if element.TopicId == topicId && (listOptions.Ids == nil || slices.Contains(listOptions.Ids, element.Id)) {
+		if (after != model.ResourceReference{}) && compare(model.ResourceReference{TopicId: element.TopicId, Id: element.Id}, after) <= 0 {
+			continue
+		}
+		if _, changed := element.RemoveExpired(t); changed {
 			result = append(result, element.Resource)
 		}
 	}
 	slices.SortFunc(result, func(a, b model.Resource) int {
-		return strings.Compare(a.Id, b.Id)
+		return compare(model.ResourceReference{TopicId: a.TopicId, Id: a.Id}, model.ResourceReference{TopicId: b.TopicId, Id: b.Id})
 	})
-	return limitOffset(result, listOptions.Limit, listOptions.Offset), nil
+	return limitOffset(result, limit, 0), nil
+}
+
+func limitOffset[T any](list []T, limit int64, offset int64) (result []T) {
+	result = list
+	if offset > 0 {
+		if offset > int64(len(result)) {
+			result = []T{}
+		} else {
+			result = result[offset:]
+		}
+	}
+	if limit > 0 {
+		if limit < int64(len(result)) {
+			result = result[:limit]
+		}
+	}
+	return result
+}
+
+// afterCursor removes the elements of the list sorted by id up to the id of the cursor
+func afterCursor[T any](list []T, options model.ListOptions, id func(element T) string) []T {
+	after, _ := options.After()
+	if after == "" {
+		return list
+	}
+	for i, element := range list {
+		if (!options.SortDesc && id(element) > after) || (options.SortDesc && id(element) < after) {
+			return list[i:]
+		}
+	}
+	return []T{}
+}
+
+func checkPerms(element ResourceWithTime, user string, roles []string, groups []string, mode model.PermissionMode, permissions ...model.Permission) bool {
+	return mode.Matches(permissions, func(p model.Permission) bool {
+		return checkPerm(element, user, roles, groups, p) && !element.Effective().IsDenied(user, roles, groups, p)
+	})
+}
+
+func checkPerm(element ResourceWithTime, user string, roles []string, groups []string, permission model.Permission) bool {
+	return element.Effective().IsGranted(user, roles, groups, permission)
+}
+
+func (this *Mock) AdminListResourceIds(ctx context.Context, topicId string, listOptions model.ListOptions) (result []string, err error) {
+	list, err := this.AdminListResources(ctx, topicId, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range list {
+		result = append(result, element.Id)
+	}
+	return result, nil
+}
+
+func (this *Mock) AdminListResources(ctx context.Context, topicId string, listOptions model.ListOptions) (result []model.Resource, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	list, err := this.listResources(topicId, listOptions, func(element ResourceWithTime) bool {
+		return listOptions.Ids == nil || slices.Contains(listOptions.Ids, element.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, element := range sortAndPage(list, listOptions) {
+		result = append(result, element.Resource)
+	}
+	return result, nil
+}
+
+func (this *Mock) AdminCountResources(ctx context.Context, topicId string, listOptions model.ListOptions) (count int64, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	list, err := this.listResources(topicId, listOptions, func(element ResourceWithTime) bool {
+		return listOptions.Ids == nil || slices.Contains(listOptions.Ids, element.Id)
+	})
+	return int64(len(list)), err
+}
+
+func (this *Mock) CountResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (count int64, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	list, err := this.listResources(topicId, options, func(element ResourceWithTime) bool {
+		return checkPerms(element, userId, roleIds, groupIds, mode, permissions...)
+	})
+	return int64(len(list)), err
 }
 
 func (this *Mock) ListResourceIdsByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) (result []string, err error) {
-	list, err := this.ListResourcesByPermissions(ctx, topicId, userId, roleIds, groupIds, options, permissions...)
+	return this.ListResourceIdsByPermissionsWithMode(ctx, topicId, userId, roleIds, groupIds, options, model.PermissionModeAll, permissions...)
+}
+
+func (this *Mock) ListResourceIdsByPermissionsWithMode(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (result []string, err error) {
+	list, err := this.listResourcesByPermissions(topicId, userId, roleIds, groupIds, options, mode, permissions...)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +796,7 @@ func (this *Mock) GetResource(ctx context.Context, topicId string, id string, op
 	defer this.mux.Unlock()
 	for _, element := range this.resources {
 		if element.TopicId == topicId && element.Id == id {
-			if options.CheckPermission && !checkPerms(element, options.UserId, options.RoleIds, options.GroupIds, options.Permissions...) {
+			if options.CheckPermission && !checkPerms(element, options.UserId, options.RoleIds, options.GroupIds, model.PermissionModeAll, options.Permissions...) {
 				return resource, model.PermissionCheckFailed
 			}
 			return element.Resource, nil
@@ -232,28 +806,17 @@ func (this *Mock) GetResource(ctx context.Context, topicId string, id string, op
 }
 
 func (this *Mock) CheckMultipleResourcePermissions(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, permissions ...model.Permission) (result map[string]bool, err error) {
+	return this.CheckMultipleResourcePermissionsWithMode(ctx, topicId, ids, userId, roleIds, groupIds, model.PermissionModeAll, permissions...)
+}
+
+func (this *Mock) CheckMultipleResourcePermissionsWithMode(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, mode model.PermissionMode, permissions ...model.Permission) (result map[string]bool, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
 	result = map[string]bool{}
-	for _, id := range ids {
-		_, err = this.GetResource(ctx, topicId, id, model.GetOptions{
-			CheckPermission: true,
-			UserId:          userId,
-			RoleIds:         roleIds,
-			GroupIds:        groupIds,
-			Permissions:     permissions,
-		})
-		if errors.Is(err, model.PermissionCheckFailed) {
-			result[id] = false
-			err = nil
-			continue
-		}
-		if errors.Is(err, model.ErrNotFound) {
-			err = nil
-			continue
-		}
-		if err != nil {
-			return result, err
+	for _, element := range this.resources {
+		if element.TopicId == topicId && slices.Contains(ids, element.Id) {
+			result[element.Id] = checkPerms(element, userId, roleIds, groupIds, mode, permissions...)
 		}
-		result[id] = true
 	}
 	return result, nil
 }
@@ -275,9 +838,10 @@ func (this *Mock) CheckResourcePermissions(ctx context.Context, topicId string,
 	return true, nil
 }
 
-func (this *Mock) SetTopic(ctx context.Context, topic model.Topic) error {
+func (this *Mock) SetTopic(ctx context.Context, topic model.Topic, change ...model.Change) error {
 	this.mux.Lock()
 	defer this.mux.Unlock()
+	this.appendWebhookEvents(changeOf(change))
 	for i, element := range this.topics {
 		if element.Id == topic.Id {
 			this.topics[i] = topic
@@ -300,17 +864,122 @@ func (this *Mock) GetTopic(ctx context.Context, id string) (result model.Topic,
 func (this *Mock) ListTopics(ctx context.Context, options model.ListOptions) (result []model.Topic, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
-	return limitOffset(this.topics, options.Limit, options.Offset), nil
+	err = options.Validate()
+	if err != nil {
+		return nil, err
+	}
+	for _, topic := range this.topics {
+		if (options.Ids == nil || slices.Contains(options.Ids, topic.Id)) && options.MatchesId(topic.Id) {
+			result = append(result, topic)
+		}
+	}
+	slices.SortFunc(result, func(a, b model.Topic) int {
+		comp := 0
+		if options.SortBy == model.SortByTimestamp {
+			comp = cmp.Compare(a.LastUpdateUnixTimestamp, b.LastUpdateUnixTimestamp)
+		}
+		if comp == 0 {
+			comp = strings.Compare(a.Id, b.Id)
+		}
+		if options.SortDesc {
+			comp = -comp
+		}
+		return comp
+	})
+	result = afterCursor(result, options, func(element model.Topic) string { return element.Id })
+	return limitOffset(result, options.Limit, options.Offset), nil
+}
+
+func (this *Mock) DeleteTopic(ctx context.Context, topic model.Topic, t time.Time, synced bool, change ...model.Change) error {
+	this.mux.Lock()
+	recorded, err := this.deleteTopic(topic, t, synced, changeOf(change))
+	if err == nil {
+		this.appendWebhookEvents(changeOf(change))
+	}
+	this.mux.Unlock()
+	if err != nil {
+		return err
+	}
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+func (this *Mock) deleteTopic(topic model.Topic, t time.Time, synced bool, change model.Change) (recorded []model.PermissionsVersion, err error) {
+	ids := []string{}
+	for _, element := range this.resources {
+		if element.TopicId == topic.Id {
+			ids = append(ids, element.Id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !synced {
+			this.appendOutboxEvent(model.OutboxEvent{Topic: topic, Id: id, Command: model.OutboxCommandDelete, CreatedAt: t.UnixMilli()})
+		}
+		version, _ := this.getVersion(topic.Id, id)
+		recorded = append(recorded, model.PermissionsVersion{
+			TopicId:   topic.Id,
+			Id:        id,
+			Version:   this.nextVersion(topic.Id, id, version),
+			Timestamp: t.UnixMilli(),
+			UserId:    change.UserId,
+			Operation: change.Operation,
+			Deleted:   true,
+		})
+	}
+	if len(recorded) > 0 {
+		this.recordVersions(recorded...)
+	}
+	this.topics = slices.DeleteFunc(this.topics, func(element model.Topic) bool {
+		return element.Id == topic.Id
+	})
+	this.resources = slices.DeleteFunc(this.resources, func(element ResourceWithTime) bool {
+		return element.TopicId == topic.Id
+	})
+	descendants, err := this.updateDescendants(func(element ResourceWithTime) bool {
+		return slices.ContainsFunc(element.ancestors, func(ancestor model.ResourceReference) bool {
+			return ancestor.TopicId == topic.Id
+		})
+	}, t, change)
+	return append(recorded, descendants...), err
+}
+
+func (this *Mock) SetServiceCredential(ctx context.Context, credential model.ServiceCredential) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.credentials = slices.DeleteFunc(this.credentials, func(element model.ServiceCredential) bool {
+		return element.Id == credential.Id
+	})
+	this.credentials = append(this.credentials, credential)
+	return nil
 }
 
-func (this *Mock) DeleteTopic(ctx context.Context, id string) error {
+func (this *Mock) GetServiceCredential(ctx context.Context, id string) (result model.ServiceCredential, err error) {
 	this.mux.Lock()
 	defer this.mux.Unlock()
-	this.topics = slices.DeleteFunc(this.topics, func(topic model.Topic) bool {
-		return topic.Id == id
+	for _, credential := range this.credentials {
+		if credential.Id == id {
+			return credential, nil
+		}
+	}
+	return result, model.ErrNotFound
+}
+
+func (this *Mock) ListServiceCredentials(ctx context.Context) (result []model.ServiceCredential, err error) {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	result = slices.Clone(this.credentials)
+	slices.SortFunc(result, func(a, b model.ServiceCredential) int {
+		return strings.Compare(a.Id, b.Id)
 	})
-	this.resources = slices.DeleteFunc(this.resources, func(element ResourceWithTime) bool {
-		return element.TopicId == id
+	return result, nil
+}
+
+func (this *Mock) RemoveServiceCredential(ctx context.Context, id string) error {
+	this.mux.Lock()
+	defer this.mux.Unlock()
+	this.credentials = slices.DeleteFunc(this.credentials, func(element model.ServiceCredential) bool {
+		return element.Id == id
 	})
 	return nil
 }
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var AuditEntryBson = getBsonFieldObject[model.AuditEntry]()
+
+const AuditEntryTimestampBson = "timestamp"
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		var err error
+		collection := db.client.Database(db.config.MongoDatabase).Collection(db.config.MongoAuditCollection)
+		err = db.ensureIndex(collection, "auditbyid", AuditEntryBson.Id, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "auditbytimestamp", AuditEntryTimestampBson, false, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "auditbytopicandresource", true, false, AuditEntryBson.TopicId, AuditEntryBson.ResourceId)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "auditbyuser", AuditEntryBson.UserId, true, false)
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Database) auditCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoAuditCollection)
+}
+
+func (this *Database) AppendAuditEntry(ctx context.Context, entry model.AuditEntry) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.auditCollection().InsertOne(ctx, entry)
+	return err
+}
+
+// ListAuditEntries returns the matching audit entries, newest first
+func (this *Database) ListAuditEntries(ctx context.Context, listOptions model.AuditListOptions) (result []model.AuditEntry, err error) {
+	result = []model.AuditEntry{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	opt := options.Find()
+	if listOptions.Limit > 0 {
+		opt.SetLimit(listOptions.Limit)
+	}
+	if listOptions.Offset > 0 {
+		opt.SetSkip(listOptions.Offset)
+	}
+	opt.SetSort(bson.D{{AuditEntryTimestampBson, -1}, {AuditEntryBson.Id, -1}})
+
+	filter := bson.M{}
+	if listOptions.TopicId != "" {
+		filter[AuditEntryBson.TopicId] = listOptions.TopicId
+	}
+	if listOptions.ResourceId != "" {
+		filter[AuditEntryBson.ResourceId] = listOptions.ResourceId
+	}
+	if listOptions.UserId != "" {
+		filter[AuditEntryBson.UserId] = listOptions.UserId
+	}
+	if listOptions.Operation != "" {
+		filter[string(AuditEntryBson.Operation)] = listOptions.Operation
+	}
+	timeFilter := bson.M{}
+	if listOptions.From != 0 {
+		timeFilter["$gte"] = listOptions.From
+	}
+	if listOptions.Until != 0 {
+		timeFilter["$lt"] = listOptions.Until
+	}
+	if len(timeFilter) > 0 {
+		filter[AuditEntryTimestampBson] = timeFilter
+	}
+
+	cursor, err := this.auditCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		element := model.AuditEntry{}
+		err = cursor.Decode(&element)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, element)
+	}
+	err = cursor.Err()
+	return result, err
+}
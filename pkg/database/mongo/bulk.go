@@ -0,0 +1,179 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetResources stores all resources with one bulk write in one transaction; each resource gets the next version.
+// the returned versions are in the order of the resources.
+func (this *Database) SetResources(ctx context.Context, resources []model.Resource, t time.Time, synced bool, change ...model.Change) (versions []int64, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	if len(resources) == 0 {
+		return []int64{}, nil
+	}
+	idsByTopic := map[string][]string{}
+	known := map[ResourceReference]bool{}
+	for _, r := range resources {
+		ref := ResourceReference{TopicId: r.TopicId, Id: r.Id}
+		if known[ref] {
+			return nil, fmt.Errorf("resource %v/%v is contained more than once", r.TopicId, r.Id)
+		}
+		known[ref] = true
+		idsByTopic[r.TopicId] = append(idsByTopic[r.TopicId], r.Id)
+	}
+	//unconditional writes retry, if a concurrent write changed a version between read and write
+	for i := 0; i < setPermissionsAttempts; i++ {
+		versions, err = this.setResources(ctx, resources, idsByTopic, t, synced, changeOf(change))
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			return versions, err
+		}
+	}
+	return versions, err
+}
+
+func (this *Database) setResources(ctx context.Context, resources []model.Resource, idsByTopic map[string][]string, t time.Time, synced bool, change model.Change) (versions []int64, err error) {
+	var recorded []model.PermissionsVersion
+	err = this.transaction(ctx, func(ctx context.Context) error {
+		versions = make([]int64, 0, len(resources))
+		recorded = make([]model.PermissionsVersion, 0, len(resources))
+		current, err := this.getPermissionsEntries(ctx, idsByTopic)
+		if err != nil {
+			return err
+		}
+		lastVersions, err := this.getLastPermissionsVersions(ctx, idsByTopic)
+		if err != nil {
+			return err
+		}
+		writes := make([]mongo.WriteModel, 0, len(resources))
+		events := []model.OutboxEvent{}
+		topics := map[string]model.Topic{}
+		//parents, which are part of the same bulk write, must precede their children
+		pending := map[ResourceReference]PermissionsEntry{}
+		for _, r := range resources {
+			element, err := this.newPermissionsEntryWithPending(ctx, r.TopicId, r.Id, r.ResourcePermissions, t, pending)
+			if err != nil {
+				return err
+			}
+			ref := ResourceReference{TopicId: r.TopicId, Id: r.Id}
+			previous, found := current[ref]
+			previousVersion := previous.Version
+			element.Version = max(previousVersion, lastVersions[ref]) + 1
+			element.Creator = previous.Creator
+			if !found {
+				element.Creator = r.Creator
+			}
+			pending[ref] = element
+			versions = append(versions, element.Version)
+			recorded = append(recorded, newPermissionsVersion(element, t, change))
+			writes = append(writes, mongo.NewReplaceOneModel().
+				SetFilter(bson.M{
+					PermissionsEntryBson.TopicId: element.TopicId,
+					PermissionsEntryBson.Id:      element.Id,
+					PermissionsEntryVersionBson:  versionFilter(previousVersion),
+				}).
+				SetReplacement(element).
+				SetUpsert(true))
+			if synced {
+				continue
+			}
+			topic, ok := topics[r.TopicId]
+			if !ok {
+				topic, _, err = this.GetTopic(ctx, r.TopicId)
+				if err != nil {
+					return err
+				}
+				topic.Id = r.TopicId
+				topics[r.TopicId] = topic
+			}
+			events = append(events, model.OutboxEvent{
+				Topic:       topic,
+				Id:          element.Id,
+				Command:     model.OutboxCommandSetPermissions,
+				Permissions: element.ToResource().Effective(),
+				Owner:       element.Creator,
+				CreatedAt:   t.UnixMilli(),
+			})
+		}
+		_, err = this.permissionsCollection().BulkWrite(ctx, writes)
+		if mongo.IsDuplicateKeyError(err) {
+			//at least one entry has been changed concurrently
+			return model.ErrVersionMismatch
+		}
+		if err != nil {
+			return err
+		}
+		err = this.appendOutboxEvents(ctx, events)
+		if err != nil {
+			return err
+		}
+		err = this.recordPermissionsVersions(ctx, recorded)
+		if err != nil {
+			return err
+		}
+		err = this.appendWebhookEvents(ctx, change)
+		if err != nil {
+			return err
+		}
+		//also repairs the inherited permissions of resources, whose parent is part of the same bulk write
+		descendants, err := this.updateDescendants(ctx, descendantsOfAny(idsByTopic), t, change)
+		recorded = append(recorded, descendants...)
+		return err
+	})
+	if err != nil {
+		return versions, err
+	}
+	change.NotifyRecorded(recorded)
+	return versions, nil
+}
+
+func (this *Database) getPermissionsEntries(ctx context.Context, idsByTopic map[string][]string) (result map[ResourceReference]PermissionsEntry, err error) {
+	result = map[ResourceReference]PermissionsEntry{}
+	for topicId, ids := range idsByTopic {
+		cursor, err := this.permissionsCollection().Find(ctx, bson.M{PermissionsEntryBson.TopicId: topicId, PermissionsEntryBson.Id: bson.M{"$in": ids}})
+		if err != nil {
+			return result, err
+		}
+		entries := []PermissionsEntry{}
+		err = cursor.All(ctx, &entries)
+		if err != nil {
+			return result, err
+		}
+		for _, entry := range entries {
+			result[ResourceReference{TopicId: entry.TopicId, Id: entry.Id}] = entry
+		}
+	}
+	return result, nil
+}
+
+func descendantsOfAny(idsByTopic map[string][]string) bson.M {
+	or := bson.A{}
+	for topicId, ids := range idsByTopic {
+		or = append(or, bson.M{PermissionsEntryAncestorsBson: bson.M{"$elemMatch": bson.M{ResourceReferenceBson.TopicId: topicId, ResourceReferenceBson.Id: bson.M{"$in": ids}}}})
+	}
+	return bson.M{"$or": or}
+}
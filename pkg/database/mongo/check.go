@@ -19,6 +19,7 @@ package mongo
 import (
 	"context"
 	"slices"
+	"time"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"go.mongodb.org/mongo-driver/bson"
@@ -33,6 +34,10 @@ func (this *Database) CheckResourcePermissions(ctx context.Context, topicId stri
 }
 
 func (this *Database) CheckMultipleResourcePermissions(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, permissions ...model.Permission) (result map[string]bool, err error) {
+	return this.CheckMultipleResourcePermissionsWithMode(ctx, topicId, ids, userId, roleIds, groupIds, model.PermissionModeAll, permissions...)
+}
+
+func (this *Database) CheckMultipleResourcePermissionsWithMode(ctx context.Context, topicId string, ids []string, userId string, roleIds []string, groupIds []string, mode model.PermissionMode, permissions ...model.Permission) (result map[string]bool, err error) {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
@@ -48,35 +53,38 @@ func (this *Database) CheckMultipleResourcePermissions(ctx context.Context, topi
 		if err != nil {
 			return nil, err
 		}
-		result[element.Id] = checkPermissions(userId, roleIds, groupIds, element, permissions...)
+		result[element.Id] = checkPermissions(userId, roleIds, groupIds, element, mode, permissions...)
 	}
 
 	err = cursor.Err()
 	return result, err
 }
 
-func checkPermissions(userId string, roleIds []string, groupIds []string, element PermissionsEntry, permission ...model.Permission) bool {
-	for _, p := range permission {
+func checkPermissions(userId string, roleIds []string, groupIds []string, element PermissionsEntry, mode model.PermissionMode, permission ...model.Permission) bool {
+	now := time.Now().UnixMilli()
+	return mode.Matches(permission, func(p model.Permission) bool {
+		var granted, denied bool
 		switch p {
 		case model.Administrate:
-			if !slices.Contains(element.AdminUsers, userId) && !containsAny(element.AdminGroups, groupIds) && !containsAny(element.AdminRoles, roleIds) {
-				return false
-			}
+			granted = slices.Contains(element.AdminUsers, userId) || containsAny(element.AdminGroups, groupIds) || containsAny(element.AdminRoles, roleIds)
+			denied = slices.Contains(element.DenyAdminUsers, userId) || containsAny(element.DenyAdminGroups, groupIds) || containsAny(element.DenyAdminRoles, roleIds)
 		case model.Read:
-			if !slices.Contains(element.ReadUsers, userId) && !containsAny(element.ReadGroups, groupIds) && !containsAny(element.ReadRoles, roleIds) {
-				return false
-			}
+			granted = slices.Contains(element.ReadUsers, userId) || containsAny(element.ReadGroups, groupIds) || containsAny(element.ReadRoles, roleIds)
+			denied = slices.Contains(element.DenyReadUsers, userId) || containsAny(element.DenyReadGroups, groupIds) || containsAny(element.DenyReadRoles, roleIds)
 		case model.Write:
-			if !slices.Contains(element.WriteUsers, userId) && !containsAny(element.WriteGroups, groupIds) && !containsAny(element.WriteRoles, roleIds) {
-				return false
-			}
+			granted = slices.Contains(element.WriteUsers, userId) || containsAny(element.WriteGroups, groupIds) || containsAny(element.WriteRoles, roleIds)
+			denied = slices.Contains(element.DenyWriteUsers, userId) || containsAny(element.DenyWriteGroups, groupIds) || containsAny(element.DenyWriteRoles, roleIds)
 		case model.Execute:
-			if !slices.Contains(element.ExecuteUsers, userId) && !containsAny(element.ExecuteGroups, groupIds) && !containsAny(element.ExecuteRoles, roleIds) {
-				return false
-			}
+			granted = slices.Contains(element.ExecuteUsers, userId) || containsAny(element.ExecuteGroups, groupIds) || containsAny(element.ExecuteRoles, roleIds)
+			denied = slices.Contains(element.DenyExecuteUsers, userId) || containsAny(element.DenyExecuteGroups, groupIds) || containsAny(element.DenyExecuteRoles, roleIds)
+		default:
+			granted = element.matchesCustom(false, userId, roleIds, groupIds, p)
+			denied = element.matchesCustom(true, userId, roleIds, groupIds, p)
 		}
-	}
-	return true
+		granted = granted || element.matchesTimeBound(false, userId, roleIds, groupIds, p, now)
+		denied = denied || element.matchesTimeBound(true, userId, roleIds, groupIds, p, now)
+		return granted && !denied
+	})
 }
 
 func containsAny(list []string, any []string) bool {
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ServiceCredentialIdBson = "id"
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		return db.ensureIndex(db.credentialCollection(), "credentialbyid", ServiceCredentialIdBson, true, true)
+	})
+}
+
+func (this *Database) credentialCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoCredentialCollection)
+}
+
+func (this *Database) SetServiceCredential(ctx context.Context, credential model.ServiceCredential) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.credentialCollection().ReplaceOne(ctx, bson.M{ServiceCredentialIdBson: credential.Id}, credential, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (this *Database) GetServiceCredential(ctx context.Context, id string) (result model.ServiceCredential, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	err = this.credentialCollection().FindOne(ctx, bson.M{ServiceCredentialIdBson: id}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return result, model.ErrNotFound
+	}
+	return result, err
+}
+
+func (this *Database) ListServiceCredentials(ctx context.Context) (result []model.ServiceCredential, err error) {
+	result = []model.ServiceCredential{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	cursor, err := this.credentialCollection().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{ServiceCredentialIdBson, 1}}))
+	if err != nil {
+		return result, err
+	}
+	err = cursor.All(ctx, &result)
+	return result, err
+}
+
+func (this *Database) RemoveServiceCredential(ctx context.Context, id string) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.credentialCollection().DeleteOne(ctx, bson.M{ServiceCredentialIdBson: id})
+	return err
+}
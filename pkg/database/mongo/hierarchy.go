@@ -0,0 +1,235 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const PermissionsEntryAncestorsBson = "ancestors"
+
+var ResourceReferenceBson = getBsonFieldObject[ResourceReference]()
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		collection := db.client.Database(db.config.MongoDatabase).Collection(db.config.MongoPermissionsCollection)
+		return db.ensureCompoundIndex(collection, "permissionsbyancestor", true, false, PermissionsEntryAncestorsBson+"."+ResourceReferenceBson.TopicId, PermissionsEntryAncestorsBson+"."+ResourceReferenceBson.Id)
+	})
+}
+
+type ResourceReference struct {
+	TopicId string `json:"topic_id" bson:"topic_id"`
+	Id      string `json:"id" bson:"id"`
+}
+
+func (this *Database) getPermissionsEntry(ctx context.Context, topicId string, id string) (entry PermissionsEntry, found bool, err error) {
+	err = this.permissionsCollection().FindOne(ctx, bson.M{PermissionsEntryBson.TopicId: topicId, PermissionsEntryBson.Id: id}).Decode(&entry)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+	return entry, true, nil
+}
+
+// newPermissionsEntry creates an entry with the effective permissions as permission lists.
+// if the permissions reference a parent, the stored effective permissions of the parent are inherited.
+// returns model.ErrInvalidParent, if the parent does not exist, would create a cycle or the effective permissions contain no admin user.
+func (this *Database) newPermissionsEntry(ctx context.Context, topic string, id string, permissions model.ResourcePermissions, t time.Time) (element PermissionsEntry, err error) {
+	return this.newPermissionsEntryWithPending(ctx, topic, id, permissions, t, nil)
+}
+
+// newPermissionsEntryWithPending is newPermissionsEntry with entries of the same bulk write, which are not stored yet;
+// pending entries are preferred over stored entries as parent.
+func (this *Database) newPermissionsEntryWithPending(ctx context.Context, topic string, id string, permissions model.ResourcePermissions, t time.Time, pending map[ResourceReference]PermissionsEntry) (element PermissionsEntry, err error) {
+	element = PermissionsEntry{
+		Timestamp:     t.UnixMilli(),
+		Synced:        true,
+		TopicId:       topic,
+		Id:            id,
+		AdminUsers:    []string{},
+		AdminGroups:   []string{},
+		AdminRoles:    []string{},
+		ReadUsers:     []string{},
+		ReadGroups:    []string{},
+		ReadRoles:     []string{},
+		WriteUsers:    []string{},
+		WriteGroups:   []string{},
+		WriteRoles:    []string{},
+		ExecuteUsers:  []string{},
+		ExecuteGroups: []string{},
+		ExecuteRoles:  []string{},
+		Ancestors:     []ResourceReference{},
+	}
+	if !permissions.HasParent() {
+		permissions.Parent = nil
+		element.setResourcePermissions(permissions)
+		return element, nil
+	}
+	own := permissions
+	element.OwnPermissions = &own
+	parentRef := ResourceReference{TopicId: permissions.Parent.TopicId, Id: permissions.Parent.Id}
+	element.Parent = &parentRef
+	parent, found := pending[parentRef]
+	if !found {
+		parent, found, err = this.getPermissionsEntry(ctx, parentRef.TopicId, parentRef.Id)
+		if err != nil {
+			return element, err
+		}
+	}
+	if !found {
+		return element, fmt.Errorf("%w: unknown parent %v/%v", model.ErrInvalidParent, parentRef.TopicId, parentRef.Id)
+	}
+	element.Ancestors = append(element.Ancestors, parent.Ancestors...)
+	effective := permissions.Inherit(parent.ToResource().Effective())
+	element.Ancestors = append(element.Ancestors, parentRef)
+	if slices.Contains(element.Ancestors, ResourceReference{TopicId: topic, Id: id}) {
+		return element, fmt.Errorf("%w: %v/%v would be its own ancestor", model.ErrInvalidParent, topic, id)
+	}
+	if !effective.HasAdminUser() {
+		return element, fmt.Errorf("%w: %v/%v would have no admin user", model.ErrInvalidParent, topic, id)
+	}
+	element.setResourcePermissions(effective)
+	return element, nil
+}
+
+// storePermissionsEntry replaces the stored entry and appends an outbox event with the effective permissions if !synced.
+// the stored entry must still have the previous version (0 if it does not exist); returns model.ErrVersionMismatch if it has been changed concurrently.
+func (this *Database) storePermissionsEntry(ctx context.Context, element PermissionsEntry, previousVersion int64, t time.Time, synced bool) error {
+	_, err := this.permissionsCollection().ReplaceOne(ctx, bson.M{
+		PermissionsEntryBson.TopicId: element.TopicId,
+		PermissionsEntryBson.Id:      element.Id,
+		PermissionsEntryVersionBson:  versionFilter(previousVersion),
+	}, element, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		//no entry with the previous version found and the upsert collides with the changed entry
+		return model.ErrVersionMismatch
+	}
+	if err != nil {
+		return err
+	}
+	if synced {
+		return nil
+	}
+	topicSnapshot, _, err := this.GetTopic(ctx, element.TopicId)
+	if err != nil {
+		return err
+	}
+	topicSnapshot.Id = element.TopicId
+	return this.appendOutboxEvent(ctx, model.OutboxEvent{
+		Topic:       topicSnapshot,
+		Id:          element.Id,
+		Command:     model.OutboxCommandSetPermissions,
+		Permissions: element.ToResource().Effective(),
+		Owner:       element.Creator,
+		CreatedAt:   t.UnixMilli(),
+	})
+}
+
+// updateDescendants recomputes the effective permissions of all entries matching the filter (descendants of a changed or removed resource).
+// entries are updated parents first; changes of resources in published topics are added to the outbox.
+// entries, whose parent has been removed, are detached: the inherited entries become own entries and the parent reference is removed,
+// so that the effective permissions (and admins) of the entry and its descendants stay unchanged.
+// changed entries get the next version, which is recorded with the user of the change and the operation model.AuditOperationInheritedChange.
+func (this *Database) updateDescendants(ctx context.Context, filter bson.M, t time.Time, change model.Change) (recorded []model.PermissionsVersion, err error) {
+	cursor, err := this.permissionsCollection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	descendants := []PermissionsEntry{}
+	err = cursor.All(ctx, &descendants)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(descendants, func(a, b PermissionsEntry) int {
+		if len(a.Ancestors) != len(b.Ancestors) {
+			return len(a.Ancestors) - len(b.Ancestors)
+		}
+		return strings.Compare(a.TopicId+"/"+a.Id, b.TopicId+"/"+b.Id)
+	})
+	change.Operation = model.AuditOperationInheritedChange
+	topics := map[string]model.Topic{}
+	for _, descendant := range descendants {
+		topic, ok := topics[descendant.TopicId]
+		if !ok {
+			topic, _, err = this.GetTopic(ctx, descendant.TopicId)
+			if err != nil {
+				return nil, err
+			}
+			topics[descendant.TopicId] = topic
+		}
+		permissions := descendant.ToResource().ResourcePermissions
+		if permissions.HasParent() {
+			_, parentExists, err := this.getPermissionsEntry(ctx, permissions.Parent.TopicId, permissions.Parent.Id)
+			if err != nil {
+				return nil, err
+			}
+			if !parentExists {
+				permissions = descendant.ToResource().Effective()
+				permissions.Parent = nil
+			}
+		}
+		element, err := this.newPermissionsEntry(ctx, descendant.TopicId, descendant.Id, permissions, t)
+		if err != nil {
+			return nil, err
+		}
+		element.Version = descendant.Version
+		element.Creator = descendant.Creator
+		if isSameResource(element.ToResource(), descendant.ToResource()) {
+			//e.g. descendants of a bulk write, which are already stored with the new parent
+			continue
+		}
+		element.Version, err = this.nextPermissionsVersion(ctx, descendant.TopicId, descendant.Id, descendant.Version)
+		if err != nil {
+			return nil, err
+		}
+		err = this.storePermissionsEntry(ctx, element, descendant.Version, t, !topic.PublishesToKafka())
+		if err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, newPermissionsVersion(element, t, change))
+	}
+	return recorded, this.recordPermissionsVersions(ctx, recorded)
+}
+
+// isSameResource returns true if the own and effective permissions are equal
+func isSameResource(a model.Resource, b model.Resource) bool {
+	return reflect.DeepEqual(a.ResourcePermissions, b.ResourcePermissions) && reflect.DeepEqual(a.EffectivePermissions, b.EffectivePermissions)
+}
+
+func descendantsOf(topicId string, id string) bson.M {
+	return bson.M{PermissionsEntryAncestorsBson: bson.M{"$elemMatch": bson.M{ResourceReferenceBson.TopicId: topicId, ResourceReferenceBson.Id: id}}}
+}
+
+func descendantsOfTopicInOtherTopics(topicId string) bson.M {
+	return bson.M{
+		PermissionsEntryAncestorsBson + "." + ResourceReferenceBson.TopicId: topicId,
+		PermissionsEntryBson.TopicId:                                        bson.M{"$ne": topicId},
+	}
+}
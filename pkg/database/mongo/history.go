@@ -0,0 +1,242 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var PermissionsVersionBson = getBsonFieldObject[model.PermissionsVersion]()
+
+const PermissionsVersionVersionBson = "version"
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		collection := db.client.Database(db.config.MongoDatabase).Collection(db.config.MongoHistoryCollection)
+		return db.ensureCompoundIndex(collection, "historybytopicidandversion", true, true, PermissionsVersionBson.TopicId, PermissionsVersionBson.Id, PermissionsVersionVersionBson)
+	})
+}
+
+func (this *Database) historyCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoHistoryCollection)
+}
+
+// newPermissionsVersion returns the snapshot of the own and effective permissions of the stored entry
+func newPermissionsVersion(element PermissionsEntry, t time.Time, change model.Change) model.PermissionsVersion {
+	resource := element.ToResource()
+	return model.PermissionsVersion{
+		TopicId:              element.TopicId,
+		Id:                   element.Id,
+		Version:              element.Version,
+		Timestamp:            t.UnixMilli(),
+		UserId:               change.UserId,
+		Operation:            change.Operation,
+		Permissions:          resource.ResourcePermissions,
+		EffectivePermissions: resource.EffectivePermissions,
+	}
+}
+
+// recordPermissionsVersions stores the versions in the history.
+// should be called in the same transaction as the change, that is described by the versions;
+// returns model.ErrVersionMismatch if a concurrent change recorded the same version.
+func (this *Database) recordPermissionsVersions(ctx context.Context, versions []model.PermissionsVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, 0, len(versions))
+	for _, version := range versions {
+		documents = append(documents, version)
+	}
+	_, err := this.historyCollection().InsertMany(ctx, documents)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.ErrVersionMismatch
+	}
+	return err
+}
+
+// nextPermissionsVersion returns the version of the next change of a resource, which is stored with the given version (0 if it does not exist).
+// the version continues after the last recorded version, so that recreated resources do not reuse the versions of their removed predecessor.
+func (this *Database) nextPermissionsVersion(ctx context.Context, topicId string, id string, stored int64) (int64, error) {
+	last, err := this.getLastPermissionsVersion(ctx, topicId, id)
+	if err != nil {
+		return 0, err
+	}
+	return max(last, stored) + 1, nil
+}
+
+// getLastPermissionsVersions returns the last recorded version of each resource; resources without versions are not contained
+func (this *Database) getLastPermissionsVersions(ctx context.Context, idsByTopic map[string][]string) (result map[ResourceReference]int64, err error) {
+	result = map[ResourceReference]int64{}
+	for topicId, ids := range idsByTopic {
+		cursor, err := this.historyCollection().Aggregate(ctx, mongo.Pipeline{
+			{{"$match", bson.M{PermissionsVersionBson.TopicId: topicId, PermissionsVersionBson.Id: bson.M{"$in": ids}}}},
+			{{"$group", bson.M{"_id": "$" + PermissionsVersionBson.Id, "version": bson.M{"$max": "$" + PermissionsVersionVersionBson}}}},
+		})
+		if err != nil {
+			return result, err
+		}
+		elements := []struct {
+			Id      string `bson:"_id"`
+			Version int64  `bson:"version"`
+		}{}
+		err = cursor.All(ctx, &elements)
+		if err != nil {
+			return result, err
+		}
+		for _, element := range elements {
+			result[ResourceReference{TopicId: topicId, Id: element.Id}] = element.Version
+		}
+	}
+	return result, nil
+}
+
+func (this *Database) getLastPermissionsVersion(ctx context.Context, topicId string, id string) (int64, error) {
+	element := model.PermissionsVersion{}
+	err := this.historyCollection().FindOne(ctx, bson.M{
+		PermissionsVersionBson.TopicId: topicId,
+		PermissionsVersionBson.Id:      id,
+	}, options.FindOne().SetSort(bson.D{{PermissionsVersionVersionBson, -1}})).Decode(&element)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return element.Version, nil
+}
+
+// ListPermissionsVersions returns the stored versions of a resource, newest first
+func (this *Database) ListPermissionsVersions(ctx context.Context, topicId string, id string, listOptions model.ListOptions) (result []model.PermissionsVersion, err error) {
+	result = []model.PermissionsVersion{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	opt := options.Find()
+	if listOptions.Limit > 0 {
+		opt.SetLimit(listOptions.Limit)
+	}
+	if listOptions.Offset > 0 {
+		opt.SetSkip(listOptions.Offset)
+	}
+	opt.SetSort(bson.D{{PermissionsVersionVersionBson, -1}})
+	cursor, err := this.historyCollection().Find(ctx, bson.M{
+		PermissionsVersionBson.TopicId: topicId,
+		PermissionsVersionBson.Id:      id,
+	}, opt)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		element := model.PermissionsVersion{}
+		err = cursor.Decode(&element)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, element)
+	}
+	err = cursor.Err()
+	return result, err
+}
+
+func (this *Database) GetPermissionsVersion(ctx context.Context, topicId string, id string, version int64) (result model.PermissionsVersion, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	err = this.historyCollection().FindOne(ctx, bson.M{
+		PermissionsVersionBson.TopicId: topicId,
+		PermissionsVersionBson.Id:      id,
+		PermissionsVersionVersionBson:  version,
+	}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return result, model.ErrNotFound
+	}
+	return result, err
+}
+
+// change stream errors of unknown or outdated resume tokens (InvalidResumeToken, ChangeStreamFatalError, ChangeStreamHistoryLost)
+var expiredResumeTokenErrorCodes = []int{260, 280, 286}
+
+// WatchPermissionsVersions uses a change stream on the history collection; the resume token is the _data field of the change stream resume token.
+// change streams need a replica set or sharded cluster.
+func (this *Database) WatchPermissionsVersions(ctx context.Context, resumeToken string) (watch model.VersionWatch, err error) {
+	opt := options.ChangeStream()
+	if resumeToken != "" {
+		if _, err := hex.DecodeString(resumeToken); err != nil {
+			return nil, model.ErrResumeTokenExpired
+		}
+		opt.SetStartAfter(bson.M{"_data": resumeToken})
+	}
+	stream, err := this.historyCollection().Watch(ctx, mongo.Pipeline{{{"$match", bson.M{"operationType": "insert"}}}}, opt)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, handleWatchError(ctx, err)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		_ = stream.Close(context.Background())
+	})
+	return func(handler func(token string, version model.PermissionsVersion) error) error {
+		if !stop() {
+			//ctx is done and the stream is closed
+			return nil
+		}
+		defer stream.Close(context.Background())
+		for stream.Next(ctx) {
+			element := struct {
+				FullDocument model.PermissionsVersion `bson:"fullDocument"`
+			}{}
+			err := stream.Decode(&element)
+			if err != nil {
+				return err
+			}
+			token, ok := stream.ResumeToken().Lookup("_data").StringValueOK()
+			if !ok {
+				return errors.New("unexpected change stream resume token")
+			}
+			err = handler(token, element.FullDocument)
+			if err != nil {
+				return err
+			}
+		}
+		return handleWatchError(ctx, stream.Err())
+	}, nil
+}
+
+func handleWatchError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range expiredResumeTokenErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return model.ErrResumeTokenExpired
+			}
+		}
+	}
+	return err
+}
@@ -19,7 +19,9 @@ package mongo
 import (
 	"context"
 	"errors"
+	"regexp"
 	"runtime/debug"
+	"time"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"go.mongodb.org/mongo-driver/bson"
@@ -45,7 +47,7 @@ func (this *Database) GetResource(ctx context.Context, topicId string, id string
 		return resource, err
 	}
 	if options.CheckPermission {
-		if !checkPermissions(options.UserId, options.RoleIds, options.GroupIds, entry, options.Permissions...) {
+		if !checkPermissions(options.UserId, options.RoleIds, options.GroupIds, entry, model.PermissionModeAll, options.Permissions...) {
 			return resource, model.PermissionCheckFailed
 		}
 	}
@@ -58,19 +60,16 @@ func (this *Database) AdminListResourceIds(ctx context.Context, topicId string,
 		ctx, _ = getTimeoutContext()
 	}
 
-	opt := options.Find()
-	if listOptions.Limit > 0 {
-		opt.SetLimit(listOptions.Limit)
-	}
-	if listOptions.Offset > 0 {
-		opt.SetSkip(listOptions.Offset)
-	}
-	opt.SetSort(bson.D{{PermissionsEntryBson.Id, 1}})
+	opt := findOptions(listOptions, PermissionsEntryBson.Id, PermissionsEntryTimestampBson)
 
 	filter := bson.M{PermissionsEntryBson.TopicId: topicId}
 	if listOptions.Ids != nil {
 		filter[PermissionsEntryBson.Id] = bson.M{"$in": listOptions.Ids}
 	}
+	err = setIdFilter(filter, PermissionsEntryBson.Id, listOptions, true)
+	if err != nil {
+		return result, err
+	}
 	cursor, err := this.permissionsCollection().Find(ctx, filter, opt)
 	if err != nil {
 		return result, err
@@ -95,19 +94,16 @@ func (this *Database) AdminListResources(ctx context.Context, topicId string, li
 		ctx, _ = getTimeoutContext()
 	}
 
-	opt := options.Find()
-	if listOptions.Limit > 0 {
-		opt.SetLimit(listOptions.Limit)
-	}
-	if listOptions.Offset > 0 {
-		opt.SetSkip(listOptions.Offset)
-	}
-	opt.SetSort(bson.D{{PermissionsEntryBson.Id, 1}})
+	opt := findOptions(listOptions, PermissionsEntryBson.Id, PermissionsEntryTimestampBson)
 
 	filter := bson.M{PermissionsEntryBson.TopicId: topicId}
 	if listOptions.Ids != nil {
 		filter[PermissionsEntryBson.Id] = bson.M{"$in": listOptions.Ids}
 	}
+	err = setIdFilter(filter, PermissionsEntryBson.Id, listOptions, true)
+	if err != nil {
+		return result, err
+	}
 	cursor, err := this.permissionsCollection().Find(ctx, filter, opt)
 	if err != nil {
 		debug.PrintStack()
@@ -132,7 +128,11 @@ func (this *Database) AdminListResources(ctx context.Context, topicId string, li
 }
 
 func (this *Database) ListResourceIdsByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, permissions ...model.Permission) ([]string, error) {
-	temp, err := this.ListResourcesByPermissions(ctx, topicId, userId, roleIds, groupIds, options, permissions...)
+	return this.ListResourceIdsByPermissionsWithMode(ctx, topicId, userId, roleIds, groupIds, options, model.PermissionModeAll, permissions...)
+}
+
+func (this *Database) ListResourceIdsByPermissionsWithMode(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, options model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) ([]string, error) {
+	temp, err := this.listResourcesByPermissions(ctx, topicId, userId, roleIds, groupIds, options, mode, permissions...)
 	if err != nil {
 		return nil, err
 	}
@@ -144,42 +144,238 @@ func (this *Database) ListResourceIdsByPermissions(ctx context.Context, topicId
 }
 
 func (this *Database) ListResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, listOptions model.ListOptions, permissions ...model.Permission) (result []model.Resource, err error) {
+	return this.listResourcesByPermissions(ctx, topicId, userId, roleIds, groupIds, listOptions, model.PermissionModeAll, permissions...)
+}
+
+func (this *Database) listResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, listOptions model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (result []model.Resource, err error) {
 	result = []model.Resource{}
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
+	filter, err := resourcesByPermissionsFilter(topicId, userId, roleIds, groupIds, mode, permissions...)
+	if err != nil {
+		return result, err
+	}
+	err = setIdFilter(filter, PermissionsEntryBson.Id, listOptions, true)
+	if err != nil {
+		return result, err
+	}
+
+	opt := findOptions(listOptions, PermissionsEntryBson.Id, PermissionsEntryTimestampBson)
+
+	cursor, err := this.permissionsCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		element := PermissionsEntry{}
+		err = cursor.Decode(&element)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, element.ToResource())
+	}
+
+	err = cursor.Err()
+	return result, err
+}
+
+// CountResourcesByPermissions counts the resources listed by ListResourceIdsByPermissionsWithMode; paging, cursor and sorting of the options are ignored
+func (this *Database) CountResourcesByPermissions(ctx context.Context, topicId string, userId string, roleIds []string, groupIds []string, listOptions model.ListOptions, mode model.PermissionMode, permissions ...model.Permission) (count int64, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	filter, err := resourcesByPermissionsFilter(topicId, userId, roleIds, groupIds, mode, permissions...)
+	if err != nil {
+		return 0, err
+	}
+	err = setIdFilter(filter, PermissionsEntryBson.Id, listOptions, false)
+	if err != nil {
+		return 0, err
+	}
+	return this.permissionsCollection().CountDocuments(ctx, filter, countOptions(listOptions))
+}
+
+// AdminCountResources counts the resources listed by AdminListResources; paging, cursor and sorting of the options are ignored
+func (this *Database) AdminCountResources(ctx context.Context, topicId string, listOptions model.ListOptions) (count int64, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	filter := bson.M{PermissionsEntryBson.TopicId: topicId}
+	if listOptions.Ids != nil {
+		filter[PermissionsEntryBson.Id] = bson.M{"$in": listOptions.Ids}
+	}
+	err = setIdFilter(filter, PermissionsEntryBson.Id, listOptions, false)
+	if err != nil {
+		return 0, err
+	}
+	return this.permissionsCollection().CountDocuments(ctx, filter, countOptions(listOptions))
+}
+
+// resourcesByPermissionsFilter matches the resources of the topic, where the user, one of the roles or one of the groups is granted the permissions
+func resourcesByPermissionsFilter(topicId string, userId string, roleIds []string, groupIds []string, mode model.PermissionMode, permissions ...model.Permission) (filter bson.M, err error) {
 	if groupIds == nil {
 		groupIds = []string{}
 	}
 	if roleIds == nil {
 		roleIds = []string{}
 	}
+	now := time.Now().UnixMilli()
+	//each permission is matched by a grant without deny
 	permissionsFilter := bson.A{}
 	for _, r := range permissions {
+		var grant, deny bson.M
 		switch r {
 		case 'r':
-			permissionsFilter = append(permissionsFilter, bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.ReadUsers[0]: userId}, bson.M{PermissionsEntryBson.ReadGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.ReadRoles[0]: bson.M{"$in": roleIds}}}})
+			grant = bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.ReadUsers[0]: userId}, bson.M{PermissionsEntryBson.ReadGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.ReadRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(false, TimeBoundEntryReadBson, true, userId, roleIds, groupIds, now)}}
+			deny = bson.M{"$nor": bson.A{bson.M{PermissionsEntryBson.DenyReadUsers[0]: userId}, bson.M{PermissionsEntryBson.DenyReadGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.DenyReadRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(true, TimeBoundEntryReadBson, true, userId, roleIds, groupIds, now)}}
 		case 'w':
-			permissionsFilter = append(permissionsFilter, bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.WriteUsers[0]: userId}, bson.M{PermissionsEntryBson.WriteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.WriteRoles[0]: bson.M{"$in": roleIds}}}})
+			grant = bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.WriteUsers[0]: userId}, bson.M{PermissionsEntryBson.WriteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.WriteRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(false, TimeBoundEntryWriteBson, true, userId, roleIds, groupIds, now)}}
+			deny = bson.M{"$nor": bson.A{bson.M{PermissionsEntryBson.DenyWriteUsers[0]: userId}, bson.M{PermissionsEntryBson.DenyWriteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.DenyWriteRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(true, TimeBoundEntryWriteBson, true, userId, roleIds, groupIds, now)}}
 		case 'x':
-			permissionsFilter = append(permissionsFilter, bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.ExecuteUsers[0]: userId}, bson.M{PermissionsEntryBson.ExecuteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.ExecuteRoles[0]: bson.M{"$in": roleIds}}}})
+			grant = bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.ExecuteUsers[0]: userId}, bson.M{PermissionsEntryBson.ExecuteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.ExecuteRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(false, TimeBoundEntryExecuteBson, true, userId, roleIds, groupIds, now)}}
+			deny = bson.M{"$nor": bson.A{bson.M{PermissionsEntryBson.DenyExecuteUsers[0]: userId}, bson.M{PermissionsEntryBson.DenyExecuteGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.DenyExecuteRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(true, TimeBoundEntryExecuteBson, true, userId, roleIds, groupIds, now)}}
 		case 'a':
-			permissionsFilter = append(permissionsFilter, bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.AdminUsers[0]: userId}, bson.M{PermissionsEntryBson.AdminGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.AdminRoles[0]: bson.M{"$in": roleIds}}}})
+			grant = bson.M{"$or": bson.A{bson.M{PermissionsEntryBson.AdminUsers[0]: userId}, bson.M{PermissionsEntryBson.AdminGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.AdminRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(false, TimeBoundEntryAdministrateBson, true, userId, roleIds, groupIds, now)}}
+			deny = bson.M{"$nor": bson.A{bson.M{PermissionsEntryBson.DenyAdminUsers[0]: userId}, bson.M{PermissionsEntryBson.DenyAdminGroups[0]: bson.M{"$in": groupIds}}, bson.M{PermissionsEntryBson.DenyAdminRoles[0]: bson.M{"$in": roleIds}}, timeBoundFilter(true, TimeBoundEntryAdministrateBson, true, userId, roleIds, groupIds, now)}}
 		default:
-			return []model.Resource{}, errors.New("invalid permissions parameter")
+			if !r.IsValid() {
+				return nil, errors.New("invalid permissions parameter")
+			}
+			grant = bson.M{"$or": bson.A{customFilter(false, r, userId, roleIds, groupIds), timeBoundFilter(false, TimeBoundEntryBson.Custom[0], string(r), userId, roleIds, groupIds, now)}}
+			deny = bson.M{"$nor": bson.A{customFilter(true, r, userId, roleIds, groupIds), timeBoundFilter(true, TimeBoundEntryBson.Custom[0], string(r), userId, roleIds, groupIds, now)}}
+		}
+		permissionsFilter = append(permissionsFilter, bson.M{"$and": bson.A{grant, deny}})
+	}
+
+	filter = bson.M{PermissionsEntryBson.TopicId: topicId}
+	if mode == model.PermissionModeAny && len(permissionsFilter) > 0 {
+		filter["$or"] = permissionsFilter
+	} else {
+		filter["$and"] = permissionsFilter
+	}
+	return filter, nil
+}
+
+// setIdFilter restricts the filter to elements with an id matching IdPrefix or IdRegex of the list options;
+// withCursor additionally restricts it to elements following the cursor in sort order
+func setIdFilter(filter bson.M, idField string, listOptions model.ListOptions, withCursor bool) error {
+	err := listOptions.Validate()
+	if err != nil {
+		return err
+	}
+	condition, ok := filter[idField].(bson.M)
+	if !ok {
+		condition = bson.M{}
+	}
+	if listOptions.IdPrefix != "" {
+		condition["$regex"] = "^" + regexp.QuoteMeta(listOptions.IdPrefix)
+	}
+	if listOptions.IdRegex != "" {
+		condition["$regex"] = listOptions.IdRegex
+	}
+	after, _ := listOptions.After()
+	if withCursor && after != "" {
+		if listOptions.SortDesc {
+			condition["$lt"] = after
+		} else {
+			condition["$gt"] = after
 		}
 	}
+	if len(condition) > 0 {
+		filter[idField] = condition
+	}
+	return nil
+}
+
+// listMaxTime limits the server side execution of list and count queries with id_regex,
+// which is evaluated by the mongodb regex engine and may backtrack
+const listMaxTime = 5 * time.Second
 
+// findOptions returns the paging and sorting of the list options; elements with equal timestamps are sorted by id
+func findOptions(listOptions model.ListOptions, idField string, timestampField string) *options.FindOptions {
 	opt := options.Find()
+	if listOptions.IdRegex != "" {
+		opt.SetMaxTime(listMaxTime)
+	}
 	if listOptions.Limit > 0 {
 		opt.SetLimit(listOptions.Limit)
 	}
 	if listOptions.Offset > 0 {
 		opt.SetSkip(listOptions.Offset)
 	}
-	opt.SetSort(bson.D{{PermissionsEntryBson.Id, 1}})
+	direction := 1
+	if listOptions.SortDesc {
+		direction = -1
+	}
+	if listOptions.SortBy == model.SortByTimestamp {
+		opt.SetSort(bson.D{{timestampField, direction}, {idField, direction}})
+	} else {
+		opt.SetSort(bson.D{{idField, direction}})
+	}
+	return opt
+}
+
+// countOptions limits the execution time of counts with id_regex like findOptions
+func countOptions(listOptions model.ListOptions) *options.CountOptions {
+	opt := options.Count()
+	if listOptions.IdRegex != "" {
+		opt.SetMaxTime(listMaxTime)
+	}
+	return opt
+}
 
-	filter := bson.M{PermissionsEntryBson.TopicId: topicId, "$and": permissionsFilter}
+// customFilter matches entries with a custom permission grant (or deny) for the user, one of the roles or one of the groups
+func customFilter(deny bool, permission model.Permission, userId string, roleIds []string, groupIds []string) bson.M {
+	return bson.M{PermissionsEntryCustomBson: bson.M{"$elemMatch": bson.M{
+		CustomPermissionEntryDenyBson:    deny,
+		CustomPermissionEntryBson.Letter: string(permission),
+		"$or": bson.A{
+			bson.M{CustomPermissionEntryBson.Kind: TimeBoundKindUser, CustomPermissionEntryBson.Id: userId},
+			bson.M{CustomPermissionEntryBson.Kind: TimeBoundKindGroup, CustomPermissionEntryBson.Id: bson.M{"$in": groupIds}},
+			bson.M{CustomPermissionEntryBson.Kind: TimeBoundKindRole, CustomPermissionEntryBson.Id: bson.M{"$in": roleIds}},
+		},
+	}}}
+}
+
+// timeBoundFilter matches entries with a currently active time-bound grant (or deny) for the user, one of the roles or one of the groups,
+// where the permission field matches the value (true for built-in permissions; the letter for custom permissions)
+func timeBoundFilter(deny bool, permissionField string, value interface{}, userId string, roleIds []string, groupIds []string, now int64) bson.M {
+	return bson.M{PermissionsEntryTimeBoundBson: bson.M{"$elemMatch": bson.M{
+		TimeBoundEntryDenyBson:       deny,
+		permissionField:              value,
+		TimeBoundEntryValidFromBson:  bson.M{"$lte": now},
+		TimeBoundEntryValidUntilBson: bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{TimeBoundEntryBson.Kind: TimeBoundKindUser, TimeBoundEntryBson.Id: userId},
+			bson.M{TimeBoundEntryBson.Kind: TimeBoundKindGroup, TimeBoundEntryBson.Id: bson.M{"$in": groupIds}},
+			bson.M{TimeBoundEntryBson.Kind: TimeBoundKindRole, TimeBoundEntryBson.Id: bson.M{"$in": roleIds}},
+		},
+	}}}
+}
+
+func (this *Database) ListResourcesWithDueValidityChange(ctx context.Context, t time.Time, after model.ResourceReference, limit int64) (result []model.Resource, err error) {
+	result = []model.Resource{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	now := t.UnixMilli()
+	opt := options.Find()
+	if limit > 0 {
+		opt.SetLimit(limit)
+	}
+	opt.SetSort(bson.D{{PermissionsEntryBson.TopicId, 1}, {PermissionsEntryBson.Id, 1}})
+	filter := bson.M{PermissionsEntryTimeBoundBson: bson.M{"$elemMatch": bson.M{"$or": bson.A{
+		bson.M{TimeBoundEntryValidUntilBson: bson.M{"$lte": now}},
+		bson.M{TimeBoundEntryValidFromBson: bson.M{"$gt": 0, "$lte": now}},
+	}}}}
+	if after.TopicId != "" || after.Id != "" {
+		filter["$or"] = bson.A{
+			bson.M{PermissionsEntryBson.TopicId: bson.M{"$gt": after.TopicId}},
+			bson.M{PermissionsEntryBson.TopicId: after.TopicId, PermissionsEntryBson.Id: bson.M{"$gt": after.Id}},
+		}
+	}
 	cursor, err := this.permissionsCollection().Find(ctx, filter, opt)
 	if err != nil {
 		return result, err
@@ -193,7 +389,6 @@ func (this *Database) ListResourcesByPermissions(ctx context.Context, topicId st
 		}
 		result = append(result, element.ToResource())
 	}
-
 	err = cursor.Err()
 	return result, err
 }
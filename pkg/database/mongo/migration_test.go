@@ -21,6 +21,7 @@ import (
 	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/tests/docker"
+	"go.mongodb.org/mongo-driver/bson"
 	"reflect"
 	"slices"
 	"strings"
@@ -139,6 +140,14 @@ func TestMigration(t *testing.T) {
 			return
 		}
 
+		//simulate entries, stored as unsynced by versions without outbox
+		for _, key := range [][2]string{{"t1", "r1"}, {"t2", "r2"}} {
+			_, err = source.permissionsCollection().UpdateOne(ctx, bson.M{PermissionsEntryBson.TopicId: key[0], PermissionsEntryBson.Id: key[1]}, bson.M{"$set": bson.M{PermissionsEntrySyncedBson: false}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
 	})
 
 	var db *Database
@@ -258,37 +267,46 @@ func TestMigration(t *testing.T) {
 	})
 
 	t.Run("check sync state", func(t *testing.T) {
-		list, err := db.ListUnsyncedResources(ctx)
+		list, err := db.ListOutboxEvents(ctx, 0, 0)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		slices.SortFunc(list, func(a, b model.Resource) int {
-			return strings.Compare(a.TopicId+a.Id, b.TopicId+b.Id)
-		})
 
-		expected := []model.Resource{
+		//remove timestamps
+		for i, e := range list {
+			e.Topic.LastUpdateUnixTimestamp = 0
+			list[i] = e
+		}
+
+		expected := []model.OutboxEvent{
 			{
+				Seq:     1,
+				Topic:   model.Topic{Id: "t1", PublishToKafkaTopic: "t1"},
 				Id:      "r1",
-				TopicId: "t1",
-				ResourcePermissions: model.ResourcePermissions{
+				Command: model.OutboxCommandSetPermissions,
+				Permissions: model.ResourcePermissions{
 					RolePermissions:  map[string]model.PermissionsMap{},
 					GroupPermissions: map[string]model.PermissionsMap{},
 					UserPermissions: map[string]model.PermissionsMap{
 						"ut1r1": {Read: true, Write: true, Execute: true, Administrate: true},
 					},
 				},
+				CreatedAt: 10000,
 			},
 			{
+				Seq:     2,
+				Topic:   model.Topic{Id: "t2"},
 				Id:      "r2",
-				TopicId: "t2",
-				ResourcePermissions: model.ResourcePermissions{
+				Command: model.OutboxCommandSetPermissions,
+				Permissions: model.ResourcePermissions{
 					RolePermissions:  map[string]model.PermissionsMap{},
 					GroupPermissions: map[string]model.PermissionsMap{},
 					UserPermissions: map[string]model.PermissionsMap{
 						"ut2r2": {Read: true, Write: true, Execute: true, Administrate: true},
 					},
 				},
+				CreatedAt: 25000,
 			},
 		}
 
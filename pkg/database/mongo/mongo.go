@@ -37,8 +37,9 @@ import (
 )
 
 type Database struct {
-	config configuration.Config
-	client *mongo.Client
+	config       configuration.Config
+	client       *mongo.Client
+	transactions bool
 }
 
 var CreateCollections = []func(db *Database) error{}
@@ -51,6 +52,10 @@ func New(conf configuration.Config) (*Database, error) {
 		return nil, err
 	}
 	db := &Database{config: conf, client: client}
+	db.transactions = db.checkTransactionSupport(ctx)
+	if !db.transactions {
+		conf.GetLogger().WarnContext(ctx, "mongodb does not support transactions (no replica set); permission changes and their outbox events will be written without transaction")
+	}
 	for _, creators := range CreateCollections {
 		err = creators(db)
 		if err != nil {
@@ -71,6 +76,10 @@ func New(conf configuration.Config) (*Database, error) {
 			}
 		}
 	}
+	err = db.moveLegacyUnsyncedResourcesToOutbox(ctx)
+	if err != nil {
+		return nil, err
+	}
 	return db, nil
 }
 
@@ -129,6 +138,36 @@ func MigrateDb(db *Database, origConf configuration.Config) error {
 	return nil
 }
 
+// checkTransactionSupport returns true if the connected mongodb is a replica set member or mongos
+func (this *Database) checkTransactionSupport(ctx context.Context) bool {
+	result := struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}{}
+	err := this.client.Database("admin").RunCommand(ctx, bson.D{{"isMaster", 1}}).Decode(&result)
+	if err != nil {
+		this.config.GetLogger().WarnContext(ctx, "unable to check mongodb transaction support", "error", err)
+		return false
+	}
+	return result.SetName != "" || result.Msg == "isdbgrid"
+}
+
+// transaction runs f in a mongodb transaction, if supported by the database; else f is called without transaction
+func (this *Database) transaction(ctx context.Context, f func(ctx context.Context) error) error {
+	if !this.transactions {
+		return f(ctx)
+	}
+	session, err := this.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, f(sessionCtx)
+	})
+	return err
+}
+
 func (this *Database) CreateId() string {
 	return uuid.NewString()
 }
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var OutboxEventBson = getBsonFieldObject[model.OutboxEvent]()
+
+const OutboxEventSeqBson = "seq"
+const OutboxEventAttemptsBson = "attempts"
+const OutboxEventNextAttemptBson = "nextattempt"
+
+const outboxSequenceId = "sequence"
+const outboxLeaseId = "relay_lease"
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		var err error
+		collection := db.client.Database(db.config.MongoDatabase).Collection(db.config.MongoOutboxCollection)
+		err = db.ensureIndex(collection, "outboxbyseq", OutboxEventSeqBson, true, true)
+		if err != nil {
+			return err
+		}
+		//ensures the existence of the state collection and the sequence document, which may not be created in a transaction
+		ctx, _ := getTimeoutContext()
+		_, err = db.outboxStateCollection().UpdateOne(ctx, bson.M{"_id": outboxSequenceId}, bson.M{"$inc": bson.M{"value": int64(0)}}, options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (this *Database) outboxCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoOutboxCollection)
+}
+
+func (this *Database) outboxStateCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoOutboxStateCollection)
+}
+
+// appendOutboxEvent sets event.Seq and stores the event.
+// should be called in the same transaction as the change that is described by the event.
+func (this *Database) appendOutboxEvent(ctx context.Context, event model.OutboxEvent) error {
+	return this.appendOutboxEvents(ctx, []model.OutboxEvent{event})
+}
+
+// appendOutboxEvents reserves one sequence number per event and stores the events in the given order
+func (this *Database) appendOutboxEvents(ctx context.Context, events []model.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	sequence := struct {
+		Value int64 `bson:"value"`
+	}{}
+	err := this.outboxStateCollection().FindOneAndUpdate(ctx,
+		bson.M{"_id": outboxSequenceId},
+		bson.M{"$inc": bson.M{"value": int64(len(events))}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&sequence)
+	if err != nil {
+		return err
+	}
+	documents := make([]interface{}, 0, len(events))
+	for i, event := range events {
+		event.Seq = sequence.Value - int64(len(events)) + int64(i) + 1
+		documents = append(documents, event)
+	}
+	_, err = this.outboxCollection().InsertMany(ctx, documents)
+	return err
+}
+
+// appendWebhookEvents stores the webhook payloads of the change as outbox events.
+// should be called in the same transaction as the change.
+func (this *Database) appendWebhookEvents(ctx context.Context, change model.Change) error {
+	return this.appendOutboxEvents(ctx, change.WebhookEvents())
+}
+
+func (this *Database) ListOutboxEvents(ctx context.Context, afterSeq int64, limit int64) (result []model.OutboxEvent, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	opt := options.Find()
+	opt.SetSort(bson.D{{OutboxEventSeqBson, 1}})
+	if limit > 0 {
+		opt.SetLimit(limit)
+	}
+	cursor, err := this.outboxCollection().Find(ctx, bson.M{OutboxEventSeqBson: bson.M{"$gt": afterSeq}}, opt)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		element := model.OutboxEvent{}
+		err = cursor.Decode(&element)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, element)
+	}
+	err = cursor.Err()
+	return result, err
+}
+
+func (this *Database) RemoveOutboxEvent(ctx context.Context, seq int64) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.outboxCollection().DeleteOne(ctx, bson.M{OutboxEventSeqBson: seq})
+	return err
+}
+
+func (this *Database) SetOutboxEventRetry(ctx context.Context, seq int64, attempts int64, nextAttempt time.Time, lastError string) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.outboxCollection().UpdateOne(ctx, bson.M{OutboxEventSeqBson: seq}, bson.M{"$set": bson.M{
+		OutboxEventAttemptsBson:    attempts,
+		OutboxEventNextAttemptBson: nextAttempt.UnixMilli(),
+		OutboxEventBson.LastError:  lastError,
+	}})
+	return err
+}
+
+// AcquireOutboxLease returns true if the holder may relay the outbox for the given duration.
+// the lease is granted if it is not held by another holder or has expired.
+func (this *Database) AcquireOutboxLease(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	return this.acquireLease(ctx, outboxLeaseId, holder, duration)
+}
+
+func (this *Database) acquireLease(ctx context.Context, leaseId string, holder string, duration time.Duration) (bool, error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	now := time.Now()
+	_, err := this.outboxStateCollection().UpdateOne(ctx, bson.M{
+		"_id": leaseId,
+		"$or": []bson.M{
+			{"holder": holder},
+			{"until": bson.M{"$lt": now.UnixMilli()}},
+		},
+	}, bson.M{"$set": bson.M{
+		"holder": holder,
+		"until":  now.Add(duration).UnixMilli(),
+	}}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		//lease is held by another holder
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseOutboxLease ends the lease, if it is held by the holder, so that other instances may relay the outbox without waiting for its expiration
+func (this *Database) ReleaseOutboxLease(ctx context.Context, holder string) error {
+	return this.releaseLease(ctx, outboxLeaseId, holder)
+}
+
+func (this *Database) releaseLease(ctx context.Context, leaseId string, holder string) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.outboxStateCollection().UpdateOne(ctx, bson.M{"_id": leaseId, "holder": holder}, bson.M{"$set": bson.M{"until": int64(0)}})
+	return err
+}
+
+// moveLegacyUnsyncedResourcesToOutbox creates outbox events for resources, that have been stored as unsynced by earlier versions
+func (this *Database) moveLegacyUnsyncedResourcesToOutbox(ctx context.Context) error {
+	cursor, err := this.permissionsCollection().Find(ctx, bson.M{PermissionsEntrySyncedBson: false}, options.Find().SetSort(bson.D{{PermissionsEntryTimestampBson, 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		element := PermissionsEntry{}
+		err = cursor.Decode(&element)
+		if err != nil {
+			return err
+		}
+		this.config.GetLogger().InfoContext(ctx, "move unsynced resource to outbox", "topicId", element.TopicId, "id", element.Id)
+		err = this.transaction(ctx, func(ctx context.Context) error {
+			topic, _, err := this.GetTopic(ctx, element.TopicId)
+			if err != nil {
+				return err
+			}
+			topic.Id = element.TopicId
+			err = this.appendOutboxEvent(ctx, model.OutboxEvent{
+				Topic:       topic,
+				Id:          element.Id,
+				Command:     model.OutboxCommandSetPermissions,
+				Permissions: element.ToResource().ResourcePermissions,
+				Owner:       element.Creator,
+				CreatedAt:   element.Timestamp,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = this.permissionsCollection().UpdateOne(ctx, bson.M{
+				PermissionsEntryBson.TopicId: element.TopicId,
+				PermissionsEntryBson.Id:      element.Id,
+			}, bson.M{"$set": bson.M{PermissionsEntrySyncedBson: true}})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
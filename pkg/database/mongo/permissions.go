@@ -17,6 +17,10 @@
 package mongo
 
 import (
+	"math"
+	"slices"
+	"time"
+
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -24,7 +28,28 @@ import (
 var PermissionsEntryBson = getBsonFieldObject[PermissionsEntry]()
 
 const PermissionsEntryTimestampBson = "timestamp"
+const PermissionsEntryVersionBson = "version"
 const PermissionsEntrySyncedBson = "synced"
+const PermissionsEntryTimeBoundBson = "time_bound"
+
+const TimeBoundEntryDenyBson = "deny"
+const TimeBoundEntryReadBson = "read"
+const TimeBoundEntryWriteBson = "write"
+const TimeBoundEntryExecuteBson = "execute"
+const TimeBoundEntryAdministrateBson = "administrate"
+const TimeBoundEntryValidFromBson = "valid_from"
+const TimeBoundEntryValidUntilBson = "valid_until"
+
+var TimeBoundEntryBson = getBsonFieldObject[TimeBoundEntry]()
+
+const PermissionsEntryCustomBson = "custom"
+const CustomPermissionEntryDenyBson = "deny"
+
+var CustomPermissionEntryBson = getBsonFieldObject[CustomPermissionEntry]()
+
+const TimeBoundKindUser = "user"
+const TimeBoundKindGroup = "group"
+const TimeBoundKindRole = "role"
 
 func init() {
 	CreateCollections = append(CreateCollections, func(db *Database) error {
@@ -34,10 +59,26 @@ func init() {
 		if err != nil {
 			return err
 		}
+		err = db.ensureCompoundIndex(collection, "permissionsbytopicandtimestamp", true, false, PermissionsEntryBson.TopicId, PermissionsEntryTimestampBson, PermissionsEntryBson.Id)
+		if err != nil {
+			return err
+		}
 		err = db.removeIndex(collection, "topicbyid")
 		if err != nil {
 			return err
 		}
+		err = db.ensureIndex(collection, "permissionsbytimeboundvalidfrom", PermissionsEntryTimeBoundBson+"."+TimeBoundEntryValidFromBson, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureIndex(collection, "permissionsbytimeboundvaliduntil", PermissionsEntryTimeBoundBson+"."+TimeBoundEntryValidUntilBson, true, false)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "permissionsbycustom", true, false, PermissionsEntryCustomBson+"."+CustomPermissionEntryBson.Letter, PermissionsEntryCustomBson+"."+CustomPermissionEntryBson.Id)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -46,7 +87,7 @@ type PermissionsEntry struct {
 	TopicId       string   `json:"topic_id" bson:"topic_id"`
 	Id            string   `json:"id" bson:"id"`
 	Timestamp     int64    `json:"timestamp" bson:"timestamp"`
-	Synced        bool     `json:"synced" bson:"synced"`
+	Synced        bool     `json:"synced" bson:"synced"` //legacy: unsynced entries are moved to the outbox on startup; new entries are always stored as synced
 	AdminUsers    []string `json:"admin_users" bson:"admin_users"`
 	AdminGroups   []string `json:"admin_groups" bson:"admin_groups"`
 	AdminRoles    []string `json:"admin_roles" bson:"admin_roles"`
@@ -59,6 +100,164 @@ type PermissionsEntry struct {
 	ExecuteUsers  []string `json:"execute_users" bson:"execute_users"`
 	ExecuteGroups []string `json:"execute_groups" bson:"execute_groups"`
 	ExecuteRoles  []string `json:"execute_roles" bson:"execute_roles"`
+
+	DenyAdminUsers    []string `json:"deny_admin_users" bson:"deny_admin_users"`
+	DenyAdminGroups   []string `json:"deny_admin_groups" bson:"deny_admin_groups"`
+	DenyAdminRoles    []string `json:"deny_admin_roles" bson:"deny_admin_roles"`
+	DenyReadUsers     []string `json:"deny_read_users" bson:"deny_read_users"`
+	DenyReadGroups    []string `json:"deny_read_groups" bson:"deny_read_groups"`
+	DenyReadRoles     []string `json:"deny_read_roles" bson:"deny_read_roles"`
+	DenyWriteUsers    []string `json:"deny_write_users" bson:"deny_write_users"`
+	DenyWriteGroups   []string `json:"deny_write_groups" bson:"deny_write_groups"`
+	DenyWriteRoles    []string `json:"deny_write_roles" bson:"deny_write_roles"`
+	DenyExecuteUsers  []string `json:"deny_execute_users" bson:"deny_execute_users"`
+	DenyExecuteGroups []string `json:"deny_execute_groups" bson:"deny_execute_groups"`
+	DenyExecuteRoles  []string `json:"deny_execute_roles" bson:"deny_execute_roles"`
+
+	//grants and denies with ValidFrom or ValidUntil are only stored here and not in the lists above
+	TimeBound []TimeBoundEntry `json:"time_bound" bson:"time_bound"`
+
+	//grants and denies of custom permissions (declared by the topic), which are not time-bound
+	Custom []CustomPermissionEntry `json:"custom" bson:"custom"`
+
+	//incremented on every change of the own permissions; 0 for entries stored before versioning
+	Version int64 `json:"version" bson:"version"`
+
+	//user who created the resource; kept on updates
+	Creator string `json:"creator" bson:"creator"`
+
+	//if the resource has a parent, the lists above contain the effective (inherited) permissions and OwnPermissions the permissions set for this resource
+	Parent         *ResourceReference         `json:"parent" bson:"parent"`
+	Ancestors      []ResourceReference        `json:"ancestors" bson:"ancestors"` //parent chain, root first
+	OwnPermissions *model.ResourcePermissions `json:"own_permissions" bson:"own_permissions"`
+}
+
+type TimeBoundEntry struct {
+	Kind         string   `json:"kind" bson:"kind"` //TimeBoundKindUser, TimeBoundKindGroup or TimeBoundKindRole
+	Id           string   `json:"id" bson:"id"`
+	Deny         bool     `json:"deny" bson:"deny"`
+	Read         bool     `json:"read" bson:"read"`
+	Write        bool     `json:"write" bson:"write"`
+	Execute      bool     `json:"execute" bson:"execute"`
+	Administrate bool     `json:"administrate" bson:"administrate"`
+	Custom       []string `json:"custom" bson:"custom"`           //letters of custom permissions
+	ValidFrom    int64    `json:"valid_from" bson:"valid_from"`   //unix millis; 0 if not limited
+	ValidUntil   int64    `json:"valid_until" bson:"valid_until"` //unix millis; math.MaxInt64 if not limited
+}
+
+type CustomPermissionEntry struct {
+	Letter string `json:"letter" bson:"letter"`
+	Kind   string `json:"kind" bson:"kind"` //TimeBoundKindUser, TimeBoundKindGroup or TimeBoundKindRole
+	Id     string `json:"id" bson:"id"`
+	Deny   bool   `json:"deny" bson:"deny"`
+}
+
+func (this CustomPermissionEntry) matches(deny bool, userId string, roleIds []string, groupIds []string, permission model.Permission) bool {
+	if this.Deny != deny || this.Letter != string(permission) {
+		return false
+	}
+	switch this.Kind {
+	case TimeBoundKindUser:
+		return this.Id == userId
+	case TimeBoundKindGroup:
+		return slices.Contains(groupIds, this.Id)
+	case TimeBoundKindRole:
+		return slices.Contains(roleIds, this.Id)
+	default:
+		return false
+	}
+}
+
+func (this *PermissionsEntry) matchesCustom(deny bool, userId string, roleIds []string, groupIds []string, permission model.Permission) bool {
+	for _, entry := range this.Custom {
+		if entry.matches(deny, userId, roleIds, groupIds, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// customLetters returns the sorted letters of the granted custom permissions
+func customLetters(permissions model.PermissionsMap) (result []string) {
+	for _, p := range permissions.List() {
+		if !p.IsBuiltIn() {
+			result = append(result, string(p))
+		}
+	}
+	return result
+}
+
+func newTimeBoundEntry(kind string, id string, deny bool, permissions model.PermissionsMap) TimeBoundEntry {
+	result := TimeBoundEntry{
+		Kind:         kind,
+		Id:           id,
+		Deny:         deny,
+		Read:         permissions.Read,
+		Write:        permissions.Write,
+		Execute:      permissions.Execute,
+		Administrate: permissions.Administrate,
+		Custom:       customLetters(permissions),
+		ValidFrom:    0,
+		ValidUntil:   math.MaxInt64,
+	}
+	if permissions.ValidFrom != nil {
+		result.ValidFrom = permissions.ValidFrom.UnixMilli()
+	}
+	if permissions.ValidUntil != nil {
+		result.ValidUntil = permissions.ValidUntil.UnixMilli()
+	}
+	return result
+}
+
+func (this TimeBoundEntry) ToPermissionsMap() model.PermissionsMap {
+	result := model.PermissionsMap{
+		Read:         this.Read,
+		Write:        this.Write,
+		Execute:      this.Execute,
+		Administrate: this.Administrate,
+	}
+	for _, letter := range this.Custom {
+		if len(letter) == 1 {
+			result = result.With(model.Permission(letter[0]), true)
+		}
+	}
+	if this.ValidFrom != 0 {
+		validFrom := time.UnixMilli(this.ValidFrom).UTC()
+		result.ValidFrom = &validFrom
+	}
+	if this.ValidUntil != math.MaxInt64 {
+		validUntil := time.UnixMilli(this.ValidUntil).UTC()
+		result.ValidUntil = &validUntil
+	}
+	return result
+}
+
+func (this TimeBoundEntry) matches(userId string, roleIds []string, groupIds []string, permission model.Permission, now int64) bool {
+	if now < this.ValidFrom || now >= this.ValidUntil {
+		return false
+	}
+	if !this.ToPermissionsMap().Has(permission) {
+		return false
+	}
+	switch this.Kind {
+	case TimeBoundKindUser:
+		return this.Id == userId
+	case TimeBoundKindGroup:
+		return slices.Contains(groupIds, this.Id)
+	case TimeBoundKindRole:
+		return slices.Contains(roleIds, this.Id)
+	default:
+		return false
+	}
+}
+
+func (this *PermissionsEntry) matchesTimeBound(deny bool, userId string, roleIds []string, groupIds []string, permission model.Permission, now int64) bool {
+	for _, entry := range this.TimeBound {
+		if entry.Deny == deny && entry.matches(userId, roleIds, groupIds, permission, now) {
+			return true
+		}
+	}
+	return false
 }
 
 func (this *Database) permissionsCollection() *mongo.Collection {
@@ -69,6 +268,8 @@ func (this *PermissionsEntry) ToResource() model.Resource {
 	result := model.Resource{
 		Id:      this.Id,
 		TopicId: this.TopicId,
+		Version: this.Version,
+		Creator: this.Creator,
 		ResourcePermissions: model.ResourcePermissions{
 			UserPermissions:  map[string]model.PermissionsMap{},
 			GroupPermissions: map[string]model.PermissionsMap{},
@@ -174,5 +375,96 @@ func (this *PermissionsEntry) ToResource() model.Resource {
 		permissions.Execute = true
 		result.RolePermissions[role] = permissions
 	}
+
+	//deny maps are only set if denies exist, to keep resources without denies unchanged
+	result.UserDenies = denyListsToMap(this.DenyAdminUsers, this.DenyReadUsers, this.DenyWriteUsers, this.DenyExecuteUsers)
+	result.GroupDenies = denyListsToMap(this.DenyAdminGroups, this.DenyReadGroups, this.DenyWriteGroups, this.DenyExecuteGroups)
+	result.RoleDenies = denyListsToMap(this.DenyAdminRoles, this.DenyReadRoles, this.DenyWriteRoles, this.DenyExecuteRoles)
+
+	for _, entry := range this.Custom {
+		target := targetMap(&result.ResourcePermissions, entry.Kind, entry.Deny)
+		if target == nil || len(entry.Letter) != 1 {
+			continue
+		}
+		if *target == nil {
+			*target = map[string]model.PermissionsMap{}
+		}
+		(*target)[entry.Id] = (*target)[entry.Id].With(model.Permission(entry.Letter[0]), true)
+	}
+
+	for _, entry := range this.TimeBound {
+		target := targetMap(&result.ResourcePermissions, entry.Kind, entry.Deny)
+		if target == nil {
+			continue
+		}
+		if *target == nil {
+			*target = map[string]model.PermissionsMap{}
+		}
+		(*target)[entry.Id] = entry.ToPermissionsMap()
+	}
+
+	if this.OwnPermissions != nil {
+		effective := result.ResourcePermissions
+		effective.Parent = this.OwnPermissions.Parent
+		result.ResourcePermissions = *this.OwnPermissions
+		if result.UserPermissions == nil {
+			result.UserPermissions = map[string]model.PermissionsMap{}
+		}
+		if result.GroupPermissions == nil {
+			result.GroupPermissions = map[string]model.PermissionsMap{}
+		}
+		if result.RolePermissions == nil {
+			result.RolePermissions = map[string]model.PermissionsMap{}
+		}
+		result.EffectivePermissions = &effective
+	}
+	return result
+}
+
+// targetMap returns the grant or deny map of the permissions for the entry kind
+func targetMap(permissions *model.ResourcePermissions, kind string, deny bool) *map[string]model.PermissionsMap {
+	switch {
+	case kind == TimeBoundKindUser && !deny:
+		return &permissions.UserPermissions
+	case kind == TimeBoundKindGroup && !deny:
+		return &permissions.GroupPermissions
+	case kind == TimeBoundKindRole && !deny:
+		return &permissions.RolePermissions
+	case kind == TimeBoundKindUser && deny:
+		return &permissions.UserDenies
+	case kind == TimeBoundKindGroup && deny:
+		return &permissions.GroupDenies
+	case kind == TimeBoundKindRole && deny:
+		return &permissions.RoleDenies
+	default:
+		return nil
+	}
+}
+
+func denyListsToMap(admin []string, read []string, write []string, execute []string) (result map[string]model.PermissionsMap) {
+	if len(admin) == 0 && len(read) == 0 && len(write) == 0 && len(execute) == 0 {
+		return nil
+	}
+	result = map[string]model.PermissionsMap{}
+	for _, id := range admin {
+		permissions := result[id]
+		permissions.Administrate = true
+		result[id] = permissions
+	}
+	for _, id := range read {
+		permissions := result[id]
+		permissions.Read = true
+		result[id] = permissions
+	}
+	for _, id := range write {
+		permissions := result[id]
+		permissions.Write = true
+		result[id] = permissions
+	}
+	for _, id := range execute {
+		permissions := result[id]
+		permissions.Execute = true
+		result[id] = permissions
+	}
 	return result
 }
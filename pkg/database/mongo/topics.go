@@ -29,6 +29,8 @@ import (
 
 var TopicBson = getBsonFieldObject[model.Topic]()
 
+const TopicLastUpdateBson = "lastupdateunixtimestamp" //default bson name of model.Topic.LastUpdateUnixTimestamp
+
 func init() {
 	CreateCollections = append(CreateCollections, func(db *Database) error {
 		var err error
@@ -45,13 +47,19 @@ func (this *Database) topicsCollection() *mongo.Collection {
 	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoTopicsCollection)
 }
 
-func (this *Database) SetTopic(ctx context.Context, topic model.Topic) error {
+// SetTopic stores the topic; webhook payloads of the change are stored in the same transaction
+func (this *Database) SetTopic(ctx context.Context, topic model.Topic, change ...model.Change) error {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
 	topic.LastUpdateUnixTimestamp = time.Now().UnixMilli()
-	_, err := this.topicsCollection().ReplaceOne(ctx, bson.M{TopicBson.Id: topic.Id}, topic, options.Replace().SetUpsert(true))
-	return err
+	return this.transaction(ctx, func(ctx context.Context) error {
+		_, err := this.topicsCollection().ReplaceOne(ctx, bson.M{TopicBson.Id: topic.Id}, topic, options.Replace().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		return this.appendWebhookEvents(ctx, changeOf(change))
+	})
 }
 
 func (this *Database) GetTopic(ctx context.Context, id string) (result model.Topic, exists bool, err error) {
@@ -77,19 +85,16 @@ func (this *Database) ListTopics(ctx context.Context, listOptions model.ListOpti
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	opt := options.Find()
-	if listOptions.Limit > 0 {
-		opt.SetLimit(listOptions.Limit)
-	}
-	if listOptions.Offset > 0 {
-		opt.SetSkip(listOptions.Offset)
-	}
-	opt.SetSort(bson.D{{TopicBson.Id, 1}})
+	opt := findOptions(listOptions, TopicBson.Id, TopicLastUpdateBson)
 
 	filter := bson.M{}
 	if listOptions.Ids != nil {
 		filter[TopicBson.Id] = bson.M{"$in": listOptions.Ids}
 	}
+	err = setIdFilter(filter, TopicBson.Id, listOptions, true)
+	if err != nil {
+		return result, err
+	}
 
 	cursor, err := this.topicsCollection().Find(ctx, filter, opt)
 	if err != nil {
@@ -108,14 +113,122 @@ func (this *Database) ListTopics(ctx context.Context, listOptions model.ListOpti
 	return result, err
 }
 
-func (this *Database) DeleteTopic(ctx context.Context, id string) error {
+// deleteTopicChunkSize limits the number of resources, that are removed in one transaction, when a topic is deleted
+const deleteTopicChunkSize = 1000
+
+// DeleteTopic removes the topic and its resources; descendants in other topics are detached and their versions are recorded with the change.
+// resources are removed in chunks with one transaction each, so that large topics do not exceed the transaction limits of mongodb;
+// the topic is removed with the last chunk. if the deletion fails, a retry continues with the remaining resources.
+func (this *Database) DeleteTopic(ctx context.Context, topic model.Topic, t time.Time, synced bool, change ...model.Change) error {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	_, err := this.topicsCollection().DeleteMany(ctx, bson.M{TopicBson.Id: id})
+	for {
+		count := 0
+		var recorded []model.PermissionsVersion
+		err := this.transaction(ctx, func(ctx context.Context) (err error) {
+			count, recorded, err = this.deleteTopicResources(ctx, topic, t, synced, changeOf(change))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		changeOf(change).NotifyRecorded(recorded)
+		if count < deleteTopicChunkSize {
+			break
+		}
+	}
+	var recorded []model.PermissionsVersion
+	err := this.transaction(ctx, func(ctx context.Context) error {
+		recorded = nil
+		//resources, that have been created since the last chunk, are removed with the topic
+		for {
+			count, deleted, err := this.deleteTopicResources(ctx, topic, t, synced, changeOf(change))
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, deleted...)
+			if count < deleteTopicChunkSize {
+				break
+			}
+		}
+		_, err := this.topicsCollection().DeleteMany(ctx, bson.M{TopicBson.Id: topic.Id})
+		if err != nil {
+			return err
+		}
+		err = this.appendWebhookEvents(ctx, changeOf(change))
+		if err != nil {
+			return err
+		}
+		descendants, err := this.updateDescendants(ctx, descendantsOfTopicInOtherTopics(topic.Id), t, changeOf(change))
+		recorded = append(recorded, descendants...)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	_, err = this.permissionsCollection().DeleteMany(ctx, bson.M{PermissionsEntryBson.TopicId: id})
-	return err
+	changeOf(change).NotifyRecorded(recorded)
+	return nil
+}
+
+// deleteTopicResources removes up to deleteTopicChunkSize resources of the topic and stores their outbox events and deleted versions;
+// returns the number of removed resources
+func (this *Database) deleteTopicResources(ctx context.Context, topic model.Topic, t time.Time, synced bool, change model.Change) (count int, recorded []model.PermissionsVersion, err error) {
+	opt := options.Find().
+		SetSort(bson.D{{PermissionsEntryBson.Id, 1}}).
+		SetLimit(deleteTopicChunkSize).
+		SetProjection(bson.M{PermissionsEntryBson.Id: 1, PermissionsEntryVersionBson: 1})
+	cursor, err := this.permissionsCollection().Find(ctx, bson.M{PermissionsEntryBson.TopicId: topic.Id}, opt)
+	if err != nil {
+		return 0, nil, err
+	}
+	elements := []PermissionsEntry{}
+	err = cursor.All(ctx, &elements)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(elements) == 0 {
+		return 0, nil, nil
+	}
+	ids := make([]string, 0, len(elements))
+	for _, element := range elements {
+		ids = append(ids, element.Id)
+	}
+	last, err := this.getLastPermissionsVersions(ctx, map[string][]string{topic.Id: ids})
+	if err != nil {
+		return 0, nil, err
+	}
+	events := []model.OutboxEvent{}
+	for _, element := range elements {
+		recorded = append(recorded, model.PermissionsVersion{
+			TopicId:   topic.Id,
+			Id:        element.Id,
+			Version:   max(last[ResourceReference{TopicId: topic.Id, Id: element.Id}], element.Version) + 1,
+			Timestamp: t.UnixMilli(),
+			UserId:    change.UserId,
+			Operation: change.Operation,
+			Deleted:   true,
+		})
+		if !synced {
+			events = append(events, model.OutboxEvent{
+				Topic:     topic,
+				Id:        element.Id,
+				Command:   model.OutboxCommandDelete,
+				CreatedAt: t.UnixMilli(),
+			})
+		}
+	}
+	err = this.recordPermissionsVersions(ctx, recorded)
+	if err != nil {
+		return 0, nil, err
+	}
+	err = this.appendOutboxEvents(ctx, events)
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = this.permissionsCollection().DeleteMany(ctx, bson.M{PermissionsEntryBson.TopicId: topic.Id, PermissionsEntryBson.Id: bson.M{"$in": ids}})
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(elements), recorded, nil
 }
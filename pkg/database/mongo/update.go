@@ -18,94 +18,205 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (this *Database) SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool) (err error) {
+func (this *Database) SetResource(ctx context.Context, r model.Resource, t time.Time, synced bool, change ...model.Change) (err error) {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	return this.SetPermissions(ctx, r.TopicId, r.Id, r.ResourcePermissions, t, synced)
+	//unconditional writes retry, if a concurrent write changed the version between read and write
+	for i := 0; i < setPermissionsAttempts; i++ {
+		err = this.setPermissions(ctx, r, t, synced, nil, changeOf(change))
+		if !errors.Is(err, model.ErrVersionMismatch) {
+			return err
+		}
+	}
+	return err
 }
 
-func (this *Database) DeleteResource(ctx context.Context, topicId string, id string) error {
+// SetResourceIfVersion stores the resource, if the stored resource has the expected version (0 if the resource does not exist; model.ExpectExisting for any existing resource).
+// returns model.ErrVersionMismatch otherwise.
+func (this *Database) SetResourceIfVersion(ctx context.Context, r model.Resource, t time.Time, synced bool, expectedVersion int64, change ...model.Change) (err error) {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	_, err := this.permissionsCollection().DeleteMany(ctx, bson.M{PermissionsEntryBson.TopicId: topicId, PermissionsEntryBson.Id: id})
-	return err
+	return this.setPermissions(ctx, r, t, synced, &expectedVersion, changeOf(change))
 }
 
-func (this *Database) MarkResourceAsSynced(ctx context.Context, topicId string, id string) error {
+func (this *Database) DeleteResource(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, change ...model.Change) error {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	_, err := this.permissionsCollection().UpdateMany(ctx, bson.M{
-		PermissionsEntryBson.TopicId: topicId,
-		PermissionsEntryBson.Id:      id,
-	}, bson.M{"$set": bson.M{PermissionsEntrySyncedBson: true}})
-	return err
+	return this.deleteResource(ctx, topic, id, t, synced, nil, changeOf(change))
 }
 
-func (this *Database) ListUnsyncedResources(ctx context.Context) (result []model.Resource, err error) {
+// DeleteResourceIfVersion removes the resource, if the stored resource has the expected version (model.ExpectExisting for any version).
+// returns model.ErrVersionMismatch otherwise, also if the resource does not exist.
+func (this *Database) DeleteResourceIfVersion(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, expectedVersion int64, change ...model.Change) error {
 	if ctx == nil {
 		ctx, _ = getTimeoutContext()
 	}
-	opt := options.Find()
-	opt.SetSort(bson.D{{PermissionsEntryBson.Id, 1}})
-	cursor, err := this.permissionsCollection().Find(ctx, bson.M{
-		PermissionsEntrySyncedBson:    false,
-		PermissionsEntryTimestampBson: bson.M{"$lt": time.Now().Add(-1 * this.config.SyncAgeLimit.GetDuration()).UnixMilli()},
-	}, opt)
-	if err != nil {
-		return result, err
+	return this.deleteResource(ctx, topic, id, t, synced, &expectedVersion, changeOf(change))
+}
+
+// changeOf returns the first change or an empty change
+func changeOf(change []model.Change) model.Change {
+	if len(change) == 0 {
+		return model.Change{}
 	}
-	defer cursor.Close(ctx)
-	for cursor.Next(ctx) {
-		element := PermissionsEntry{}
-		err = cursor.Decode(&element)
+	return change[0]
+}
+
+func (this *Database) deleteResource(ctx context.Context, topic model.Topic, id string, t time.Time, synced bool, expectedVersion *int64, change model.Change) error {
+	var recorded []model.PermissionsVersion
+	err := this.transaction(ctx, func(ctx context.Context) error {
+		recorded = nil
+		current, found, err := this.getPermissionsEntry(ctx, topic.Id, id)
+		if err != nil {
+			return err
+		}
+		filter := bson.M{PermissionsEntryBson.TopicId: topic.Id, PermissionsEntryBson.Id: id}
+		if expectedVersion != nil && *expectedVersion != model.ExpectExisting {
+			filter[PermissionsEntryVersionBson] = versionFilter(*expectedVersion)
+		}
+		result, err := this.permissionsCollection().DeleteMany(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != nil && result.DeletedCount == 0 {
+			return model.ErrVersionMismatch
+		}
+		if found {
+			version, err := this.nextPermissionsVersion(ctx, topic.Id, id, current.Version)
+			if err != nil {
+				return err
+			}
+			recorded = []model.PermissionsVersion{{
+				TopicId:   topic.Id,
+				Id:        id,
+				Version:   version,
+				Timestamp: t.UnixMilli(),
+				UserId:    change.UserId,
+				Operation: change.Operation,
+				Deleted:   true,
+			}}
+			err = this.recordPermissionsVersions(ctx, recorded)
+			if err != nil {
+				return err
+			}
+		}
+		if !synced {
+			err = this.appendOutboxEvent(ctx, model.OutboxEvent{
+				Topic:     topic,
+				Id:        id,
+				Command:   model.OutboxCommandDelete,
+				CreatedAt: t.UnixMilli(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		err = this.appendWebhookEvents(ctx, change)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		result = append(result, element.ToResource())
+		//children are detached from the removed parent and keep their effective permissions
+		descendants, err := this.updateDescendants(ctx, descendantsOf(topic.Id, id), t, change)
+		recorded = append(recorded, descendants...)
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	err = cursor.Err()
-	return result, err
+	change.NotifyRecorded(recorded)
+	return nil
 }
 
 func (this *Database) SetPermissions(ctx context.Context, topic string, id string, permissions model.ResourcePermissions, t time.Time, synced bool) (err error) {
-	if ctx == nil {
-		ctx, _ = getTimeoutContext()
+	return this.SetResource(ctx, model.Resource{TopicId: topic, Id: id, ResourcePermissions: permissions}, t, synced)
+}
+
+// number of attempts of an unconditional write, if concurrent writes changed the version
+const setPermissionsAttempts = 5
+
+// setPermissions stores the permissions with the next version of the resource and records the version in the history; r.Creator is only stored for new resources.
+// if expectedVersion is not nil, the stored resource must have this version (0 if it does not exist).
+func (this *Database) setPermissions(ctx context.Context, r model.Resource, t time.Time, synced bool, expectedVersion *int64, change model.Change) (err error) {
+	topic, id := r.TopicId, r.Id
+	var recorded []model.PermissionsVersion
+	err = this.transaction(ctx, func(ctx context.Context) error {
+		recorded = nil
+		current, found, err := this.getPermissionsEntry(ctx, topic, id)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != nil && !matchesExpectedVersion(*expectedVersion, current.Version, found) {
+			return model.ErrVersionMismatch
+		}
+		element, err := this.newPermissionsEntry(ctx, topic, id, r.ResourcePermissions, t)
+		if err != nil {
+			return err
+		}
+		element.Version, err = this.nextPermissionsVersion(ctx, topic, id, current.Version)
+		if err != nil {
+			return err
+		}
+		element.Creator = current.Creator
+		if !found {
+			element.Creator = r.Creator
+		}
+		err = this.storePermissionsEntry(ctx, element, current.Version, t, synced)
+		if err != nil {
+			return err
+		}
+		recorded = []model.PermissionsVersion{newPermissionsVersion(element, t, change)}
+		err = this.recordPermissionsVersions(ctx, recorded)
+		if err != nil {
+			return err
+		}
+		err = this.appendWebhookEvents(ctx, change)
+		if err != nil {
+			return err
+		}
+		descendants, err := this.updateDescendants(ctx, descendantsOf(topic, id), t, change)
+		recorded = append(recorded, descendants...)
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	element := PermissionsEntry{
-		Timestamp:     t.UnixMilli(),
-		Synced:        synced,
-		TopicId:       topic,
-		Id:            id,
-		AdminUsers:    []string{},
-		AdminGroups:   []string{},
-		AdminRoles:    []string{},
-		ReadUsers:     []string{},
-		ReadGroups:    []string{},
-		ReadRoles:     []string{},
-		WriteUsers:    []string{},
-		WriteGroups:   []string{},
-		WriteRoles:    []string{},
-		ExecuteUsers:  []string{},
-		ExecuteGroups: []string{},
-		ExecuteRoles:  []string{},
-	}
-	element.setResourcePermissions(permissions)
-	_, err = this.permissionsCollection().ReplaceOne(ctx, bson.M{PermissionsEntryBson.TopicId: element.TopicId, PermissionsEntryBson.Id: element.Id}, element, options.Replace().SetUpsert(true))
-	return err
+	change.NotifyRecorded(recorded)
+	return nil
+}
+
+// matchesExpectedVersion returns true if the stored version matches the expected version; model.ExpectExisting matches every existing resource
+func matchesExpectedVersion(expected int64, stored int64, exists bool) bool {
+	if expected == model.ExpectExisting {
+		return exists
+	}
+	return expected == stored
+}
+
+// versionFilter matches entries with the version; entries stored before versioning have no version field and match 0
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
 }
 
 func (this *PermissionsEntry) setResourcePermissions(permissions model.ResourcePermissions) {
 	for group, permission := range permissions.GroupPermissions {
+		if permission.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindGroup, group, false, permission))
+			continue
+		}
+		this.appendCustom(TimeBoundKindGroup, group, false, permission)
 		if permission.Administrate {
 			this.AdminGroups = append(this.AdminGroups, group)
 		}
@@ -120,6 +231,11 @@ func (this *PermissionsEntry) setResourcePermissions(permissions model.ResourceP
 		}
 	}
 	for role, permission := range permissions.RolePermissions {
+		if permission.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindRole, role, false, permission))
+			continue
+		}
+		this.appendCustom(TimeBoundKindRole, role, false, permission)
 		if permission.Administrate {
 			this.AdminRoles = append(this.AdminRoles, role)
 		}
@@ -134,6 +250,11 @@ func (this *PermissionsEntry) setResourcePermissions(permissions model.ResourceP
 		}
 	}
 	for user, permission := range permissions.UserPermissions {
+		if permission.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindUser, user, false, permission))
+			continue
+		}
+		this.appendCustom(TimeBoundKindUser, user, false, permission)
 		if permission.Administrate {
 			this.AdminUsers = append(this.AdminUsers, user)
 		}
@@ -147,4 +268,72 @@ func (this *PermissionsEntry) setResourcePermissions(permissions model.ResourceP
 			this.ReadUsers = append(this.ReadUsers, user)
 		}
 	}
+	for group, deny := range permissions.GroupDenies {
+		if deny.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindGroup, group, true, deny))
+			continue
+		}
+		this.appendCustom(TimeBoundKindGroup, group, true, deny)
+		if deny.Administrate {
+			this.DenyAdminGroups = append(this.DenyAdminGroups, group)
+		}
+		if deny.Execute {
+			this.DenyExecuteGroups = append(this.DenyExecuteGroups, group)
+		}
+		if deny.Write {
+			this.DenyWriteGroups = append(this.DenyWriteGroups, group)
+		}
+		if deny.Read {
+			this.DenyReadGroups = append(this.DenyReadGroups, group)
+		}
+	}
+	for role, deny := range permissions.RoleDenies {
+		if deny.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindRole, role, true, deny))
+			continue
+		}
+		this.appendCustom(TimeBoundKindRole, role, true, deny)
+		if deny.Administrate {
+			this.DenyAdminRoles = append(this.DenyAdminRoles, role)
+		}
+		if deny.Execute {
+			this.DenyExecuteRoles = append(this.DenyExecuteRoles, role)
+		}
+		if deny.Write {
+			this.DenyWriteRoles = append(this.DenyWriteRoles, role)
+		}
+		if deny.Read {
+			this.DenyReadRoles = append(this.DenyReadRoles, role)
+		}
+	}
+	for user, deny := range permissions.UserDenies {
+		if deny.IsTimeBound() {
+			this.TimeBound = append(this.TimeBound, newTimeBoundEntry(TimeBoundKindUser, user, true, deny))
+			continue
+		}
+		this.appendCustom(TimeBoundKindUser, user, true, deny)
+		if deny.Administrate {
+			this.DenyAdminUsers = append(this.DenyAdminUsers, user)
+		}
+		if deny.Execute {
+			this.DenyExecuteUsers = append(this.DenyExecuteUsers, user)
+		}
+		if deny.Write {
+			this.DenyWriteUsers = append(this.DenyWriteUsers, user)
+		}
+		if deny.Read {
+			this.DenyReadUsers = append(this.DenyReadUsers, user)
+		}
+	}
+}
+
+func (this *PermissionsEntry) appendCustom(kind string, id string, deny bool, permissions model.PermissionsMap) {
+	for _, letter := range customLetters(permissions) {
+		this.Custom = append(this.Custom, CustomPermissionEntry{
+			Letter: letter,
+			Kind:   kind,
+			Id:     id,
+			Deny:   deny,
+		})
+	}
 }
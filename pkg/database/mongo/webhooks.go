@@ -0,0 +1,222 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var WebhookDeliveryBson = getBsonFieldObject[model.WebhookDelivery]()
+
+const WebhookIdBson = "id"
+const WebhookDeliveryCreatedAtBson = "createdat"
+const WebhookDeliveryNextAttemptBson = "nextattempt"
+
+const webhookLeaseId = "webhook_lease"
+
+func init() {
+	CreateCollections = append(CreateCollections, func(db *Database) error {
+		var err error
+		err = db.ensureIndex(db.webhookCollection(), "webhookbyid", WebhookIdBson, true, true)
+		if err != nil {
+			return err
+		}
+		collection := db.webhookDeliveryCollection()
+		err = db.ensureIndex(collection, "webhookdeliverybyid", WebhookDeliveryBson.Id, true, true)
+		if err != nil {
+			return err
+		}
+		err = db.ensureCompoundIndex(collection, "webhookdeliverybywebhookandcreation", true, false, WebhookDeliveryBson.WebhookId, WebhookDeliveryCreatedAtBson)
+		if err != nil {
+			return err
+		}
+		return db.ensureCompoundIndex(collection, "webhookdeliverybystatusandnextattempt", true, false, string(WebhookDeliveryBson.Status), WebhookDeliveryNextAttemptBson)
+	})
+}
+
+func (this *Database) webhookCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoWebhookCollection)
+}
+
+func (this *Database) webhookDeliveryCollection() *mongo.Collection {
+	return this.client.Database(this.config.MongoDatabase).Collection(this.config.MongoWebhookDeliveryCollection)
+}
+
+func (this *Database) SetWebhook(ctx context.Context, webhook model.Webhook) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.webhookCollection().ReplaceOne(ctx, bson.M{WebhookIdBson: webhook.Id}, webhook, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (this *Database) GetWebhook(ctx context.Context, id string) (result model.Webhook, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	err = this.webhookCollection().FindOne(ctx, bson.M{WebhookIdBson: id}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return result, model.ErrNotFound
+	}
+	return result, err
+}
+
+func (this *Database) ListWebhooks(ctx context.Context) (result []model.Webhook, err error) {
+	result = []model.Webhook{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	cursor, err := this.webhookCollection().Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{WebhookIdBson, 1}}))
+	if err != nil {
+		return result, err
+	}
+	err = cursor.All(ctx, &result)
+	return result, err
+}
+
+// RemoveWebhook removes the webhook and its deliveries
+func (this *Database) RemoveWebhook(ctx context.Context, id string) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.webhookCollection().DeleteOne(ctx, bson.M{WebhookIdBson: id})
+	if err != nil {
+		return err
+	}
+	_, err = this.webhookDeliveryCollection().DeleteMany(ctx, bson.M{WebhookDeliveryBson.WebhookId: id})
+	return err
+}
+
+// AddWebhookDeliveries stores the deliveries; deliveries with an already stored id are ignored
+func (this *Database) AddWebhookDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	documents := make([]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		documents = append(documents, delivery)
+	}
+	_, err := this.webhookDeliveryCollection().InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Code != 11000 { //duplicate key
+				return err
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// ListDueWebhookDeliveries returns pending deliveries with a next attempt until t, oldest first
+func (this *Database) ListDueWebhookDeliveries(ctx context.Context, t time.Time, limit int64) (result []model.WebhookDelivery, err error) {
+	result = []model.WebhookDelivery{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	opt := options.Find().SetSort(bson.D{{WebhookDeliveryCreatedAtBson, 1}, {WebhookDeliveryBson.Id, 1}})
+	if limit > 0 {
+		opt.SetLimit(limit)
+	}
+	cursor, err := this.webhookDeliveryCollection().Find(ctx, bson.M{
+		string(WebhookDeliveryBson.Status): model.WebhookDeliveryPending,
+		WebhookDeliveryNextAttemptBson:     bson.M{"$lte": t.UnixMilli()},
+	}, opt)
+	if err != nil {
+		return result, err
+	}
+	err = cursor.All(ctx, &result)
+	return result, err
+}
+
+func (this *Database) UpdateWebhookDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	result, err := this.webhookDeliveryCollection().ReplaceOne(ctx, bson.M{WebhookDeliveryBson.Id: delivery.Id}, delivery)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return model.ErrNotFound
+	}
+	return nil
+}
+
+func (this *Database) GetWebhookDelivery(ctx context.Context, webhookId string, id string) (result model.WebhookDelivery, err error) {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	err = this.webhookDeliveryCollection().FindOne(ctx, bson.M{WebhookDeliveryBson.WebhookId: webhookId, WebhookDeliveryBson.Id: id}).Decode(&result)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return result, model.ErrNotFound
+	}
+	return result, err
+}
+
+// ListWebhookDeliveries returns the deliveries of the webhook, newest first
+func (this *Database) ListWebhookDeliveries(ctx context.Context, webhookId string, listOptions model.WebhookDeliveryListOptions) (result []model.WebhookDelivery, err error) {
+	result = []model.WebhookDelivery{}
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	opt := options.Find().SetSort(bson.D{{WebhookDeliveryCreatedAtBson, -1}, {WebhookDeliveryBson.Id, -1}})
+	if listOptions.Limit > 0 {
+		opt.SetLimit(listOptions.Limit)
+	}
+	if listOptions.Offset > 0 {
+		opt.SetSkip(listOptions.Offset)
+	}
+	filter := bson.M{WebhookDeliveryBson.WebhookId: webhookId}
+	if listOptions.Status != "" {
+		filter[string(WebhookDeliveryBson.Status)] = listOptions.Status
+	}
+	cursor, err := this.webhookDeliveryCollection().Find(ctx, filter, opt)
+	if err != nil {
+		return result, err
+	}
+	err = cursor.All(ctx, &result)
+	return result, err
+}
+
+// RemoveWebhookDeliveriesBefore removes delivered and failed deliveries, that have been created before t
+func (this *Database) RemoveWebhookDeliveriesBefore(ctx context.Context, t time.Time) error {
+	if ctx == nil {
+		ctx, _ = getTimeoutContext()
+	}
+	_, err := this.webhookDeliveryCollection().DeleteMany(ctx, bson.M{
+		string(WebhookDeliveryBson.Status): bson.M{"$ne": model.WebhookDeliveryPending},
+		WebhookDeliveryCreatedAtBson:       bson.M{"$lt": t.UnixMilli()},
+	})
+	return err
+}
+
+// AcquireWebhookLease returns true if the holder may send webhook deliveries for the given duration
+func (this *Database) AcquireWebhookLease(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	return this.acquireLease(ctx, webhookLeaseId, holder, duration)
+}
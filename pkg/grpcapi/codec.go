@@ -0,0 +1,46 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the content-subtype of the service (content-type "application/grpc+json").
+// messages are the json encoded model types, as used by the http api; this is json over grpc, not protobuf,
+// so the encoding is not faster than the http api. grpc only saves the connection and request overhead.
+const CodecName = "json"
+
+func init() {
+	encoding.RegisterCodec(Codec{})
+}
+
+type Codec struct{}
+
+func (Codec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (Codec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (Codec) Name() string {
+	return CodecName
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import "github.com/SENERGY-Platform/permissions-v2/pkg/model"
+
+// permissions are encoded like the permissions query parameter of the http api (e.g. "rx")
+
+type Empty struct{}
+
+type IdRequest struct {
+	Id string `json:"id"`
+}
+
+type ListRequest struct {
+	TopicId string            `json:"topic_id,omitempty"`
+	Id      string            `json:"id,omitempty"`
+	Options model.ListOptions `json:"options"`
+}
+
+type ImportRequest struct {
+	Import  model.ImportExport        `json:"import"`
+	Options model.ImportExportOptions `json:"options"`
+}
+
+type WebhookDeliveriesRequest struct {
+	WebhookId string                           `json:"webhook_id"`
+	Options   model.WebhookDeliveryListOptions `json:"options"`
+}
+
+type WebhookDeliveryRequest struct {
+	WebhookId  string `json:"webhook_id"`
+	DeliveryId string `json:"delivery_id"`
+}
+
+type CheckRequest struct {
+	TopicId     string               `json:"topic_id"`
+	Id          string               `json:"id"`
+	Permissions string               `json:"permissions"`
+	Mode        model.PermissionMode `json:"mode,omitempty"`
+}
+
+type AccessibleRequest struct {
+	TopicId     string               `json:"topic_id"`
+	Options     model.ListOptions    `json:"options"`
+	Permissions string               `json:"permissions"`
+	Mode        model.PermissionMode `json:"mode,omitempty"`
+}
+
+type ResourcesRequest struct {
+	TopicId string   `json:"topic_id"`
+	Ids     []string `json:"ids"`
+}
+
+type ExplainRequest struct {
+	TopicId     string                `json:"topic_id"`
+	Id          string                `json:"id"`
+	OnBehalf    *model.ExplainSubject `json:"on_behalf,omitempty"`
+	Permissions string                `json:"permissions"`
+}
+
+type ResourceRequest struct {
+	TopicId         string `json:"topic_id"`
+	Id              string `json:"id"`
+	ExpectedVersion int64  `json:"expected_version,omitempty"` //only used by writes; see model.WriteOptions
+}
+
+type SetPermissionRequest struct {
+	TopicId         string                    `json:"topic_id"`
+	Id              string                    `json:"id"`
+	Permissions     model.ResourcePermissions `json:"permissions"`
+	ExpectedVersion int64                     `json:"expected_version,omitempty"`
+}
+
+type PatchPermissionRequest struct {
+	TopicId         string                 `json:"topic_id"`
+	Id              string                 `json:"id"`
+	Patch           model.PermissionsPatch `json:"patch"`
+	ExpectedVersion int64                  `json:"expected_version,omitempty"`
+}
+
+type BulkRequest struct {
+	TopicId string                      `json:"topic_id"`
+	Items   []model.BulkPermissionsItem `json:"items"`
+}
+
+type DiffRequest struct {
+	TopicId     string `json:"topic_id"`
+	Id          string `json:"id"`
+	FromVersion int64  `json:"from_version"`
+	ToVersion   int64  `json:"to_version"`
+}
+
+type RollbackRequest struct {
+	TopicId string `json:"topic_id"`
+	Id      string `json:"id"`
+	Version int64  `json:"version"`
+}
+
+type WatchRequest struct {
+	TopicId     string               `json:"topic_id"`
+	ResumeToken string               `json:"resume_token,omitempty"`
+	Permissions string               `json:"permissions,omitempty"`
+	Mode        model.PermissionMode `json:"mode,omitempty"`
+}
+
+// WriteOptions returns the options of a write request with the expected version
+func WriteOptions(expectedVersion int64) []model.WriteOptions {
+	if expectedVersion == 0 {
+		return nil
+	}
+	return []model.WriteOptions{{ExpectedVersion: expectedVersion}}
+}
+
+// ExpectedVersion returns the expected version of the first options; 0 means no check
+func ExpectedVersion(options []model.WriteOptions) int64 {
+	if len(options) == 0 {
+		return 0
+	}
+	return options[0].ExpectedVersion
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/api"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/auth"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/configuration"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// Start serves the grpc api on config.GrpcPort; an empty port or "-" disables the grpc api
+func Start(ctx context.Context, config configuration.Config, ctrl api.Controller) error {
+	if config.GrpcPort == "" || config.GrpcPort == "-" {
+		return nil
+	}
+	server, err := NewServer(config, ctrl)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", ":"+config.GrpcPort)
+	if err != nil {
+		return err
+	}
+	go func() {
+		config.GetLogger().InfoContext(ctx, "grpc listening on "+listener.Addr().String())
+		err := server.Serve(listener)
+		if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			config.GetLogger().ErrorContext(ctx, "grpc server stopped", "error", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			//change feed streams do not end on their own
+			server.Stop()
+		}
+		config.GetLogger().InfoContext(ctx, "grpc shutdown")
+	}()
+	return nil
+}
+
+// NewServer returns a grpc server with the registered service
+func NewServer(config configuration.Config, ctrl api.Controller, options ...grpc.ServerOption) (*grpc.Server, error) {
+	verifier, err := auth.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token verification config: %w", err)
+	}
+	check := &requestCheck{
+		version:     model.ClientVersion,
+		editForward: config.EditForward != "" && config.EditForward != "-",
+		trusted:     verifier.TrustedNetworks(),
+	}
+	options = append(options, grpc.ChainUnaryInterceptor(check.unary), grpc.ChainStreamInterceptor(check.stream))
+	server := grpc.NewServer(options...)
+	server.RegisterService(&ServiceDesc, ctrl)
+	return server, nil
+}
+
+type requestCheck struct {
+	version     string
+	editForward bool
+	trusted     auth.TrustedNetworks
+}
+
+func (this *requestCheck) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	err := this.check(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(this.markTrusted(ctx), req)
+}
+
+func (this *requestCheck) stream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := this.check(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: stream, ctx: this.markTrusted(stream.Context())})
+}
+
+// check rejects old clients, like the version check of the http api.
+// writes are rejected if the instance forwards edits, because the forward is only available for http requests.
+func (this *requestCheck) check(ctx context.Context, fullMethod string) error {
+	if values := metadata.ValueFromIncomingContext(ctx, VersionMetadata); len(values) > 0 && values[0] != "" && values[0] != this.version {
+		return statusError(ctx, fmt.Errorf("if metadata version is set it should be '%v'; got '%v'", this.version, values[0]), http.StatusUpgradeRequired)
+	}
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if this.editForward && writeMethods[method] {
+		return statusError(ctx, errors.New("this instance forwards edits; use the http api for changes"), http.StatusNotImplemented)
+	}
+	return nil
+}
+
+// markTrusted marks requests from trusted networks, whose tokens are not verified by the controller (see auth.WithTrustedNetwork).
+// the x-forwarded-for metadata is evaluated like the X-Forwarded-For header of the http api.
+func (this *requestCheck) markTrusted(ctx context.Context) context.Context {
+	if len(this.trusted) == 0 {
+		return ctx
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil || len(metadata.ValueFromIncomingContext(ctx, "forwarded")) > 0 {
+		return ctx
+	}
+	if this.trusted.Contains(append([]string{p.Addr.String()}, auth.ForwardedFor(metadata.ValueFromIncomingContext(ctx, "x-forwarded-for"))...)...) {
+		return auth.WithTrustedNetwork(ctx)
+	}
+	return ctx
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (this *serverStream) Context() context.Context {
+	return this.ctx
+}
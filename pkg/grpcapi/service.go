@@ -0,0 +1,354 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/api"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const ServiceName = "permissions.v2.Permissions"
+
+// method names of the service; the methods mirror the api.Controller methods with the same name
+const (
+	MethodListTopics            = "ListTopics"
+	MethodGetTopic              = "GetTopic"
+	MethodRemoveTopic           = "RemoveTopic"
+	MethodSetTopic              = "SetTopic"
+	MethodAdminListResourceIds  = "AdminListResourceIds"
+	MethodAdminCountResourceIds = "AdminCountResourceIds"
+	MethodExport                = "Export"
+	MethodImport                = "Import"
+	MethodListAuditEntries      = "ListAuditEntries"
+	MethodListWebhooks          = "ListWebhooks"
+	MethodGetWebhook            = "GetWebhook"
+	MethodSetWebhook            = "SetWebhook"
+	MethodRemoveWebhook         = "RemoveWebhook"
+	MethodListWebhookDeliveries = "ListWebhookDeliveries"
+	MethodRetryWebhookDelivery  = "RetryWebhookDelivery"
+
+	MethodListServiceCredentials    = "ListServiceCredentials"
+	MethodGetServiceCredential      = "GetServiceCredential"
+	MethodCreateServiceCredential   = "CreateServiceCredential"
+	MethodUpdateServiceCredential   = "UpdateServiceCredential"
+	MethodRenewServiceCredentialKey = "RenewServiceCredentialKey"
+	MethodRemoveServiceCredential   = "RemoveServiceCredential"
+
+	MethodCheckPermission            = "CheckPermission" //evaluated with CheckRequest.Mode
+	MethodCheckMultiplePermissions   = "CheckMultiplePermissions"
+	MethodCheckPermissionsBatch      = "CheckPermissionsBatch"
+	MethodListAccessibleResourceIds  = "ListAccessibleResourceIds"
+	MethodCountAccessibleResourceIds = "CountAccessibleResourceIds"
+	MethodListComputedPermissions    = "ListComputedPermissions"
+	MethodExplainPermission          = "ExplainPermission"
+
+	MethodListResourcesWithAdminPermission  = "ListResourcesWithAdminPermission"
+	MethodCountResourcesWithAdminPermission = "CountResourcesWithAdminPermission"
+	MethodGetResource                       = "GetResource"
+	MethodRemoveResource                    = "RemoveResource"
+	MethodSetPermission                     = "SetPermission"
+	MethodSetPermissionsBulk                = "SetPermissionsBulk"
+	MethodPatchPermission                   = "PatchPermission"
+	MethodListPermissionVersions            = "ListPermissionVersions"
+	MethodDiffPermissionVersions            = "DiffPermissionVersions"
+	MethodRollbackPermissions               = "RollbackPermissions"
+
+	MethodWatchPermissionChanges = "WatchPermissionChanges" //server stream of model.ChangeEvent
+)
+
+// writeMethods change stored permissions, topics, webhooks or service credentials
+var writeMethods = map[string]bool{
+	MethodRemoveTopic:               true,
+	MethodSetTopic:                  true,
+	MethodImport:                    true,
+	MethodSetWebhook:                true,
+	MethodRemoveWebhook:             true,
+	MethodRetryWebhookDelivery:      true,
+	MethodCreateServiceCredential:   true,
+	MethodUpdateServiceCredential:   true,
+	MethodRenewServiceCredentialKey: true,
+	MethodRemoveServiceCredential:   true,
+	MethodRemoveResource:            true,
+	MethodSetPermission:             true,
+	MethodSetPermissionsBulk:        true,
+	MethodPatchPermission:           true,
+	MethodRollbackPermissions:       true,
+}
+
+// FullMethod returns the name of the method, as used by grpc.ClientConnInterface.Invoke
+func FullMethod(method string) string {
+	return "/" + ServiceName + "/" + method
+}
+
+// ServiceDesc describes the service; the registered server must implement api.Controller
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*api.Controller)(nil),
+	Methods: []grpc.MethodDesc{
+		unary(MethodListTopics, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.ListTopicsContext(ctx, token, req.Options)
+		}),
+		unary(MethodGetTopic, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			return ctrl.GetTopicContext(ctx, token, req.Id)
+		}),
+		unary(MethodRemoveTopic, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			err, code := ctrl.RemoveTopicContext(ctx, token, req.Id)
+			return Empty{}, err, code
+		}),
+		unary(MethodSetTopic, func(ctx context.Context, ctrl api.Controller, token string, req model.Topic) (any, error, int) {
+			return ctrl.SetTopicContext(ctx, token, req)
+		}),
+		unary(MethodAdminListResourceIds, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.AdminListResourceIdsContext(ctx, token, req.TopicId, req.Options)
+		}),
+		unary(MethodAdminCountResourceIds, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.AdminCountResourceIdsContext(ctx, token, req.TopicId, req.Options)
+		}),
+		unary(MethodExport, func(ctx context.Context, ctrl api.Controller, token string, req model.ImportExportOptions) (any, error, int) {
+			return ctrl.ExportContext(ctx, token, req)
+		}),
+		unary(MethodImport, func(ctx context.Context, ctrl api.Controller, token string, req ImportRequest) (any, error, int) {
+			err, code := ctrl.ImportContext(ctx, token, req.Import, req.Options)
+			return Empty{}, err, code
+		}),
+		unary(MethodListAuditEntries, func(ctx context.Context, ctrl api.Controller, token string, req model.AuditListOptions) (any, error, int) {
+			return ctrl.ListAuditEntriesContext(ctx, token, req)
+		}),
+		unary(MethodListWebhooks, func(ctx context.Context, ctrl api.Controller, token string, req Empty) (any, error, int) {
+			return ctrl.ListWebhooksContext(ctx, token)
+		}),
+		unary(MethodGetWebhook, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			return ctrl.GetWebhookContext(ctx, token, req.Id)
+		}),
+		unary(MethodSetWebhook, func(ctx context.Context, ctrl api.Controller, token string, req model.Webhook) (any, error, int) {
+			return ctrl.SetWebhookContext(ctx, token, req)
+		}),
+		unary(MethodRemoveWebhook, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			err, code := ctrl.RemoveWebhookContext(ctx, token, req.Id)
+			return Empty{}, err, code
+		}),
+		unary(MethodListWebhookDeliveries, func(ctx context.Context, ctrl api.Controller, token string, req WebhookDeliveriesRequest) (any, error, int) {
+			return ctrl.ListWebhookDeliveriesContext(ctx, token, req.WebhookId, req.Options)
+		}),
+		unary(MethodRetryWebhookDelivery, func(ctx context.Context, ctrl api.Controller, token string, req WebhookDeliveryRequest) (any, error, int) {
+			return ctrl.RetryWebhookDeliveryContext(ctx, token, req.WebhookId, req.DeliveryId)
+		}),
+		unary(MethodListServiceCredentials, func(ctx context.Context, ctrl api.Controller, token string, req Empty) (any, error, int) {
+			return ctrl.ListServiceCredentialsContext(ctx, token)
+		}),
+		unary(MethodGetServiceCredential, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			return ctrl.GetServiceCredentialContext(ctx, token, req.Id)
+		}),
+		unary(MethodCreateServiceCredential, func(ctx context.Context, ctrl api.Controller, token string, req model.ServiceCredential) (any, error, int) {
+			return ctrl.CreateServiceCredentialContext(ctx, token, req)
+		}),
+		unary(MethodUpdateServiceCredential, func(ctx context.Context, ctrl api.Controller, token string, req model.ServiceCredential) (any, error, int) {
+			return ctrl.UpdateServiceCredentialContext(ctx, token, req)
+		}),
+		unary(MethodRenewServiceCredentialKey, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			return ctrl.RenewServiceCredentialKeyContext(ctx, token, req.Id)
+		}),
+		unary(MethodRemoveServiceCredential, func(ctx context.Context, ctrl api.Controller, token string, req IdRequest) (any, error, int) {
+			err, code := ctrl.RemoveServiceCredentialContext(ctx, token, req.Id)
+			return Empty{}, err, code
+		}),
+
+		unary(MethodCheckPermission, func(ctx context.Context, ctrl api.Controller, token string, req CheckRequest) (any, error, int) {
+			permissions, mode, err := permissionsAndMode(req.Permissions, req.Mode)
+			if err != nil {
+				return nil, err, http.StatusBadRequest
+			}
+			return ctrl.CheckPermissionWithModeContext(ctx, token, req.TopicId, req.Id, mode, permissions...)
+		}),
+		unary(MethodCheckMultiplePermissions, func(ctx context.Context, ctrl api.Controller, token string, req model.CheckQuery) (any, error, int) {
+			permissions, mode, err := permissionsAndMode(req.Permissions, req.Mode)
+			if err != nil {
+				return nil, err, http.StatusBadRequest
+			}
+			return ctrl.CheckMultiplePermissionsWithModeContext(ctx, token, req.TopicId, req.Ids, mode, permissions...)
+		}),
+		unary(MethodCheckPermissionsBatch, func(ctx context.Context, ctrl api.Controller, token string, req []model.CheckQuery) (any, error, int) {
+			return ctrl.CheckPermissionsBatchContext(ctx, token, req)
+		}),
+		unary(MethodListAccessibleResourceIds, func(ctx context.Context, ctrl api.Controller, token string, req AccessibleRequest) (any, error, int) {
+			permissions, mode, err := permissionsAndMode(req.Permissions, req.Mode)
+			if err != nil {
+				return nil, err, http.StatusBadRequest
+			}
+			return ctrl.ListAccessibleResourceIdsWithModeContext(ctx, token, req.TopicId, req.Options, mode, permissions...)
+		}),
+		unary(MethodCountAccessibleResourceIds, func(ctx context.Context, ctrl api.Controller, token string, req AccessibleRequest) (any, error, int) {
+			permissions, mode, err := permissionsAndMode(req.Permissions, req.Mode)
+			if err != nil {
+				return nil, err, http.StatusBadRequest
+			}
+			return ctrl.CountAccessibleResourceIdsContext(ctx, token, req.TopicId, req.Options, mode, permissions...)
+		}),
+		unary(MethodListComputedPermissions, func(ctx context.Context, ctrl api.Controller, token string, req ResourcesRequest) (any, error, int) {
+			return ctrl.ListComputedPermissionsContext(ctx, token, req.TopicId, req.Ids)
+		}),
+		unary(MethodExplainPermission, func(ctx context.Context, ctrl api.Controller, token string, req ExplainRequest) (any, error, int) {
+			permissions, _, err := permissionsAndMode(req.Permissions, "")
+			if err != nil {
+				return nil, err, http.StatusBadRequest
+			}
+			return ctrl.ExplainPermissionContext(ctx, token, req.TopicId, req.Id, req.OnBehalf, permissions...)
+		}),
+
+		unary(MethodListResourcesWithAdminPermission, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.ListResourcesWithAdminPermissionContext(ctx, token, req.TopicId, req.Options)
+		}),
+		unary(MethodCountResourcesWithAdminPermission, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.CountResourcesWithAdminPermissionContext(ctx, token, req.TopicId, req.Options)
+		}),
+		unary(MethodGetResource, func(ctx context.Context, ctrl api.Controller, token string, req ResourceRequest) (any, error, int) {
+			return ctrl.GetResourceContext(ctx, token, req.TopicId, req.Id)
+		}),
+		unary(MethodRemoveResource, func(ctx context.Context, ctrl api.Controller, token string, req ResourceRequest) (any, error, int) {
+			err, code := ctrl.RemoveResourceContext(ctx, token, req.TopicId, req.Id, WriteOptions(req.ExpectedVersion)...)
+			return Empty{}, err, code
+		}),
+		unary(MethodSetPermission, func(ctx context.Context, ctrl api.Controller, token string, req SetPermissionRequest) (any, error, int) {
+			return ctrl.SetPermissionContext(ctx, token, req.TopicId, req.Id, req.Permissions, WriteOptions(req.ExpectedVersion)...)
+		}),
+		unary(MethodSetPermissionsBulk, func(ctx context.Context, ctrl api.Controller, token string, req BulkRequest) (any, error, int) {
+			return ctrl.SetPermissionsBulkContext(ctx, token, req.TopicId, req.Items)
+		}),
+		unary(MethodPatchPermission, func(ctx context.Context, ctrl api.Controller, token string, req PatchPermissionRequest) (any, error, int) {
+			return ctrl.PatchPermissionContext(ctx, token, req.TopicId, req.Id, req.Patch, WriteOptions(req.ExpectedVersion)...)
+		}),
+		unary(MethodListPermissionVersions, func(ctx context.Context, ctrl api.Controller, token string, req ListRequest) (any, error, int) {
+			return ctrl.ListPermissionVersionsContext(ctx, token, req.TopicId, req.Id, req.Options)
+		}),
+		unary(MethodDiffPermissionVersions, func(ctx context.Context, ctrl api.Controller, token string, req DiffRequest) (any, error, int) {
+			return ctrl.DiffPermissionVersionsContext(ctx, token, req.TopicId, req.Id, req.FromVersion, req.ToVersion)
+		}),
+		unary(MethodRollbackPermissions, func(ctx context.Context, ctrl api.Controller, token string, req RollbackRequest) (any, error, int) {
+			return ctrl.RollbackPermissionsContext(ctx, token, req.TopicId, req.Id, req.Version)
+		}),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    MethodWatchPermissionChanges,
+			Handler:       watchPermissionChanges,
+			ServerStreams: true,
+		},
+	},
+}
+
+// unary returns the description of a method, that decodes the request, calls the controller with the token of the metadata and returns the result
+func unary[Req any](name string, call func(ctx context.Context, ctrl api.Controller, token string, req Req) (any, error, int)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := new(Req)
+			err := dec(req)
+			if err != nil {
+				return nil, statusError(ctx, err, http.StatusBadRequest)
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				result, err, code := call(ctx, srv.(api.Controller), getToken(ctx), *req.(*Req))
+				if err != nil {
+					return nil, statusError(ctx, err, code)
+				}
+				return result, statusError(ctx, nil, code)
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: FullMethod(name)}, handler)
+		},
+	}
+}
+
+func watchPermissionChanges(srv any, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	req := WatchRequest{}
+	err := stream.RecvMsg(&req)
+	if err != nil {
+		return statusError(ctx, err, http.StatusBadRequest)
+	}
+	permissions, err := model.PermissionListFromString(req.Permissions)
+	if err != nil {
+		return statusError(ctx, err, http.StatusBadRequest)
+	}
+	mode, err := model.PermissionModeFromString(string(req.Mode))
+	if err != nil {
+		return statusError(ctx, err, http.StatusBadRequest)
+	}
+	events, err, code := srv.(api.Controller).WatchPermissionChangesContext(ctx, getToken(ctx), req.TopicId, model.ChangeFeedOptions{
+		ResumeToken: req.ResumeToken,
+		Permissions: permissions,
+		Mode:        mode,
+	})
+	if err != nil {
+		return statusError(ctx, err, code)
+	}
+	//the header signals the client, that the watch is established
+	err = stream.SendHeader(metadata.Pairs(HttpStatusMetadata, "200"))
+	if err != nil {
+		return err
+	}
+	for event, err := range events {
+		if errors.Is(err, model.ErrResumeTokenExpired) {
+			return statusError(ctx, err, http.StatusGone)
+		}
+		if err != nil {
+			return statusError(ctx, err, http.StatusInternalServerError)
+		}
+		err = stream.SendMsg(event)
+		if err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.Unavailable, "change feed closed")
+}
+
+// permissionsAndMode parses the permissions and mode of a check; like the http api, checks default to read permissions
+func permissionsAndMode(permissions string, mode model.PermissionMode) (model.PermissionList, model.PermissionMode, error) {
+	if permissions == "" {
+		permissions = "r"
+	}
+	list, err := model.PermissionListFromString(permissions)
+	if err != nil {
+		return nil, "", err
+	}
+	mode, err = model.PermissionModeFromString(string(mode))
+	if err != nil {
+		return nil, "", err
+	}
+	return list, mode, nil
+}
+
+func getToken(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, AuthorizationMetadata)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/SENERGY-Platform/permissions-v2/pkg/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// HttpStatusMetadata is the trailer, that contains the http status code of the controller result;
+// clients return this code, to be interchangeable with the http client
+const HttpStatusMetadata = "x-http-status"
+
+// AuthorizationMetadata contains the token, like the Authorization header of the http api
+const AuthorizationMetadata = "authorization"
+
+// VersionMetadata optionally contains the model.ClientVersion of the client
+const VersionMetadata = "version"
+
+var httpToGrpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusGone:                codes.OutOfRange,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUpgradeRequired:     codes.FailedPrecondition,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+var grpcToHttpCodes = map[codes.Code]int{
+	codes.InvalidArgument:    http.StatusBadRequest,
+	codes.Unauthenticated:    http.StatusUnauthorized,
+	codes.PermissionDenied:   http.StatusForbidden,
+	codes.NotFound:           http.StatusNotFound,
+	codes.Aborted:            http.StatusConflict,
+	codes.OutOfRange:         http.StatusGone,
+	codes.FailedPrecondition: http.StatusPreconditionFailed,
+	codes.Unimplemented:      http.StatusNotImplemented,
+	codes.DeadlineExceeded:   http.StatusGatewayTimeout,
+	codes.Canceled:           http.StatusInternalServerError,
+}
+
+// GrpcCode returns the grpc status code for a http status code
+func GrpcCode(httpCode int) codes.Code {
+	if code, ok := httpToGrpcCodes[httpCode]; ok {
+		return code
+	}
+	if httpCode >= 400 && httpCode < 500 {
+		return codes.InvalidArgument
+	}
+	return codes.Internal
+}
+
+// HttpCode returns the http status code of a call result; the HttpStatusMetadata trailer is preferred over the grpc status code
+func HttpCode(err error, trailer metadata.MD) int {
+	if values := trailer.Get(HttpStatusMetadata); len(values) > 0 {
+		code, parseErr := strconv.Atoi(values[0])
+		if parseErr == nil {
+			return code
+		}
+	}
+	if err == nil {
+		return http.StatusOK
+	}
+	if code, ok := grpcToHttpCodes[status.Code(err)]; ok {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// FromError returns the error and http status code of a call result, in the form of the http client
+func FromError(err error, trailer metadata.MD) (error, int) {
+	code := HttpCode(err, trailer)
+	if err == nil {
+		return nil, code
+	}
+	if s, ok := status.FromError(err); ok {
+		if violation, ok := model.SharingPolicyErrorFromResponse([]byte(s.Message())); ok && code == http.StatusForbidden {
+			return fmt.Errorf("unexpected statuscode %v: %w", code, violation), code
+		}
+		return fmt.Errorf("unexpected statuscode %v: %v", code, s.Message()), code
+	}
+	return err, code
+}
+
+// statusError returns the grpc status error of a controller result and sets the HttpStatusMetadata trailer
+func statusError(ctx context.Context, err error, code int) error {
+	if code == 0 {
+		code = http.StatusInternalServerError
+		if err == nil {
+			code = http.StatusOK
+		}
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(HttpStatusMetadata, strconv.Itoa(code)))
+	if err == nil {
+		return nil
+	}
+	//sharing policy violations are sent as json, like the http api does
+	var violation *model.SharingPolicyError
+	if errors.As(err, &violation) {
+		msg, _ := json.Marshal(violation)
+		return status.Error(GrpcCode(code), string(msg))
+	}
+	return status.Error(GrpcCode(code), err.Error())
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"net/url"
+	"strconv"
+)
+
+type AuditOperation string
+
+const (
+	AuditOperationSetPermission        AuditOperation = "set_permission"
+	AuditOperationPatchPermission      AuditOperation = "patch_permission"
+	AuditOperationBulkSetPermission    AuditOperation = "bulk_set_permission"
+	AuditOperationRemoveResource       AuditOperation = "remove_resource"
+	AuditOperationSetTopic             AuditOperation = "set_topic"
+	AuditOperationRemoveTopic          AuditOperation = "remove_topic"
+	AuditOperationImport               AuditOperation = "import"
+	AuditOperationLoadPermissionSearch AuditOperation = "load_permission_search"
+	AuditOperationExpirySweep          AuditOperation = "expiry_sweep"
+	AuditOperationRollback             AuditOperation = "rollback"
+
+	//only used for permission versions of descendants, whose inherited permissions are changed by a change of an ancestor
+	AuditOperationInheritedChange AuditOperation = "inherited_change"
+)
+
+// AuditEntry records a change of resource permissions or of a topic.
+// resource operations set ResourceId and the permissions; topic operations set the topics.
+type AuditEntry struct {
+	Id         string         `json:"id"`
+	Timestamp  int64          `json:"timestamp"` //unix milliseconds
+	UserId     string         `json:"user_id"`   //acting user; empty for changes by the service itself (e.g. expiry sweep)
+	Operation  AuditOperation `json:"operation"`
+	TopicId    string         `json:"topic_id"`
+	ResourceId string         `json:"resource_id,omitempty"`
+
+	PreviousPermissions *ResourcePermissions `json:"previous_permissions,omitempty"` //nil if the resource did not exist
+	NewPermissions      *ResourcePermissions `json:"new_permissions,omitempty"`      //nil if the resource was removed
+
+	PreviousTopic *Topic `json:"previous_topic,omitempty"` //nil if the topic did not exist
+	NewTopic      *Topic `json:"new_topic,omitempty"`      //nil if the topic was removed
+}
+
+type AuditListOptions struct {
+	Limit  int64 // 0 -> unlimited
+	Offset int64
+
+	//optional filters; empty values are ignored
+	TopicId    string
+	ResourceId string
+	UserId     string
+	Operation  AuditOperation
+	From       int64 //unix milliseconds; inclusive
+	Until      int64 //unix milliseconds; exclusive
+}
+
+func AuditListOptionsFromQuery(q url.Values) (result AuditListOptions, err error) {
+	listOptions, err := ListOptionsFromQuery(q)
+	if err != nil {
+		return result, err
+	}
+	result.Limit = listOptions.Limit
+	result.Offset = listOptions.Offset
+	result.TopicId = q.Get("topic")
+	result.ResourceId = q.Get("resource")
+	result.UserId = q.Get("user")
+	result.Operation = AuditOperation(q.Get("operation"))
+	if from := q.Get("from"); from != "" {
+		result.From, err = strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			return result, err
+		}
+	}
+	if until := q.Get("until"); until != "" {
+		result.Until, err = strconv.ParseInt(until, 10, 64)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
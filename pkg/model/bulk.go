@@ -0,0 +1,34 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+// BulkPermissionsItem is one resource of a bulk permissions write
+type BulkPermissionsItem struct {
+	Id          string              `json:"id"`
+	Permissions ResourcePermissions `json:"permissions"`
+}
+
+// BulkPermissionsResult is the outcome of one item of a bulk permissions write, in the order of the request items.
+// Code is the http status code, the item would have produced as single write (200 on success).
+type BulkPermissionsResult struct {
+	Id      string `json:"id"`
+	Code    int    `json:"code"`
+	Error   string `json:"error,omitempty"`
+	Version int64  `json:"version,omitempty"` //version of the stored resource on success
+
+	Violations []SharingPolicyViolation `json:"violations,omitempty"` //set if the item violates the sharing policy of the topic
+}
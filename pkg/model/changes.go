@@ -0,0 +1,48 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+// ChangeEvent is a permissions change of a resource, as delivered by the change feed.
+// subscribers without admin rights on the topic receive events without Permissions and UserId.
+type ChangeEvent struct {
+	Token string `json:"token"` //resume token; a subscription started with this token continues after this event
+	PermissionsVersion
+
+	//true if the subscriber had the subscribed permissions before this change and lost them by this change;
+	//only set for subscribers without admin rights on the topic, the event contains no details
+	AccessLost bool `json:"access_lost,omitempty"`
+}
+
+// VersionWatch calls handler for each watched permissions version with its resume token
+type VersionWatch = func(handler func(token string, version PermissionsVersion) error) error
+
+type ChangeFeedOptions struct {
+	ResumeToken string //optional; continue after the event with this token
+
+	//optional; only changes of resources the subscriber holds these permissions on (evaluated with Mode)
+	//subscribers without admin rights on the topic always receive filtered events; Administrate is used if no permission is set
+	Permissions PermissionList
+	Mode        PermissionMode
+}
+
+// WithoutDetails returns the event without the permissions and the acting user
+func (this ChangeEvent) WithoutDetails() ChangeEvent {
+	this.Permissions = ResourcePermissions{}
+	this.EffectivePermissions = nil
+	this.UserId = ""
+	return this
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+// CheckQuery is one query of a batch permission check
+type CheckQuery struct {
+	TopicId     string   `json:"topic_id"`
+	Ids         []string `json:"ids"`
+	Permissions string   `json:"permissions"` //in the form of 'rwxa' and letters of custom permissions declared by the topic; defaults to 'r'
+
+	Mode PermissionMode `json:"mode,omitempty"` //optional; PermissionModeAll (default) or PermissionModeAny
+}
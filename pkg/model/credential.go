@@ -0,0 +1,133 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ServiceCredentialAuthPrefix precedes the key of a service credential in the Authorization header (instead of "Bearer ")
+const ServiceCredentialAuthPrefix = "ApiKey "
+
+// ServiceCredentialUserPrefix precedes the credential id in the user id of requests with service credentials (e.g. in the audit log)
+const ServiceCredentialUserPrefix = "service:"
+
+type ServiceCredentialScope string
+
+const (
+	ServiceCredentialScopeCheck      ServiceCredentialScope = "check"       //permission checks and reads of resource permissions, history and change feed
+	ServiceCredentialScopeManage     ServiceCredentialScope = "manage"      //changes of resource permissions; includes check
+	ServiceCredentialScopeTopicAdmin ServiceCredentialScope = "topic_admin" //reads and changes of topics
+)
+
+var ServiceCredentialScopes = []ServiceCredentialScope{ServiceCredentialScopeCheck, ServiceCredentialScopeManage, ServiceCredentialScopeTopicAdmin}
+
+var serviceCredentialIdPattern = regexp.MustCompile("^[a-zA-Z0-9_\\-]+$")
+
+// ServiceCredential is a named api key of an internal service.
+// within its topics and scopes, the service acts like an admin; other admin operations (audit, import/export, webhooks, credentials) are not allowed.
+type ServiceCredential struct {
+	Id          string                   `json:"id"` //name of the service; ascii letters, digits, '_' and '-'
+	Description string                   `json:"description,omitempty"`
+	TopicIds    []string                 `json:"topic_ids,omitempty"` //optional; empty -> all topics; operations without topic (e.g. list topics) need access to all topics
+	Scopes      []ServiceCredentialScope `json:"scopes"`
+	Disabled    bool                     `json:"disabled"`   //requests with disabled credentials are rejected
+	CreatedAt   int64                    `json:"created_at"` //unix milliseconds; set by the service
+
+	KeyHash string `json:"-"` //sha256 of the key; the key itself is only returned on creation and key renewal
+}
+
+// ServiceCredentialWithKey is returned on creation and key renewal; the key can not be read later
+type ServiceCredentialWithKey struct {
+	ServiceCredential
+	Key string `json:"key"` //use with ServiceCredentialAuthPrefix as Authorization header
+}
+
+func (this ServiceCredential) Validate() error {
+	if !serviceCredentialIdPattern.MatchString(this.Id) {
+		return errors.New("id must consist of ascii letters, digits, '_' and '-'")
+	}
+	if len(this.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range this.Scopes {
+		if !slices.Contains(ServiceCredentialScopes, scope) {
+			return fmt.Errorf("unknown scope '%v'", scope)
+		}
+	}
+	return nil
+}
+
+// Allows returns true if the credential has the scope and access to all topics; manage includes check
+func (this ServiceCredential) Allows(scope ServiceCredentialScope, topicIds ...string) bool {
+	if !slices.Contains(this.Scopes, scope) && !(scope == ServiceCredentialScopeCheck && slices.Contains(this.Scopes, ServiceCredentialScopeManage)) {
+		return false
+	}
+	if len(this.TopicIds) == 0 {
+		return true
+	}
+	if len(topicIds) == 0 {
+		return false
+	}
+	for _, topicId := range topicIds {
+		if !slices.Contains(this.TopicIds, topicId) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewServiceCredentialKey returns a random key for the credential id
+func NewServiceCredentialKey(id string) (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return id + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// IsServiceCredentialAuth returns true if the Authorization header value starts with ServiceCredentialAuthPrefix
+func IsServiceCredentialAuth(auth string) bool {
+	return len(auth) >= len(ServiceCredentialAuthPrefix) && strings.EqualFold(auth[:len(ServiceCredentialAuthPrefix)], ServiceCredentialAuthPrefix)
+}
+
+// ParseServiceCredentialAuth returns the credential id and key of an Authorization header value with ServiceCredentialAuthPrefix
+func ParseServiceCredentialAuth(auth string) (id string, key string, err error) {
+	if !IsServiceCredentialAuth(auth) {
+		return "", "", errors.New("missing service credential")
+	}
+	key = strings.TrimSpace(auth[len(ServiceCredentialAuthPrefix):])
+	id, _, ok := strings.Cut(key, ".")
+	if !ok || id == "" {
+		return "", "", errors.New("invalid service credential key")
+	}
+	return id, key, nil
+}
+
+func HashServiceCredentialKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -20,3 +20,8 @@ import "errors"
 
 var PermissionCheckFailed = errors.New("permission check failed")
 var ErrNotFound = errors.New("not found")
+var ErrInvalidParent = errors.New("invalid parent")
+var ErrUnknownPermission = errors.New("unknown permission")
+var ErrVersionMismatch = errors.New("version mismatch")
+var ErrResumeTokenExpired = errors.New("resume token expired")
+var ErrSharingPolicyViolation = errors.New("sharing policy violation")
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import "time"
+
+// ExplainSubject is the user, roles and groups a permission check is explained for
+type ExplainSubject struct {
+	UserId   string   `json:"user_id"`
+	RoleIds  []string `json:"role_ids"`
+	GroupIds []string `json:"group_ids"`
+}
+
+type Explanation struct {
+	TopicId string         `json:"topic_id"`
+	Id      string         `json:"id"`
+	Subject ExplainSubject `json:"subject"`
+
+	Access      bool                    `json:"access"` //result of the permission check of all permissions together; equal to CheckPermission
+	Permissions []PermissionExplanation `json:"permissions"`
+}
+
+type PermissionExplanation struct {
+	Permission string            `json:"permission"`
+	Granted    bool              `json:"granted"` //result of a check of this permission alone; Access may be false although all permissions are granted alone, if they are granted by different sources (topic defaults and resource)
+	Rules      []ExplanationRule `json:"rules"`   //all currently active rules matching the subject; empty if no rule grants the permission
+}
+
+const RuleSourceAdmin = "admin"                //the subject has the admin role
+const RuleSourceTopicDefault = "topic_default" //the default permissions of the topic
+const RuleSourceResource = "resource"          //the permissions of the resource
+
+const RuleKindUser = "user"
+const RuleKindGroup = "group"
+const RuleKindRole = "role"
+
+const RuleEffectGrant = "grant"
+const RuleEffectDeny = "deny"
+
+type ExplanationRule struct {
+	Source     string     `json:"source"`              //RuleSourceAdmin, RuleSourceTopicDefault or RuleSourceResource
+	Kind       string     `json:"kind,omitempty"`      //RuleKindUser, RuleKindGroup or RuleKindRole; empty for RuleSourceAdmin
+	Subject    string     `json:"subject,omitempty"`   //user, group or role id of the matching entry
+	Effect     string     `json:"effect"`              //RuleEffectGrant or RuleEffectDeny
+	Inherited  bool       `json:"inherited,omitempty"` //the entry is inherited from the parent of the resource
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"reflect"
+	"slices"
+)
+
+// PermissionsVersion is a snapshot of the (own) permissions of a resource after a change.
+// changes of inherited permissions are recorded as versions of the descendants with the operation AuditOperationInheritedChange.
+// the snapshot is stored by the database in the same transaction as the change; Version is the version of the changed resource (Resource.Version).
+// versions are numbered per resource, starting with 1; versions of removed resources are not reused, if the resource is recreated.
+type PermissionsVersion struct {
+	TopicId   string         `json:"topic_id"`
+	Id        string         `json:"id"`
+	Version   int64          `json:"version"`
+	Timestamp int64          `json:"timestamp"` //unix milliseconds
+	UserId    string         `json:"user_id"`   //acting user; empty for changes by the service itself (e.g. expiry sweep)
+	Operation AuditOperation `json:"operation"`
+
+	Deleted     bool                `json:"deleted"` //true if the resource was removed by this change; Permissions is empty
+	Permissions ResourcePermissions `json:"permissions"`
+
+	EffectivePermissions *ResourcePermissions `json:"effective_permissions,omitempty"` //set if permissions are inherited from a parent
+}
+
+// Effective returns the permissions including the inherited permissions at the time of the version
+func (this PermissionsVersion) Effective() ResourcePermissions {
+	if this.EffectivePermissions != nil {
+		return *this.EffectivePermissions
+	}
+	return this.Permissions
+}
+
+type PermissionsDiff struct {
+	TopicId string `json:"topic_id"`
+	Id      string `json:"id"`
+	From    int64  `json:"from"`
+	To      int64  `json:"to"`
+
+	Changes []PermissionsChange `json:"changes"`
+	Parent  *ParentChange       `json:"parent,omitempty"` //nil if the parent is unchanged
+}
+
+// Change describes a write of resource permissions for the PermissionsVersion snapshots recorded by the database
+type Change struct {
+	UserId    string //acting user; empty for changes by the service itself
+	Operation AuditOperation
+
+	Recorded func(versions []PermissionsVersion) //optional; called with the recorded versions after the write is committed
+	Webhooks []WebhookPayload                    //optional; stored as outbox events in the same transaction as the write
+}
+
+// WebhookEvents returns the outbox events of the webhook payloads
+func (this Change) WebhookEvents() (result []OutboxEvent) {
+	for _, payload := range this.Webhooks {
+		result = append(result, OutboxEvent{
+			Topic:     Topic{Id: payload.TopicId},
+			Id:        payload.ResourceId,
+			Command:   OutboxCommandWebhook,
+			CreatedAt: payload.Timestamp,
+			Webhook:   &payload,
+		})
+	}
+	return result
+}
+
+// NotifyRecorded calls Recorded, if set and versions have been recorded
+func (this Change) NotifyRecorded(versions []PermissionsVersion) {
+	if this.Recorded != nil && len(versions) > 0 {
+		this.Recorded(versions)
+	}
+}
+
+// PermissionsChange describes a changed entry of a ResourcePermissions map.
+// Before is nil for added entries, After is nil for removed entries.
+type PermissionsChange struct {
+	Field  string          `json:"field"` //json name of the map; e.g. "user_permissions" or "group_denies"
+	Key    string          `json:"key"`   //user, group or role id
+	Before *PermissionsMap `json:"before,omitempty"`
+	After  *PermissionsMap `json:"after,omitempty"`
+}
+
+type ParentChange struct {
+	Before *ResourceReference `json:"before,omitempty"`
+	After  *ResourceReference `json:"after,omitempty"`
+}
+
+// Diff returns the changes from these permissions to the other permissions, sorted by field and key
+func (this ResourcePermissions) Diff(other ResourcePermissions) (changes []PermissionsChange, parent *ParentChange) {
+	changes = []PermissionsChange{}
+	fields := []struct {
+		name   string
+		before map[string]PermissionsMap
+		after  map[string]PermissionsMap
+	}{
+		{name: "user_permissions", before: this.UserPermissions, after: other.UserPermissions},
+		{name: "group_permissions", before: this.GroupPermissions, after: other.GroupPermissions},
+		{name: "role_permissions", before: this.RolePermissions, after: other.RolePermissions},
+		{name: "user_denies", before: this.UserDenies, after: other.UserDenies},
+		{name: "group_denies", before: this.GroupDenies, after: other.GroupDenies},
+		{name: "role_denies", before: this.RoleDenies, after: other.RoleDenies},
+	}
+	for _, field := range fields {
+		keys := []string{}
+		for key := range field.before {
+			keys = append(keys, key)
+		}
+		for key := range field.after {
+			if _, ok := field.before[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			before, beforeOk := field.before[key]
+			after, afterOk := field.after[key]
+			if beforeOk && afterOk && reflect.DeepEqual(before, after) {
+				continue
+			}
+			change := PermissionsChange{Field: field.name, Key: key}
+			if beforeOk {
+				change.Before = &before
+			}
+			if afterOk {
+				change.After = &after
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	var beforeParent, afterParent *ResourceReference
+	if this.HasParent() {
+		beforeParent = this.Parent
+	}
+	if other.HasParent() {
+		afterParent = other.Parent
+	}
+	if !reflect.DeepEqual(beforeParent, afterParent) {
+		parent = &ParentChange{Before: beforeParent, After: afterParent}
+	}
+	return changes, parent
+}
+
+// ForRollback returns the permissions of the version in a form, that replaces all currently stored entries and the parent,
+// even if applied by a non-admin user (nil maps and parents would otherwise be replaced by the stored values)
+func (this PermissionsVersion) ForRollback() ResourcePermissions {
+	result := this.Permissions
+	for _, m := range []*map[string]PermissionsMap{&result.UserPermissions, &result.GroupPermissions, &result.RolePermissions, &result.UserDenies, &result.GroupDenies, &result.RoleDenies} {
+		if *m == nil {
+			*m = map[string]PermissionsMap{}
+		}
+	}
+	if !result.HasParent() {
+		result.Parent = &ResourceReference{}
+	}
+	return result
+}
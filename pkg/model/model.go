@@ -18,6 +18,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"regexp"
 	"slices"
@@ -37,6 +38,86 @@ type Topic struct {
 	LastUpdateUnixTimestamp int64 `json:"last_update_unix_timestamp"` //should be ignored by the user; is set by db
 
 	DefaultPermissions ResourcePermissions `json:"default_permissions"`
+
+	CustomPermissions []CustomPermission `json:"custom_permissions,omitempty"` //additional permissions, usable besides r, w, x and a
+
+	//optional delegated admins; they may manage the topic config (except kafka settings), list all resources of the topic,
+	//read, set and remove them and export/import the topic, without being platform admins
+	AdminUsers  []string `json:"admin_users,omitempty"`
+	AdminRoles  []string `json:"admin_roles,omitempty"`
+	AdminGroups []string `json:"admin_groups,omitempty"`
+
+	CreationPolicy *ResourceCreationPolicy `json:"creation_policy,omitempty"` //optional; allows users, who are no admins, to create new resources
+
+	SharingPolicy *SharingPolicy `json:"sharing_policy,omitempty"` //optional; limits the permissions, users who are no admins may grant
+}
+
+// ResourceCreationPolicy allows users with one of the roles or groups to create new resource ids of the topic.
+// the creator becomes admin of the new resource.
+type ResourceCreationPolicy struct {
+	Roles  []string `json:"roles,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+
+	//optional; entries added to new resources, if the creator does not set an entry for the same user, group or role
+	InitialPermissions ResourcePermissions `json:"initial_permissions"`
+}
+
+func (this ResourceCreationPolicy) Validate() error {
+	for _, list := range [][]string{this.Roles, this.Groups} {
+		if slices.Contains(list, "") {
+			return errors.New("creation policy contains an empty role or group")
+		}
+	}
+	if this.InitialPermissions.Parent != nil {
+		return errors.New("initial permissions of the creation policy may not reference a parent")
+	}
+	return nil
+}
+
+// Allows returns true if one of the roles or groups may create resources
+func (this ResourceCreationPolicy) Allows(roleIds []string, groupIds []string) bool {
+	for _, role := range roleIds {
+		if slices.Contains(this.Roles, role) {
+			return true
+		}
+	}
+	for _, group := range groupIds {
+		if slices.Contains(this.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the permissions of a new resource: the initial permissions are added, if not set in permissions,
+// and the creator gets r, w, x and a without denies
+func (this ResourceCreationPolicy) Apply(creator string, permissions ResourcePermissions) ResourcePermissions {
+	add := func(target map[string]PermissionsMap, initial map[string]PermissionsMap) map[string]PermissionsMap {
+		result := map[string]PermissionsMap{}
+		for key, value := range initial {
+			result[key] = value
+		}
+		for key, value := range target {
+			result[key] = value
+		}
+		return result
+	}
+	permissions.UserPermissions = add(permissions.UserPermissions, this.InitialPermissions.UserPermissions)
+	permissions.GroupPermissions = add(permissions.GroupPermissions, this.InitialPermissions.GroupPermissions)
+	permissions.RolePermissions = add(permissions.RolePermissions, this.InitialPermissions.RolePermissions)
+	permissions.UserDenies = add(permissions.UserDenies, this.InitialPermissions.UserDenies)
+	permissions.GroupDenies = add(permissions.GroupDenies, this.InitialPermissions.GroupDenies)
+	permissions.RoleDenies = add(permissions.RoleDenies, this.InitialPermissions.RoleDenies)
+
+	own := permissions.UserPermissions[creator]
+	permissions.UserPermissions[creator] = PermissionsMap{Read: true, Write: true, Execute: true, Administrate: true, Custom: own.Custom}
+	delete(permissions.UserDenies, creator)
+	return permissions
+}
+
+type CustomPermission struct {
+	Letter      string `json:"letter"` //single ascii letter, other than r, w, x and a; e.g. "s"
+	Description string `json:"description"`
 }
 
 func (this Topic) Validate() error {
@@ -49,9 +130,122 @@ func (this Topic) Validate() error {
 	if this.PublishToKafkaTopic != "" && !regexp.MustCompile("^[a-zA-Z0-9\\._\\-]+$").MatchString(this.PublishToKafkaTopic) {
 		return errors.New("kafka topic contains invalid characters")
 	}
+	known := map[string]bool{}
+	for _, custom := range this.CustomPermissions {
+		if len(custom.Letter) != 1 || !Permission(custom.Letter[0]).IsValid() {
+			return fmt.Errorf("custom permission '%v' is not a single ascii letter", custom.Letter)
+		}
+		if Permission(custom.Letter[0]).IsBuiltIn() {
+			return fmt.Errorf("custom permission '%v' collides with a built-in permission", custom.Letter)
+		}
+		if known[custom.Letter] {
+			return fmt.Errorf("custom permission '%v' is declared more than once", custom.Letter)
+		}
+		known[custom.Letter] = true
+	}
+	for _, list := range [][]string{this.AdminUsers, this.AdminRoles, this.AdminGroups} {
+		if slices.Contains(list, "") {
+			return errors.New("topic admins contain an empty entry")
+		}
+	}
+	if this.CreationPolicy != nil {
+		err := this.CreationPolicy.Validate()
+		if err != nil {
+			return err
+		}
+		err = this.ValidateResourcePermissions(this.CreationPolicy.InitialPermissions)
+		if err != nil {
+			return err
+		}
+	}
+	if this.SharingPolicy != nil {
+		err := this.SharingPolicy.Validate()
+		if err != nil {
+			return err
+		}
+		for _, max := range []*PermissionsMap{this.SharingPolicy.MaxUserPermissions, this.SharingPolicy.MaxGroupPermissions, this.SharingPolicy.MaxRolePermissions} {
+			if max != nil {
+				err = this.ValidateResourcePermissions(ResourcePermissions{UserPermissions: map[string]PermissionsMap{"": *max}})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return this.ValidateResourcePermissions(this.DefaultPermissions)
+}
+
+// IsTopicAdmin returns true if the user, one of the roles or one of the groups is a delegated admin of the topic
+// platform admins are not evaluated
+func (this Topic) IsTopicAdmin(userId string, roleIds []string, groupIds []string) bool {
+	if userId != "" && slices.Contains(this.AdminUsers, userId) {
+		return true
+	}
+	for _, role := range roleIds {
+		if slices.Contains(this.AdminRoles, role) {
+			return true
+		}
+	}
+	for _, group := range groupIds {
+		if slices.Contains(this.AdminGroups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// KafkaSettingsEqual returns true if both topics publish in the same way to kafka
+func (this Topic) KafkaSettingsEqual(topic Topic) bool {
+	return this.PublishToKafkaTopic == topic.PublishToKafkaTopic &&
+		this.EnsureKafkaTopicInit == topic.EnsureKafkaTopicInit &&
+		this.EnsureKafkaTopicInitPartitionNumber == topic.EnsureKafkaTopicInitPartitionNumber
+}
+
+// SupportsPermission returns true for built-in permissions and custom permissions declared by the topic
+func (this Topic) SupportsPermission(permission Permission) bool {
+	if permission.IsBuiltIn() {
+		return true
+	}
+	return slices.ContainsFunc(this.CustomPermissions, func(custom CustomPermission) bool {
+		return custom.Letter == string(permission)
+	})
+}
+
+// ValidatePermissions returns an error wrapping ErrUnknownPermission, if a permission is not supported by the topic
+func (this Topic) ValidatePermissions(permissions PermissionList) error {
+	for _, permission := range permissions {
+		if !this.SupportsPermission(permission) {
+			return fmt.Errorf("%w '%v' in topic '%v'", ErrUnknownPermission, string(permission), this.Id)
+		}
+	}
+	return nil
+}
+
+// ValidateResourcePermissions returns an error wrapping ErrUnknownPermission, if an entry uses custom permissions not declared by the topic
+func (this Topic) ValidateResourcePermissions(permissions ResourcePermissions) error {
+	for _, m := range []map[string]PermissionsMap{permissions.UserPermissions, permissions.GroupPermissions, permissions.RolePermissions, permissions.UserDenies, permissions.GroupDenies, permissions.RoleDenies} {
+		for _, entry := range m {
+			for key, value := range entry.Custom {
+				if !value {
+					continue
+				}
+				if len(key) != 1 || !Permission(key[0]).IsValid() || Permission(key[0]).IsBuiltIn() {
+					return fmt.Errorf("%w '%v' in topic '%v'", ErrUnknownPermission, key, this.Id)
+				}
+				err := this.ValidatePermissions(PermissionList{Permission(key[0])})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
 	return nil
 }
 
+func (this Topic) PublishesToKafka() bool {
+	return this.PublishToKafkaTopic != "" && this.PublishToKafkaTopic != "-"
+}
+
 func (this Topic) Equal(topic Topic) bool {
 	if this.Id != topic.Id {
 		return false
@@ -65,6 +259,18 @@ func (this Topic) Equal(topic Topic) bool {
 	if this.EnsureKafkaTopicInitPartitionNumber != topic.EnsureKafkaTopicInitPartitionNumber {
 		return false
 	}
+	if len(this.CustomPermissions) != len(topic.CustomPermissions) || (len(this.CustomPermissions) > 0 && !reflect.DeepEqual(this.CustomPermissions, topic.CustomPermissions)) {
+		return false
+	}
+	if !slices.Equal(this.AdminUsers, topic.AdminUsers) || !slices.Equal(this.AdminRoles, topic.AdminRoles) || !slices.Equal(this.AdminGroups, topic.AdminGroups) {
+		return false
+	}
+	if !reflect.DeepEqual(this.CreationPolicy, topic.CreationPolicy) {
+		return false
+	}
+	if !reflect.DeepEqual(this.SharingPolicy, topic.SharingPolicy) {
+		return false
+	}
 
 	if this.DefaultPermissions.UserPermissions == nil {
 		this.DefaultPermissions.UserPermissions = map[string]PermissionsMap{}
@@ -75,6 +281,15 @@ func (this Topic) Equal(topic Topic) bool {
 	if this.DefaultPermissions.RolePermissions == nil {
 		this.DefaultPermissions.RolePermissions = map[string]PermissionsMap{}
 	}
+	if this.DefaultPermissions.UserDenies == nil {
+		this.DefaultPermissions.UserDenies = map[string]PermissionsMap{}
+	}
+	if this.DefaultPermissions.GroupDenies == nil {
+		this.DefaultPermissions.GroupDenies = map[string]PermissionsMap{}
+	}
+	if this.DefaultPermissions.RoleDenies == nil {
+		this.DefaultPermissions.RoleDenies = map[string]PermissionsMap{}
+	}
 
 	if topic.DefaultPermissions.UserPermissions == nil {
 		topic.DefaultPermissions.UserPermissions = map[string]PermissionsMap{}
@@ -85,6 +300,15 @@ func (this Topic) Equal(topic Topic) bool {
 	if topic.DefaultPermissions.RolePermissions == nil {
 		topic.DefaultPermissions.RolePermissions = map[string]PermissionsMap{}
 	}
+	if topic.DefaultPermissions.UserDenies == nil {
+		topic.DefaultPermissions.UserDenies = map[string]PermissionsMap{}
+	}
+	if topic.DefaultPermissions.GroupDenies == nil {
+		topic.DefaultPermissions.GroupDenies = map[string]PermissionsMap{}
+	}
+	if topic.DefaultPermissions.RoleDenies == nil {
+		topic.DefaultPermissions.RoleDenies = map[string]PermissionsMap{}
+	}
 
 	if !reflect.DeepEqual(this.DefaultPermissions, topic.DefaultPermissions) {
 		return false
@@ -17,14 +17,139 @@
 package model
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
+// TotalCountHeader contains the number of elements of a whole list, if requested with the query parameter total=true
+const TotalCountHeader = "X-Total-Count"
+
 type ListOptions struct {
 	Limit  int64 // 0 -> unlimited
 	Offset int64
 	Ids    []string
+	Cursor string // optional; continues a list after the last element of the previous page (see NextCursor); may not be combined with Offset
+
+	IdPrefix string // optional; lists only elements with an id starting with the prefix
+	IdRegex  string // optional; lists only elements with an id matching the regular expression; may not be combined with IdPrefix; only usable by admins
+
+	SortBy   string // SortById (default) or SortByTimestamp; cursors are only supported for SortById
+	SortDesc bool
+}
+
+// MaxIdRegexLength limits the length of ListOptions.IdRegex
+const MaxIdRegexLength = 256
+
+const SortById = "id"
+const SortByTimestamp = "timestamp" //time of the last change; resources: permissions, topics: topic config
+
+// NextCursor returns the opaque cursor to request the page following the element with the given id
+func NextCursor(lastId string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastId))
+}
+
+// After returns the id after which the list continues; "" if no cursor is set
+func (this ListOptions) After() (id string, err error) {
+	if this.Cursor == "" {
+		return "", nil
+	}
+	temp, err := base64.RawURLEncoding.DecodeString(this.Cursor)
+	if err != nil {
+		return "", errors.New("invalid cursor")
+	}
+	return string(temp), nil
+}
+
+func (this ListOptions) Validate() error {
+	if this.Cursor != "" && this.Offset > 0 {
+		return errors.New("cursor can not be combined with offset")
+	}
+	if this.SortBy != "" && this.SortBy != SortById && this.SortBy != SortByTimestamp {
+		return fmt.Errorf("unknown sort field '%v'", this.SortBy)
+	}
+	if this.Cursor != "" && this.SortBy == SortByTimestamp {
+		return errors.New("cursor can only be used with lists sorted by id")
+	}
+	if this.IdPrefix != "" && this.IdRegex != "" {
+		return errors.New("id_prefix can not be combined with id_regex")
+	}
+	if len(this.IdRegex) > MaxIdRegexLength {
+		return fmt.Errorf("id_regex may not be longer than %v characters", MaxIdRegexLength)
+	}
+	if this.IdRegex != "" {
+		_, err := regexp.Compile(this.IdRegex)
+		if err != nil {
+			return fmt.Errorf("invalid id_regex: %w", err)
+		}
+	}
+	_, err := this.After()
+	return err
+}
+
+// NextPage returns the options for the page following a page with the given element count and last element id;
+// ok is false if the page was not limited or not full, which means that no further page exists.
+// lists sorted by id continue with a cursor, other lists with an offset.
+func (this ListOptions) NextPage(count int, lastId string) (next ListOptions, ok bool) {
+	if this.Limit <= 0 || int64(count) < this.Limit {
+		return this, false
+	}
+	next = this
+	if this.SortBy == SortByTimestamp {
+		next.Offset = this.Offset + int64(count)
+		return next, true
+	}
+	next.Offset = 0
+	next.Cursor = NextCursor(lastId)
+	return next, true
+}
+
+// MatchesId returns true if the id matches IdPrefix and IdRegex
+func (this ListOptions) MatchesId(id string) bool {
+	if !strings.HasPrefix(id, this.IdPrefix) {
+		return false
+	}
+	if this.IdRegex != "" {
+		matches, err := regexp.MatchString(this.IdRegex, id)
+		return err == nil && matches
+	}
+	return true
+}
+
+// Query returns the url query parameters of the options, as parsed by ListOptionsFromQuery; Ids are not included
+func (this ListOptions) Query() url.Values {
+	query := url.Values{}
+	if this.Limit > 0 {
+		query.Set("limit", strconv.FormatInt(this.Limit, 10))
+	}
+	if this.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(this.Offset, 10))
+	}
+	if this.Cursor != "" {
+		query.Set("cursor", this.Cursor)
+	}
+	if this.IdPrefix != "" {
+		query.Set("id_prefix", this.IdPrefix)
+	}
+	if this.IdRegex != "" {
+		query.Set("id_regex", this.IdRegex)
+	}
+	if this.SortBy != "" || this.SortDesc {
+		sortBy := this.SortBy
+		if sortBy == "" {
+			sortBy = SortById
+		}
+		if this.SortDesc {
+			query.Set("sort", sortBy+".desc")
+		} else {
+			query.Set("sort", sortBy+".asc")
+		}
+	}
+	return query
 }
 
 func ListOptionsFromQuery(q url.Values) (result ListOptions, err error) {
@@ -42,7 +167,22 @@ func ListOptionsFromQuery(q url.Values) (result ListOptions, err error) {
 			return result, err
 		}
 	}
-	return result, err
+	result.Cursor = q.Get("cursor")
+	result.IdPrefix = q.Get("id_prefix")
+	result.IdRegex = q.Get("id_regex")
+	sort := q.Get("sort")
+	if sort != "" {
+		field, direction, _ := strings.Cut(sort, ".")
+		switch direction {
+		case "", "asc":
+		case "desc":
+			result.SortDesc = true
+		default:
+			return result, fmt.Errorf("unknown sort direction '%v'", direction)
+		}
+		result.SortBy = field
+	}
+	return result, result.Validate()
 }
 
 type GetOptions struct {
@@ -52,3 +192,43 @@ type GetOptions struct {
 	RoleIds         []string
 	Permissions     PermissionList
 }
+
+type WriteOptions struct {
+	ExpectedVersion int64 // 0 -> no check; ExpectExisting -> the write fails with ErrVersionMismatch if the resource does not exist; otherwise the write fails with ErrVersionMismatch if the stored resource has another version
+
+	OnStored func(version int64) //optional; called by the controller with the new version after a successful set or patch; not called by the clients
+}
+
+// ExpectExisting may be used as WriteOptions.ExpectedVersion (If-Match: *) to only write resources, which exist in any version
+const ExpectExisting int64 = -1
+
+// ETag returns the version as strong http entity tag
+func ETag(version int64) string {
+	if version == ExpectExisting {
+		return "*"
+	}
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseETag returns the expected version of an If-Match value with an entity tag created by ETag; "" returns 0 (no check), "*" returns ExpectExisting.
+// weak entity tags are rejected, because If-Match uses the strong comparison.
+func ParseETag(etag string) (version int64, err error) {
+	etag = strings.TrimSpace(etag)
+	if etag == "" {
+		return 0, nil
+	}
+	if etag == "*" {
+		return ExpectExisting, nil
+	}
+	if strings.HasPrefix(etag, "W/") {
+		return 0, errors.New("weak entity tags can not be used with If-Match")
+	}
+	version, err = strconv.ParseInt(strings.Trim(etag, "\""), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if version < 0 {
+		return 0, errors.New("invalid version")
+	}
+	return version, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+type OutboxCommand string
+
+const (
+	OutboxCommandSetPermissions OutboxCommand = "set_permissions"
+	OutboxCommandDelete         OutboxCommand = "delete"
+	OutboxCommandWebhook        OutboxCommand = "webhook" //creates the deliveries of Webhook for all matching webhooks
+)
+
+// OutboxEvent is a pending kafka publish or webhook notification of a change.
+// events are relayed in order of Seq; events with the same Key() are never published out of order.
+type OutboxEvent struct {
+	Seq         int64               `json:"seq"`
+	Topic       Topic               `json:"topic"` //snapshot of the topic at the time of the change; allows publishing of deletions after the topic is removed
+	Id          string              `json:"id"`
+	Command     OutboxCommand       `json:"command"`
+	Permissions ResourcePermissions `json:"permissions"`
+	Owner       string              `json:"owner,omitempty"` //creator of the resource (Resource.Creator); published as owner of the rights command
+	CreatedAt   int64               `json:"created_at"`      //unix milliseconds
+	Attempts    int64               `json:"attempts"`        //count of failed publish attempts
+	NextAttempt int64               `json:"next_attempt"`    //unix milliseconds; set after failed publish attempts
+	LastError   string              `json:"last_error"`
+	Webhook     *WebhookPayload     `json:"webhook,omitempty"` //payload of OutboxCommandWebhook events
+}
+
+// Key groups events, which must be relayed in order; webhook notifications are not held back by failed kafka publishes
+func (this OutboxEvent) Key() string {
+	if this.Command == OutboxCommandWebhook {
+		return string(OutboxCommandWebhook) + ":" + this.Topic.Id + "/" + this.Id
+	}
+	return this.Topic.Id + "/" + this.Id
+}
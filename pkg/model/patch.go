@@ -0,0 +1,59 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+// PermissionsPatch changes individual entries of the ResourcePermissions of a resource.
+// a PermissionsMap replaces the entry of the user, group or role; null removes the entry; entries not listed are unchanged.
+type PermissionsPatch struct {
+	UserPermissions  map[string]*PermissionsMap `json:"user_permissions,omitempty"`
+	GroupPermissions map[string]*PermissionsMap `json:"group_permissions,omitempty"`
+	RolePermissions  map[string]*PermissionsMap `json:"role_permissions,omitempty"`
+
+	UserDenies  map[string]*PermissionsMap `json:"user_denies,omitempty"`
+	GroupDenies map[string]*PermissionsMap `json:"group_denies,omitempty"`
+	RoleDenies  map[string]*PermissionsMap `json:"role_denies,omitempty"`
+}
+
+// Apply returns a copy of the permissions with the patch applied; the parent is unchanged
+func (this PermissionsPatch) Apply(permissions ResourcePermissions) ResourcePermissions {
+	apply := func(current map[string]PermissionsMap, patch map[string]*PermissionsMap) map[string]PermissionsMap {
+		if current == nil && len(patch) == 0 {
+			return nil
+		}
+		result := map[string]PermissionsMap{}
+		for key, value := range current {
+			result[key] = value
+		}
+		for key, value := range patch {
+			if value == nil {
+				delete(result, key)
+			} else {
+				result[key] = *value
+			}
+		}
+		return result
+	}
+	return ResourcePermissions{
+		UserPermissions:  apply(permissions.UserPermissions, this.UserPermissions),
+		GroupPermissions: apply(permissions.GroupPermissions, this.GroupPermissions),
+		RolePermissions:  apply(permissions.RolePermissions, this.RolePermissions),
+		UserDenies:       apply(permissions.UserDenies, this.UserDenies),
+		GroupDenies:      apply(permissions.GroupDenies, this.GroupDenies),
+		RoleDenies:       apply(permissions.RoleDenies, this.RoleDenies),
+		Parent:           permissions.Parent,
+	}
+}
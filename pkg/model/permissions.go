@@ -18,6 +18,8 @@ package model
 
 import (
 	"fmt"
+	"slices"
+	"time"
 )
 
 type Permission rune
@@ -27,16 +29,32 @@ const Write Permission = 'w'        //user may write the resource (metadata)(e.g
 const Administrate Permission = 'a' // user may delete resource; user may change resource rights (e.g. delete device)
 const Execute Permission = 'x'      //user may use the resource (e.g. cmd to device; read device data; read database)
 
+// IsBuiltIn returns true for the permissions every topic supports (r, w, x and a)
+func (this Permission) IsBuiltIn() bool {
+	switch this {
+	case Read, Write, Execute, Administrate:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValid returns true for ascii letters, which may be used as built-in or custom permissions
+// custom permissions must be declared by the topic (Topic.CustomPermissions) to be usable
+func (this Permission) IsValid() bool {
+	return (this >= 'a' && this <= 'z') || (this >= 'A' && this <= 'Z')
+}
+
 type PermissionList []Permission
 
+var BuiltInPermissions = PermissionList{Read, Write, Execute, Administrate}
+
 func PermissionListFromString(str string) (result PermissionList, err error) {
 	for _, p := range str {
-		switch Permission(p) {
-		case Read, Write, Execute, Administrate:
-			result = append(result, Permission(p))
-		default:
-			return result, fmt.Errorf("unknown permission '%v'", p)
+		if !Permission(p).IsValid() {
+			return result, fmt.Errorf("unknown permission '%v'", string(p))
 		}
+		result = append(result, Permission(p))
 	}
 	return result, nil
 }
@@ -45,6 +63,43 @@ func (this PermissionList) Encode() string {
 	return string(this)
 }
 
+// PermissionMode defines how a list of permissions is evaluated by checks and lists
+type PermissionMode string
+
+const (
+	PermissionModeAll PermissionMode = "all" //all permissions must be granted; default
+	PermissionModeAny PermissionMode = "any" //at least one of the permissions must be granted
+)
+
+// PermissionModeFromString parses the mode; "" returns PermissionModeAll
+func PermissionModeFromString(str string) (PermissionMode, error) {
+	switch PermissionMode(str) {
+	case "", PermissionModeAll:
+		return PermissionModeAll, nil
+	case PermissionModeAny:
+		return PermissionModeAny, nil
+	default:
+		return "", fmt.Errorf("unknown permission mode '%v'", str)
+	}
+}
+
+// Matches evaluates the permissions with the mode; granted is called for each permission until the result is known.
+// an empty permission list always matches.
+func (this PermissionMode) Matches(permissions PermissionList, granted func(permission Permission) bool) bool {
+	if len(permissions) == 0 {
+		return true
+	}
+	if this == PermissionModeAny {
+		return slices.ContainsFunc(permissions, granted)
+	}
+	for _, permission := range permissions {
+		if !granted(permission) {
+			return false
+		}
+	}
+	return true
+}
+
 type GroupPermissions struct {
 	GroupName string `json:"group_name"`
 	PermissionsMap
@@ -54,29 +109,239 @@ type Resource struct {
 	Id      string `json:"id"`
 	TopicId string `json:"topic_id"`
 	ResourcePermissions
+
+	EffectivePermissions *ResourcePermissions `json:"effective_permissions,omitempty"` //set by the database if permissions are inherited from a parent; ignored on updates
+
+	Version int64 `json:"version"` //set by the database; incremented on every change of the resource permissions; ignored on updates (use WriteOptions)
+
+	Creator string `json:"creator,omitempty"` //user who created the resource; stored on creation and kept on updates; empty for resources created before it was recorded
+}
+
+// Effective returns the permissions including the inherited permissions of the parent chain
+func (this Resource) Effective() ResourcePermissions {
+	if this.EffectivePermissions != nil {
+		return *this.EffectivePermissions
+	}
+	return this.ResourcePermissions
+}
+
+type ResourceReference struct {
+	TopicId string `json:"topic_id"`
+	Id      string `json:"id"`
 }
 
 type ResourcePermissions struct {
 	UserPermissions  map[string]PermissionsMap `json:"user_permissions"`
 	GroupPermissions map[string]PermissionsMap `json:"group_permissions"`
 	RolePermissions  map[string]PermissionsMap `json:"role_permissions"`
+
+	//denies override grants of the same ResourcePermissions; a true flag in a PermissionsMap denies the permission
+	//denies in topic default permissions override the topic default grants and all grants of resources in the topic
+	//nil deny maps are replaced by the currently stored denies on updates by non-admin users
+	UserDenies  map[string]PermissionsMap `json:"user_denies"`
+	GroupDenies map[string]PermissionsMap `json:"group_denies"`
+	RoleDenies  map[string]PermissionsMap `json:"role_denies"`
+
+	//optional; the resource inherits the permissions of the parent; own entries override inherited entries of the same user, group or role
+	//nil is replaced by the currently stored parent on updates by non-admin users; an empty reference removes the parent
+	Parent *ResourceReference `json:"parent,omitempty"`
 }
 
+// Valid returns true if the permissions contain an admin user or reference a parent.
+// admins may be inherited: the database rejects permissions with ErrInvalidParent, if the parent does not exist
+// or the effective permissions (including the inherited entries) contain no admin user.
 func (this ResourcePermissions) Valid() bool {
-	//needs at least one admin user
-	for _, r := range this.UserPermissions {
-		if r.Administrate {
+	if this.HasParent() {
+		return true
+	}
+	return this.HasAdminUser()
+}
+
+// HasAdminUser returns true if at least one user has the admin permission, which is not denied and does not expire.
+// the parent is not evaluated; use it on effective permissions to include inherited admins.
+func (this ResourcePermissions) HasAdminUser() bool {
+	for user, r := range this.UserPermissions {
+		if r.Administrate && r.ValidUntil == nil && !this.UserDenies[user].Administrate {
+			return true
+		}
+	}
+	return false
+}
+
+func (this ResourcePermissions) HasParent() bool {
+	return this.Parent != nil && *this.Parent != (ResourceReference{})
+}
+
+// Inherit returns the effective permissions of a resource with these (own) permissions and the effective permissions of its parent.
+// own entries override inherited entries of the same user, group or role.
+func (this ResourcePermissions) Inherit(parent ResourcePermissions) (result ResourcePermissions) {
+	merge := func(inherited map[string]PermissionsMap, own map[string]PermissionsMap) map[string]PermissionsMap {
+		if inherited == nil && own == nil {
+			return nil
+		}
+		merged := map[string]PermissionsMap{}
+		for key, value := range inherited {
+			merged[key] = value
+		}
+		for key, value := range own {
+			merged[key] = value
+		}
+		return merged
+	}
+	return ResourcePermissions{
+		UserPermissions:  merge(parent.UserPermissions, this.UserPermissions),
+		GroupPermissions: merge(parent.GroupPermissions, this.GroupPermissions),
+		RolePermissions:  merge(parent.RolePermissions, this.RolePermissions),
+		UserDenies:       merge(parent.UserDenies, this.UserDenies),
+		GroupDenies:      merge(parent.GroupDenies, this.GroupDenies),
+		RoleDenies:       merge(parent.RoleDenies, this.RoleDenies),
+		Parent:           this.Parent,
+	}
+}
+
+// IsGranted returns true if any of the currently active grants matches the user, one of the roles or one of the groups for the permission
+// denies are not evaluated
+func (this ResourcePermissions) IsGranted(userId string, roleIds []string, groupIds []string, permission Permission) bool {
+	return matchesAny(this.UserPermissions, this.RolePermissions, this.GroupPermissions, userId, roleIds, groupIds, permission, time.Now())
+}
+
+// IsDenied returns true if any of the currently active deny entries matches the user, one of the roles or one of the groups for the permission
+func (this ResourcePermissions) IsDenied(userId string, roleIds []string, groupIds []string, permission Permission) bool {
+	return matchesAny(this.UserDenies, this.RoleDenies, this.GroupDenies, userId, roleIds, groupIds, permission, time.Now())
+}
+
+func matchesAny(users map[string]PermissionsMap, roles map[string]PermissionsMap, groups map[string]PermissionsMap, userId string, roleIds []string, groupIds []string, permission Permission, t time.Time) bool {
+	if entry := users[userId]; entry.Has(permission) && entry.IsActive(t) {
+		return true
+	}
+	for _, role := range roleIds {
+		if entry := roles[role]; entry.Has(permission) && entry.IsActive(t) {
+			return true
+		}
+	}
+	for _, group := range groupIds {
+		if entry := groups[group]; entry.Has(permission) && entry.IsActive(t) {
 			return true
 		}
 	}
 	return false
 }
 
+// RemoveExpired returns a copy of the permissions without entries that expired until t;
+// entries that became active until t lose their ValidFrom limit.
+// changed is true if any entry was removed or updated.
+func (this ResourcePermissions) RemoveExpired(t time.Time) (result ResourcePermissions, changed bool) {
+	result = this
+	for _, m := range []*map[string]PermissionsMap{&result.UserPermissions, &result.GroupPermissions, &result.RolePermissions, &result.UserDenies, &result.GroupDenies, &result.RoleDenies} {
+		if *m == nil {
+			continue
+		}
+		updated := map[string]PermissionsMap{}
+		for key, entry := range *m {
+			if entry.ValidUntil != nil && !entry.ValidUntil.After(t) {
+				changed = true
+				continue
+			}
+			if entry.ValidFrom != nil && !entry.ValidFrom.After(t) {
+				changed = true
+				entry.ValidFrom = nil
+			}
+			updated[key] = entry
+		}
+		*m = updated
+	}
+	return result, changed
+}
+
 type PermissionsMap struct {
 	Read         bool `json:"read"`
 	Write        bool `json:"write"`
 	Execute      bool `json:"execute"`
 	Administrate bool `json:"administrate"`
+
+	Custom map[string]bool `json:"custom,omitempty"` //custom permissions declared by the topic; key is the permission letter
+
+	ValidFrom  *time.Time `json:"valid_from,omitempty"`  //optional; the entry is ignored before this time
+	ValidUntil *time.Time `json:"valid_until,omitempty"` //optional; the entry is ignored from this time on and will be removed
+}
+
+// IsActive returns false if t is outside the optional ValidFrom/ValidUntil time frame
+func (this PermissionsMap) IsActive(t time.Time) bool {
+	if this.ValidFrom != nil && t.Before(*this.ValidFrom) {
+		return false
+	}
+	if this.ValidUntil != nil && !t.Before(*this.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// IsTimeBound returns true if the entry is limited by ValidFrom or ValidUntil
+func (this PermissionsMap) IsTimeBound() bool {
+	return this.ValidFrom != nil || this.ValidUntil != nil
+}
+
+func (this PermissionsMap) Has(permission Permission) bool {
+	switch permission {
+	case Read:
+		return this.Read
+	case Write:
+		return this.Write
+	case Execute:
+		return this.Execute
+	case Administrate:
+		return this.Administrate
+	default:
+		return this.Custom[string(permission)]
+	}
+}
+
+// With returns a copy of the PermissionsMap with the permission set to value
+func (this PermissionsMap) With(permission Permission, value bool) PermissionsMap {
+	switch permission {
+	case Read:
+		this.Read = value
+	case Write:
+		this.Write = value
+	case Execute:
+		this.Execute = value
+	case Administrate:
+		this.Administrate = value
+	default:
+		custom := map[string]bool{}
+		for key, v := range this.Custom {
+			if v {
+				custom[key] = true
+			}
+		}
+		if value {
+			custom[string(permission)] = true
+		} else {
+			delete(custom, string(permission))
+		}
+		this.Custom = custom
+		if len(this.Custom) == 0 {
+			this.Custom = nil
+		}
+	}
+	return this
+}
+
+// List returns the granted permissions; built-in permissions first, followed by the sorted custom permissions
+func (this PermissionsMap) List() (result PermissionList) {
+	for _, p := range BuiltInPermissions {
+		if this.Has(p) {
+			result = append(result, p)
+		}
+	}
+	custom := PermissionList{}
+	for key, value := range this.Custom {
+		if value && len(key) == 1 && Permission(key[0]).IsValid() && !Permission(key[0]).IsBuiltIn() {
+			custom = append(custom, Permission(key[0]))
+		}
+	}
+	slices.Sort(custom)
+	return append(result, custom...)
 }
 
 type ComputedPermissions struct {
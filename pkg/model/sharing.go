@@ -0,0 +1,174 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SharingPolicy limits the permissions, users who are no admins may grant to others on resources of the topic.
+// only permissions, which are not already granted by the stored entry of the same user, group or role, are limited.
+// platform admins and delegated topic admins are not affected.
+type SharingPolicy struct {
+	//optional; upper limits of the permissions, which may be granted to users, groups or roles; nil means no limit
+	//administrate is not limited by these maps but by AllowAdminDelegation
+	MaxUserPermissions  *PermissionsMap `json:"max_user_permissions,omitempty"`
+	MaxGroupPermissions *PermissionsMap `json:"max_group_permissions,omitempty"`
+	MaxRolePermissions  *PermissionsMap `json:"max_role_permissions,omitempty"`
+
+	AllowedRoles         []string `json:"allowed_roles"`          //roles, which may be granted permissions; empty: no role
+	AllowGroups          bool     `json:"allow_groups"`           //groups, which have no entry yet, may be added
+	AllowAdminDelegation bool     `json:"allow_admin_delegation"` //the administrate permission may be granted
+}
+
+const SharingRuleMaxPermissions = "max_permissions"              //the permission exceeds the Max*Permissions of the grantee kind
+const SharingRuleAllowedRoles = "allowed_roles"                  //the role is not in AllowedRoles
+const SharingRuleAllowGroups = "allow_groups"                    //groups may not be added
+const SharingRuleAllowAdminDelegation = "allow_admin_delegation" //administrate may not be granted
+
+// SharingPolicyViolation describes a grant, which is rejected by the sharing policy of the topic
+type SharingPolicyViolation struct {
+	Rule        string `json:"rule"`        //SharingRuleMaxPermissions, SharingRuleAllowedRoles, SharingRuleAllowGroups or SharingRuleAllowAdminDelegation
+	Kind        string `json:"kind"`        //RuleKindUser, RuleKindGroup or RuleKindRole
+	Subject     string `json:"subject"`     //user, group or role id of the entry
+	Permissions string `json:"permissions"` //rejected permissions; e.g. "wa"
+}
+
+// SharingPolicyError is returned (with http.StatusForbidden) if a change of resource permissions violates the sharing policy of the topic.
+// the api responds with this error as json body; errors.Is(err, ErrSharingPolicyViolation) is true.
+type SharingPolicyError struct {
+	Message    string                   `json:"error"`
+	Violations []SharingPolicyViolation `json:"violations"`
+}
+
+func NewSharingPolicyError(violations []SharingPolicyViolation) *SharingPolicyError {
+	descriptions := []string{}
+	for _, violation := range violations {
+		descriptions = append(descriptions, fmt.Sprintf("%v '%v' may not be granted '%v' (%v)", violation.Kind, violation.Subject, violation.Permissions, violation.Rule))
+	}
+	return &SharingPolicyError{
+		Message:    ErrSharingPolicyViolation.Error() + ": " + strings.Join(descriptions, "; "),
+		Violations: violations,
+	}
+}
+
+func (this *SharingPolicyError) Error() string {
+	return this.Message
+}
+
+func (this *SharingPolicyError) Unwrap() error {
+	return ErrSharingPolicyViolation
+}
+
+// SharingPolicyErrorFromResponse returns the *SharingPolicyError of an error response body, if the body contains one
+func SharingPolicyErrorFromResponse(body []byte) (result *SharingPolicyError, ok bool) {
+	result = &SharingPolicyError{}
+	err := json.Unmarshal(body, result)
+	if err != nil || len(result.Violations) == 0 {
+		return nil, false
+	}
+	return result, true
+}
+
+func (this SharingPolicy) Validate() error {
+	for _, max := range []*PermissionsMap{this.MaxUserPermissions, this.MaxGroupPermissions, this.MaxRolePermissions} {
+		if max == nil {
+			continue
+		}
+		if max.IsTimeBound() {
+			return errors.New("max permissions of the sharing policy may not be time bound")
+		}
+		if max.Administrate {
+			return errors.New("max permissions of the sharing policy may not contain administrate; use allow_admin_delegation")
+		}
+	}
+	if slices.Contains(this.AllowedRoles, "") {
+		return errors.New("sharing policy contains an empty role")
+	}
+	return nil
+}
+
+// Check returns a *SharingPolicyError with all violations, if permissions grants more than allowed compared to the current permissions.
+// denies and removed entries are not limited. if an entry is valid for a wider time frame than the stored entry, all its permissions count as added.
+func (this SharingPolicy) Check(current ResourcePermissions, permissions ResourcePermissions) error {
+	violations := []SharingPolicyViolation{}
+	check := func(kind string, max *PermissionsMap, stored map[string]PermissionsMap, requested map[string]PermissionsMap) {
+		subjects := []string{}
+		for subject := range requested {
+			subjects = append(subjects, subject)
+		}
+		slices.Sort(subjects)
+		for _, subject := range subjects {
+			previous, exists := stored[subject]
+			extended := requested[subject].extendsValidityOf(previous)
+			added := PermissionList{}
+			for _, permission := range requested[subject].List() {
+				if extended || !previous.Has(permission) {
+					added = append(added, permission)
+				}
+			}
+			if len(added) == 0 {
+				continue
+			}
+			if kind == RuleKindGroup && !exists && !this.AllowGroups {
+				violations = append(violations, SharingPolicyViolation{Rule: SharingRuleAllowGroups, Kind: kind, Subject: subject, Permissions: added.Encode()})
+				continue
+			}
+			if kind == RuleKindRole && !slices.Contains(this.AllowedRoles, subject) {
+				violations = append(violations, SharingPolicyViolation{Rule: SharingRuleAllowedRoles, Kind: kind, Subject: subject, Permissions: added.Encode()})
+				continue
+			}
+			exceeding := PermissionList{}
+			for _, permission := range added {
+				if permission == Administrate {
+					if !this.AllowAdminDelegation {
+						violations = append(violations, SharingPolicyViolation{Rule: SharingRuleAllowAdminDelegation, Kind: kind, Subject: subject, Permissions: string(Administrate)})
+					}
+					continue
+				}
+				if max != nil && !max.Has(permission) {
+					exceeding = append(exceeding, permission)
+				}
+			}
+			if len(exceeding) > 0 {
+				violations = append(violations, SharingPolicyViolation{Rule: SharingRuleMaxPermissions, Kind: kind, Subject: subject, Permissions: exceeding.Encode()})
+			}
+		}
+	}
+	check(RuleKindUser, this.MaxUserPermissions, current.UserPermissions, permissions.UserPermissions)
+	check(RuleKindGroup, this.MaxGroupPermissions, current.GroupPermissions, permissions.GroupPermissions)
+	check(RuleKindRole, this.MaxRolePermissions, current.RolePermissions, permissions.RolePermissions)
+	if len(violations) > 0 {
+		return NewSharingPolicyError(violations)
+	}
+	return nil
+}
+
+// extendsValidityOf returns true if the entry is valid outside the ValidFrom/ValidUntil time frame of the previous entry
+func (this PermissionsMap) extendsValidityOf(previous PermissionsMap) bool {
+	if previous.ValidFrom != nil && (this.ValidFrom == nil || this.ValidFrom.Before(*previous.ValidFrom)) {
+		return true
+	}
+	if previous.ValidUntil != nil && (this.ValidUntil == nil || this.ValidUntil.After(*previous.ValidUntil)) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2025 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookEventSetResource    WebhookEventType = "set_resource"
+	WebhookEventRemoveResource WebhookEventType = "remove_resource"
+	WebhookEventSetTopic       WebhookEventType = "set_topic"
+	WebhookEventRemoveTopic    WebhookEventType = "remove_topic"
+)
+
+var WebhookEventTypes = []WebhookEventType{WebhookEventSetResource, WebhookEventRemoveResource, WebhookEventSetTopic, WebhookEventRemoveTopic}
+
+// headers of webhook callbacks
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp" //unix seconds of the attempt
+	WebhookSignatureHeader = "X-Webhook-Signature" //"sha256=" followed by the hex encoded hmac of SignWebhook
+)
+
+// Webhook is a subscription of an url to permission and topic changes
+type Webhook struct {
+	Id     string `json:"id"`
+	Url    string `json:"url"`
+	Secret string `json:"secret,omitempty"` //key of the callback signatures; write only; an empty secret keeps the stored secret on updates
+
+	TopicIds []string           `json:"topic_ids,omitempty"` //optional; empty -> all topics
+	Events   []WebhookEventType `json:"events,omitempty"`    //optional; empty -> all events
+
+	Disabled bool `json:"disabled"` //no new deliveries are created for disabled webhooks
+}
+
+func (this Webhook) Validate() error {
+	if this.Id == "" {
+		return errors.New("id is required")
+	}
+	if this.Secret == "" {
+		return errors.New("secret is required")
+	}
+	u, err := url.Parse(this.Url)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https url")
+	}
+	for _, event := range this.Events {
+		if !slices.Contains(WebhookEventTypes, event) {
+			return fmt.Errorf("unknown webhook event '%v'", event)
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the webhook is enabled and subscribed to the event of the topic
+func (this Webhook) Matches(event WebhookEventType, topicId string) bool {
+	if this.Disabled {
+		return false
+	}
+	if len(this.TopicIds) > 0 && !slices.Contains(this.TopicIds, topicId) {
+		return false
+	}
+	return len(this.Events) == 0 || slices.Contains(this.Events, event)
+}
+
+// WebhookPayload is the json body of a webhook callback.
+// resource events set ResourceId (and Permissions for set_resource); topic events set Topic for set_topic.
+type WebhookPayload struct {
+	Event       WebhookEventType     `json:"event"`
+	Timestamp   int64                `json:"timestamp"` //unix milliseconds of the change
+	UserId      string               `json:"user_id"`   //acting user; empty for changes by the service itself
+	TopicId     string               `json:"topic_id"`
+	ResourceId  string               `json:"resource_id,omitempty"`
+	Permissions *ResourcePermissions `json:"permissions,omitempty"`
+	Topic       *Topic               `json:"topic,omitempty"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" //no further attempts; may be retried manually
+)
+
+// WebhookDelivery is a pending or finished callback of a webhook; finished deliveries form the delivery log
+type WebhookDelivery struct {
+	Id        string                `json:"id"`
+	WebhookId string                `json:"webhook_id"`
+	Payload   WebhookPayload        `json:"payload"`
+	Status    WebhookDeliveryStatus `json:"status"`
+	CreatedAt int64                 `json:"created_at"` //unix milliseconds
+
+	Attempts       int64  `json:"attempts"`
+	NextAttempt    int64  `json:"next_attempt"`     //unix milliseconds; next attempt of pending deliveries
+	LastAttempt    int64  `json:"last_attempt"`     //unix milliseconds
+	LastStatusCode int    `json:"last_status_code"` //http status code of the last attempt; 0 if no response was received
+	LastError      string `json:"last_error"`
+}
+
+type WebhookDeliveryListOptions struct {
+	Limit  int64 // 0 -> unlimited
+	Offset int64
+
+	Status WebhookDeliveryStatus //optional filter
+}
+
+func WebhookDeliveryListOptionsFromQuery(q url.Values) (result WebhookDeliveryListOptions, err error) {
+	listOptions, err := ListOptionsFromQuery(q)
+	if err != nil {
+		return result, err
+	}
+	result.Limit = listOptions.Limit
+	result.Offset = listOptions.Offset
+	result.Status = WebhookDeliveryStatus(q.Get("status"))
+	switch result.Status {
+	case "", WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed:
+		return result, nil
+	default:
+		return result, fmt.Errorf("unknown delivery status '%v'", result.Status)
+	}
+}
+
+// SignWebhook returns the hex encoded hmac-sha256 of "<timestamp>.<body>", which is sent in the WebhookSignatureHeader (prefixed with "sha256=")
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
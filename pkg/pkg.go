@@ -23,6 +23,7 @@ import (
 	"github.com/SENERGY-Platform/permissions-v2/pkg/controller"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/controller/kafka"
 	"github.com/SENERGY-Platform/permissions-v2/pkg/database"
+	"github.com/SENERGY-Platform/permissions-v2/pkg/grpcapi"
 )
 
 func Start(ctx context.Context, config configuration.Config) error {
@@ -38,5 +39,9 @@ func Start(ctx context.Context, config configuration.Config) error {
 	if err != nil {
 		return err
 	}
+	err = grpcapi.Start(ctx, config, ctrl)
+	if err != nil {
+		return err
+	}
 	return nil
 }
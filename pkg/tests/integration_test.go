@@ -297,21 +297,25 @@ func TestOptionalPublish(t *testing.T) {
 		})
 	})
 
-	t.Run("check synced marks", func(t *testing.T) {
+	t.Run("check outbox", func(t *testing.T) {
 		db, err := database.New(config)
 		if err != nil {
 			t.Error(err)
 			return
 		}
-		list, err := db.ListUnsyncedResources(nil)
-		if err != nil {
-			t.Error(err)
-			return
-		}
-		if len(list) > 0 {
-			t.Error(list)
-			return
+		//the relay publishes the events asynchronously
+		var list []model.OutboxEvent
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+			list, err = db.ListOutboxEvents(nil, 0, 0)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(list) == 0 {
+				return
+			}
 		}
+		t.Error(list)
 	})
 
 	t.Run("check consumed", func(t *testing.T) {
@@ -732,7 +736,7 @@ func RunTestsWithClient(config configuration.Config, c client.Client) func(t *te
 
 		t.Run("try deleted topic", func(t *testing.T) {
 			t.Run("try to_be_deleted", func(t *testing.T) {
-				_, err, code := c.SetPermission(TestToken, "to_be_deleted", "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {true, true, true, true}}})
+				_, err, code := c.SetPermission(TestToken, "to_be_deleted", "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}}})
 				if err == nil {
 					t.Error("expect error")
 					return
@@ -764,7 +768,7 @@ func RunTestsWithClient(config configuration.Config, c client.Client) func(t *te
 			})
 
 			t.Run("try to_be_deleted_2", func(t *testing.T) {
-				_, err, code := c.SetPermission(TestToken, "to_be_deleted_2", "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {true, true, true, true}}})
+				_, err, code := c.SetPermission(TestToken, "to_be_deleted_2", "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}}})
 				if err == nil {
 					t.Error("expect error")
 					return
@@ -806,7 +810,7 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 
 			t.Run("initial permissions set", func(t *testing.T) {
 				_, err, code := c.SetPermission(client.InternalAdminToken, topicId, "b", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
 					RolePermissions:  nil,
 				})
@@ -820,9 +824,9 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 				}
 
 				_, err, code = c.SetPermission(client.InternalAdminToken, topicId, "buseradmin", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
-					RolePermissions:  map[string]model.PermissionsMap{"user": {true, true, true, true}},
+					RolePermissions:  map[string]model.PermissionsMap{"user": {Read: true, Write: true, Execute: true, Administrate: true}},
 				})
 				if err != nil {
 					t.Error(err)
@@ -834,7 +838,7 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 				}
 
 				_, err, code = c.SetPermission(client.InternalAdminToken, topicId, "c", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
 					RolePermissions:  nil,
 				})
@@ -850,7 +854,7 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 
 			t.Run("update permissions", func(t *testing.T) {
 				_, err, code := c.SetPermission(client.InternalAdminToken, topicId, "a", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}, SecendOwnerTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}, SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
 					RolePermissions:  nil,
 				})
@@ -863,7 +867,7 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 					return
 				}
 				_, err, code = c.SetPermission(TestToken, topicId, "c", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}, SecendOwnerTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}, SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
 					RolePermissions:  map[string]model.PermissionsMap{},
 				})
@@ -950,9 +954,9 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 					user := SecondOwnerToken
 					token, _ := jwt.Parse(user)
 					_, err, code := c.SetPermission(user, topicId, id, model.ResourcePermissions{
-						UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}, SecendOwnerTokenUser: {true, true, true, true}},
+						UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}, SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 						GroupPermissions: nil,
-						RolePermissions:  map[string]model.PermissionsMap{"user": {true, true, true, true}, "g2": {true, true, true, true}},
+						RolePermissions:  map[string]model.PermissionsMap{"user": {Read: true, Write: true, Execute: true, Administrate: true}, "g2": {Read: true, Write: true, Execute: true, Administrate: true}},
 					})
 					if access {
 						if err != nil {
@@ -980,9 +984,9 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 
 		t.Run("prevent admin less resource", func(t *testing.T) {
 			_, err, _ := c.SetPermission(client.InternalAdminToken, topicId, "adminless", model.ResourcePermissions{
-				UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}, SecendOwnerTokenUser: {true, true, true, false}},
+				UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}, SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: false}},
 				GroupPermissions: nil,
-				RolePermissions:  map[string]model.PermissionsMap{"g2": {true, true, true, true}},
+				RolePermissions:  map[string]model.PermissionsMap{"g2": {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
@@ -990,9 +994,9 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 			}
 
 			_, err, _ = c.SetPermission(TestToken, topicId, "adminless", model.ResourcePermissions{
-				UserPermissions:  map[string]model.PermissionsMap{SecendOwnerTokenUser: {true, true, true, false}},
+				UserPermissions:  map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: false}},
 				GroupPermissions: nil,
-				RolePermissions:  map[string]model.PermissionsMap{"g2": {true, true, true, true}},
+				RolePermissions:  map[string]model.PermissionsMap{"g2": {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err == nil {
 				t.Error("expect error")
@@ -1000,9 +1004,9 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 			}
 
 			_, err, _ = c.SetPermission(TestToken, topicId, "adminless", model.ResourcePermissions{
-				UserPermissions:  map[string]model.PermissionsMap{SecendOwnerTokenUser: {true, true, true, true}},
+				UserPermissions:  map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 				GroupPermissions: nil,
-				RolePermissions:  map[string]model.PermissionsMap{"g2": {true, true, true, true}},
+				RolePermissions:  map[string]model.PermissionsMap{"g2": {Read: true, Write: true, Execute: true, Administrate: true}},
 			})
 			if err != nil {
 				t.Error(err)
@@ -1013,23 +1017,23 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 		t.Run("check permissions", func(t *testing.T) {
 			t.Run("init permissions", func(t *testing.T) {
 				_, err, _ := c.SetPermission(client.InternalAdminToken, topicId, "1", model.ResourcePermissions{
-					UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+					UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 				})
 				if err != nil {
 					t.Error(err)
 					return
 				}
 				_, err, _ = c.SetPermission(client.InternalAdminToken, topicId, "2", model.ResourcePermissions{
-					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}},
+					UserPermissions:  map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 					GroupPermissions: nil,
-					RolePermissions:  map[string]model.PermissionsMap{"user": {true, true, true, true}},
+					RolePermissions:  map[string]model.PermissionsMap{"user": {Read: true, Write: true, Execute: true, Administrate: true}},
 				})
 				if err != nil {
 					t.Error(err)
 					return
 				}
 				_, err, _ = c.SetPermission(client.InternalAdminToken, topicId, "3", model.ResourcePermissions{
-					UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {true, true, true, true}, SecendOwnerTokenUser: {true, true, true, true}},
+					UserPermissions: map[string]model.PermissionsMap{TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}, SecendOwnerTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}},
 				})
 				if err != nil {
 					t.Error(err)
@@ -1320,7 +1324,7 @@ func RunTestsWithTopic(config configuration.Config, c client.Client, topicId str
 					return
 				}
 
-				_, err, code = c.SetPermission(TestToken, topicId, "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {true, true, true, true}}})
+				_, err, code = c.SetPermission(TestToken, topicId, "nope", model.ResourcePermissions{UserPermissions: map[string]model.PermissionsMap{SecendOwnerTokenUser: {Read: true}, TestTokenUser: {Read: true, Write: true, Execute: true, Administrate: true}}})
 				if err == nil {
 					t.Error("expect error")
 					return